@@ -1,30 +1,120 @@
 package main
 
 import (
+	"context"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
 	"jadesheart/comix_back/internal/config"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/add_bookmark"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/add_to_reading_list"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/approve_upload"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/create_author"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/create_comment"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/create_reading_list"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/create_upload"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/create_webhook"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/delete_author"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/delete_bookmark"
 	"jadesheart/comix_back/internal/http-server/handlers/comix/delete_comix"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/delete_comment"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/delete_page"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/delete_rating"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/delete_reading_list"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/delete_tag"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/delete_upload"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/delete_webhook"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/download_comix"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/edit_author"
 	"jadesheart/comix_back/internal/http-server/handlers/comix/edit_comix"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/edit_comix_meta"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/edit_comment"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/edit_reading_list"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/edit_tag"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/edit_tag_meta"
 	"jadesheart/comix_back/internal/http-server/handlers/comix/find_comix"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/finish_upload"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/get_all_authors"
 	"jadesheart/comix_back/internal/http-server/handlers/comix/get_all_tag_comix"
 	"jadesheart/comix_back/internal/http-server/handlers/comix/get_all_tags"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/get_audit_events"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/get_author_by_slug"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/get_author_comix"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/get_bookmarks"
 	"jadesheart/comix_back/internal/http-server/handlers/comix/get_comix"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/get_comix_by_slug"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/get_comix_cover"
 	"jadesheart/comix_back/internal/http-server/handlers/comix/get_comix_for_main_page"
 	"jadesheart/comix_back/internal/http-server/handlers/comix/get_comix_photo"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/get_comment_replies"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/get_comments"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/get_continue_reading"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/get_feed"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/get_meta"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/get_moderation_queue"
 	"jadesheart/comix_back/internal/http-server/handlers/comix/get_number_of_comics"
 	"jadesheart/comix_back/internal/http-server/handlers/comix/get_number_of_comix_form_name"
 	get_number_of_comics_from_tag "jadesheart/comix_back/internal/http-server/handlers/comix/get_number_of_comix_form_tag"
 	"jadesheart/comix_back/internal/http-server/handlers/comix/get_photo"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/get_rating"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/get_reading_list"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/get_reading_lists"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/get_releases"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/get_reports"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/get_reviews"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/get_sitemap"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/get_tag_by_slug"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/get_tag_cover"
 	"jadesheart/comix_back/internal/http-server/handlers/comix/get_tag_description"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/get_trash"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/get_trending_comix"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/get_upload"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/get_webhook_deliveries"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/get_webhooks"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/import_comix"
 	"jadesheart/comix_back/internal/http-server/handlers/comix/insert"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/insert_comix_cover"
 	"jadesheart/comix_back/internal/http-server/handlers/comix/insert_photo"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/insert_tag_cover"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/login_reader"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/logout_reader"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/merge_tags"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/rate_comix"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/register_reader"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/reject_upload"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/remove_comment"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/remove_from_reading_list"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/reorder_pages"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/reorder_reading_list"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/replace_page"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/replay_webhook_delivery"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/report_content"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/reschedule_release"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/resolve_report"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/restore_comix"
 	"jadesheart/comix_back/internal/http-server/handlers/comix/save"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/save_progress"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/set_comix_credits"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/upload_chunk"
+	mwCache "jadesheart/comix_back/internal/http-server/middleware/cache"
 	mnLogger "jadesheart/comix_back/internal/http-server/middleware/logger"
+	"jadesheart/comix_back/internal/http-server/middleware/ratelimit"
+	"jadesheart/comix_back/internal/http-server/middleware/session"
+	"jadesheart/comix_back/internal/lib/archive"
+	"jadesheart/comix_back/internal/lib/audit"
+	"jadesheart/comix_back/internal/lib/cache"
+	"jadesheart/comix_back/internal/lib/images"
 	"jadesheart/comix_back/internal/lib/logger/handlers/slogpretty"
 	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/lib/photos"
+	"jadesheart/comix_back/internal/lib/sitemap"
+	"jadesheart/comix_back/internal/lib/upload"
 	"jadesheart/comix_back/internal/storage/postgres"
+	"jadesheart/comix_back/internal/worker/pages"
+	"jadesheart/comix_back/internal/worker/publish"
+	"jadesheart/comix_back/internal/worker/purge"
+	"jadesheart/comix_back/internal/worker/uploads"
+	"jadesheart/comix_back/internal/worker/webhooks"
 	"log/slog"
 	"net/http"
 	"os"
@@ -42,6 +132,24 @@ func main() {
 
 	logger.Info("Successful init database")
 
+	// Страницы комиксов лежат в папке медиа: <тэг>/<комикс>/<файл страницы>. Порядок страниц хранится в базе.
+	mediaRoot, err := photos.NewRoot(cfg.Media.Root)
+	if err != nil {
+		logger.Error("Failed to init media root", sl.Err(err))
+		os.Exit(1)
+	}
+
+	pages.Backfill(logger, storage, mediaRoot)
+
+	uploadStore, err := upload.New(cfg.Upload.Dir, cfg.Upload.MaxSize)
+	if err != nil {
+		logger.Error("Failed to init upload store", sl.Err(err))
+		os.Exit(1)
+	}
+
+	cacheStore := setupCacheStore(logger, cfg.Cache)
+	responseCache := cache.New(cacheStore, cfg.Cache.TTL)
+
 	router := chi.NewRouter()
 
 	router.Use(middleware.RequestID)
@@ -50,28 +158,218 @@ func main() {
 	router.Use(middleware.URLFormat)
 	corsHandler := cors.New(cors.Options{
 		AllowedOrigins: []string{"*"},
-		AllowedMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders: []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
+		AllowedMethods: []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders: []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "If-None-Match", "If-Modified-Since", audit.ActorHeader,
+			upload.HeaderResumable, upload.HeaderOffset, upload.HeaderLength, upload.HeaderChecksum},
+		ExposedHeaders: []string{"Location", "Content-Disposition", "ETag", upload.HeaderResumable, upload.HeaderOffset, upload.HeaderLength},
 	})
 
 	// Добавляем обработчик CORS в цепочку middleware
 	router.Use(corsHandler.Handler)
-	router.Post("/newtag", save.New(logger, storage))
-	router.Post("/newcomix", insert.New(logger, storage))
-	router.Post("/insertphoto", insert_photo.New(logger, storage))
-	router.Post("/getcomix", get_comix.New(logger, storage))
-	router.Post("/gettagdescription", get_tag_description.New(logger, storage))
-	router.Post("/getmainpagecomix", get_comix_for_main_page.New(logger, storage))
-	router.Post("/getalltagcomix", get_all_tag_comix.New(logger, storage))
-	router.Post("/alltags", get_all_tags.New(logger, storage))
-	router.Post("/deletecomix", delete_comix.New(logger, storage))
-	router.Post("/findcomix", find_comix.New(logger, storage))
-	router.Post("/editcomix", edit_comix.New(logger, storage))
-	router.Post("/getquantitycomix", get_number_of_comics.New(logger, storage))
-	router.Post("/getquantitytag", get_number_of_comics_from_tag.New(logger, storage))
-	router.Post("/getquantityname", get_number_of_comix_form_name.New(logger, storage))
-	router.Get("/{folder1}/{folder2}/{fileName}", get_photo.New(logger))
-	router.Get("/comix/{tag}/{name}/", get_comix_photo.New(logger, storage))
+	// Читатель с токеном в Authorization попадает в контекст запроса, без токена запрос анонимный
+	router.Use(session.New(logger, storage))
+
+	proxies, err := ratelimit.ParseProxies(cfg.RateLimit.ProxyHeader, cfg.RateLimit.TrustedProxies)
+	if err != nil {
+		logger.Error("Failed to init trusted proxies", sl.Err(err))
+		os.Exit(1)
+	}
+
+	limiter := ratelimit.NewLimiter(cfg.RateLimit.LockoutBase, cfg.RateLimit.LockoutMax, cfg.RateLimit.FreeFailures)
+	limiter.TrustProxies(proxies)
+	readerLimiter := ratelimit.NewLimiter(cfg.RateLimit.ReaderLockoutBase, cfg.RateLimit.ReaderLockoutMax, cfg.RateLimit.ReaderFreeFailures)
+	readerLimiter.TrustProxies(proxies)
+	readBudget := ratelimit.Budget{
+		Name:      "read",
+		PerMinute: cfg.RateLimit.ReadPerMinute,
+		Burst:     cfg.RateLimit.ReadBurst,
+	}
+	writeBudget := ratelimit.Budget{
+		Name:      "write",
+		PerMinute: cfg.RateLimit.WritePerMinute,
+		Burst:     cfg.RateLimit.WriteBurst,
+		Protected: true,
+	}
+	readerAuthBudget := ratelimit.Budget{
+		Name:      "reader_auth",
+		PerMinute: cfg.RateLimit.ReaderAuthPerMinute,
+		Burst:     cfg.RateLimit.ReaderAuthBurst,
+		Protected: true,
+	}
+	imageLimits := images.Limits{
+		MaxFileSize:    cfg.Images.MaxFileSize,
+		MaxRequestSize: cfg.Images.MaxRequestSize,
+		MaxPixels:      cfg.Images.MaxPixels,
+	}
+	uploadBudget := ratelimit.Budget{
+		Name:      "upload",
+		PerMinute: cfg.RateLimit.UploadPerMinute,
+		Burst:     cfg.RateLimit.UploadBurst,
+	}
+	// Комментарии и отзывы пишут читатели со своим токеном, поэтому бюджет считается по читателю
+	commentBudget := ratelimit.Budget{
+		Name:      "comment",
+		PerMinute: cfg.RateLimit.CommentPerMinute,
+		Burst:     cfg.RateLimit.CommentBurst,
+		Key:       session.ReaderKey,
+	}
+
+	router.Group(func(r chi.Router) {
+		r.Use(ratelimit.New(logger, limiter, writeBudget))
+
+		r.Post("/newtag", save.New(logger, storage, responseCache, mediaRoot))
+		r.Post("/newcomix", insert.New(logger, storage, responseCache, mediaRoot, cfg.Moderation.Enabled))
+		r.Post("/insertphoto", insert_photo.New(logger, storage, mediaRoot, imageLimits, cfg.Moderation.Enabled))
+		r.Post("/importcomix", import_comix.New(logger, storage, uploadStore, responseCache, mediaRoot, archive.Limits{
+			MaxPages:     cfg.Import.MaxPages,
+			MaxPageSize:  cfg.Import.MaxPageSize,
+			MaxTotalSize: cfg.Import.MaxTotalSize,
+		}, imageLimits, cfg.Upload.MaxSize, cfg.Moderation.Enabled))
+		r.Post(create_upload.Path, create_upload.New(logger, storage, uploadStore))
+		r.Post(create_upload.Path+"finish", finish_upload.New(logger, storage, uploadStore, mediaRoot, imageLimits, cfg.Moderation.Enabled))
+		r.Post("/replacepage", replace_page.New(logger, storage, mediaRoot, imageLimits))
+		r.Post("/deletepage", delete_page.New(logger, storage, mediaRoot))
+		r.Post("/reorderpages", reorder_pages.New(logger, storage))
+		r.Post("/deletecomix", delete_comix.New(logger, storage, responseCache))
+		r.Post("/editcomix", edit_comix.New(logger, storage, responseCache, mediaRoot))
+		r.Post("/editcomixmeta", edit_comix_meta.New(logger, storage, responseCache))
+		r.Post("/comixcover", insert_comix_cover.New(logger, storage, responseCache, mediaRoot, imageLimits))
+		r.Post("/edittag", edit_tag.New(logger, storage, responseCache, mediaRoot))
+		r.Post("/edittagmeta", edit_tag_meta.New(logger, storage, responseCache))
+		r.Post("/tagcover", insert_tag_cover.New(logger, storage, responseCache, mediaRoot, imageLimits))
+		r.Post("/newauthor", create_author.New(logger, storage))
+		r.Post("/editauthor", edit_author.New(logger, storage))
+		r.Post("/deleteauthor", delete_author.New(logger, storage))
+		r.Post("/comixcredits", set_comix_credits.New(logger, storage))
+		r.Post("/mergetags", merge_tags.New(logger, storage, responseCache, mediaRoot))
+		r.Post("/deletetag", delete_tag.New(logger, storage, responseCache, mediaRoot))
+		r.Post("/auditlog", get_audit_events.New(logger, storage))
+		r.Post("/trash", get_trash.New(logger, storage))
+		r.Post("/trash/restore", restore_comix.New(logger, storage, responseCache))
+		r.Post("/removecomment", remove_comment.New(logger, storage))
+		r.Post("/moderation/queue", get_moderation_queue.New(logger, storage))
+		r.Post("/moderation/approve", approve_upload.New(logger, storage, responseCache))
+		r.Post("/moderation/reject", reject_upload.New(logger, storage, mediaRoot))
+		r.Post("/moderation/reports", get_reports.New(logger, storage))
+		r.Post("/moderation/reports/resolve", resolve_report.New(logger, storage))
+		r.Post("/releases", get_releases.New(logger, storage))
+		r.Post("/releases/reschedule", reschedule_release.New(logger, storage, responseCache))
+		r.Post("/webhooks", get_webhooks.New(logger, storage))
+		r.Post("/webhooks/create", create_webhook.New(logger, storage))
+		r.Post("/webhooks/delete", delete_webhook.New(logger, storage))
+		r.Post("/webhooks/deliveries", get_webhook_deliveries.New(logger, storage))
+		r.Post("/webhooks/deliveries/replay", replay_webhook_delivery.New(logger, storage))
+	})
+
+	// Вход читателя защищён от перебора своим лимитером: ошибки читателей не блокируют администраторов, и наоборот
+	router.Group(func(r chi.Router) {
+		r.Use(ratelimit.New(logger, readerLimiter, readerAuthBudget))
+
+		r.Post("/api/readers/register", register_reader.New(logger, storage, cfg.Readers.SessionTTL))
+		r.Post("/api/readers/login", login_reader.New(logger, storage, cfg.Readers.SessionTTL))
+	})
+
+	// Части загрузки идут десятками подряд, поэтому у них свой бюджет. Паролем они не защищены:
+	// id загрузки случайный и известен только тому, кто её создал с паролем.
+	router.Group(func(r chi.Router) {
+		r.Use(ratelimit.New(logger, limiter, uploadBudget))
+
+		r.Head(create_upload.Path+"{id}", get_upload.New(logger, uploadStore))
+		r.Patch(create_upload.Path+"{id}", upload_chunk.New(logger, uploadStore, cfg.Upload.ChunkSize))
+		r.Delete(create_upload.Path+"{id}", delete_upload.New(logger, uploadStore))
+	})
+
+	router.Group(func(r chi.Router) {
+		r.Use(ratelimit.New(logger, limiter, readBudget))
+
+		r.Post("/getcomix", get_comix.New(logger, storage))
+		r.With(mwCache.New(logger, responseCache, mwCache.TagGroup(cache.KeyTagDescription))).
+			Post("/gettagdescription", get_tag_description.New(logger, storage))
+		r.With(mwCache.New(logger, responseCache, mwCache.Group(cache.KeyMainPage))).
+			Post("/getmainpagecomix", get_comix_for_main_page.New(logger, storage, cfg.Ratings.MinVotes))
+		r.Post("/getalltagcomix", get_all_tag_comix.New(logger, storage, cfg.Ratings.MinVotes))
+		r.With(mwCache.New(logger, responseCache, mwCache.Group(cache.KeyAllTags))).
+			Post("/alltags", get_all_tags.New(logger, storage))
+		r.Post("/findcomix", find_comix.New(logger, storage, cfg.Ratings.MinVotes))
+		r.With(mwCache.New(logger, responseCache, mwCache.Group(cache.KeyMainPage))).
+			Post("/getquantitycomix", get_number_of_comics.New(logger, storage))
+		r.With(mwCache.New(logger, responseCache, mwCache.TagGroup(cache.KeyTagComix))).
+			Post("/getquantitytag", get_number_of_comics_from_tag.New(logger, storage))
+		r.With(mwCache.New(logger, responseCache, mwCache.Group(cache.KeySearch))).
+			Post("/getquantityname", get_number_of_comix_form_name.New(logger, storage))
+		r.Get("/{folder1}/{folder2}/{fileName}", get_photo.New(logger, storage, mediaRoot))
+		r.Get("/comix/{tag}/{name}/", get_comix_photo.New(logger, storage, mediaRoot))
+		r.Get(get_comix_by_slug.Path+"{slug}", get_comix_by_slug.New(logger, storage))
+		r.Get(get_comix_by_slug.Path+"{slug}/cover", get_comix_cover.New(logger, storage, mediaRoot))
+		r.Get(get_comix_by_slug.Path+"{slug}/download", download_comix.New(logger, storage, mediaRoot))
+		r.Get(download_comix.Path+"{id}/download", download_comix.New(logger, storage, mediaRoot))
+		r.Get(get_comix_by_slug.Path+"{slug}/comments", get_comments.New(logger, storage))
+		r.Get(get_comix_by_slug.Path+"{slug}/reviews", get_reviews.New(logger, storage))
+		r.Get("/api/comments/{id}/replies", get_comment_replies.New(logger, storage))
+		r.Get(get_tag_by_slug.Path+"{slug}", get_tag_by_slug.New(logger, storage))
+		r.Get(get_tag_by_slug.Path+"{slug}/cover", get_tag_cover.New(logger, storage, mediaRoot))
+		r.Get("/authors", get_all_authors.New(logger, storage))
+		r.Get(get_author_by_slug.Path+"{slug}", get_author_by_slug.New(logger, storage))
+		r.Get(get_author_by_slug.Path+"{slug}/comics", get_author_comix.New(logger, storage))
+		r.Get(get_reading_list.Path+"{slug}", get_reading_list.New(logger, storage))
+		// Расширение отрезает middleware.URLFormat, поэтому /feed.xml и /feed.json попадают в один маршрут
+		r.Get(get_feed.Path, get_feed.New(logger, storage, cfg.Site.URL, cfg.Site.Title))
+		r.Get(get_feed.TagPath+"{slug}", get_feed.New(logger, storage, cfg.Site.URL, cfg.Site.Title))
+		r.Get(get_sitemap.Path, get_sitemap.New(logger, storage, cfg.Site.URL, sitemap.MaxURLs))
+		r.Get(get_sitemap.Path+"/{page}", get_sitemap.New(logger, storage, cfg.Site.URL, sitemap.MaxURLs))
+		r.Get(get_meta.Path+"{kind}/{slug}", get_meta.New(logger, storage, cfg.Site.URL, cfg.Site.Title))
+		r.Get("/api/trending", get_trending_comix.New(logger, storage, cacheStore, cfg.Trending.CacheTTL, cfg.Trending.DecayHalfLife))
+	})
+
+	// Обработчики /api/me/... берут читателя из session.Reader и не проверяют, что он есть:
+	// все они должны регистрироваться только в группах с session.Required ниже.
+	router.Group(func(r chi.Router) {
+		r.Use(ratelimit.New(logger, limiter, readBudget))
+		r.Use(session.Required)
+
+		r.Post("/api/me/logout", logout_reader.New(logger, storage))
+		r.Get("/api/me/bookmarks", get_bookmarks.New(logger, storage))
+		r.Put("/api/me/bookmarks/{slug}", add_bookmark.New(logger, storage))
+		r.Delete("/api/me/bookmarks/{slug}", delete_bookmark.New(logger, storage))
+		r.Put("/api/me/progress/{slug}", save_progress.New(logger, storage))
+		r.Get("/api/me/continue", get_continue_reading.New(logger, storage))
+		r.Get("/api/me/lists", get_reading_lists.New(logger, storage))
+		r.Post("/api/me/lists", create_reading_list.New(logger, storage))
+		r.Patch("/api/me/lists/{slug}", edit_reading_list.New(logger, storage))
+		r.Delete("/api/me/lists/{slug}", delete_reading_list.New(logger, storage))
+		r.Put("/api/me/lists/{slug}/order", reorder_reading_list.New(logger, storage))
+		r.Put("/api/me/lists/{slug}/comix/{comix}", add_to_reading_list.New(logger, storage))
+		r.Delete("/api/me/lists/{slug}/comix/{comix}", remove_from_reading_list.New(logger, storage))
+		r.Delete("/api/me/comments/{id}", delete_comment.New(logger, storage))
+		r.Get("/api/me/ratings/{slug}", get_rating.New(logger, storage))
+		r.Delete("/api/me/ratings/{slug}", delete_rating.New(logger, storage))
+	})
+
+	router.Group(func(r chi.Router) {
+		r.Use(session.Required)
+		r.Use(ratelimit.New(logger, limiter, commentBudget))
+
+		r.Post("/api/me/comix/{slug}/comments", create_comment.New(logger, storage))
+		r.Patch("/api/me/comments/{id}", edit_comment.New(logger, storage))
+		r.Put("/api/me/ratings/{slug}", rate_comix.New(logger, storage))
+		r.Post("/api/me/reports", report_content.New(logger, storage))
+	})
+
+	purger := purge.New(logger, storage, mediaRoot, cfg.Trash.Retention, cfg.Trash.PurgeInterval)
+	go purger.Run(context.Background())
+
+	publisher := publish.New(logger, storage, responseCache, cfg.Publish.Interval)
+	go publisher.Run(context.Background())
+
+	dispatcher := webhooks.New(logger, storage, cfg.Webhooks.Timeout, webhooks.Retry{
+		MaxAttempts: cfg.Webhooks.MaxAttempts,
+		Base:        cfg.Webhooks.BackoffBase,
+		Max:         cfg.Webhooks.BackoffMax,
+	}, cfg.Webhooks.Interval, cfg.Webhooks.AllowPrivateAddresses)
+	go dispatcher.Run(context.Background())
+
+	uploadCleaner := uploads.New(logger, uploadStore, cfg.Upload.TTL, cfg.Upload.CleanupInterval)
+	go uploadCleaner.Run(context.Background())
 
 	logger.Info("starting server", slog.String("addres", cfg.Address))
 	srv := &http.Server{
@@ -102,6 +400,18 @@ func setupLogger(env string) *slog.Logger {
 	return logger
 }
 
+func setupCacheStore(logger *slog.Logger, cfg config.Cache) cache.Store {
+	if cfg.RedisAddress == "" {
+		logger.Info("using in-process cache")
+
+		return cache.NewMemory()
+	}
+
+	logger.Info("using redis cache", slog.String("address", cfg.RedisAddress))
+
+	return cache.NewRedis(logger, cfg.RedisAddress, cfg.RedisPassword, cfg.RedisDB, cfg.RedisTimeout)
+}
+
 func setupPrettySlog() *slog.Logger {
 	opts := slogpretty.PrettyHandlerOptions{
 		SlogOpts: &slog.HandlerOptions{
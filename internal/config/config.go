@@ -12,6 +12,20 @@ type Config struct {
 	Env         string `yaml:"env" env-default:"local" env-required:"true"`
 	StoragePath string `yaml:"storage_path" env-required:"true"`
 	HTTPServer  `yaml:"http_server"`
+	Trending    `yaml:"trending"`
+	Cache       `yaml:"cache"`
+	RateLimit   `yaml:"rate_limit"`
+	Trash       `yaml:"trash"`
+	Upload      `yaml:"upload"`
+	Import      `yaml:"import"`
+	Images      `yaml:"images"`
+	Media       `yaml:"media"`
+	Readers     `yaml:"readers"`
+	Ratings     `yaml:"ratings"`
+	Moderation  `yaml:"moderation"`
+	Publish     `yaml:"publish"`
+	Site        `yaml:"site"`
+	Webhooks    `yaml:"webhooks"`
 }
 
 type HTTPServer struct {
@@ -20,6 +34,119 @@ type HTTPServer struct {
 	IdleTimeout time.Duration `yaml:"idle_timeout" env-default:"60s"`
 }
 
+type Trending struct {
+	CacheTTL      time.Duration `yaml:"cache_ttl" env-default:"1m"`
+	DecayHalfLife time.Duration `yaml:"decay_half_life" env-default:"72h"`
+}
+
+type Cache struct {
+	TTL           time.Duration `yaml:"ttl" env-default:"5m"`
+	RedisAddress  string        `yaml:"redis_address"`
+	RedisPassword string        `yaml:"redis_password" env:"REDIS_PASSWORD"`
+	RedisDB       int           `yaml:"redis_db" env-default:"0"`
+	RedisTimeout  time.Duration `yaml:"redis_timeout" env-default:"200ms"`
+}
+
+type RateLimit struct {
+	ReadPerMinute  int `yaml:"read_per_minute" env-default:"300"`
+	ReadBurst      int `yaml:"read_burst" env-default:"60"`
+	WritePerMinute int `yaml:"write_per_minute" env-default:"30"`
+	WriteBurst     int `yaml:"write_burst" env-default:"10"`
+	// UploadPerMinute - бюджет на части загрузки: одна глава - это десятки запросов подряд
+	UploadPerMinute int `yaml:"upload_per_minute" env-default:"600"`
+	UploadBurst     int `yaml:"upload_burst" env-default:"60"`
+	// CommentPerMinute - бюджет на комментарии, считается по читателю, а не по IP
+	CommentPerMinute int           `yaml:"comment_per_minute" env-default:"5"`
+	CommentBurst     int           `yaml:"comment_burst" env-default:"3"`
+	FreeFailures     int           `yaml:"free_failures" env-default:"3"`
+	LockoutBase      time.Duration `yaml:"lockout_base" env-default:"2s"`
+	LockoutMax       time.Duration `yaml:"lockout_max" env-default:"15m"`
+	// ReaderAuth* и Reader* - бюджет и блокировка входа и регистрации читателей, отдельные от администраторских
+	ReaderAuthPerMinute int           `yaml:"reader_auth_per_minute" env-default:"20"`
+	ReaderAuthBurst     int           `yaml:"reader_auth_burst" env-default:"10"`
+	ReaderFreeFailures  int           `yaml:"reader_free_failures" env-default:"5"`
+	ReaderLockoutBase   time.Duration `yaml:"reader_lockout_base" env-default:"2s"`
+	ReaderLockoutMax    time.Duration `yaml:"reader_lockout_max" env-default:"15m"`
+	// ProxyHeader - заголовок с адресом клиента, который ставит прокси, например X-Forwarded-For.
+	// Заголовку верят только в запросах с адресов TrustedProxies (подсети или отдельные адреса)
+	ProxyHeader    string   `yaml:"proxy_header"`
+	TrustedProxies []string `yaml:"trusted_proxies"`
+}
+
+type Trash struct {
+	Retention     time.Duration `yaml:"retention" env-default:"720h"`
+	PurgeInterval time.Duration `yaml:"purge_interval" env-default:"1h"`
+}
+
+type Upload struct {
+	Dir             string        `yaml:"dir" env-default:"internal/storage/web/uploads"`
+	MaxSize         int64         `yaml:"max_size" env-default:"52428800"`
+	ChunkSize       int64         `yaml:"chunk_size" env-default:"4194304"`
+	TTL             time.Duration `yaml:"ttl" env-default:"24h"`
+	CleanupInterval time.Duration `yaml:"cleanup_interval" env-default:"1h"`
+}
+
+// Import - ограничения на распаковку архивов комиксов. Сам архив ограничен Upload.MaxSize.
+type Import struct {
+	MaxPages     int   `yaml:"max_pages" env-default:"1000"`
+	MaxPageSize  int64 `yaml:"max_page_size" env-default:"20971520"`
+	MaxTotalSize int64 `yaml:"max_total_size" env-default:"536870912"`
+}
+
+// Images - ограничения на загружаемые картинки: страницы, обложки и страницы из архивов
+type Images struct {
+	MaxFileSize    int64 `yaml:"max_file_size" env-default:"20971520"`
+	MaxRequestSize int64 `yaml:"max_request_size" env-default:"209715200"`
+	// MaxPixels проверяется до распаковки картинки, 40 мегапикселей - это 160 МБ памяти на одну картинку
+	MaxPixels int64 `yaml:"max_pixels" env-default:"40000000"`
+}
+
+// Media - папка страниц и обложек: <тэг>/<комикс>/<файл>. Файлы читаются и пишутся только внутри неё.
+type Media struct {
+	Root string `yaml:"root" env-default:"internal/storage/web/photos"`
+}
+
+// Readers - учётные записи читателей: закладки и места чтения
+type Readers struct {
+	SessionTTL time.Duration `yaml:"session_ttl" env-default:"720h"`
+}
+
+// Ratings - MinVotes - сколько оценок нужно комиксу, чтобы встать в списки по рейтингу
+type Ratings struct {
+	MinVotes int `yaml:"min_votes" env-default:"5"`
+}
+
+// Moderation - Enabled - новые комиксы и страницы не видны читателям, пока их не одобрит модератор
+type Moderation struct {
+	Enabled bool `yaml:"enabled" env-default:"false"`
+}
+
+// Site - URL - публичный адрес сервиса без / в конце, от него строятся абсолютные ссылки в лентах,
+// карте сайта и метаданных страниц.
+// Title - название сайта в лентах и карточках страниц
+type Site struct {
+	URL   string `yaml:"url" env-default:"http://localhost:8082"`
+	Title string `yaml:"title" env-default:"Comix"`
+}
+
+// Publish - Interval - как часто проверяются запланированные выпуски, на столько выход может опоздать
+type Publish struct {
+	Interval time.Duration `yaml:"interval" env-default:"1m"`
+}
+
+// Webhooks - Interval - как часто отправляются доставки из outbox, Timeout - ограничение одной попытки.
+// После MaxAttempts неудачных попыток доставка помечается неудавшейся, паузы между попытками
+// растут от BackoffBase вдвое, но не больше BackoffMax
+type Webhooks struct {
+	Interval    time.Duration `yaml:"interval" env-default:"10s"`
+	Timeout     time.Duration `yaml:"timeout" env-default:"5s"`
+	MaxAttempts int           `yaml:"max_attempts" env-default:"8"`
+	BackoffBase time.Duration `yaml:"backoff_base" env-default:"30s"`
+	BackoffMax  time.Duration `yaml:"backoff_max" env-default:"6h"`
+	// AllowPrivateAddresses разрешает доставку на loopback и внутренние адреса, например в локальных тестах
+	AllowPrivateAddresses bool `yaml:"allow_private_addresses" env-default:"false"`
+}
+
 func MustLoad() *Config {
 	configPath := getConfigFlag()
 	if configPath == "" {
@@ -0,0 +1,77 @@
+package add_bookmark_test
+
+import (
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/add_bookmark"
+	"jadesheart/comix_back/internal/http-server/middleware/session"
+	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type ResponseMock struct {
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type MockBookmarkAdder struct {
+	bookmarks map[int][]int
+}
+
+func (m *MockBookmarkAdder) GetComixBySlug(comixSlug string) (postgres.ComixFromAllComix, error) {
+	// Старый slug тоже находит комикс
+	if comixSlug == "watchmen" || comixSlug == "old-watchmen" {
+		return postgres.ComixFromAllComix{ID: 3, Slug: "watchmen"}, nil
+	}
+	return postgres.ComixFromAllComix{}, storage.ErrComixNotFound
+}
+
+func (m *MockBookmarkAdder) AddBookmark(readerID int, comixID int) error {
+	m.bookmarks[readerID] = append(m.bookmarks[readerID], comixID)
+	return nil
+}
+
+func doRequest(t *testing.T, adder *MockBookmarkAdder, comixSlug string, reader *postgres.Reader) (int, ResponseMock) {
+	router := chi.NewRouter()
+	router.With(session.Required).Put("/api/me/bookmarks/{slug}", add_bookmark.New(slogdiscard.NewDiscardLogger(), adder))
+
+	req, err := http.NewRequest("PUT", "/api/me/bookmarks/"+comixSlug, nil)
+	assert.NoError(t, err)
+	if reader != nil {
+		req = req.WithContext(session.WithReader(req.Context(), *reader))
+	}
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	var responseBody ResponseMock
+
+	if err := json.Unmarshal(rr.Body.Bytes(), &responseBody); err != nil {
+		t.Fatalf("Ошибка при распоковке JSON: %s", err)
+	}
+
+	return rr.Code, responseBody
+}
+
+func TestAddBookmark(t *testing.T) {
+	adder := &MockBookmarkAdder{bookmarks: map[int][]int{}}
+	reader := &postgres.Reader{ID: 5, Username: "reader"}
+
+	_, responseBody := doRequest(t, adder, "old-watchmen", reader)
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+	assert.Equal(t, []int{3}, adder.bookmarks[5])
+
+	_, responseBody = doRequest(t, adder, "unknown", reader)
+	assert.Equal(t, http.StatusBadRequest, responseBody.Status)
+	assert.Equal(t, "Comix not exists", responseBody.Error)
+
+	code, responseBody := doRequest(t, adder, "watchmen", nil)
+	assert.Equal(t, http.StatusUnauthorized, code)
+	assert.Equal(t, http.StatusUnauthorized, responseBody.Status)
+	assert.Equal(t, []int{3}, adder.bookmarks[5])
+}
@@ -0,0 +1,70 @@
+package add_to_reading_list
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"jadesheart/comix_back/internal/http-server/middleware/session"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+)
+
+type ReadingListAdder interface {
+	GetComixBySlug(comixSlug string) (postgres.ComixFromAllComix, error)
+	AddToReadingList(readerID int, listSlug string, comixID int) error
+}
+
+// New добавляет комикс {comix} в конец списка {slug} читателя запроса.
+func New(log *slog.Logger, readingListAdder ReadingListAdder) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.add_to_reading_list.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		reader, _ := session.Reader(r.Context())
+
+		comix, err := readingListAdder.GetComixBySlug(chi.URLParam(r, "comix"))
+		if errors.Is(err, storage.ErrComixNotFound) {
+			render.JSON(w, r, resp.Error("Comix not exists"))
+
+			return
+		}
+		if err != nil {
+			log.Error("Cannot get comix from bd", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("Cannot get comix from bd"))
+
+			return
+		}
+
+		err = readingListAdder.AddToReadingList(reader.ID, chi.URLParam(r, "slug"), comix.ID)
+		if errors.Is(err, storage.ErrListNotFound) {
+			render.JSON(w, r, resp.Error("List not exists"))
+
+			return
+		}
+		if errors.Is(err, storage.ErrComixNotFound) {
+			render.JSON(w, r, resp.Error("Comix not exists"))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed add comix to reading list", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed add comix to reading list"))
+
+			return
+		}
+
+		render.JSON(w, r, resp.OK())
+
+	}
+}
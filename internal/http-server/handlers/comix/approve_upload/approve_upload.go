@@ -0,0 +1,161 @@
+package approve_upload
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator"
+	"jadesheart/comix_back/internal/http-server/middleware/ratelimit"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/audit"
+	"jadesheart/comix_back/internal/lib/cache"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+)
+
+// Что можно одобрить
+const (
+	KindComix = "comix"
+	KindPage  = "page"
+)
+
+// Request - id - id комикса на проверке или страницы в очереди модерации, reason - необязательный комментарий модератора
+type Request struct {
+	Password string `json:"password" validate:"required"`
+	Kind     string `json:"kind" validate:"required,oneof=comix page"`
+	ID       int    `json:"id" validate:"required,min=1"`
+	Reason   string `json:"reason" validate:"max=1000"`
+}
+
+type Response struct {
+	Status int            `json:"status,omitempty"`
+	Error  string         `json:"error,omitempty"`
+	Page   *postgres.Page `json:"page,omitempty"`
+}
+
+type UploadApprover interface {
+	ApproveComix(id int) (postgres.PendingComix, error)
+	ApprovePendingPage(id int) (postgres.PendingPage, postgres.Page, error)
+	CheckPass(inputPass string) (bool, error)
+	AddAuditEvent(event postgres.AuditEvent) error
+}
+
+type CacheInvalidator interface {
+	Invalidate(groups ...string)
+}
+
+// New одобряет комикс или страницу из очереди модерации. Одобренный комикс появляется в списках вместе со своими страницами.
+func New(log *slog.Logger, uploadApprover UploadApprover, cacheInvalidator CacheInvalidator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.approve_upload.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("failed decode request json", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed decode request json"))
+
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Error("failed validate", sl.Err(err))
+
+			render.JSON(w, r, resp.ValidateErrors(validateErr))
+
+			return
+		}
+
+		res, err := uploadApprover.CheckPass(req.Password)
+		if err != nil {
+			log.Error("failed password verified", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed password verified"))
+
+			return
+		}
+
+		if !res {
+			log.Warn("incorrect password", slog.String("remote_addr", ratelimit.ClientIP(r)))
+
+			ratelimit.AuthFailed(r.Context())
+
+			render.JSON(w, r, resp.Error("incorrect password"))
+
+			return
+		}
+
+		ratelimit.AuthSucceeded(r.Context())
+
+		if req.Kind == KindComix {
+			comix, err := uploadApprover.ApproveComix(req.ID)
+			if errors.Is(err, storage.ErrComixNotFound) {
+				render.JSON(w, r, resp.Error("Comix is not pending review"))
+
+				return
+			}
+			if err != nil {
+				log.Error("Cannot approve comix", sl.Err(err))
+
+				render.JSON(w, r, resp.Error("Cannot approve comix"))
+
+				return
+			}
+
+			cacheInvalidator.Invalidate(cache.KeyMainPage, cache.KeySearch, cache.KeyAllTags, cache.KeyTagComix(comix.ComixTag))
+
+			err = uploadApprover.AddAuditEvent(audit.NewEvent(r, audit.ActionComixApprove, audit.ComixTarget(comix.ComixTag, comix.ComixName),
+				map[string]interface{}{"reviewStatus": postgres.ReviewPending, "pendingPages": comix.PendingPages},
+				map[string]interface{}{"reviewStatus": postgres.ReviewApproved, "reason": req.Reason}))
+			if err != nil {
+				log.Error("failed write audit event", sl.Err(err))
+			}
+
+			responseOK(w, r, nil)
+
+			return
+		}
+
+		pending, page, err := uploadApprover.ApprovePendingPage(req.ID)
+		if errors.Is(err, storage.ErrPageNotFound) {
+			render.JSON(w, r, resp.Error("Page is not pending review"))
+
+			return
+		}
+		if err != nil {
+			log.Error("Cannot approve page", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("Cannot approve page"))
+
+			return
+		}
+
+		err = uploadApprover.AddAuditEvent(audit.NewEvent(r, audit.ActionPageApprove, audit.ComixTarget(pending.ComixTag, pending.ComixName),
+			pending, map[string]interface{}{"page": page.ID, "position": page.Position, "reason": req.Reason}))
+		if err != nil {
+			log.Error("failed write audit event", sl.Err(err))
+		}
+
+		responseOK(w, r, &page)
+
+	}
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request, page *postgres.Page) {
+	render.JSON(w, r, Response{
+		Status: resp.StatusOK,
+		Page:   page,
+	})
+}
@@ -0,0 +1,129 @@
+package approve_upload_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/approve_upload"
+	"jadesheart/comix_back/internal/lib/cache"
+	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type ResponseMock struct {
+	Status int            `json:"status,omitempty"`
+	Error  string         `json:"error,omitempty"`
+	Page   *postgres.Page `json:"page,omitempty"`
+}
+
+type MockUploadApprover struct {
+	events []postgres.AuditEvent
+}
+
+func (m *MockUploadApprover) ApproveComix(id int) (postgres.PendingComix, error) {
+	if id != 3 {
+		return postgres.PendingComix{}, storage.ErrComixNotFound
+	}
+	return postgres.PendingComix{ComixFromAllComix: postgres.ComixFromAllComix{ID: 3, ComixTag: "horror", ComixName: "Watchmen"}}, nil
+}
+
+func (m *MockUploadApprover) ApprovePendingPage(id int) (postgres.PendingPage, postgres.Page, error) {
+	if id != 9 {
+		return postgres.PendingPage{}, postgres.Page{}, storage.ErrPageNotFound
+	}
+	return postgres.PendingPage{ID: 9, ComixID: 4, ComixTag: "horror", ComixName: "Hellboy", File: "new.jpg"},
+		postgres.Page{ID: 40, Position: 12, File: "new.jpg"}, nil
+}
+
+func (m *MockUploadApprover) CheckPass(inputPass string) (bool, error) {
+	return inputPass == "password", nil
+}
+
+func (m *MockUploadApprover) AddAuditEvent(event postgres.AuditEvent) error {
+	m.events = append(m.events, event)
+	return nil
+}
+
+type MockCacheInvalidator struct {
+	groups []string
+}
+
+func (m *MockCacheInvalidator) Invalidate(groups ...string) {
+	m.groups = append(m.groups, groups...)
+}
+
+func doRequest(t *testing.T, approver *MockUploadApprover, invalidator *MockCacheInvalidator, body map[string]interface{}) ResponseMock {
+	handler := approve_upload.New(slogdiscard.NewDiscardLogger(), approver, invalidator)
+
+	jsonBody, _ := json.Marshal(body)
+
+	req, err := http.NewRequest("POST", "/moderation/approve", bytes.NewBuffer(jsonBody))
+	assert.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	var responseBody ResponseMock
+
+	if err := json.Unmarshal(rr.Body.Bytes(), &responseBody); err != nil {
+		t.Fatalf("Ошибка при распоковке JSON: %s", err)
+	}
+
+	return responseBody
+}
+
+func TestApproveUpload_Comix(t *testing.T) {
+	approver := &MockUploadApprover{}
+	invalidator := &MockCacheInvalidator{}
+
+	responseBody := doRequest(t, approver, invalidator, map[string]interface{}{"password": "password", "kind": "comix", "id": 3})
+
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+	assert.Contains(t, invalidator.groups, cache.KeyMainPage)
+	assert.Contains(t, invalidator.groups, cache.KeyTagComix("horror"))
+
+	assert.Len(t, approver.events, 1)
+	assert.Equal(t, "comix.approve", approver.events[0].Action)
+	assert.Equal(t, "comix:horror/Watchmen", approver.events[0].Target)
+}
+
+func TestApproveUpload_Page(t *testing.T) {
+	approver := &MockUploadApprover{}
+	invalidator := &MockCacheInvalidator{}
+
+	responseBody := doRequest(t, approver, invalidator, map[string]interface{}{"password": "password", "kind": "page", "id": 9, "reason": "ok"})
+
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+	assert.Equal(t, &postgres.Page{ID: 40, Position: 12, File: "new.jpg"}, responseBody.Page)
+
+	assert.Len(t, approver.events, 1)
+	assert.Equal(t, "comix.pages.approve", approver.events[0].Action)
+	assert.Equal(t, "comix:horror/Hellboy", approver.events[0].Target)
+}
+
+func TestApproveUpload_InvalidRequest(t *testing.T) {
+	requestsBody := []map[string]interface{}{
+		{"kind": "comix", "id": 3},
+		{"password": "password", "id": 3},
+		{"password": "password", "kind": "author", "id": 3},
+		{"password": "password", "kind": "comix"},
+		{"password": "wrong_password", "kind": "comix", "id": 3},
+		{"password": "password", "kind": "comix", "id": 4},
+		{"password": "password", "kind": "page", "id": 3},
+	}
+
+	approver := &MockUploadApprover{}
+	invalidator := &MockCacheInvalidator{}
+
+	for _, body := range requestsBody {
+		responseBody := doRequest(t, approver, invalidator, body)
+		assert.Equal(t, http.StatusBadRequest, responseBody.Status, body)
+	}
+
+	assert.Empty(t, approver.events)
+	assert.Empty(t, invalidator.groups)
+}
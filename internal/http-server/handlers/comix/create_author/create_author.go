@@ -0,0 +1,120 @@
+package create_author
+
+import (
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator"
+	"jadesheart/comix_back/internal/http-server/middleware/ratelimit"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/audit"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// Request - avatar и links - ссылки на внешние ресурсы
+type Request struct {
+	Password string   `json:"password" validate:"required"`
+	Name     string   `json:"name" validate:"required,max=200"`
+	Bio      string   `json:"bio" validate:"max=5000"`
+	Avatar   string   `json:"avatar" validate:"omitempty,url"`
+	Links    []string `json:"links" validate:"max=20,dive,url"`
+}
+
+type Response struct {
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+	Slug   string `json:"slug,omitempty"`
+}
+
+type AuthorCreator interface {
+	CreateAuthor(author postgres.Author) (postgres.Author, error)
+	CheckPass(inputPass string) (bool, error)
+	AddAuditEvent(event postgres.AuditEvent) error
+}
+
+func New(log *slog.Logger, authorCreator AuthorCreator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.create_author.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("failed decode request json", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed decode request json"))
+
+			return
+		}
+
+		req.Name = strings.TrimSpace(req.Name)
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Error("failed validate", sl.Err(err))
+
+			render.JSON(w, r, resp.ValidateErrors(validateErr))
+
+			return
+		}
+
+		res, err := authorCreator.CheckPass(req.Password)
+		if err != nil {
+			log.Error("failed password verified", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed password verified"))
+
+			return
+		}
+
+		if !res {
+			log.Warn("incorrect password", slog.String("remote_addr", ratelimit.ClientIP(r)))
+
+			ratelimit.AuthFailed(r.Context())
+
+			render.JSON(w, r, resp.Error("incorrect password"))
+
+			return
+		}
+
+		ratelimit.AuthSucceeded(r.Context())
+
+		author, err := authorCreator.CreateAuthor(postgres.Author{
+			Name:   req.Name,
+			Bio:    req.Bio,
+			Avatar: req.Avatar,
+			Links:  req.Links,
+		})
+		if err != nil {
+			log.Error("failed create author", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed create author"))
+
+			return
+		}
+
+		err = authorCreator.AddAuditEvent(audit.NewEvent(r, audit.ActionAuthorCreate, audit.AuthorTarget(author.Slug), nil, author))
+		if err != nil {
+			log.Error("failed write audit event", sl.Err(err))
+		}
+
+		responseOK(w, r, author.Slug)
+
+	}
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request, slug string) {
+	render.JSON(w, r, Response{
+		Status: resp.StatusOK,
+		Slug:   slug,
+	})
+}
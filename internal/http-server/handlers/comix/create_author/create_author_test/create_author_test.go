@@ -0,0 +1,89 @@
+package create_author_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/create_author"
+	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type ResponseMock struct {
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+	Slug   string `json:"slug,omitempty"`
+}
+
+type MockAuthorCreator struct {
+	created postgres.Author
+}
+
+func (m *MockAuthorCreator) CreateAuthor(author postgres.Author) (postgres.Author, error) {
+	author.ID = 1
+	author.Slug = "alan-moore"
+	m.created = author
+	return author, nil
+}
+
+func (m *MockAuthorCreator) CheckPass(inputPass string) (bool, error) {
+	return inputPass == "password", nil
+}
+
+func (m *MockAuthorCreator) AddAuditEvent(event postgres.AuditEvent) error {
+	return nil
+}
+
+func doRequest(t *testing.T, creator *MockAuthorCreator, body map[string]interface{}) ResponseMock {
+	handler := create_author.New(slogdiscard.NewDiscardLogger(), creator)
+
+	jsonBody, _ := json.Marshal(body)
+
+	req, err := http.NewRequest("POST", "/newauthor", bytes.NewBuffer(jsonBody))
+	assert.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	var responseBody ResponseMock
+
+	if err := json.Unmarshal(rr.Body.Bytes(), &responseBody); err != nil {
+		t.Fatalf("Ошибка при распоковке JSON: %s", err)
+	}
+
+	return responseBody
+}
+
+func TestCreateAuthor_Success(t *testing.T) {
+	creator := &MockAuthorCreator{}
+
+	responseBody := doRequest(t, creator, map[string]interface{}{
+		"password": "password",
+		"name":     " Alan Moore ",
+		"bio":      "Writer",
+		"links":    []string{"https://example.com/moore"},
+	})
+
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+	assert.Equal(t, "alan-moore", responseBody.Slug)
+	assert.Equal(t, "Alan Moore", creator.created.Name)
+	assert.Equal(t, []string{"https://example.com/moore"}, creator.created.Links)
+}
+
+func TestCreateAuthor_InvalidRequest(t *testing.T) {
+	cases := []map[string]interface{}{
+		{"password": "password", "name": "  "},
+		{"password": "password", "name": "Alan Moore", "avatar": "not a url"},
+		{"password": "password", "name": "Alan Moore", "links": []string{"not a url"}},
+		{"password": "wrong_password", "name": "Alan Moore"},
+	}
+
+	for _, c := range cases {
+		responseBody := doRequest(t, &MockAuthorCreator{}, c)
+
+		assert.Equal(t, http.StatusBadRequest, responseBody.Status)
+	}
+}
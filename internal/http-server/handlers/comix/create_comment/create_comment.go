@@ -0,0 +1,114 @@
+package create_comment
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator"
+	"jadesheart/comix_back/internal/http-server/middleware/session"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// Request - parentId - id комментария, на который отвечают, без него комментарий корневой
+type Request struct {
+	Body     string `json:"body" validate:"required,max=5000"`
+	ParentID int64  `json:"parentId" validate:"min=0"`
+}
+
+type Response struct {
+	Status  int              `json:"status,omitempty"`
+	Error   string           `json:"error,omitempty"`
+	Comment postgres.Comment `json:"comment"`
+}
+
+type CommentCreator interface {
+	GetComixBySlug(comixSlug string) (postgres.ComixFromAllComix, error)
+	AddComment(readerID int, comixID int, parentID int64, body string) (postgres.Comment, error)
+}
+
+// New добавляет комментарий читателя запроса к комиксу {slug}.
+func New(log *slog.Logger, commentCreator CommentCreator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.create_comment.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("failed decode request json", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed decode request json"))
+
+			return
+		}
+
+		req.Body = strings.TrimSpace(req.Body)
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Error("failed validate", sl.Err(err))
+
+			render.JSON(w, r, resp.ValidateErrors(validateErr))
+
+			return
+		}
+
+		reader, _ := session.Reader(r.Context())
+
+		comix, err := commentCreator.GetComixBySlug(chi.URLParam(r, "slug"))
+		if errors.Is(err, storage.ErrComixNotFound) {
+			render.JSON(w, r, resp.Error("Comix not exists"))
+
+			return
+		}
+		if err != nil {
+			log.Error("Cannot get comix from bd", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("Cannot get comix from bd"))
+
+			return
+		}
+
+		comment, err := commentCreator.AddComment(reader.ID, comix.ID, req.ParentID, req.Body)
+		if errors.Is(err, storage.ErrComixNotFound) {
+			render.JSON(w, r, resp.Error("Comix not exists"))
+
+			return
+		}
+		if errors.Is(err, storage.ErrCommentNotFound) {
+			render.JSON(w, r, resp.Error("Comment not exists"))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed add comment", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed add comment"))
+
+			return
+		}
+
+		responseOK(w, r, comment)
+
+	}
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request, comment postgres.Comment) {
+	render.JSON(w, r, Response{
+		Status:  resp.StatusOK,
+		Comment: comment,
+	})
+}
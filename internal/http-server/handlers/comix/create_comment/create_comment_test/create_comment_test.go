@@ -0,0 +1,104 @@
+package create_comment_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/create_comment"
+	"jadesheart/comix_back/internal/http-server/middleware/session"
+	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type ResponseMock struct {
+	Status  int              `json:"status,omitempty"`
+	Error   string           `json:"error,omitempty"`
+	Comment postgres.Comment `json:"comment"`
+}
+
+type MockCommentCreator struct {
+	added []postgres.Comment
+}
+
+func (m *MockCommentCreator) GetComixBySlug(comixSlug string) (postgres.ComixFromAllComix, error) {
+	if comixSlug == "watchmen" {
+		return postgres.ComixFromAllComix{ID: 3, Slug: "watchmen"}, nil
+	}
+	return postgres.ComixFromAllComix{}, storage.ErrComixNotFound
+}
+
+func (m *MockCommentCreator) AddComment(readerID int, comixID int, parentID int64, body string) (postgres.Comment, error) {
+	if parentID > 100 {
+		return postgres.Comment{}, storage.ErrCommentNotFound
+	}
+	comment := postgres.Comment{ID: int64(len(m.added) + 1), ParentID: parentID, ReaderID: readerID, Author: "reader", Body: body}
+	m.added = append(m.added, comment)
+	return comment, nil
+}
+
+func doRequest(t *testing.T, creator *MockCommentCreator, comixSlug string, body map[string]interface{}) ResponseMock {
+	router := chi.NewRouter()
+	router.With(session.Required).Post("/api/me/comix/{slug}/comments", create_comment.New(slogdiscard.NewDiscardLogger(), creator))
+
+	jsonBody, _ := json.Marshal(body)
+
+	req, err := http.NewRequest("POST", "/api/me/comix/"+comixSlug+"/comments", bytes.NewBuffer(jsonBody))
+	assert.NoError(t, err)
+	req = req.WithContext(session.WithReader(req.Context(), postgres.Reader{ID: 5, Username: "reader"}))
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	var responseBody ResponseMock
+
+	if err := json.Unmarshal(rr.Body.Bytes(), &responseBody); err != nil {
+		t.Fatalf("Ошибка при распоковке JSON: %s", err)
+	}
+
+	return responseBody
+}
+
+func TestCreateComment_Success(t *testing.T) {
+	creator := &MockCommentCreator{}
+
+	responseBody := doRequest(t, creator, "watchmen", map[string]interface{}{"body": "  Who watches the watchmen?  "})
+
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+	assert.Equal(t, "Who watches the watchmen?", responseBody.Comment.Body)
+	assert.Len(t, creator.added, 1)
+	assert.Equal(t, 5, creator.added[0].ReaderID)
+
+	responseBody = doRequest(t, creator, "watchmen", map[string]interface{}{"body": "Rorschach", "parentId": 1})
+
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+	assert.Equal(t, int64(1), responseBody.Comment.ParentID)
+}
+
+func TestCreateComment_Errors(t *testing.T) {
+	cases := []struct {
+		slug  string
+		body  map[string]interface{}
+		error string
+	}{
+		{"watchmen", map[string]interface{}{"body": "   "}, "Body is not valid"},
+		{"watchmen", map[string]interface{}{"body": strings.Repeat("a", 5001)}, "Body is not valid"},
+		{"watchmen", map[string]interface{}{"body": "reply", "parentId": 400}, "Comment not exists"},
+		{"unknown", map[string]interface{}{"body": "comment"}, "Comix not exists"},
+	}
+
+	for _, c := range cases {
+		creator := &MockCommentCreator{}
+
+		responseBody := doRequest(t, creator, c.slug, c.body)
+
+		assert.Equal(t, http.StatusBadRequest, responseBody.Status)
+		assert.Equal(t, c.error, responseBody.Error)
+		assert.Empty(t, creator.added)
+	}
+}
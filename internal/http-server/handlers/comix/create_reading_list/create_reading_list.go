@@ -0,0 +1,86 @@
+package create_reading_list
+
+import (
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator"
+	"jadesheart/comix_back/internal/http-server/middleware/session"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// Request - public: список открывается по slug любым посетителем
+type Request struct {
+	Name   string `json:"name" validate:"required,max=100"`
+	Public bool   `json:"public"`
+}
+
+type Response struct {
+	Status int                  `json:"status,omitempty"`
+	Error  string               `json:"error,omitempty"`
+	List   postgres.ReadingList `json:"list"`
+}
+
+type ReadingListCreator interface {
+	CreateReadingList(readerID int, name string, public bool) (postgres.ReadingList, error)
+}
+
+// New создаёт список читателя запроса.
+func New(log *slog.Logger, readingListCreator ReadingListCreator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.create_reading_list.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("failed decode request json", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed decode request json"))
+
+			return
+		}
+
+		req.Name = strings.TrimSpace(req.Name)
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Error("failed validate", sl.Err(err))
+
+			render.JSON(w, r, resp.ValidateErrors(validateErr))
+
+			return
+		}
+
+		reader, _ := session.Reader(r.Context())
+
+		list, err := readingListCreator.CreateReadingList(reader.ID, req.Name, req.Public)
+		if err != nil {
+			log.Error("failed create reading list", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed create reading list"))
+
+			return
+		}
+
+		responseOK(w, r, list)
+
+	}
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request, list postgres.ReadingList) {
+	render.JSON(w, r, Response{
+		Status: resp.StatusOK,
+		List:   list,
+	})
+}
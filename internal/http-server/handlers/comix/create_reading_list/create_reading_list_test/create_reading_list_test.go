@@ -0,0 +1,83 @@
+package create_reading_list_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/create_reading_list"
+	"jadesheart/comix_back/internal/http-server/middleware/session"
+	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type ResponseMock struct {
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+	List   struct {
+		Slug   string
+		Public bool
+	} `json:"list"`
+}
+
+type MockReadingListCreator struct {
+	created []postgres.ReadingList
+}
+
+func (m *MockReadingListCreator) CreateReadingList(readerID int, name string, public bool) (postgres.ReadingList, error) {
+	list := postgres.ReadingList{ID: 1, Slug: "to-read", Name: name, Public: public, ReaderID: readerID}
+	m.created = append(m.created, list)
+	return list, nil
+}
+
+func doRequest(t *testing.T, creator *MockReadingListCreator, body map[string]interface{}) ResponseMock {
+	handler := create_reading_list.New(slogdiscard.NewDiscardLogger(), creator)
+
+	jsonBody, _ := json.Marshal(body)
+
+	req, err := http.NewRequest("POST", "/api/me/lists", bytes.NewBuffer(jsonBody))
+	assert.NoError(t, err)
+	req = req.WithContext(session.WithReader(req.Context(), postgres.Reader{ID: 4, Username: "reader"}))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	var responseBody ResponseMock
+
+	if err := json.Unmarshal(rr.Body.Bytes(), &responseBody); err != nil {
+		t.Fatalf("Ошибка при распоковке JSON: %s", err)
+	}
+
+	return responseBody
+}
+
+func TestCreateReadingList_Success(t *testing.T) {
+	creator := &MockReadingListCreator{}
+
+	responseBody := doRequest(t, creator, map[string]interface{}{"name": "  To read ", "public": true})
+
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+	assert.Equal(t, "to-read", responseBody.List.Slug)
+	assert.True(t, responseBody.List.Public)
+	assert.Equal(t, "To read", creator.created[0].Name)
+	assert.Equal(t, 4, creator.created[0].ReaderID)
+}
+
+func TestCreateReadingList_InvalidName(t *testing.T) {
+	cases := []map[string]interface{}{
+		{},
+		{"name": "   "},
+		{"name": string(bytes.Repeat([]byte("a"), 101))},
+	}
+
+	for _, body := range cases {
+		creator := &MockReadingListCreator{}
+
+		responseBody := doRequest(t, creator, body)
+
+		assert.Equal(t, http.StatusBadRequest, responseBody.Status)
+		assert.Empty(t, creator.created)
+	}
+}
@@ -0,0 +1,139 @@
+package create_upload
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator"
+	"jadesheart/comix_back/internal/http-server/middleware/ratelimit"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/lib/upload"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Path - маршрут загрузок, адрес созданной загрузки - Path + id
+const Path = "/uploads/"
+
+// Request - checksum - sha256 всего файла в hex, проверяется при завершении загрузки
+type Request struct {
+	Password string `json:"password" validate:"required"`
+	FileName string `json:"fileName" validate:"required"`
+	Size     int64  `json:"size" validate:"required,min=1"`
+	Checksum string `json:"checksum" validate:"omitempty,len=64,hexadecimal"`
+}
+
+type Response struct {
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+	ID     string `json:"id,omitempty"`
+	Offset int64  `json:"offset"`
+}
+
+type PassChecker interface {
+	CheckPass(inputPass string) (bool, error)
+}
+
+type UploadCreator interface {
+	Create(fileName string, size int64, checksum string) (upload.Info, error)
+	MaxSize() int64
+}
+
+func New(log *slog.Logger, passChecker PassChecker, uploadCreator UploadCreator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.create_upload.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		w.Header().Set(upload.HeaderResumable, upload.TusVersion)
+
+		var req Request
+
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("failed decode request json", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed decode request json"))
+
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Error("failed validate", sl.Err(err))
+
+			render.JSON(w, r, resp.ValidateErrors(validateErr))
+
+			return
+		}
+
+		ext := strings.ToLower(filepath.Ext(req.FileName))
+		if ext != ".jpg" && ext != ".jpeg" && ext != ".png" {
+			render.JSON(w, r, resp.Error("file is not a image or not supported: "+req.FileName))
+
+			return
+		}
+
+		res, err := passChecker.CheckPass(req.Password)
+		if err != nil {
+			log.Error("failed password verified", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed password verified"))
+
+			return
+		}
+
+		if !res {
+			log.Warn("incorrect password", slog.String("remote_addr", ratelimit.ClientIP(r)))
+
+			ratelimit.AuthFailed(r.Context())
+
+			render.JSON(w, r, resp.Error("incorrect password"))
+
+			return
+		}
+
+		ratelimit.AuthSucceeded(r.Context())
+
+		info, err := uploadCreator.Create(req.FileName, req.Size, req.Checksum)
+		if errors.Is(err, upload.ErrTooLarge) {
+			render.JSON(w, r, resp.Error(fmt.Sprintf("file is too large, max size is %d bytes", uploadCreator.MaxSize())))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed create upload", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed create upload"))
+
+			return
+		}
+
+		log.Info("upload created", slog.String("upload", info.ID), slog.Int64("size", info.Size))
+
+		w.Header().Set("Location", Path+info.ID)
+		w.Header().Set(upload.HeaderOffset, "0")
+		w.Header().Set(upload.HeaderLength, strconv.FormatInt(info.Size, 10))
+
+		render.Status(r, http.StatusCreated)
+
+		responseOK(w, r, info.ID)
+
+	}
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request, id string) {
+	render.JSON(w, r, Response{
+		Status: resp.StatusOK,
+		ID:     id,
+	})
+}
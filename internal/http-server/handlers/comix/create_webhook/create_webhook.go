@@ -0,0 +1,146 @@
+package create_webhook
+
+import (
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator"
+	"jadesheart/comix_back/internal/http-server/middleware/ratelimit"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/audit"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/lib/webhook"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Request - events - события из webhook.Events, пустой список - все события.
+// Secret - секрет подписи, не передан - создаётся случайный.
+type Request struct {
+	Password string   `json:"password" validate:"required"`
+	URL      string   `json:"url" validate:"required,max=2000"`
+	Events   []string `json:"events"`
+	Secret   string   `json:"secret" validate:"omitempty,min=16,max=200"`
+}
+
+type Response struct {
+	Status  int               `json:"status,omitempty"`
+	Error   string            `json:"error,omitempty"`
+	Webhook *postgres.Webhook `json:"webhook,omitempty"`
+}
+
+type WebhookCreator interface {
+	CreateWebhook(url string, secret string, events []string) (postgres.Webhook, error)
+	CheckPass(inputPass string) (bool, error)
+	AddAuditEvent(event postgres.AuditEvent) error
+}
+
+// New регистрирует вебхук. Секрет возвращается только в ответе на этот запрос.
+func New(log *slog.Logger, webhookCreator WebhookCreator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.create_webhook.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("failed decode request json", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed decode request json"))
+
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Error("failed validate", sl.Err(err))
+
+			render.JSON(w, r, resp.ValidateErrors(validateErr))
+
+			return
+		}
+
+		target, err := url.Parse(req.URL)
+		if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+			render.JSON(w, r, resp.Error("url must be an absolute http or https URL"))
+
+			return
+		}
+
+		for _, event := range req.Events {
+			if !webhook.ValidEvent(event) {
+				render.JSON(w, r, resp.Error("unknown event "+event+", events are: "+strings.Join(webhook.Events, ", ")))
+
+				return
+			}
+		}
+
+		res, err := webhookCreator.CheckPass(req.Password)
+		if err != nil {
+			log.Error("failed password verified", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed password verified"))
+
+			return
+		}
+
+		if !res {
+			log.Warn("incorrect password", slog.String("remote_addr", ratelimit.ClientIP(r)))
+
+			ratelimit.AuthFailed(r.Context())
+
+			render.JSON(w, r, resp.Error("incorrect password"))
+
+			return
+		}
+
+		ratelimit.AuthSucceeded(r.Context())
+
+		secret := req.Secret
+		if secret == "" {
+			secret, err = webhook.NewSecret()
+			if err != nil {
+				log.Error("failed create webhook secret", sl.Err(err))
+
+				render.JSON(w, r, resp.Error("failed create webhook"))
+
+				return
+			}
+		}
+
+		created, err := webhookCreator.CreateWebhook(target.String(), secret, req.Events)
+		if err != nil {
+			log.Error("failed create webhook", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed create webhook"))
+
+			return
+		}
+
+		logged := created
+		logged.Secret = ""
+
+		err = webhookCreator.AddAuditEvent(audit.NewEvent(r, audit.ActionWebhookCreate, audit.WebhookTarget(created.ID), nil, logged))
+		if err != nil {
+			log.Error("failed write audit event", sl.Err(err))
+		}
+
+		responseOK(w, r, created)
+
+	}
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request, created postgres.Webhook) {
+	render.JSON(w, r, Response{
+		Status:  resp.StatusOK,
+		Webhook: &created,
+	})
+}
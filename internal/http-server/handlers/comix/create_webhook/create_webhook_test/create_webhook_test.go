@@ -0,0 +1,116 @@
+package create_webhook_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/create_webhook"
+	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type ResponseMock struct {
+	Status  int               `json:"status,omitempty"`
+	Error   string            `json:"error,omitempty"`
+	Webhook *postgres.Webhook `json:"webhook,omitempty"`
+}
+
+type MockWebhookCreator struct {
+	created []postgres.Webhook
+	events  []postgres.AuditEvent
+}
+
+func (m *MockWebhookCreator) CreateWebhook(url string, secret string, events []string) (postgres.Webhook, error) {
+	webhook := postgres.Webhook{ID: len(m.created) + 1, URL: url, Secret: secret, Events: events}
+	m.created = append(m.created, webhook)
+	return webhook, nil
+}
+
+func (m *MockWebhookCreator) CheckPass(inputPass string) (bool, error) {
+	return inputPass == "password", nil
+}
+
+func (m *MockWebhookCreator) AddAuditEvent(event postgres.AuditEvent) error {
+	m.events = append(m.events, event)
+	return nil
+}
+
+func doRequest(t *testing.T, creator *MockWebhookCreator, body map[string]interface{}) ResponseMock {
+	handler := create_webhook.New(slogdiscard.NewDiscardLogger(), creator)
+
+	jsonBody, _ := json.Marshal(body)
+
+	req, err := http.NewRequest("POST", "/webhooks/create", bytes.NewBuffer(jsonBody))
+	assert.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	var responseBody ResponseMock
+
+	if err := json.Unmarshal(rr.Body.Bytes(), &responseBody); err != nil {
+		t.Fatalf("Ошибка при распоковке JSON: %s", err)
+	}
+
+	return responseBody
+}
+
+func TestCreateWebhook(t *testing.T) {
+	creator := &MockWebhookCreator{}
+
+	responseBody := doRequest(t, creator, map[string]interface{}{
+		"password": "password",
+		"url":      "https://example.com/hooks/comix",
+		"events":   []string{"comix.created", "comix.pages_added"},
+	})
+
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+	assert.Equal(t, "https://example.com/hooks/comix", responseBody.Webhook.URL)
+	assert.Equal(t, []string{"comix.created", "comix.pages_added"}, responseBody.Webhook.Events)
+	// Секрет создаётся сервером и отдаётся один раз
+	assert.Len(t, responseBody.Webhook.Secret, 64)
+
+	assert.Len(t, creator.events, 1)
+	assert.Equal(t, "webhook.create", creator.events[0].Action)
+	assert.Equal(t, "webhook:1", creator.events[0].Target)
+	assert.NotContains(t, string(creator.events[0].After), responseBody.Webhook.Secret)
+}
+
+func TestCreateWebhook_OwnSecret(t *testing.T) {
+	creator := &MockWebhookCreator{}
+
+	responseBody := doRequest(t, creator, map[string]interface{}{
+		"password": "password",
+		"url":      "http://localhost:9000/hook",
+		"secret":   "0123456789abcdef",
+	})
+
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+	assert.Equal(t, "0123456789abcdef", creator.created[0].Secret)
+	assert.Empty(t, creator.created[0].Events)
+}
+
+func TestCreateWebhook_InvalidRequest(t *testing.T) {
+	requestsBody := []map[string]interface{}{
+		{"url": "https://example.com/hook"},
+		{"password": "password"},
+		{"password": "password", "url": "ftp://example.com/hook"},
+		{"password": "password", "url": "/hook"},
+		{"password": "password", "url": "https://example.com/hook", "events": []string{"comix.purged"}},
+		{"password": "password", "url": "https://example.com/hook", "secret": "short"},
+		{"password": "wrong_password", "url": "https://example.com/hook"},
+	}
+
+	creator := &MockWebhookCreator{}
+
+	for _, body := range requestsBody {
+		responseBody := doRequest(t, creator, body)
+		assert.Equal(t, http.StatusBadRequest, responseBody.Status, body)
+	}
+
+	assert.Empty(t, creator.created)
+	assert.Empty(t, creator.events)
+}
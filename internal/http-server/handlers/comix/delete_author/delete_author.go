@@ -0,0 +1,119 @@
+package delete_author
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator"
+	"jadesheart/comix_back/internal/http-server/middleware/ratelimit"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/audit"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+)
+
+type Request struct {
+	Password string `json:"password" validate:"required"`
+	Slug     string `json:"slug" validate:"required"`
+}
+
+type Response struct {
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type AuthorDeleter interface {
+	GetAuthorBySlug(authorSlug string) (postgres.Author, error)
+	DeleteAuthor(authorSlug string) error
+	CheckPass(inputPass string) (bool, error)
+	AddAuditEvent(event postgres.AuditEvent) error
+}
+
+func New(log *slog.Logger, authorDeleter AuthorDeleter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.delete_author.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("failed decode request json", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed decode request json"))
+
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Error("failed validate", sl.Err(err))
+
+			render.JSON(w, r, resp.ValidateErrors(validateErr))
+
+			return
+		}
+
+		res, err := authorDeleter.CheckPass(req.Password)
+		if err != nil {
+			log.Error("failed password verified", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed password verified"))
+
+			return
+		}
+
+		if !res {
+			log.Warn("incorrect password", slog.String("remote_addr", ratelimit.ClientIP(r)))
+
+			ratelimit.AuthFailed(r.Context())
+
+			render.JSON(w, r, resp.Error("incorrect password"))
+
+			return
+		}
+
+		ratelimit.AuthSucceeded(r.Context())
+
+		before, err := authorDeleter.GetAuthorBySlug(req.Slug)
+		if err != nil && !errors.Is(err, storage.ErrAuthorNotFound) {
+			log.Error("failed get author", sl.Err(err))
+		}
+
+		err = authorDeleter.DeleteAuthor(req.Slug)
+		if errors.Is(err, storage.ErrAuthorNotFound) {
+			render.JSON(w, r, resp.Error("Author not exists"))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed delete author", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed delete author"))
+
+			return
+		}
+
+		err = authorDeleter.AddAuditEvent(audit.NewEvent(r, audit.ActionAuthorDelete, audit.AuthorTarget(req.Slug), before, nil))
+		if err != nil {
+			log.Error("failed write audit event", sl.Err(err))
+		}
+
+		responseOK(w, r)
+
+	}
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request) {
+	render.JSON(w, r, Response{
+		Status: resp.StatusOK,
+	})
+}
@@ -0,0 +1,60 @@
+package delete_bookmark
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"jadesheart/comix_back/internal/http-server/middleware/session"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+)
+
+type BookmarkDeleter interface {
+	GetComixBySlug(comixSlug string) (postgres.ComixFromAllComix, error)
+	DeleteBookmark(readerID int, comixID int) error
+}
+
+// New убирает комикс по slug из закладок читателя запроса.
+func New(log *slog.Logger, bookmarkDeleter BookmarkDeleter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.delete_bookmark.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		reader, _ := session.Reader(r.Context())
+
+		comix, err := bookmarkDeleter.GetComixBySlug(chi.URLParam(r, "slug"))
+		if errors.Is(err, storage.ErrComixNotFound) {
+			render.JSON(w, r, resp.Error("Comix not exists"))
+
+			return
+		}
+		if err != nil {
+			log.Error("Cannot get comix from bd", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("Cannot get comix from bd"))
+
+			return
+		}
+
+		err = bookmarkDeleter.DeleteBookmark(reader.ID, comix.ID)
+		if err != nil {
+			log.Error("failed delete bookmark", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed delete bookmark"))
+
+			return
+		}
+
+		render.JSON(w, r, resp.OK())
+
+	}
+}
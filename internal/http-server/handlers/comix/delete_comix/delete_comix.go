@@ -1,12 +1,18 @@
 package delete_comix
 
 import (
+	"errors"
 	"fmt"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
 	"github.com/go-playground/validator"
+	"jadesheart/comix_back/internal/http-server/middleware/ratelimit"
 	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/audit"
+	"jadesheart/comix_back/internal/lib/cache"
 	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
 	"log/slog"
 	"net/http"
 	"reflect"
@@ -23,12 +29,17 @@ type Response struct {
 }
 
 type ComixDeleter interface {
-	DeleteComixFromTagTable(tag string, name string) error
-	DeleteComixFromAllComixTable(name string) error
+	SoftDeleteComix(tagName string, name string) error
 	CheckPass(inputPass string) (bool, error)
+	GetComixByName(tagName string, name string) (postgres.Comix, error)
+	AddAuditEvent(event postgres.AuditEvent) error
 }
 
-func New(log *slog.Logger, comixDeleter ComixDeleter) http.HandlerFunc {
+type CacheInvalidator interface {
+	Invalidate(groups ...string)
+}
+
+func New(log *slog.Logger, comixDeleter ComixDeleter, cacheInvalidator CacheInvalidator) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		op := "handlers.comix.delete_comix.New"
 
@@ -79,23 +90,30 @@ func New(log *slog.Logger, comixDeleter ComixDeleter) http.HandlerFunc {
 		}
 
 		if !res {
-			log.Info("INCORRECT PASSWORD", slog.Any("Trying input password: ", req.Password))
+			log.Warn("incorrect password", slog.String("remote_addr", ratelimit.ClientIP(r)))
+
+			ratelimit.AuthFailed(r.Context())
 
 			render.JSON(w, r, resp.Error("incorrect password"))
 
 			return
 		}
 
-		err = comixDeleter.DeleteComixFromTagTable(req.TagName, req.Name)
+		ratelimit.AuthSucceeded(r.Context())
+
+		before, err := comixDeleter.GetComixByName(req.TagName, req.Name)
 		if err != nil {
-			log.Error("Cannot delete comix from bd", sl.Err(err))
+			log.Error("failed get comix before delete", sl.Err(err))
+		}
 
-			render.JSON(w, r, resp.Error("Cannot delete comix from bd"))
+		err = comixDeleter.SoftDeleteComix(req.TagName, req.Name)
+		if errors.Is(err, storage.ErrComixNotFound) {
+			log.Info("comix not exists or already in trash", slog.String("tagName", req.TagName), slog.String("name", req.Name))
+
+			render.JSON(w, r, resp.Error("Comix not exists"))
 
 			return
 		}
-
-		err = comixDeleter.DeleteComixFromAllComixTable(req.Name)
 		if err != nil {
 			log.Error("Cannot delete comix from bd", sl.Err(err))
 
@@ -104,6 +122,19 @@ func New(log *slog.Logger, comixDeleter ComixDeleter) http.HandlerFunc {
 			return
 		}
 
+		cacheInvalidator.Invalidate(cache.KeyMainPage, cache.KeySearch, cache.KeyAllTags, cache.KeyTagComix(req.TagName))
+
+		err = comixDeleter.AddAuditEvent(audit.NewEvent(r, audit.ActionComixDelete, audit.ComixTarget(req.TagName, req.Name), map[string]interface{}{
+			"tagName":     req.TagName,
+			"name":        req.Name,
+			"description": before.Description,
+			"uploadDate":  before.UploadDate,
+			"views":       before.Views,
+		}, nil))
+		if err != nil {
+			log.Error("failed write audit event", sl.Err(err))
+		}
+
 		responseOK(w, r)
 
 	}
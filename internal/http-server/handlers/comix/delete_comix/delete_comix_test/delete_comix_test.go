@@ -4,10 +4,14 @@ import (
 	"bytes"
 	"encoding/json"
 	"jadesheart/comix_back/internal/http-server/handlers/comix/delete_comix"
+	"jadesheart/comix_back/internal/lib/cache"
 	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert" // Импорт библиотеки testify для написания тестов
 )
@@ -15,11 +19,10 @@ import (
 // Определение структуры-заглушки для интерфейса ComixDeleter
 type mockComixDeleter struct{}
 
-func (m *mockComixDeleter) DeleteComixFromTagTable(tag string, name string) error {
-	return nil
-}
-
-func (m *mockComixDeleter) DeleteComixFromAllComixTable(name string) error {
+func (m *mockComixDeleter) SoftDeleteComix(tagName string, name string) error {
+	if name == "comixIsNotExist" {
+		return storage.ErrComixNotFound
+	}
 	return nil
 }
 
@@ -30,6 +33,14 @@ func (m *mockComixDeleter) CheckPass(inputPass string) (bool, error) {
 	return false, nil
 }
 
+func (m *mockComixDeleter) GetComixByName(tagName string, name string) (postgres.Comix, error) {
+	return postgres.Comix{}, nil
+}
+
+func (m *mockComixDeleter) AddAuditEvent(event postgres.AuditEvent) error {
+	return nil
+}
+
 type MockResponse struct {
 	Status int    `json:"status"`
 	Error  string `json:"error,omitempty"`
@@ -40,7 +51,7 @@ func TestDeleteComixHandler_Success(t *testing.T) {
 	mockLogger := slogdiscard.NewDiscardLogger() // инициализируйте ваш mock логгер здесь
 
 	// Создание обработчика, передача логгера и объекта-заглушки
-	handler := delete_comix.New(mockLogger, &mockComixDeleter{})
+	handler := delete_comix.New(mockLogger, &mockComixDeleter{}, cache.New(cache.NewMemory(), time.Minute))
 
 	// Создание тела запроса в формате JSON
 	requestBody := map[string]interface{}{
@@ -72,7 +83,7 @@ func TestDeleteComixHandler_Success(t *testing.T) {
 func TestDeleteComixHandler_InvalidRequest(t *testing.T) {
 	mockLogger := slogdiscard.NewDiscardLogger() // инициализируйте ваш mock логгер здесь
 
-	handler := delete_comix.New(mockLogger, &mockComixDeleter{})
+	handler := delete_comix.New(mockLogger, &mockComixDeleter{}, cache.New(cache.NewMemory(), time.Minute))
 
 	// Создание недопустимого тела запроса (отсутствует обязательное поле "password")
 	invalidRequestBody := map[string]interface{}{
@@ -101,7 +112,7 @@ func TestDeleteComixHandler_InvalidRequest(t *testing.T) {
 func TestDeleteComixHandler_FailedPasswordVerification(t *testing.T) {
 	mockLogger := slogdiscard.NewDiscardLogger()
 
-	handler := delete_comix.New(mockLogger, &mockComixDeleter{})
+	handler := delete_comix.New(mockLogger, &mockComixDeleter{}, cache.New(cache.NewMemory(), time.Minute))
 
 	// Создание запроса с неверным паролем
 	requestBody := map[string]interface{}{
@@ -127,4 +138,33 @@ func TestDeleteComixHandler_FailedPasswordVerification(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, responseBody.Status) // Проверка кода статуса ответа
 }
 
+// Тест удаления несуществующего комикса
+func TestDeleteComixHandler_NotExist(t *testing.T) {
+	mockLogger := slogdiscard.NewDiscardLogger()
+
+	handler := delete_comix.New(mockLogger, &mockComixDeleter{}, cache.New(cache.NewMemory(), time.Minute))
+
+	requestBody := map[string]interface{}{
+		"password": "password",
+		"tagName":  "exampleTag",
+		"name":     "comixIsNotExist",
+	}
+
+	jsonBody, _ := json.Marshal(requestBody)
+	req, err := http.NewRequest("POST", "/delete", bytes.NewBuffer(jsonBody))
+	assert.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	var responseBody MockResponse
+
+	if err := json.Unmarshal(rr.Body.Bytes(), &responseBody); err != nil {
+		t.Errorf("Ошибка в распоковке JSON: %s", err)
+		return
+	}
+
+	assert.Equal(t, http.StatusBadRequest, responseBody.Status)
+}
+
 // Дополнительные тесты могут быть добавлены для покрытия других сценариев ошибок в вашем обработчике
@@ -0,0 +1,57 @@
+package delete_comment
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"jadesheart/comix_back/internal/http-server/middleware/session"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/storage"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type CommentDeleter interface {
+	DeleteComment(readerID int, id int64) error
+}
+
+// New удаляет комментарий {id} читателя запроса. Ответы на него остаются в ветке.
+func New(log *slog.Logger, commentDeleter CommentDeleter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.delete_comment.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil || id < 1 {
+			render.JSON(w, r, resp.Error("Comment not exists"))
+
+			return
+		}
+
+		reader, _ := session.Reader(r.Context())
+
+		err = commentDeleter.DeleteComment(reader.ID, id)
+		if errors.Is(err, storage.ErrCommentNotFound) {
+			render.JSON(w, r, resp.Error("Comment not exists"))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed delete comment", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed delete comment"))
+
+			return
+		}
+
+		render.JSON(w, r, resp.OK())
+
+	}
+}
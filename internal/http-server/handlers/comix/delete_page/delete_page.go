@@ -0,0 +1,142 @@
+package delete_page
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator"
+	"jadesheart/comix_back/internal/http-server/middleware/ratelimit"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/audit"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/lib/photos"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+	"os"
+)
+
+type Request struct {
+	Password string `json:"password" validate:"required"`
+	TagName  string `json:"tagName" validate:"required"`
+	Name     string `json:"name" validate:"required"`
+	PageID   int    `json:"pageId" validate:"required,min=1"`
+}
+
+type Response struct {
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type PageDeleter interface {
+	DeletePage(tagName string, name string, pageID int) (string, error)
+	CheckPass(inputPass string) (bool, error)
+	AddAuditEvent(event postgres.AuditEvent) error
+}
+
+func New(log *slog.Logger, pageDeleter PageDeleter, mediaRoot *photos.Root) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.delete_page.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("failed decode request json", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed decode request json"))
+
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Error("failed validate", sl.Err(err))
+
+			render.JSON(w, r, resp.ValidateErrors(validateErr))
+
+			return
+		}
+
+		comixDir, err := mediaRoot.ComixDir(req.TagName, req.Name)
+		if err != nil {
+			log.Warn("unsafe comix name", sl.Err(err), slog.String("remote_addr", ratelimit.ClientIP(r)))
+
+			render.JSON(w, r, resp.Error("invalid comix name"))
+
+			return
+		}
+
+		res, err := pageDeleter.CheckPass(req.Password)
+		if err != nil {
+			log.Error("failed password verified", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed password verified"))
+
+			return
+		}
+
+		if !res {
+			log.Warn("incorrect password", slog.String("remote_addr", ratelimit.ClientIP(r)))
+
+			ratelimit.AuthFailed(r.Context())
+
+			render.JSON(w, r, resp.Error("incorrect password"))
+
+			return
+		}
+
+		ratelimit.AuthSucceeded(r.Context())
+
+		file, err := pageDeleter.DeletePage(req.TagName, req.Name, req.PageID)
+		if errors.Is(err, storage.ErrComixNotFound) {
+			render.JSON(w, r, resp.Error("Comix not exists"))
+
+			return
+		}
+		if errors.Is(err, storage.ErrPageNotFound) {
+			render.JSON(w, r, resp.Error("Page not exists"))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed delete page", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed delete page"))
+
+			return
+		}
+
+		pagePath, err := mediaRoot.Join(comixDir, file)
+		if err == nil {
+			err = os.Remove(pagePath)
+		}
+		if err != nil && !os.IsNotExist(err) {
+			log.Error("failed remove page file", sl.Err(err))
+		}
+
+		err = pageDeleter.AddAuditEvent(audit.NewEvent(r, audit.ActionPageDelete, audit.ComixTarget(req.TagName, req.Name), map[string]interface{}{
+			"page": req.PageID,
+			"file": file,
+		}, nil))
+		if err != nil {
+			log.Error("failed write audit event", sl.Err(err))
+		}
+
+		responseOK(w, r)
+
+	}
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request) {
+	render.JSON(w, r, Response{
+		Status: resp.StatusOK,
+	})
+}
@@ -0,0 +1,103 @@
+package delete_page_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/delete_page"
+	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"jadesheart/comix_back/internal/lib/photos"
+	"jadesheart/comix_back/internal/lib/photos/photostest"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type ResponseMock struct {
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type MockPageDeleter struct{}
+
+func (m *MockPageDeleter) DeletePage(tagName string, name string, pageID int) (string, error) {
+	if name != "Watchmen" {
+		return "", storage.ErrComixNotFound
+	}
+	if pageID != 3 {
+		return "", storage.ErrPageNotFound
+	}
+	return "page-3.jpg", nil
+}
+
+func (m *MockPageDeleter) CheckPass(inputPass string) (bool, error) {
+	return inputPass == "password", nil
+}
+
+func (m *MockPageDeleter) AddAuditEvent(event postgres.AuditEvent) error {
+	return nil
+}
+
+func doRequest(t *testing.T, mediaRoot *photos.Root, body map[string]interface{}) ResponseMock {
+	jsonBody, _ := json.Marshal(body)
+
+	req, err := http.NewRequest("POST", "/deletepage", bytes.NewBuffer(jsonBody))
+	assert.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	delete_page.New(slogdiscard.NewDiscardLogger(), &MockPageDeleter{}, mediaRoot).ServeHTTP(rr, req)
+
+	var responseBody ResponseMock
+
+	if err := json.Unmarshal(rr.Body.Bytes(), &responseBody); err != nil {
+		t.Fatalf("Ошибка при распоковке JSON: %s", err)
+	}
+
+	return responseBody
+}
+
+func TestDeletePage_Success(t *testing.T) {
+	mediaRoot := photostest.NewRoot(t)
+
+	comixDir := filepath.Join(mediaRoot.Dir(), "Horror", "Watchmen")
+	assert.NoError(t, os.MkdirAll(comixDir, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(comixDir, "page-3.jpg"), []byte("page"), 0644))
+
+	responseBody := doRequest(t, mediaRoot, map[string]interface{}{
+		"password": "password",
+		"tagName":  "horror",
+		"name":     "Watchmen",
+		"pageId":   3,
+	})
+
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+
+	_, err := os.Stat(filepath.Join(comixDir, "page-3.jpg"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestDeletePage_InvalidRequest(t *testing.T) {
+	cases := []struct {
+		body  map[string]interface{}
+		error string
+	}{
+		{map[string]interface{}{"password": "password", "tagName": "Horror", "name": "Watchmen", "pageId": 4}, "Page not exists"},
+		{map[string]interface{}{"password": "password", "tagName": "Horror", "name": "Sandman", "pageId": 3}, "Comix not exists"},
+		{map[string]interface{}{"password": "wrong_password", "tagName": "Horror", "name": "Watchmen", "pageId": 3}, "incorrect password"},
+		{map[string]interface{}{"password": "password", "tagName": "Horror", "name": "..", "pageId": 3}, "invalid comix name"},
+		{map[string]interface{}{"password": "password", "tagName": "..", "name": "etc", "pageId": 3}, "invalid comix name"},
+	}
+
+	mediaRoot := photostest.NewRoot(t)
+
+	for _, c := range cases {
+		responseBody := doRequest(t, mediaRoot, c.body)
+
+		assert.Equal(t, http.StatusBadRequest, responseBody.Status)
+		assert.Equal(t, c.error, responseBody.Error)
+	}
+}
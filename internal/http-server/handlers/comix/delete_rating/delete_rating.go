@@ -0,0 +1,83 @@
+package delete_rating
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"jadesheart/comix_back/internal/http-server/middleware/session"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+)
+
+type Response struct {
+	Status int                    `json:"status,omitempty"`
+	Error  string                 `json:"error,omitempty"`
+	Rating postgres.RatingSummary `json:"rating"`
+}
+
+type RatingDeleter interface {
+	GetComixBySlug(comixSlug string) (postgres.ComixFromAllComix, error)
+	DeleteRating(readerID int, comixID int) (postgres.RatingSummary, error)
+}
+
+// New убирает оценку и отзыв читателя запроса к комиксу {slug} и отдаёт новую сводку оценок.
+func New(log *slog.Logger, ratingDeleter RatingDeleter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.delete_rating.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		reader, _ := session.Reader(r.Context())
+
+		comix, err := ratingDeleter.GetComixBySlug(chi.URLParam(r, "slug"))
+		if errors.Is(err, storage.ErrComixNotFound) {
+			render.JSON(w, r, resp.Error("Comix not exists"))
+
+			return
+		}
+		if err != nil {
+			log.Error("Cannot get comix from bd", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("Cannot get comix from bd"))
+
+			return
+		}
+
+		summary, err := ratingDeleter.DeleteRating(reader.ID, comix.ID)
+		if errors.Is(err, storage.ErrComixNotFound) {
+			render.JSON(w, r, resp.Error("Comix not exists"))
+
+			return
+		}
+		if errors.Is(err, storage.ErrRatingNotFound) {
+			render.JSON(w, r, resp.Error("Rating not exists"))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed delete rating", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed delete rating"))
+
+			return
+		}
+
+		responseOK(w, r, summary)
+
+	}
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request, summary postgres.RatingSummary) {
+	render.JSON(w, r, Response{
+		Status: resp.StatusOK,
+		Rating: summary,
+	})
+}
@@ -0,0 +1,49 @@
+package delete_reading_list
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"jadesheart/comix_back/internal/http-server/middleware/session"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/storage"
+	"log/slog"
+	"net/http"
+)
+
+type ReadingListDeleter interface {
+	DeleteReadingList(readerID int, listSlug string) error
+}
+
+// New удаляет список читателя запроса.
+func New(log *slog.Logger, readingListDeleter ReadingListDeleter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.delete_reading_list.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		reader, _ := session.Reader(r.Context())
+
+		err := readingListDeleter.DeleteReadingList(reader.ID, chi.URLParam(r, "slug"))
+		if errors.Is(err, storage.ErrListNotFound) {
+			render.JSON(w, r, resp.Error("List not exists"))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed delete reading list", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed delete reading list"))
+
+			return
+		}
+
+		render.JSON(w, r, resp.OK())
+
+	}
+}
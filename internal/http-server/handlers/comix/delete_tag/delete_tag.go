@@ -0,0 +1,156 @@
+package delete_tag
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator"
+	"jadesheart/comix_back/internal/http-server/middleware/ratelimit"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/audit"
+	"jadesheart/comix_back/internal/lib/cache"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/lib/photos"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+	"os"
+)
+
+type Request struct {
+	Password string `json:"password" validate:"required"`
+	TagName  string `json:"tagName" validate:"required"`
+	// Force - удалить тэг вместе со всеми его комиксами
+	Force bool `json:"force"`
+}
+
+type Response struct {
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type TagDeleter interface {
+	DeleteTag(tagName string, force bool) error
+	GetTagDescription(tagName string) (string, error)
+	CheckPass(inputPass string) (bool, error)
+	AddAuditEvent(event postgres.AuditEvent) error
+}
+
+type CacheInvalidator interface {
+	Invalidate(groups ...string)
+}
+
+func New(log *slog.Logger, tagDeleter TagDeleter, cacheInvalidator CacheInvalidator, mediaRoot *photos.Root) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.delete_tag.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("failed decode request json", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed decode request json"))
+
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Error("failed validate", sl.Err(err))
+
+			render.JSON(w, r, resp.ValidateErrors(validateErr))
+
+			return
+		}
+
+		tagDir, err := mediaRoot.TagDir(req.TagName)
+		if err != nil {
+			log.Warn("unsafe tag name", sl.Err(err), slog.String("remote_addr", ratelimit.ClientIP(r)))
+
+			render.JSON(w, r, resp.Error("invalid tag name"))
+
+			return
+		}
+
+		res, err := tagDeleter.CheckPass(req.Password)
+		if err != nil {
+			log.Error("failed password verified", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed password verified"))
+
+			return
+		}
+
+		if !res {
+			log.Warn("incorrect password", slog.String("remote_addr", ratelimit.ClientIP(r)))
+
+			ratelimit.AuthFailed(r.Context())
+
+			render.JSON(w, r, resp.Error("incorrect password"))
+
+			return
+		}
+
+		ratelimit.AuthSucceeded(r.Context())
+
+		description, err := tagDeleter.GetTagDescription(req.TagName)
+		if err != nil {
+			log.Error("failed get tag description before delete", sl.Err(err))
+		}
+
+		err = tagDeleter.DeleteTag(req.TagName, req.Force)
+		if errors.Is(err, storage.ErrTagNotFound) {
+			render.JSON(w, r, resp.Error("Tag not exists"))
+
+			return
+		}
+		if errors.Is(err, storage.ErrTagNotEmpty) {
+			log.Info("tag still has comix", slog.String("tagName", req.TagName))
+
+			render.JSON(w, r, resp.Error("tag still has comix, use force to delete them too"))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed delete tag", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed delete tag"))
+
+			return
+		}
+
+		err = os.RemoveAll(tagDir)
+		if err != nil {
+			log.Error("failed remove tag folder", sl.Err(err))
+		}
+
+		cacheInvalidator.Invalidate(cache.KeyAllTags, cache.KeyMainPage, cache.KeySearch,
+			cache.KeyTagDescription(req.TagName), cache.KeyTagComix(req.TagName))
+
+		err = tagDeleter.AddAuditEvent(audit.NewEvent(r, audit.ActionTagDelete, audit.TagTarget(req.TagName), map[string]interface{}{
+			"tagName":     req.TagName,
+			"description": description,
+			"force":       req.Force,
+		}, nil))
+		if err != nil {
+			log.Error("failed write audit event", sl.Err(err))
+		}
+
+		responseOK(w, r)
+
+	}
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request) {
+	render.JSON(w, r, Response{
+		Status: resp.StatusOK,
+	})
+}
@@ -0,0 +1,157 @@
+package delete_tag_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/delete_tag"
+	"jadesheart/comix_back/internal/lib/cache"
+	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"jadesheart/comix_back/internal/lib/photos"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type ResponseMock struct {
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// MockTagDeleter - тэг "Horror" содержит комиксы, "Empty" пустой.
+type MockTagDeleter struct{}
+
+func (m *MockTagDeleter) DeleteTag(tagName string, force bool) error {
+	switch tagName {
+	case "Horror":
+		if !force {
+			return storage.ErrTagNotEmpty
+		}
+		return nil
+	case "Empty":
+		return nil
+	}
+	return storage.ErrTagNotFound
+}
+
+func (m *MockTagDeleter) GetTagDescription(tagName string) (string, error) {
+	return "description", nil
+}
+
+func (m *MockTagDeleter) CheckPass(inputPass string) (bool, error) {
+	return inputPass == "password", nil
+}
+
+func (m *MockTagDeleter) AddAuditEvent(event postgres.AuditEvent) error {
+	return nil
+}
+
+func doRequest(t *testing.T, mediaRoot *photos.Root, body map[string]interface{}) ResponseMock {
+	handler := delete_tag.New(slogdiscard.NewDiscardLogger(), &MockTagDeleter{}, cache.New(cache.NewMemory(), time.Minute), mediaRoot)
+
+	jsonBody, _ := json.Marshal(body)
+
+	req, err := http.NewRequest("POST", "/deletetag", bytes.NewBuffer(jsonBody))
+	assert.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	var responseBody ResponseMock
+
+	if err := json.Unmarshal(rr.Body.Bytes(), &responseBody); err != nil {
+		t.Fatalf("Ошибка при распоковке JSON: %s", err)
+	}
+
+	return responseBody
+}
+
+func setupPhotos(t *testing.T) *photos.Root {
+	mediaRoot, err := photos.NewRoot(t.TempDir())
+	assert.NoError(t, err)
+
+	assert.NoError(t, os.MkdirAll(filepath.Join(mediaRoot.Dir(), "Horror", "comix"), 0755))
+
+	return mediaRoot
+}
+
+func TestDeleteTag_NotEmpty(t *testing.T) {
+	mediaRoot := setupPhotos(t)
+
+	responseBody := doRequest(t, mediaRoot, map[string]interface{}{
+		"password": "password",
+		"tagName":  "Horror",
+	})
+
+	assert.Equal(t, http.StatusBadRequest, responseBody.Status)
+	assert.Equal(t, "tag still has comix, use force to delete them too", responseBody.Error)
+
+	_, err := os.Stat(filepath.Join(mediaRoot.Dir(), "Horror", "comix"))
+	assert.NoError(t, err)
+}
+
+func TestDeleteTag_Force(t *testing.T) {
+	mediaRoot := setupPhotos(t)
+
+	responseBody := doRequest(t, mediaRoot, map[string]interface{}{
+		"password": "password",
+		"tagName":  "horror",
+		"force":    true,
+	})
+
+	assert.Equal(t, http.StatusBadRequest, responseBody.Status)
+
+	responseBody = doRequest(t, mediaRoot, map[string]interface{}{
+		"password": "password",
+		"tagName":  "Horror",
+		"force":    true,
+	})
+
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+
+	_, err := os.Stat(filepath.Join(mediaRoot.Dir(), "Horror"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestDeleteTag_InvalidRequest(t *testing.T) {
+	requestsBody := []map[string]interface{}{
+		{"tagName": "Empty"},
+		{"password": "password"},
+		{"password": "wrong_password", "tagName": "Empty"},
+	}
+
+	mediaRoot := setupPhotos(t)
+
+	for _, m := range requestsBody {
+		responseBody := doRequest(t, mediaRoot, m)
+
+		assert.Equal(t, http.StatusBadRequest, responseBody.Status)
+	}
+}
+
+func TestDeleteTag_UnsafeName(t *testing.T) {
+	mediaRoot := setupPhotos(t)
+
+	outside := filepath.Join(t.TempDir(), "outside")
+	assert.NoError(t, os.Mkdir(outside, 0755))
+	assert.NoError(t, os.Symlink(outside, filepath.Join(mediaRoot.Dir(), "Link")))
+
+	for _, tagName := range []string{"..", "Horror/comix", "Link"} {
+		responseBody := doRequest(t, mediaRoot, map[string]interface{}{
+			"password": "password",
+			"tagName":  tagName,
+			"force":    true,
+		})
+
+		assert.Equal(t, http.StatusBadRequest, responseBody.Status)
+		assert.Equal(t, "invalid tag name", responseBody.Error)
+	}
+
+	_, err := os.Stat(outside)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,44 @@
+package delete_upload
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/lib/upload"
+	"log/slog"
+	"net/http"
+)
+
+type UploadRemover interface {
+	Remove(id string) error
+}
+
+// New отменяет загрузку по протоколу tus (DELETE) и удаляет уже полученные данные.
+func New(log *slog.Logger, uploadRemover UploadRemover) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.delete_upload.New"
+
+		id := chi.URLParam(r, "id")
+
+		w.Header().Set(upload.HeaderResumable, upload.TusVersion)
+
+		err := uploadRemover.Remove(id)
+		switch {
+		case err == nil:
+			w.WriteHeader(http.StatusNoContent)
+		case errors.Is(err, upload.ErrNotFound):
+			http.Error(w, "upload not found", http.StatusNotFound)
+		case errors.Is(err, upload.ErrBusy):
+			http.Error(w, "upload is being written by another request", http.StatusLocked)
+		default:
+			log.Error("failed remove upload", sl.Err(err),
+				slog.String("op", op),
+				slog.String("request_id", middleware.GetReqID(r.Context())),
+				slog.String("upload", id),
+			)
+
+			http.Error(w, "failed remove upload", http.StatusInternalServerError)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package download_comix
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/get_comix_by_slug"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/archive"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/lib/pdf"
+	"jadesheart/comix_back/internal/lib/photos"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Форматы скачивания
+const (
+	FormatCBZ = "cbz"
+	FormatPDF = "pdf"
+)
+
+// Path - маршрут скачивания по id комикса: Path + "{id}/download"
+const Path = "/comics/"
+
+// downloadTimeout заменяет общий WriteTimeout сервера: большой комикс не успевает уйти за несколько секунд
+const downloadTimeout = 10 * time.Minute
+
+type ComixGetter interface {
+	GetComixBySlug(comixSlug string) (postgres.ComixFromAllComix, error)
+	GetComixByID(id int) (postgres.ComixFromAllComix, error)
+	GetComixPages(tagName string, name string) ([]postgres.Page, error)
+}
+
+// New отдаёт комикс одним файлом: cbz с ComicInfo.xml или pdf, страницы в том же порядке, что и в get_comix_photo.
+// Комикс ищется по {id} на Path + "{id}/download" или по {slug} на get_comix_by_slug.Path + "{slug}/download".
+// Файл собирается по ходу отправки и целиком в памяти не держится.
+func New(log *slog.Logger, comixGetter ComixGetter, mediaRoot *photos.Root) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.download_comix.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = FormatCBZ
+		}
+		if format != FormatCBZ && format != FormatPDF {
+			render.JSON(w, r, resp.Error("format must be cbz or pdf"))
+
+			return
+		}
+
+		comixSlug := chi.URLParam(r, "slug")
+
+		comix, err := getComix(comixGetter, chi.URLParam(r, "id"), comixSlug)
+		if errors.Is(err, storage.ErrComixNotFound) {
+			render.JSON(w, r, resp.Error("Comix not exists"))
+
+			return
+		}
+		if err != nil {
+			log.Error("Cannot get comix from bd", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("Cannot get comix from bd"))
+
+			return
+		}
+
+		if comixSlug != "" && comix.Slug != comixSlug {
+			http.Redirect(w, r, get_comix_by_slug.Path+comix.Slug+"/download?"+r.URL.RawQuery, http.StatusMovedPermanently)
+
+			return
+		}
+
+		pages, err := comixGetter.GetComixPages(comix.ComixTag, comix.ComixName)
+		if err != nil {
+			log.Error("Cannot get comix pages from bd", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("Cannot get comix from bd"))
+
+			return
+		}
+
+		if len(pages) == 0 {
+			render.JSON(w, r, resp.Error("Comix has no pages"))
+
+			return
+		}
+
+		comixDir, err := mediaRoot.ComixDir(comix.ComixTag, comix.ComixName)
+		if err != nil {
+			log.Error("unsafe comix directory", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed read pages"))
+
+			return
+		}
+
+		paths := make([]string, 0, len(pages))
+		for _, page := range pages {
+			path, err := mediaRoot.Join(comixDir, page.File)
+			if err != nil {
+				log.Error("unsafe page path", sl.Err(err))
+
+				render.JSON(w, r, resp.Error("failed read pages"))
+
+				return
+			}
+
+			paths = append(paths, path)
+		}
+
+		// Страницы проверяются до первого байта ответа, пока ещё можно ответить ошибкой
+		var pdfPages []pdf.Page
+		if format == FormatPDF {
+			pdfPages, err = pdf.Scan(paths)
+			if errors.Is(err, pdf.ErrUnsupportedImage) {
+				log.Warn("comix has pages unsupported in pdf", sl.Err(err))
+
+				render.JSON(w, r, resp.Error("Comix has pages that can not be put in pdf, download cbz"))
+
+				return
+			}
+			if err != nil {
+				log.Error("failed read pages", sl.Err(err))
+
+				render.JSON(w, r, resp.Error("failed read pages"))
+
+				return
+			}
+		}
+
+		_ = http.NewResponseController(w).SetWriteDeadline(time.Now().Add(downloadTimeout))
+
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+			"filename": comix.ComixName + "." + format,
+		}))
+
+		switch format {
+		case FormatPDF:
+			w.Header().Set("Content-Type", "application/pdf")
+
+			err = pdf.Write(w, pdf.Info{
+				Title:    comix.ComixName,
+				Author:   strings.Join(comix.Authors, ", "),
+				Subject:  comix.Description,
+				Keywords: comix.ComixTag,
+			}, pdfPages)
+		default:
+			w.Header().Set("Content-Type", "application/vnd.comicbook+zip")
+
+			err = archive.WriteCBZ(w, comicInfo(comix), paths)
+		}
+
+		// Заголовки уже ушли, ответить ошибкой нельзя: клиент получит оборванный файл
+		if err != nil {
+			log.Error("failed write comix file", sl.Err(err), slog.String("format", format))
+		}
+	}
+}
+
+// getComix находит комикс по id, если он есть в маршруте, иначе по slug
+func getComix(comixGetter ComixGetter, idParam string, comixSlug string) (postgres.ComixFromAllComix, error) {
+	if idParam == "" {
+		return comixGetter.GetComixBySlug(comixSlug)
+	}
+
+	id, err := strconv.Atoi(idParam)
+	if err != nil || id < 1 {
+		return postgres.ComixFromAllComix{}, storage.ErrComixNotFound
+	}
+
+	return comixGetter.GetComixByID(id)
+}
+
+// comicInfo - ComicInfo.xml для cbz: название, тэг, описание и сведения о комиксе
+func comicInfo(comix postgres.ComixFromAllComix) archive.ComicInfo {
+	return archive.ComicInfo{
+		Title:       comix.ComixName,
+		Series:      comix.ComixName,
+		Summary:     comix.Description,
+		Writer:      strings.Join(comix.Authors, ", "),
+		Tags:        comix.ComixTag,
+		LanguageISO: comix.Language,
+	}
+}
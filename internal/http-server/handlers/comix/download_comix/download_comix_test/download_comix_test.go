@@ -0,0 +1,170 @@
+package download_comix_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"image"
+	"image/jpeg"
+	"io"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/download_comix"
+	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"jadesheart/comix_back/internal/lib/photos"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type MockComixGetter struct{}
+
+func (m *MockComixGetter) GetComixBySlug(comixSlug string) (postgres.ComixFromAllComix, error) {
+	if comixSlug != "watchmen" && comixSlug != "old-watchmen" {
+		return postgres.ComixFromAllComix{}, storage.ErrComixNotFound
+	}
+
+	return watchmen(), nil
+}
+
+func (m *MockComixGetter) GetComixByID(id int) (postgres.ComixFromAllComix, error) {
+	if id != 7 {
+		return postgres.ComixFromAllComix{}, storage.ErrComixNotFound
+	}
+
+	return watchmen(), nil
+}
+
+func watchmen() postgres.ComixFromAllComix {
+	comix := postgres.ComixFromAllComix{
+		ID:          7,
+		Slug:        "watchmen",
+		ComixName:   "Watchmen",
+		ComixTag:    "horror",
+		Description: "Who watches the watchmen?",
+	}
+	comix.Authors = []string{"Alan Moore"}
+
+	return comix
+}
+
+func (m *MockComixGetter) GetComixPages(tagName string, name string) ([]postgres.Page, error) {
+	return []postgres.Page{
+		{ID: 2, Position: 1, File: "page-b.jpg"},
+		{ID: 1, Position: 2, File: "page-a.jpg"},
+	}, nil
+}
+
+// setup переходит во временную папку с комиксом Horror/Watchmen из двух страниц
+func setup(t *testing.T) *photos.Root {
+	wd, err := os.Getwd()
+	assert.NoError(t, err)
+	assert.NoError(t, os.Chdir(t.TempDir()))
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	comixDir := filepath.Join("internal/storage/web/photos", "Horror", "Watchmen")
+	assert.NoError(t, os.MkdirAll(comixDir, 0755))
+
+	for _, name := range []string{"page-a.jpg", "page-b.jpg"} {
+		buf := &bytes.Buffer{}
+		assert.NoError(t, jpeg.Encode(buf, image.NewGray(image.Rect(0, 0, 10, 20)), nil))
+		assert.NoError(t, os.WriteFile(filepath.Join(comixDir, name), buf.Bytes(), 0644))
+	}
+
+	mediaRoot, err := photos.NewRoot("internal/storage/web/photos")
+	assert.NoError(t, err)
+
+	return mediaRoot
+}
+
+func doRequest(mediaRoot *photos.Root, url string) *httptest.ResponseRecorder {
+	router := chi.NewRouter()
+	handler := download_comix.New(slogdiscard.NewDiscardLogger(), &MockComixGetter{}, mediaRoot)
+	router.Get("/api/comix/{slug}/download", handler)
+	router.Get(download_comix.Path+"{id}/download", handler)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, url, nil))
+
+	return rr
+}
+
+func TestDownloadComix_CBZ(t *testing.T) {
+	mediaRoot := setup(t)
+
+	rr := doRequest(mediaRoot, "/api/comix/watchmen/download?format=cbz")
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/vnd.comicbook+zip", rr.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename=Watchmen.cbz`, rr.Header().Get("Content-Disposition"))
+
+	zr, err := zip.NewReader(bytes.NewReader(rr.Body.Bytes()), int64(rr.Body.Len()))
+	assert.NoError(t, err)
+
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	assert.Equal(t, []string{"ComicInfo.xml", "001.jpg", "002.jpg"}, names)
+
+	// Страницы идут в порядке из базы, а не по именам файлов
+	first, err := zr.File[1].Open()
+	assert.NoError(t, err)
+	data, _ := io.ReadAll(first)
+	expected, _ := os.ReadFile(filepath.Join("internal/storage/web/photos/Horror/Watchmen", "page-b.jpg"))
+	assert.Equal(t, expected, data)
+
+	info, err := zr.File[0].Open()
+	assert.NoError(t, err)
+	xml, _ := io.ReadAll(info)
+	assert.Contains(t, string(xml), "<Title>Watchmen</Title>")
+	assert.Contains(t, string(xml), "<Tags>horror</Tags>")
+	assert.Contains(t, string(xml), "<Summary>Who watches the watchmen?</Summary>")
+	assert.Contains(t, string(xml), "<PageCount>2</PageCount>")
+}
+
+func TestDownloadComix_PDF(t *testing.T) {
+	mediaRoot := setup(t)
+
+	rr := doRequest(mediaRoot, "/api/comix/watchmen/download?format=pdf")
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/pdf", rr.Header().Get("Content-Type"))
+	assert.True(t, bytes.HasPrefix(rr.Body.Bytes(), []byte("%PDF-")))
+	assert.Contains(t, rr.Body.String(), "/Count 2")
+}
+
+func TestDownloadComix_ByID(t *testing.T) {
+	mediaRoot := setup(t)
+
+	rr := doRequest(mediaRoot, "/comics/7/download?format=cbz")
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `attachment; filename=Watchmen.cbz`, rr.Header().Get("Content-Disposition"))
+
+	zr, err := zip.NewReader(bytes.NewReader(rr.Body.Bytes()), int64(rr.Body.Len()))
+	assert.NoError(t, err)
+	assert.Len(t, zr.File, 3)
+}
+
+func TestDownloadComix_RedirectsOldSlug(t *testing.T) {
+	mediaRoot := setup(t)
+
+	rr := doRequest(mediaRoot, "/api/comix/old-watchmen/download?format=pdf")
+
+	assert.Equal(t, http.StatusMovedPermanently, rr.Code)
+	assert.Equal(t, "/api/comix/watchmen/download?format=pdf", rr.Header().Get("Location"))
+}
+
+func TestDownloadComix_InvalidRequest(t *testing.T) {
+	mediaRoot := setup(t)
+
+	for _, url := range []string{"/api/comix/watchmen/download?format=epub", "/api/comix/unknown/download",
+		"/comics/8/download", "/comics/watchmen/download", "/comics/0/download"} {
+		rr := doRequest(mediaRoot, url)
+		assert.Contains(t, rr.Body.String(), `"status":400`, url)
+	}
+}
@@ -0,0 +1,149 @@
+package edit_author
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator"
+	"jadesheart/comix_back/internal/http-server/middleware/ratelimit"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/audit"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// Request - автор ищется по slug, не переданные поля не меняются
+type Request struct {
+	Password string    `json:"password" validate:"required"`
+	Slug     string    `json:"slug" validate:"required"`
+	Name     *string   `json:"name" validate:"omitempty,min=1,max=200"`
+	Bio      *string   `json:"bio" validate:"omitempty,max=5000"`
+	Avatar   *string   `json:"avatar" validate:"omitempty,url"`
+	Links    *[]string `json:"links" validate:"omitempty,max=20,dive,url"`
+}
+
+type Response struct {
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+	Slug   string `json:"slug,omitempty"`
+}
+
+type AuthorEditor interface {
+	GetAuthorBySlug(authorSlug string) (postgres.Author, error)
+	EditAuthor(authorSlug string, update postgres.AuthorUpdate) (postgres.Author, error)
+	CheckPass(inputPass string) (bool, error)
+	AddAuditEvent(event postgres.AuditEvent) error
+}
+
+func New(log *slog.Logger, authorEditor AuthorEditor) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.edit_author.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("failed decode request json", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed decode request json"))
+
+			return
+		}
+
+		if req.Name != nil {
+			name := strings.TrimSpace(*req.Name)
+			req.Name = &name
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Error("failed validate", sl.Err(err))
+
+			render.JSON(w, r, resp.ValidateErrors(validateErr))
+
+			return
+		}
+
+		if req.Name == nil && req.Bio == nil && req.Avatar == nil && req.Links == nil {
+			render.JSON(w, r, resp.Error("nothing to change: pass name, bio, avatar or links"))
+
+			return
+		}
+
+		if req.Name != nil && *req.Name == "" {
+			render.JSON(w, r, resp.Error("name cannot be empty"))
+
+			return
+		}
+
+		res, err := authorEditor.CheckPass(req.Password)
+		if err != nil {
+			log.Error("failed password verified", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed password verified"))
+
+			return
+		}
+
+		if !res {
+			log.Warn("incorrect password", slog.String("remote_addr", ratelimit.ClientIP(r)))
+
+			ratelimit.AuthFailed(r.Context())
+
+			render.JSON(w, r, resp.Error("incorrect password"))
+
+			return
+		}
+
+		ratelimit.AuthSucceeded(r.Context())
+
+		before, err := authorEditor.GetAuthorBySlug(req.Slug)
+		if err != nil && !errors.Is(err, storage.ErrAuthorNotFound) {
+			log.Error("failed get author", sl.Err(err))
+		}
+
+		author, err := authorEditor.EditAuthor(req.Slug, postgres.AuthorUpdate{
+			Name:   req.Name,
+			Bio:    req.Bio,
+			Avatar: req.Avatar,
+			Links:  req.Links,
+		})
+		if errors.Is(err, storage.ErrAuthorNotFound) {
+			render.JSON(w, r, resp.Error("Author not exists"))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed edit author", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed edit author"))
+
+			return
+		}
+
+		err = authorEditor.AddAuditEvent(audit.NewEvent(r, audit.ActionAuthorEdit, audit.AuthorTarget(req.Slug), before, author))
+		if err != nil {
+			log.Error("failed write audit event", sl.Err(err))
+		}
+
+		responseOK(w, r, author.Slug)
+
+	}
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request, slug string) {
+	render.JSON(w, r, Response{
+		Status: resp.StatusOK,
+		Slug:   slug,
+	})
+}
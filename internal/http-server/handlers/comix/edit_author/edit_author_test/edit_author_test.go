@@ -0,0 +1,104 @@
+package edit_author_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/edit_author"
+	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type ResponseMock struct {
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+	Slug   string `json:"slug,omitempty"`
+}
+
+type MockAuthorEditor struct {
+	update postgres.AuthorUpdate
+}
+
+func (m *MockAuthorEditor) GetAuthorBySlug(authorSlug string) (postgres.Author, error) {
+	if authorSlug != "alan-moore" {
+		return postgres.Author{}, storage.ErrAuthorNotFound
+	}
+	return postgres.Author{ID: 1, Slug: "alan-moore", Name: "Alan Moore"}, nil
+}
+
+func (m *MockAuthorEditor) EditAuthor(authorSlug string, update postgres.AuthorUpdate) (postgres.Author, error) {
+	if authorSlug != "alan-moore" {
+		return postgres.Author{}, storage.ErrAuthorNotFound
+	}
+	m.update = update
+	author := postgres.Author{ID: 1, Slug: "alan-moore", Name: "Alan Moore"}
+	if update.Name != nil {
+		author.Slug = "a-moore"
+	}
+	return author, nil
+}
+
+func (m *MockAuthorEditor) CheckPass(inputPass string) (bool, error) {
+	return inputPass == "password", nil
+}
+
+func (m *MockAuthorEditor) AddAuditEvent(event postgres.AuditEvent) error {
+	return nil
+}
+
+func doRequest(t *testing.T, editor *MockAuthorEditor, body map[string]interface{}) ResponseMock {
+	handler := edit_author.New(slogdiscard.NewDiscardLogger(), editor)
+
+	jsonBody, _ := json.Marshal(body)
+
+	req, err := http.NewRequest("POST", "/editauthor", bytes.NewBuffer(jsonBody))
+	assert.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	var responseBody ResponseMock
+
+	if err := json.Unmarshal(rr.Body.Bytes(), &responseBody); err != nil {
+		t.Fatalf("Ошибка при распоковке JSON: %s", err)
+	}
+
+	return responseBody
+}
+
+func TestEditAuthor_Rename(t *testing.T) {
+	editor := &MockAuthorEditor{}
+
+	responseBody := doRequest(t, editor, map[string]interface{}{
+		"password": "password",
+		"slug":     "alan-moore",
+		"name":     "A. Moore",
+		"links":    []string{},
+	})
+
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+	assert.Equal(t, "a-moore", responseBody.Slug)
+	assert.Equal(t, "A. Moore", *editor.update.Name)
+	assert.Equal(t, []string{}, *editor.update.Links)
+	assert.Nil(t, editor.update.Bio)
+}
+
+func TestEditAuthor_InvalidRequest(t *testing.T) {
+	cases := []map[string]interface{}{
+		{"password": "password", "slug": "alan-moore"},
+		{"password": "password", "slug": "alan-moore", "name": " "},
+		{"password": "password", "slug": "alan-moore", "links": []string{"not a url"}},
+		{"password": "wrong_password", "slug": "alan-moore", "bio": "Writer"},
+		{"password": "password", "slug": "missing", "bio": "Writer"},
+	}
+
+	for _, c := range cases {
+		responseBody := doRequest(t, &MockAuthorEditor{}, c)
+
+		assert.Equal(t, http.StatusBadRequest, responseBody.Status)
+	}
+}
@@ -1,18 +1,22 @@
 package edit_comix
 
 import (
+	"errors"
 	"fmt"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
 	"github.com/go-playground/validator"
-	"io"
-	"io/ioutil"
+	"jadesheart/comix_back/internal/http-server/middleware/ratelimit"
 	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/audit"
+	"jadesheart/comix_back/internal/lib/cache"
+	"jadesheart/comix_back/internal/lib/comixmeta"
 	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/lib/photos"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
 	"log/slog"
 	"net/http"
-	"os"
-	"path/filepath"
 	"reflect"
 )
 
@@ -26,16 +30,37 @@ type Request struct {
 type Response struct {
 	Status int    `json:"status,omitempty"`
 	Error  string `json:"error,omitempty"`
+	Slug   string `json:"slug,omitempty"`
 }
 
 type ComixEditor interface {
 	EditComixFromAllComixTable(name string, param string, newValue string) error
 	EditComixFromTagTable(tag string, name string, param string, newValue string) error
-	EditComixTag(tag string, name string, newValue string) error
+	MoveComixToTag(tagName string, name string, newTagName string) error
 	CheckPass(inputPass string) (bool, error)
+	GetComixByName(tagName string, name string) (postgres.Comix, error)
+	CheckComixExists(tagName string, name string) (bool, error)
+	ComixInTrash(tagName string, name string) (bool, error)
+	RenameComix(tagName string, name string, newName string) (string, error)
+	AddAuditEvent(event postgres.AuditEvent) error
 }
 
-func New(log *slog.Logger, comixEditor ComixEditor) http.HandlerFunc {
+type CacheInvalidator interface {
+	Invalidate(groups ...string)
+}
+
+// editableParams - параметры, которые меняются через default-ветку: столбец all_comix и
+// столбец таблицы тэга, пустой - если в таблице тэга такого нет. Имя параметра подставляется
+// в запрос, поэтому всё остальное (id, slug, views...) отклоняется до обращения к базе.
+var editableParams = map[string]string{
+	"description": "description",
+	"comix_date":  "upload_date",
+	"status":      "",
+	"language":    "",
+	"age_rating":  "",
+}
+
+func New(log *slog.Logger, comixEditor ComixEditor, cacheInvalidator CacheInvalidator, mediaRoot *photos.Root) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		op := "handlers.comix.edit_comix.New"
 
@@ -76,6 +101,35 @@ func New(log *slog.Logger, comixEditor ComixEditor) http.HandlerFunc {
 			return
 		}
 
+		if msg := validateParam(req.Param, req.NewValue); msg != "" {
+			log.Info("rejected comix param", slog.String("param", req.Param))
+
+			render.JSON(w, r, resp.Error(msg))
+
+			return
+		}
+
+		// Папка комикса проверяется до изменений в базе: после них переносить её за пределы папки медиа уже поздно
+		newTag, newName := req.TagName, req.Name
+		switch req.Param {
+		case "comix_name":
+			newName = req.NewValue
+		case "comix_tag":
+			newTag = req.NewValue
+		}
+
+		_, err = mediaRoot.ComixDir(req.TagName, req.Name)
+		if err == nil {
+			_, err = mediaRoot.ComixDir(newTag, newName)
+		}
+		if err != nil {
+			log.Warn("unsafe comix name", sl.Err(err), slog.String("remote_addr", ratelimit.ClientIP(r)))
+
+			render.JSON(w, r, resp.Error("invalid comix name"))
+
+			return
+		}
+
 		res, err := comixEditor.CheckPass(req.Password)
 		if err != nil {
 			log.Error("failed password verified", sl.Err(err))
@@ -86,41 +140,43 @@ func New(log *slog.Logger, comixEditor ComixEditor) http.HandlerFunc {
 		}
 
 		if !res {
-			log.Info("INCORRECT PASSWORD", slog.Any("Trying input password: ", req.Password))
+			log.Warn("incorrect password", slog.String("remote_addr", ratelimit.ClientIP(r)))
+
+			ratelimit.AuthFailed(r.Context())
 
 			render.JSON(w, r, resp.Error("incorrect password"))
 
 			return
 		}
 
-		err = comixEditor.EditComixFromAllComixTable(req.Name, req.Param, req.NewValue)
+		ratelimit.AuthSucceeded(r.Context())
+
+		before, err := comixEditor.GetComixByName(req.TagName, req.Name)
 		if err != nil {
-			log.Error("failed edit comix", sl.Err(err))
+			log.Error("failed get comix before edit", sl.Err(err))
+		}
 
-			render.JSON(w, r, resp.Error("failed edit comix"))
+		var newSlug string
 
-			return
-		}
-		if req.Param == "comix_tag" {
-			err = comixEditor.EditComixTag(req.TagName, req.Name, req.NewValue)
+		switch req.Param {
+		case "comix_name":
+			newSlug, err = renameComix(log, comixEditor, mediaRoot, req.TagName, req.Name, req.NewValue)
 			if err != nil {
-				log.Error("failed edit comix tag", sl.Err(err))
-
-				render.JSON(w, r, resp.Error("failed edit comix tag"))
+				render.JSON(w, r, resp.Error(err.Error()))
 
 				return
 			}
-			err = editComixDir(req.TagName, req.Name[:len(req.Name)-1], req.NewValue)
-			if err != nil {
-				log.Error("failed edit comix photo dir", sl.Err(err))
 
-				render.JSON(w, r, resp.Error("failed edit comix photo dir"))
+		case "comix_tag":
+			err = moveComixToTag(log, comixEditor, mediaRoot, req.TagName, req.Name, req.NewValue)
+			if err != nil {
+				render.JSON(w, r, resp.Error(err.Error()))
 
 				return
 			}
 
-		} else {
-			err = comixEditor.EditComixFromTagTable(req.TagName, req.Name, req.Param, req.NewValue)
+		default:
+			err = comixEditor.EditComixFromAllComixTable(req.Name, req.Param, req.NewValue)
 			if err != nil {
 				log.Error("failed edit comix", sl.Err(err))
 
@@ -128,76 +184,189 @@ func New(log *slog.Logger, comixEditor ComixEditor) http.HandlerFunc {
 
 				return
 			}
+
+			if column := editableParams[req.Param]; column != "" {
+				err = comixEditor.EditComixFromTagTable(req.TagName, req.Name, column, req.NewValue)
+				if err != nil {
+					log.Error("failed edit comix", sl.Err(err))
+
+					render.JSON(w, r, resp.Error("failed edit comix"))
+
+					return
+				}
+			}
 		}
-		responseOK(w, r)
+
+		groups := []string{cache.KeyMainPage, cache.KeySearch, cache.KeyTagComix(req.TagName)}
+		if req.Param == "comix_tag" {
+			groups = append(groups, cache.KeyAllTags, cache.KeyTagComix(req.NewValue))
+		}
+		cacheInvalidator.Invalidate(groups...)
+
+		err = comixEditor.AddAuditEvent(audit.NewEvent(r, audit.ActionComixEdit, audit.ComixTarget(req.TagName, req.Name), map[string]interface{}{
+			req.Param: oldValue(before, req.TagName, req.Name, req.Param),
+		}, map[string]string{
+			req.Param: req.NewValue,
+		}))
+		if err != nil {
+			log.Error("failed write audit event", sl.Err(err))
+		}
+
+		responseOK(w, r, newSlug)
 
 	}
 }
 
-func responseOK(w http.ResponseWriter, r *http.Request) {
+func responseOK(w http.ResponseWriter, r *http.Request, slug string) {
 	render.JSON(w, r, Response{
 		Status: resp.StatusOK,
+		Slug:   slug,
 	})
 }
 
-func editComixDir(tag string, name string, newTag string) error {
-	sourceDir := fmt.Sprintf("internal/storage/web/photos/%s/%s", tag, name)
-	destDir := fmt.Sprintf("internal/storage/web/photos/%s/%s", newTag, name)
+// validateParam проверяет, что параметр можно менять и новое значение допустимо.
+// Возвращает текст ошибки для клиента или пустую строку.
+func validateParam(param string, newValue string) string {
+	switch param {
+	case "comix_name", "comix_tag":
+		return ""
+	case "status":
+		if !comixmeta.ValidStatus(newValue) {
+			return "status must be ongoing, completed or hiatus"
+		}
+	case "language":
+		if !comixmeta.ValidLanguage(newValue) {
+			return "language must be a two-letter ISO 639-1 code"
+		}
+	case "age_rating":
+		if !comixmeta.ValidAgeRating(newValue) {
+			return "age_rating must be one of 0+, 6+, 12+, 16+, 18+"
+		}
+	default:
+		if _, ok := editableParams[param]; !ok {
+			return "param must be one of comix_name, comix_tag, description, comix_date, status, language, age_rating"
+		}
+	}
+
+	return ""
+}
+
+// oldValue - значение изменённого поля до изменения; nil, если поле не читается в postgres.Comix
+func oldValue(before postgres.Comix, tag string, name string, param string) interface{} {
+	switch param {
+	case "comix_tag":
+		return tag
+	case "comix_name":
+		return name
+	case "description":
+		return before.Description
+	case "comix_date":
+		return before.UploadDate
+	case "status":
+		return before.Status
+	case "language":
+		return before.Language
+	case "age_rating":
+		return before.AgeRating
+	default:
+		return nil
+	}
+}
 
-	// Создание новой директории
-	err := os.MkdirAll(destDir, 0755)
+// renameComix переименовывает комикс вместе с папкой страниц.
+// Папка переносится первой и возвращается обратно, если база не приняла переименование.
+// Возвращаемая ошибка - текст для ответа клиенту, подробности пишутся в лог.
+func renameComix(log *slog.Logger, comixEditor ComixEditor, mediaRoot *photos.Root, tag string, name string, newName string) (string, error) {
+	exists, err := comixEditor.CheckComixExists(tag, newName)
 	if err != nil {
-		return err
+		log.Error("Cannot check exists comix in table", sl.Err(err))
+
+		return "", errors.New("Cannot check exists comix in table")
 	}
 
-	// Получение файлов из исходной директории
-	files, err := ioutil.ReadDir(sourceDir)
+	inTrash, err := comixEditor.ComixInTrash(tag, newName)
 	if err != nil {
-		return err
+		log.Error("Cannot check comix in trash", sl.Err(err))
+
+		return "", errors.New("Cannot check comix in trash")
 	}
 
-	// Копирование файлов в новую директорию
-	for _, file := range files {
-		sourceFile := filepath.Join(sourceDir, file.Name())
-		destFile := filepath.Join(destDir, file.Name())
+	if exists || inTrash {
+		log.Info("comix with new name already exists", slog.String("newName", newName))
 
-		err = copyFile(sourceFile, destFile)
-		if err != nil {
-			return err
-		}
+		return "", errors.New("Comix already exists")
 	}
 
-	// Удаление старой директории
-	err = os.RemoveAll(sourceDir)
+	err = moveComixDir(mediaRoot, tag, name, tag, newName)
 	if err != nil {
-		return err
+		log.Error("failed move comix photo dir", sl.Err(err))
+
+		return "", errors.New("failed edit comix photo dir")
 	}
-	return nil
+
+	newSlug, err := comixEditor.RenameComix(tag, name, newName)
+	if err != nil {
+		log.Error("failed rename comix", sl.Err(err))
+
+		if err := moveComixDir(mediaRoot, tag, newName, tag, name); err != nil {
+			log.Error("failed move comix photo dir back", sl.Err(err))
+		}
+
+		if errors.Is(err, storage.ErrComixNotFound) {
+			return "", errors.New("Comix not exists")
+		}
+
+		return "", errors.New("failed rename comix")
+	}
+
+	return newSlug, nil
 }
 
-// Функция для копирования файла
-func copyFile(src, dst string) error {
-	sourceFile, err := os.Open(src)
+// moveComixToTag переносит комикс в другой тэг вместе с папкой страниц.
+// Папка переносится до транзакции в базе и возвращается обратно, если база не приняла перенос.
+// Возвращаемая ошибка - текст для ответа клиенту, подробности пишутся в лог.
+func moveComixToTag(log *slog.Logger, comixEditor ComixEditor, mediaRoot *photos.Root, tag string, name string, newTag string) error {
+	err := moveComixDir(mediaRoot, tag, name, newTag, name)
 	if err != nil {
-		return err
+		log.Error("failed move comix photo dir", sl.Err(err))
+
+		return errors.New("failed edit comix photo dir")
 	}
-	defer sourceFile.Close()
 
-	destFile, err := os.Create(dst)
+	err = comixEditor.MoveComixToTag(tag, name, newTag)
 	if err != nil {
-		return err
+		log.Error("failed move comix to tag", sl.Err(err))
+
+		if err := moveComixDir(mediaRoot, newTag, name, tag, name); err != nil {
+			log.Error("failed move comix photo dir back", sl.Err(err))
+		}
+
+		switch {
+		case errors.Is(err, storage.ErrComixNotFound):
+			return errors.New("Comix not exists")
+		case errors.Is(err, storage.ErrTagNotFound):
+			return errors.New("Tag not exists")
+		case errors.Is(err, storage.ErrComixExists):
+			return errors.New("Comix already exists")
+		}
+
+		return errors.New("failed edit comix tag")
 	}
-	defer destFile.Close()
 
-	_, err = io.Copy(destFile, sourceFile)
+	return nil
+}
+
+// moveComixDir переносит папку страниц комикса одним переименованием.
+func moveComixDir(mediaRoot *photos.Root, tag string, name string, newTag string, newName string) error {
+	sourceDir, err := mediaRoot.ComixDir(tag, name)
 	if err != nil {
 		return err
 	}
 
-	err = destFile.Sync()
+	destDir, err := mediaRoot.ComixDir(newTag, newName)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return photos.MoveDir(sourceDir, destDir)
 }
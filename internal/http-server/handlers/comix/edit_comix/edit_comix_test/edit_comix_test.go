@@ -5,26 +5,43 @@ import (
 	"encoding/json"
 	"github.com/stretchr/testify/assert"
 	"jadesheart/comix_back/internal/http-server/handlers/comix/edit_comix"
+	"jadesheart/comix_back/internal/lib/cache"
 	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"jadesheart/comix_back/internal/lib/photos"
+	"jadesheart/comix_back/internal/lib/photos/photostest"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 )
 
 type ResponseMock struct {
 	Status int    `json:"status,omitempty"`
 	Error  string `json:"error,omitempty"`
+	Slug   string `json:"slug,omitempty"`
 }
 
-type MockComixEditor struct{}
+type MockComixEditor struct {
+	events []postgres.AuditEvent
+	edited []string
+}
 
 func (m *MockComixEditor) EditComixFromAllComixTable(name string, param string, newValue string) error {
+	m.edited = append(m.edited, "all_comix."+param)
 	return nil
 }
 func (m *MockComixEditor) EditComixFromTagTable(tag string, name string, param string, newValue string) error {
+	m.edited = append(m.edited, tag+"."+param)
 	return nil
 }
-func (m *MockComixEditor) EditComixTag(tag string, name string, newValue string) error {
+func (m *MockComixEditor) MoveComixToTag(tagName string, name string, newTagName string) error {
+	if newTagName == "missingTag" {
+		return storage.ErrTagNotFound
+	}
 	return nil
 }
 
@@ -35,16 +52,37 @@ func (m *MockComixEditor) CheckPass(inputPass string) (bool, error) {
 	return false, nil
 }
 
+func (m *MockComixEditor) GetComixByName(tagName string, name string) (postgres.Comix, error) {
+	return postgres.Comix{Description: "old description", UploadDate: "2020-01-01"}, nil
+}
+
+func (m *MockComixEditor) CheckComixExists(tagName string, name string) (bool, error) {
+	return name == "existingName", nil
+}
+
+func (m *MockComixEditor) ComixInTrash(tagName string, name string) (bool, error) {
+	return false, nil
+}
+
+func (m *MockComixEditor) RenameComix(tagName string, name string, newName string) (string, error) {
+	return "renamed-comix", nil
+}
+
+func (m *MockComixEditor) AddAuditEvent(event postgres.AuditEvent) error {
+	m.events = append(m.events, event)
+	return nil
+}
+
 func TestEdit_Success(t *testing.T) {
 	mockLogger := slogdiscard.NewDiscardLogger() // инициализируйте ваш mock логгер здесь
 
-	handler := edit_comix.New(mockLogger, &MockComixEditor{})
+	handler := edit_comix.New(mockLogger, &MockComixEditor{}, cache.New(cache.NewMemory(), time.Minute), photostest.NewRoot(t))
 
 	requestBody := map[string]interface{}{
 		"password": "password",
 		"tagName":  "exampleTag",
 		"name":     "exampleName",
-		"param":    "exampleParam",
+		"param":    "description",
 		"newValue": "exampleNewValue",
 	}
 
@@ -69,13 +107,13 @@ func TestEdit_Success(t *testing.T) {
 func TestEdit_IncorrectPassword(t *testing.T) {
 	mockLogger := slogdiscard.NewDiscardLogger() // инициализируйте ваш mock логгер здесь
 
-	handler := edit_comix.New(mockLogger, &MockComixEditor{})
+	handler := edit_comix.New(mockLogger, &MockComixEditor{}, cache.New(cache.NewMemory(), time.Minute), photostest.NewRoot(t))
 
 	requestBody := map[string]interface{}{
 		"password": "wrongPass",
 		"tagName":  "exampleTag",
 		"name":     "exampleName",
-		"param":    "exampleParam",
+		"param":    "description",
 		"newValue": "exampleNewValue",
 	}
 
@@ -104,22 +142,22 @@ func TestEdit_EmptyValueParams(t *testing.T) {
 		{
 			"tagName": "exampleTag",
 			"name":    "exampleName",
-			"param":   "exampleParam",
+			"param":   "description",
 		}, {
 			"password": "password",
 			"tagName":  "exampleTag",
-			"param":    "exampleParam",
+			"param":    "description",
 			"newValue": "exampleNewValue",
 		}, {
 			"password": "password",
 			"name":     "exampleName",
-			"param":    "exampleParam",
+			"param":    "description",
 			"newValue": "exampleNewValue",
 		},
 	}
 
 	for _, m := range requestsBody {
-		handler := edit_comix.New(mockLogger, &MockComixEditor{})
+		handler := edit_comix.New(mockLogger, &MockComixEditor{}, cache.New(cache.NewMemory(), time.Minute), photostest.NewRoot(t))
 
 		jsonBody, _ := json.Marshal(m)
 
@@ -140,3 +178,257 @@ func TestEdit_EmptyValueParams(t *testing.T) {
 	}
 
 }
+
+func TestEdit_RenameMovesPhotoDir(t *testing.T) {
+	mockLogger := slogdiscard.NewDiscardLogger()
+
+	mediaRoot := photostest.NewRoot(t)
+
+	oldDir := filepath.Join(mediaRoot.Dir(), "exampleTag", "exampleName")
+	assert.NoError(t, os.MkdirAll(oldDir, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(oldDir, "1.jpg"), []byte("page"), 0644))
+
+	handler := edit_comix.New(mockLogger, &MockComixEditor{}, cache.New(cache.NewMemory(), time.Minute), mediaRoot)
+
+	jsonBody, _ := json.Marshal(map[string]interface{}{
+		"password": "password",
+		"tagName":  "exampleTag",
+		"name":     "exampleName",
+		"param":    "comix_name",
+		"newValue": "Renamed Comix",
+	})
+
+	req, err := http.NewRequest("POST", "/editcomix", bytes.NewBuffer(jsonBody))
+	assert.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	var responseBody ResponseMock
+
+	if err := json.Unmarshal(rr.Body.Bytes(), &responseBody); err != nil {
+		t.Fatalf("Ошибка при распоковке JSON: %s", err)
+	}
+
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+	assert.Equal(t, "renamed-comix", responseBody.Slug)
+
+	_, err = os.Stat(filepath.Join(mediaRoot.Dir(), "exampleTag", "Renamed Comix", "1.jpg"))
+	assert.NoError(t, err)
+
+	_, err = os.Stat(oldDir)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestEdit_RenameToExistingName(t *testing.T) {
+	mockLogger := slogdiscard.NewDiscardLogger()
+
+	handler := edit_comix.New(mockLogger, &MockComixEditor{}, cache.New(cache.NewMemory(), time.Minute), photostest.NewRoot(t))
+
+	jsonBody, _ := json.Marshal(map[string]interface{}{
+		"password": "password",
+		"tagName":  "exampleTag",
+		"name":     "exampleName",
+		"param":    "comix_name",
+		"newValue": "existingName",
+	})
+
+	req, err := http.NewRequest("POST", "/editcomix", bytes.NewBuffer(jsonBody))
+	assert.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	var responseBody ResponseMock
+
+	if err := json.Unmarshal(rr.Body.Bytes(), &responseBody); err != nil {
+		t.Fatalf("Ошибка при распоковке JSON: %s", err)
+	}
+
+	assert.Equal(t, http.StatusBadRequest, responseBody.Status)
+	assert.Equal(t, "Comix already exists", responseBody.Error)
+}
+
+func TestEdit_UnsafeName(t *testing.T) {
+	mediaRoot := photostest.NewRoot(t)
+
+	comixDir := filepath.Join(mediaRoot.Dir(), "exampleTag", "exampleName")
+	assert.NoError(t, os.MkdirAll(comixDir, 0755))
+
+	handler := edit_comix.New(slogdiscard.NewDiscardLogger(), &MockComixEditor{}, cache.New(cache.NewMemory(), time.Minute), mediaRoot)
+
+	cases := []map[string]interface{}{
+		{"param": "comix_name", "newValue": "../../escaped"},
+		{"param": "comix_tag", "newValue": ".."},
+		{"param": "comix_name", "newValue": ".."},
+	}
+
+	for _, c := range cases {
+		c["password"] = "password"
+		c["tagName"] = "exampleTag"
+		c["name"] = "exampleName"
+
+		jsonBody, _ := json.Marshal(c)
+
+		req, err := http.NewRequest("POST", "/editcomix", bytes.NewBuffer(jsonBody))
+		assert.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		var responseBody ResponseMock
+
+		if err := json.Unmarshal(rr.Body.Bytes(), &responseBody); err != nil {
+			t.Fatalf("Ошибка при распоковке JSON: %s", err)
+		}
+
+		assert.Equal(t, http.StatusBadRequest, responseBody.Status)
+		assert.Equal(t, "invalid comix name", responseBody.Error)
+	}
+
+	_, err := os.Stat(comixDir)
+	assert.NoError(t, err)
+}
+
+func TestEdit_AuditRecordsEditedField(t *testing.T) {
+	cases := []struct {
+		param  string
+		before string
+	}{
+		{param: "description", before: `{"description":"old description"}`},
+		{param: "comix_date", before: `{"comix_date":"2020-01-01"}`},
+		{param: "comix_name", before: `{"comix_name":"exampleName"}`},
+	}
+
+	for _, c := range cases {
+		editor := &MockComixEditor{}
+		handler := edit_comix.New(slogdiscard.NewDiscardLogger(), editor, cache.New(cache.NewMemory(), time.Minute), photostest.NewRoot(t))
+
+		jsonBody, _ := json.Marshal(map[string]interface{}{
+			"password": "password",
+			"tagName":  "exampleTag",
+			"name":     "exampleName",
+			"param":    c.param,
+			"newValue": "newValue",
+		})
+
+		req, err := http.NewRequest("POST", "/editcomix", bytes.NewBuffer(jsonBody))
+		assert.NoError(t, err)
+
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+
+		if assert.Len(t, editor.events, 1, c.param) {
+			assert.JSONEq(t, c.before, string(editor.events[0].Before), c.param)
+			assert.JSONEq(t, `{"`+c.param+`":"newValue"}`, string(editor.events[0].After), c.param)
+		}
+	}
+}
+
+func TestEdit_EditableParams(t *testing.T) {
+	cases := []struct {
+		param    string
+		newValue string
+		edited   []string
+	}{
+		{param: "description", newValue: "new description", edited: []string{"all_comix.description", "exampleTag.description"}},
+		{param: "comix_date", newValue: "2021-02-03", edited: []string{"all_comix.comix_date", "exampleTag.upload_date"}},
+		{param: "status", newValue: "completed", edited: []string{"all_comix.status"}},
+		{param: "language", newValue: "en", edited: []string{"all_comix.language"}},
+		{param: "age_rating", newValue: "16+", edited: []string{"all_comix.age_rating"}},
+	}
+
+	for _, c := range cases {
+		editor := &MockComixEditor{}
+		responseBody := doEdit(t, editor, photostest.NewRoot(t), c.param, c.newValue)
+
+		assert.Equal(t, http.StatusOK, responseBody.Status, c.param)
+		assert.Equal(t, c.edited, editor.edited, c.param)
+	}
+}
+
+func TestEdit_RejectsNotEditableParams(t *testing.T) {
+	cases := []struct {
+		param    string
+		newValue string
+	}{
+		{param: "id", newValue: "1"},
+		{param: "slug", newValue: "other-comix"},
+		{param: "views", newValue: "1000000"},
+		{param: "deleted_at", newValue: "2020-01-01"},
+		{param: "description = 'x', views", newValue: "1"},
+		{param: "status", newValue: "abandoned"},
+		{param: "language", newValue: "english"},
+		{param: "age_rating", newValue: "21+"},
+	}
+
+	for _, c := range cases {
+		editor := &MockComixEditor{}
+		responseBody := doEdit(t, editor, photostest.NewRoot(t), c.param, c.newValue)
+
+		assert.Equal(t, http.StatusBadRequest, responseBody.Status, c.param)
+		assert.Empty(t, editor.edited, c.param)
+		assert.Empty(t, editor.events, c.param)
+	}
+}
+
+func TestEdit_MoveToTagMovesPhotoDir(t *testing.T) {
+	mediaRoot := photostest.NewRoot(t)
+
+	oldDir := filepath.Join(mediaRoot.Dir(), "exampleTag", "exampleName")
+	assert.NoError(t, os.MkdirAll(oldDir, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(oldDir, "1.jpg"), []byte("page"), 0644))
+
+	responseBody := doEdit(t, &MockComixEditor{}, mediaRoot, "comix_tag", "otherTag")
+
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+
+	_, err := os.Stat(filepath.Join(mediaRoot.Dir(), "otherTag", "exampleName", "1.jpg"))
+	assert.NoError(t, err)
+
+	_, err = os.Stat(oldDir)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestEdit_MoveToTagFailedMovesPhotoDirBack(t *testing.T) {
+	mediaRoot := photostest.NewRoot(t)
+
+	oldDir := filepath.Join(mediaRoot.Dir(), "exampleTag", "exampleName")
+	assert.NoError(t, os.MkdirAll(oldDir, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(oldDir, "1.jpg"), []byte("page"), 0644))
+
+	editor := &MockComixEditor{}
+	responseBody := doEdit(t, editor, mediaRoot, "comix_tag", "missingTag")
+
+	assert.Equal(t, http.StatusBadRequest, responseBody.Status)
+	assert.Equal(t, "Tag not exists", responseBody.Error)
+	assert.Empty(t, editor.events)
+
+	_, err := os.Stat(filepath.Join(oldDir, "1.jpg"))
+	assert.NoError(t, err)
+
+	_, err = os.Stat(filepath.Join(mediaRoot.Dir(), "missingTag", "exampleName"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func doEdit(t *testing.T, editor *MockComixEditor, mediaRoot *photos.Root, param string, newValue string) ResponseMock {
+	handler := edit_comix.New(slogdiscard.NewDiscardLogger(), editor, cache.New(cache.NewMemory(), time.Minute), mediaRoot)
+
+	jsonBody, _ := json.Marshal(map[string]interface{}{
+		"password": "password",
+		"tagName":  "exampleTag",
+		"name":     "exampleName",
+		"param":    param,
+		"newValue": newValue,
+	})
+
+	req, err := http.NewRequest("POST", "/editcomix", bytes.NewBuffer(jsonBody))
+	assert.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	var responseBody ResponseMock
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &responseBody))
+
+	return responseBody
+}
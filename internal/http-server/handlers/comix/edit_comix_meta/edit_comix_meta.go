@@ -0,0 +1,177 @@
+package edit_comix_meta
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator"
+	"jadesheart/comix_back/internal/http-server/middleware/ratelimit"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/audit"
+	"jadesheart/comix_back/internal/lib/cache"
+	"jadesheart/comix_back/internal/lib/comixmeta"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// Request - не переданные поля не меняются, пустая строка в language и ageRating их очищает
+type Request struct {
+	Password  string    `json:"password" validate:"required"`
+	TagName   string    `json:"tagName" validate:"required"`
+	Name      string    `json:"name" validate:"required"`
+	Authors   *[]string `json:"authors"`
+	Status    *string   `json:"status"`
+	Language  *string   `json:"language"`
+	AgeRating *string   `json:"ageRating"`
+}
+
+type Response struct {
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type ComixMetaEditor interface {
+	EditComixMeta(tagName string, name string, update postgres.ComixMetaUpdate) error
+	CheckPass(inputPass string) (bool, error)
+	AddAuditEvent(event postgres.AuditEvent) error
+}
+
+type CacheInvalidator interface {
+	Invalidate(groups ...string)
+}
+
+func New(log *slog.Logger, comixMetaEditor ComixMetaEditor, cacheInvalidator CacheInvalidator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.edit_comix_meta.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("failed decode request json", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed decode request json"))
+
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Error("failed validate", sl.Err(err))
+
+			render.JSON(w, r, resp.ValidateErrors(validateErr))
+
+			return
+		}
+
+		if msg := validateMeta(&req); msg != "" {
+			render.JSON(w, r, resp.Error(msg))
+
+			return
+		}
+
+		res, err := comixMetaEditor.CheckPass(req.Password)
+		if err != nil {
+			log.Error("failed password verified", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed password verified"))
+
+			return
+		}
+
+		if !res {
+			log.Warn("incorrect password", slog.String("remote_addr", ratelimit.ClientIP(r)))
+
+			ratelimit.AuthFailed(r.Context())
+
+			render.JSON(w, r, resp.Error("incorrect password"))
+
+			return
+		}
+
+		ratelimit.AuthSucceeded(r.Context())
+
+		err = comixMetaEditor.EditComixMeta(req.TagName, req.Name, postgres.ComixMetaUpdate{
+			Authors:   req.Authors,
+			Status:    req.Status,
+			Language:  req.Language,
+			AgeRating: req.AgeRating,
+		})
+		if errors.Is(err, storage.ErrComixNotFound) {
+			render.JSON(w, r, resp.Error("Comix not exists"))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed edit comix meta", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed edit comix meta"))
+
+			return
+		}
+
+		cacheInvalidator.Invalidate(cache.KeyMainPage, cache.KeySearch, cache.KeyTagComix(req.TagName))
+
+		err = comixMetaEditor.AddAuditEvent(audit.NewEvent(r, audit.ActionComixEdit, audit.ComixTarget(req.TagName, req.Name), nil, map[string]interface{}{
+			"authors":   req.Authors,
+			"status":    req.Status,
+			"language":  req.Language,
+			"ageRating": req.AgeRating,
+		}))
+		if err != nil {
+			log.Error("failed write audit event", sl.Err(err))
+		}
+
+		responseOK(w, r)
+
+	}
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request) {
+	render.JSON(w, r, Response{
+		Status: resp.StatusOK,
+	})
+}
+
+// validateMeta проверяет переданные поля и чистит список авторов от пустых имён.
+// Возвращает текст ошибки для клиента или пустую строку.
+func validateMeta(req *Request) string {
+	if req.Authors == nil && req.Status == nil && req.Language == nil && req.AgeRating == nil {
+		return "nothing to change: pass authors, status, language or ageRating"
+	}
+
+	if req.Authors != nil {
+		authors := make([]string, 0, len(*req.Authors))
+		for _, author := range *req.Authors {
+			author = strings.TrimSpace(author)
+			if author != "" {
+				authors = append(authors, author)
+			}
+		}
+		req.Authors = &authors
+	}
+
+	if req.Status != nil && !comixmeta.ValidStatus(*req.Status) {
+		return "status must be ongoing, completed or hiatus"
+	}
+
+	if req.Language != nil && !comixmeta.ValidLanguage(*req.Language) {
+		return "language must be a two-letter ISO 639-1 code"
+	}
+
+	if req.AgeRating != nil && !comixmeta.ValidAgeRating(*req.AgeRating) {
+		return "ageRating must be one of 0+, 6+, 12+, 16+, 18+"
+	}
+
+	return ""
+}
@@ -0,0 +1,97 @@
+package edit_comix_meta_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/edit_comix_meta"
+	"jadesheart/comix_back/internal/lib/cache"
+	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type ResponseMock struct {
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type MockComixMetaEditor struct {
+	update postgres.ComixMetaUpdate
+}
+
+func (m *MockComixMetaEditor) EditComixMeta(tagName string, name string, update postgres.ComixMetaUpdate) error {
+	if name != "comixExist" {
+		return storage.ErrComixNotFound
+	}
+	m.update = update
+	return nil
+}
+
+func (m *MockComixMetaEditor) CheckPass(inputPass string) (bool, error) {
+	return inputPass == "password", nil
+}
+
+func (m *MockComixMetaEditor) AddAuditEvent(event postgres.AuditEvent) error {
+	return nil
+}
+
+func doRequest(t *testing.T, editor *MockComixMetaEditor, body map[string]interface{}) ResponseMock {
+	handler := edit_comix_meta.New(slogdiscard.NewDiscardLogger(), editor, cache.New(cache.NewMemory(), time.Minute))
+
+	jsonBody, _ := json.Marshal(body)
+
+	req, err := http.NewRequest("POST", "/editcomixmeta", bytes.NewBuffer(jsonBody))
+	assert.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	var responseBody ResponseMock
+
+	if err := json.Unmarshal(rr.Body.Bytes(), &responseBody); err != nil {
+		t.Fatalf("Ошибка при распоковке JSON: %s", err)
+	}
+
+	return responseBody
+}
+
+func TestEditComixMeta_Success(t *testing.T) {
+	editor := &MockComixMetaEditor{}
+
+	responseBody := doRequest(t, editor, map[string]interface{}{
+		"password":  "password",
+		"tagName":   "Horror",
+		"name":      "comixExist",
+		"authors":   []string{" Alan Moore ", ""},
+		"status":    "completed",
+		"ageRating": "",
+	})
+
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+	assert.Equal(t, []string{"Alan Moore"}, *editor.update.Authors)
+	assert.Equal(t, "completed", *editor.update.Status)
+	assert.Equal(t, "", *editor.update.AgeRating)
+	assert.Nil(t, editor.update.Language)
+}
+
+func TestEditComixMeta_InvalidRequest(t *testing.T) {
+	cases := []map[string]interface{}{
+		{"password": "password", "tagName": "Horror", "name": "comixExist"},
+		{"password": "password", "tagName": "Horror", "name": "comixExist", "status": "dropped"},
+		{"password": "password", "tagName": "Horror", "name": "comixExist", "language": "rus"},
+		{"password": "password", "tagName": "Horror", "name": "comixExist", "ageRating": "21+"},
+		{"password": "wrong_password", "tagName": "Horror", "name": "comixExist", "status": "hiatus"},
+		{"password": "password", "tagName": "Horror", "name": "comixNotExist", "status": "hiatus"},
+	}
+
+	for _, c := range cases {
+		responseBody := doRequest(t, &MockComixMetaEditor{}, c)
+
+		assert.Equal(t, http.StatusBadRequest, responseBody.Status)
+	}
+}
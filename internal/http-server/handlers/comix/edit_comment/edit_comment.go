@@ -0,0 +1,100 @@
+package edit_comment
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator"
+	"jadesheart/comix_back/internal/http-server/middleware/session"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type Request struct {
+	Body string `json:"body" validate:"required,max=5000"`
+}
+
+type Response struct {
+	Status  int              `json:"status,omitempty"`
+	Error   string           `json:"error,omitempty"`
+	Comment postgres.Comment `json:"comment"`
+}
+
+type CommentEditor interface {
+	EditComment(readerID int, id int64, body string) (postgres.Comment, error)
+}
+
+// New меняет текст комментария {id}. Изменить можно только свой комментарий.
+func New(log *slog.Logger, commentEditor CommentEditor) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.edit_comment.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil || id < 1 {
+			render.JSON(w, r, resp.Error("Comment not exists"))
+
+			return
+		}
+
+		var req Request
+
+		err = render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("failed decode request json", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed decode request json"))
+
+			return
+		}
+
+		req.Body = strings.TrimSpace(req.Body)
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Error("failed validate", sl.Err(err))
+
+			render.JSON(w, r, resp.ValidateErrors(validateErr))
+
+			return
+		}
+
+		reader, _ := session.Reader(r.Context())
+
+		comment, err := commentEditor.EditComment(reader.ID, id, req.Body)
+		if errors.Is(err, storage.ErrCommentNotFound) {
+			render.JSON(w, r, resp.Error("Comment not exists"))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed edit comment", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed edit comment"))
+
+			return
+		}
+
+		responseOK(w, r, comment)
+
+	}
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request, comment postgres.Comment) {
+	render.JSON(w, r, Response{
+		Status:  resp.StatusOK,
+		Comment: comment,
+	})
+}
@@ -0,0 +1,100 @@
+package edit_reading_list
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator"
+	"jadesheart/comix_back/internal/http-server/middleware/session"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// Request - не переданные поля не меняются. При смене названия меняется slug, старый продолжает работать.
+type Request struct {
+	Name   *string `json:"name" validate:"omitempty,min=1,max=100"`
+	Public *bool   `json:"public"`
+}
+
+type Response struct {
+	Status int                  `json:"status,omitempty"`
+	Error  string               `json:"error,omitempty"`
+	List   postgres.ReadingList `json:"list"`
+}
+
+type ReadingListEditor interface {
+	EditReadingList(readerID int, listSlug string, update postgres.ReadingListUpdate) (postgres.ReadingList, error)
+}
+
+// New изменяет список читателя запроса.
+func New(log *slog.Logger, readingListEditor ReadingListEditor) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.edit_reading_list.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("failed decode request json", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed decode request json"))
+
+			return
+		}
+
+		if req.Name != nil {
+			name := strings.TrimSpace(*req.Name)
+			req.Name = &name
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Error("failed validate", sl.Err(err))
+
+			render.JSON(w, r, resp.ValidateErrors(validateErr))
+
+			return
+		}
+
+		reader, _ := session.Reader(r.Context())
+
+		list, err := readingListEditor.EditReadingList(reader.ID, chi.URLParam(r, "slug"), postgres.ReadingListUpdate{
+			Name:   req.Name,
+			Public: req.Public,
+		})
+		if errors.Is(err, storage.ErrListNotFound) {
+			render.JSON(w, r, resp.Error("List not exists"))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed edit reading list", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed edit reading list"))
+
+			return
+		}
+
+		responseOK(w, r, list)
+
+	}
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request, list postgres.ReadingList) {
+	render.JSON(w, r, Response{
+		Status: resp.StatusOK,
+		List:   list,
+	})
+}
@@ -0,0 +1,244 @@
+package edit_tag
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator"
+	"jadesheart/comix_back/internal/http-server/middleware/ratelimit"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/audit"
+	"jadesheart/comix_back/internal/lib/cache"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/lib/photos"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// Параметры тэга, которые можно изменить
+const (
+	ParamDescription = "description"
+	ParamTagName     = "tag_name"
+)
+
+// tagNamePattern - название тэга одновременно имя таблицы и папки, поэтому только латиница.
+var tagNamePattern = regexp.MustCompile(`^[a-zA-Z]+$`)
+
+type Request struct {
+	Password string `json:"password" validate:"required"`
+	TagName  string `json:"tagName" validate:"required"`
+	Param    string `json:"param" validate:"required"`
+	NewValue string `json:"newValue" validate:"required"`
+}
+
+type Response struct {
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+	Slug   string `json:"slug,omitempty"`
+}
+
+type TagEditor interface {
+	EditTagDescription(tagName string, description string) error
+	RenameTag(tagName string, newTagName string) (string, error)
+	GetTagDescription(tagName string) (string, error)
+	CheckPass(inputPass string) (bool, error)
+	AddAuditEvent(event postgres.AuditEvent) error
+}
+
+type CacheInvalidator interface {
+	Invalidate(groups ...string)
+}
+
+func New(log *slog.Logger, tagEditor TagEditor, cacheInvalidator CacheInvalidator, mediaRoot *photos.Root) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.edit_tag.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("failed decode request json", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed decode request json"))
+
+			return
+		}
+
+		reqType := reflect.TypeOf(req)
+		for i := 0; i < reqType.NumField(); i++ {
+			field := reqType.Field(i)
+			fieldValue := reflect.ValueOf(req).FieldByName(field.Name)
+			if fieldValue.IsZero() {
+				errorMsg := fmt.Sprintf("zero point value: %s", field.Name)
+				render.JSON(w, r, resp.Error(errorMsg))
+				return
+			}
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Error("failed validate", sl.Err(err))
+
+			render.JSON(w, r, resp.ValidateErrors(validateErr))
+
+			return
+		}
+
+		if req.Param != ParamDescription && req.Param != ParamTagName {
+			render.JSON(w, r, resp.Error(fmt.Sprintf("param must be %s or %s", ParamDescription, ParamTagName)))
+
+			return
+		}
+
+		if req.Param == ParamTagName && !tagNamePattern.MatchString(req.NewValue) {
+			render.JSON(w, r, resp.Error("tag name must contain only latin letters"))
+
+			return
+		}
+
+		res, err := tagEditor.CheckPass(req.Password)
+		if err != nil {
+			log.Error("failed password verified", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed password verified"))
+
+			return
+		}
+
+		if !res {
+			log.Warn("incorrect password", slog.String("remote_addr", ratelimit.ClientIP(r)))
+
+			ratelimit.AuthFailed(r.Context())
+
+			render.JSON(w, r, resp.Error("incorrect password"))
+
+			return
+		}
+
+		ratelimit.AuthSucceeded(r.Context())
+
+		var newSlug string
+		var before interface{}
+
+		switch req.Param {
+		case ParamDescription:
+			description, err := tagEditor.GetTagDescription(req.TagName)
+			if err != nil {
+				log.Error("failed get tag description before edit", sl.Err(err))
+			}
+			before = map[string]string{"description": description}
+
+			err = tagEditor.EditTagDescription(req.TagName, req.NewValue)
+			if errors.Is(err, storage.ErrTagNotFound) {
+				render.JSON(w, r, resp.Error("Tag not exists"))
+
+				return
+			}
+			if err != nil {
+				log.Error("failed edit tag description", sl.Err(err))
+
+				render.JSON(w, r, resp.Error("failed edit tag description"))
+
+				return
+			}
+
+			cacheInvalidator.Invalidate(cache.KeyAllTags, cache.KeyTagDescription(req.TagName))
+
+		case ParamTagName:
+			before = map[string]string{"tagName": req.TagName}
+
+			newSlug, err = renameTag(log, tagEditor, mediaRoot, req.TagName, req.NewValue)
+			if err != nil {
+				render.JSON(w, r, resp.Error(err.Error()))
+
+				return
+			}
+
+			cacheInvalidator.Invalidate(cache.KeyAllTags, cache.KeyMainPage, cache.KeySearch,
+				cache.KeyTagDescription(req.TagName), cache.KeyTagComix(req.TagName),
+				cache.KeyTagDescription(req.NewValue), cache.KeyTagComix(req.NewValue))
+		}
+
+		err = tagEditor.AddAuditEvent(audit.NewEvent(r, audit.ActionTagEdit, audit.TagTarget(req.TagName), before, map[string]string{
+			req.Param: req.NewValue,
+		}))
+		if err != nil {
+			log.Error("failed write audit event", sl.Err(err))
+		}
+
+		responseOK(w, r, newSlug)
+
+	}
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request, slug string) {
+	render.JSON(w, r, Response{
+		Status: resp.StatusOK,
+		Slug:   slug,
+	})
+}
+
+// renameTag переименовывает тэг вместе с папкой страниц.
+// Папка переносится первой и возвращается обратно, если база не приняла переименование.
+// При смене только регистра папка не трогается: она ищется без учёта регистра.
+// Возвращаемая ошибка - текст для ответа клиенту, подробности пишутся в лог.
+func renameTag(log *slog.Logger, tagEditor TagEditor, mediaRoot *photos.Root, tagName string, newTagName string) (string, error) {
+	sourceDir, err := mediaRoot.TagDir(tagName)
+	if err != nil {
+		log.Warn("unsafe tag name", sl.Err(err))
+
+		return "", errors.New("invalid tag name")
+	}
+
+	destDir, err := mediaRoot.Resolve(newTagName)
+	if err != nil {
+		log.Warn("unsafe tag name", sl.Err(err))
+
+		return "", errors.New("invalid tag name")
+	}
+
+	moveDir := !strings.EqualFold(tagName, newTagName)
+
+	if moveDir {
+		err = photos.MoveDir(sourceDir, destDir)
+		if err != nil {
+			log.Error("failed move tag photo dir", sl.Err(err))
+
+			return "", errors.New("failed move tag photo dir")
+		}
+	}
+
+	newSlug, err := tagEditor.RenameTag(tagName, newTagName)
+	if err != nil {
+		log.Error("failed rename tag", sl.Err(err))
+
+		if moveDir {
+			if err := photos.MoveDir(destDir, sourceDir); err != nil {
+				log.Error("failed move tag photo dir back", sl.Err(err))
+			}
+		}
+
+		switch {
+		case errors.Is(err, storage.ErrTagNotFound):
+			return "", errors.New("Tag not exists")
+		case errors.Is(err, storage.ComixTagIsExists):
+			return "", errors.New("tag already exists")
+		}
+
+		return "", errors.New("failed rename tag")
+	}
+
+	return newSlug, nil
+}
@@ -0,0 +1,155 @@
+package edit_tag_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/edit_tag"
+	"jadesheart/comix_back/internal/lib/cache"
+	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"jadesheart/comix_back/internal/lib/photos"
+	"jadesheart/comix_back/internal/lib/photos/photostest"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type ResponseMock struct {
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+	Slug   string `json:"slug,omitempty"`
+}
+
+type MockTagEditor struct {
+	description string
+	renamedTo   string
+}
+
+func (m *MockTagEditor) EditTagDescription(tagName string, description string) error {
+	if tagName == "missing" {
+		return storage.ErrTagNotFound
+	}
+	m.description = description
+	return nil
+}
+
+func (m *MockTagEditor) RenameTag(tagName string, newTagName string) (string, error) {
+	if newTagName == "taken" {
+		return "", storage.ComixTagIsExists
+	}
+	m.renamedTo = newTagName
+	return "comedy", nil
+}
+
+func (m *MockTagEditor) GetTagDescription(tagName string) (string, error) {
+	return "old description", nil
+}
+
+func (m *MockTagEditor) CheckPass(inputPass string) (bool, error) {
+	return inputPass == "password", nil
+}
+
+func (m *MockTagEditor) AddAuditEvent(event postgres.AuditEvent) error {
+	return nil
+}
+
+func doRequest(t *testing.T, mediaRoot *photos.Root, editor *MockTagEditor, body map[string]interface{}) ResponseMock {
+	handler := edit_tag.New(slogdiscard.NewDiscardLogger(), editor, cache.New(cache.NewMemory(), time.Minute), mediaRoot)
+
+	jsonBody, _ := json.Marshal(body)
+
+	req, err := http.NewRequest("POST", "/edittag", bytes.NewBuffer(jsonBody))
+	assert.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	var responseBody ResponseMock
+
+	if err := json.Unmarshal(rr.Body.Bytes(), &responseBody); err != nil {
+		t.Fatalf("Ошибка при распоковке JSON: %s", err)
+	}
+
+	return responseBody
+}
+
+func TestEditTag_Description(t *testing.T) {
+	editor := &MockTagEditor{}
+
+	responseBody := doRequest(t, photostest.NewRoot(t), editor, map[string]interface{}{
+		"password": "password",
+		"tagName":  "Horror",
+		"param":    "description",
+		"newValue": "Scary comix",
+	})
+
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+	assert.Equal(t, "Scary comix", editor.description)
+}
+
+func TestEditTag_RenameMovesFolder(t *testing.T) {
+	mediaRoot := photostest.NewRoot(t)
+
+	oldDir := filepath.Join(mediaRoot.Dir(), "Humor", "comix")
+	assert.NoError(t, os.MkdirAll(oldDir, 0755))
+
+	editor := &MockTagEditor{}
+
+	responseBody := doRequest(t, mediaRoot, editor, map[string]interface{}{
+		"password": "password",
+		"tagName":  "humor",
+		"param":    "tag_name",
+		"newValue": "Comedy",
+	})
+
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+	assert.Equal(t, "comedy", responseBody.Slug)
+	assert.Equal(t, "Comedy", editor.renamedTo)
+
+	_, err := os.Stat(filepath.Join(mediaRoot.Dir(), "Comedy", "comix"))
+	assert.NoError(t, err)
+}
+
+func TestEditTag_RenameFailedMovesFolderBack(t *testing.T) {
+	mediaRoot := photostest.NewRoot(t)
+
+	oldDir := filepath.Join(mediaRoot.Dir(), "Humor")
+	assert.NoError(t, os.MkdirAll(oldDir, 0755))
+
+	responseBody := doRequest(t, mediaRoot, &MockTagEditor{}, map[string]interface{}{
+		"password": "password",
+		"tagName":  "Humor",
+		"param":    "tag_name",
+		"newValue": "taken",
+	})
+
+	assert.Equal(t, http.StatusBadRequest, responseBody.Status)
+	assert.Equal(t, "tag already exists", responseBody.Error)
+
+	_, err := os.Stat(oldDir)
+	assert.NoError(t, err)
+}
+
+func TestEditTag_InvalidRequest(t *testing.T) {
+	requestsBody := []map[string]interface{}{
+		{"password": "password", "tagName": "Horror", "param": "views", "newValue": "1"},
+		{"password": "password", "tagName": "Horror", "param": "tag_name", "newValue": "drop table"},
+		{"password": "password", "tagName": "missing", "param": "description", "newValue": "text"},
+		{"password": "wrong_password", "tagName": "Horror", "param": "description", "newValue": "text"},
+		{"password": "password", "param": "description", "newValue": "text"},
+		{"password": "password", "tagName": "..", "param": "tag_name", "newValue": "Comedy"},
+	}
+
+	mediaRoot := photostest.NewRoot(t)
+
+	for _, m := range requestsBody {
+		responseBody := doRequest(t, mediaRoot, &MockTagEditor{}, m)
+
+		assert.Equal(t, http.StatusBadRequest, responseBody.Status)
+	}
+}
@@ -0,0 +1,153 @@
+package edit_tag_meta
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator"
+	"jadesheart/comix_back/internal/http-server/middleware/ratelimit"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/audit"
+	"jadesheart/comix_back/internal/lib/cache"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+	"regexp"
+)
+
+// colorPattern - акцентный цвет тэга в виде #rrggbb
+var colorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// Request - не переданные поля не меняются. parent: "" делает тэг тэгом верхнего уровня, color: "" убирает цвет.
+type Request struct {
+	Password  string  `json:"password" validate:"required"`
+	TagName   string  `json:"tagName" validate:"required"`
+	Parent    *string `json:"parent"`
+	Color     *string `json:"color"`
+	SortOrder *int    `json:"sortOrder"`
+}
+
+type Response struct {
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type TagMetaEditor interface {
+	EditTagMeta(tagName string, meta postgres.TagMeta) error
+	CheckPass(inputPass string) (bool, error)
+	AddAuditEvent(event postgres.AuditEvent) error
+}
+
+type CacheInvalidator interface {
+	Invalidate(groups ...string)
+}
+
+func New(log *slog.Logger, tagMetaEditor TagMetaEditor, cacheInvalidator CacheInvalidator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.edit_tag_meta.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("failed decode request json", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed decode request json"))
+
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Error("failed validate", sl.Err(err))
+
+			render.JSON(w, r, resp.ValidateErrors(validateErr))
+
+			return
+		}
+
+		if req.Parent == nil && req.Color == nil && req.SortOrder == nil {
+			render.JSON(w, r, resp.Error("nothing to change: pass parent, color or sortOrder"))
+
+			return
+		}
+
+		if req.Color != nil && *req.Color != "" && !colorPattern.MatchString(*req.Color) {
+			render.JSON(w, r, resp.Error("color must be in format #rrggbb"))
+
+			return
+		}
+
+		res, err := tagMetaEditor.CheckPass(req.Password)
+		if err != nil {
+			log.Error("failed password verified", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed password verified"))
+
+			return
+		}
+
+		if !res {
+			log.Warn("incorrect password", slog.String("remote_addr", ratelimit.ClientIP(r)))
+
+			ratelimit.AuthFailed(r.Context())
+
+			render.JSON(w, r, resp.Error("incorrect password"))
+
+			return
+		}
+
+		ratelimit.AuthSucceeded(r.Context())
+
+		err = tagMetaEditor.EditTagMeta(req.TagName, postgres.TagMeta{
+			Parent:    req.Parent,
+			Color:     req.Color,
+			SortOrder: req.SortOrder,
+		})
+		if errors.Is(err, storage.ErrTagNotFound) {
+			render.JSON(w, r, resp.Error("Tag not exists"))
+
+			return
+		}
+		if errors.Is(err, storage.ErrTagCycle) {
+			render.JSON(w, r, resp.Error("tag cannot be nested into itself or its subtag"))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed edit tag meta", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed edit tag meta"))
+
+			return
+		}
+
+		cacheInvalidator.Invalidate(cache.KeyAllTags)
+
+		err = tagMetaEditor.AddAuditEvent(audit.NewEvent(r, audit.ActionTagEdit, audit.TagTarget(req.TagName), nil, map[string]interface{}{
+			"parent":    req.Parent,
+			"color":     req.Color,
+			"sortOrder": req.SortOrder,
+		}))
+		if err != nil {
+			log.Error("failed write audit event", sl.Err(err))
+		}
+
+		responseOK(w, r)
+
+	}
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request) {
+	render.JSON(w, r, Response{
+		Status: resp.StatusOK,
+	})
+}
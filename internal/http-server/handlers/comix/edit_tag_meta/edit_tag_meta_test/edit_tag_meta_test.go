@@ -0,0 +1,109 @@
+package edit_tag_meta_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/edit_tag_meta"
+	"jadesheart/comix_back/internal/lib/cache"
+	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type ResponseMock struct {
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type MockTagMetaEditor struct {
+	meta postgres.TagMeta
+}
+
+func (m *MockTagMetaEditor) EditTagMeta(tagName string, meta postgres.TagMeta) error {
+	if meta.Parent != nil && *meta.Parent == "Subhorror" {
+		return storage.ErrTagCycle
+	}
+	m.meta = meta
+	return nil
+}
+
+func (m *MockTagMetaEditor) CheckPass(inputPass string) (bool, error) {
+	return inputPass == "password", nil
+}
+
+func (m *MockTagMetaEditor) AddAuditEvent(event postgres.AuditEvent) error {
+	return nil
+}
+
+func doRequest(t *testing.T, editor *MockTagMetaEditor, body map[string]interface{}) ResponseMock {
+	handler := edit_tag_meta.New(slogdiscard.NewDiscardLogger(), editor, cache.New(cache.NewMemory(), time.Minute))
+
+	jsonBody, _ := json.Marshal(body)
+
+	req, err := http.NewRequest("POST", "/edittagmeta", bytes.NewBuffer(jsonBody))
+	assert.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	var responseBody ResponseMock
+
+	if err := json.Unmarshal(rr.Body.Bytes(), &responseBody); err != nil {
+		t.Fatalf("Ошибка при распоковке JSON: %s", err)
+	}
+
+	return responseBody
+}
+
+func TestEditTagMeta_Success(t *testing.T) {
+	editor := &MockTagMetaEditor{}
+
+	responseBody := doRequest(t, editor, map[string]interface{}{
+		"password":  "password",
+		"tagName":   "Slasher",
+		"parent":    "Horror",
+		"color":     "#ff0000",
+		"sortOrder": 0,
+	})
+
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+	assert.Equal(t, "Horror", *editor.meta.Parent)
+	assert.Equal(t, "#ff0000", *editor.meta.Color)
+	assert.Equal(t, 0, *editor.meta.SortOrder)
+}
+
+func TestEditTagMeta_OnlyPassedFieldsChange(t *testing.T) {
+	editor := &MockTagMetaEditor{}
+
+	responseBody := doRequest(t, editor, map[string]interface{}{
+		"password": "password",
+		"tagName":  "Slasher",
+		"parent":   "",
+	})
+
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+	assert.Equal(t, "", *editor.meta.Parent)
+	assert.Nil(t, editor.meta.Color)
+	assert.Nil(t, editor.meta.SortOrder)
+}
+
+func TestEditTagMeta_InvalidRequest(t *testing.T) {
+	requestsBody := []map[string]interface{}{
+		{"password": "password", "tagName": "Horror"},
+		{"password": "password", "tagName": "Horror", "color": "red"},
+		{"password": "password", "tagName": "Horror", "parent": "Subhorror"},
+		{"password": "wrong_password", "tagName": "Horror", "sortOrder": 1},
+		{"password": "password", "sortOrder": 1},
+	}
+
+	for _, m := range requestsBody {
+		responseBody := doRequest(t, &MockTagMetaEditor{}, m)
+
+		assert.Equal(t, http.StatusBadRequest, responseBody.Status)
+	}
+}
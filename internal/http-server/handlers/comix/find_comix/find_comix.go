@@ -6,6 +6,7 @@ import (
 	"github.com/go-chi/render"
 	"github.com/go-playground/validator"
 	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/api/sorting"
 	"jadesheart/comix_back/internal/lib/logger/sl"
 	"jadesheart/comix_back/internal/storage/postgres"
 	"log/slog"
@@ -25,10 +26,12 @@ type Response struct {
 }
 
 type ComixGetter interface {
-	FindComixFromAllComix(name string, pageToDisplay int) ([]postgres.ComixFromAllComix, error)
+	FindComixFromAllComix(name string, pageToDisplay int, order postgres.ComixOrder) ([]postgres.ComixFromAllComix, error)
 }
 
-func New(log *slog.Logger, comixGetter ComixGetter) http.HandlerFunc {
+// New ищет комиксы по названию. Параметр запроса sort=rating сортирует по средней оценке,
+// комиксы меньше чем с minVotes оценками идут в конце.
+func New(log *slog.Logger, comixGetter ComixGetter, minVotes int) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		op := "handlers.comix.find_comix.New"
 
@@ -69,7 +72,14 @@ func New(log *slog.Logger, comixGetter ComixGetter) http.HandlerFunc {
 			return
 		}
 
-		comix, err := comixGetter.FindComixFromAllComix(req.Name, req.PageToDisplay)
+		order, ok := sorting.Comix(r, minVotes)
+		if !ok {
+			render.JSON(w, r, resp.Error("sort must be new or rating"))
+
+			return
+		}
+
+		comix, err := comixGetter.FindComixFromAllComix(req.Name, req.PageToDisplay, order)
 		if err != nil {
 			log.Error("Cannot get comix from bd", sl.Err(err))
 
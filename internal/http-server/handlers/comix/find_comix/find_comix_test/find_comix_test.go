@@ -19,14 +19,14 @@ type ResponseMock struct {
 
 type MockComixGetter struct{}
 
-func (m *MockComixGetter) FindComixFromAllComix(name string, pageToDisplay int) ([]postgres.ComixFromAllComix, error) {
+func (m *MockComixGetter) FindComixFromAllComix(name string, pageToDisplay int, order postgres.ComixOrder) ([]postgres.ComixFromAllComix, error) {
 	return nil, nil
 }
 
 func TestComixFind_Success(t *testing.T) {
 	mockLogger := slogdiscard.NewDiscardLogger()
 
-	handler := find_comix.New(mockLogger, &MockComixGetter{})
+	handler := find_comix.New(mockLogger, &MockComixGetter{}, 5)
 
 	requestBody := map[string]interface{}{
 		"name":       "exampleName",
@@ -64,7 +64,7 @@ func TestComixFind_EmptyValueParams(t *testing.T) {
 	}
 
 	for _, m := range requestsBody {
-		handler := find_comix.New(mockLogger, &MockComixGetter{})
+		handler := find_comix.New(mockLogger, &MockComixGetter{}, 5)
 
 		jsonBody, _ := json.Marshal(m)
 
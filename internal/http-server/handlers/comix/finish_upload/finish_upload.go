@@ -0,0 +1,290 @@
+package finish_upload
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator"
+	"jadesheart/comix_back/internal/http-server/middleware/ratelimit"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/api/schedule"
+	"jadesheart/comix_back/internal/lib/audit"
+	"jadesheart/comix_back/internal/lib/images"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/lib/photos"
+	"jadesheart/comix_back/internal/lib/upload"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Request - uploads - id завершённых загрузок в порядке страниц. Position - куда вставить первую страницу,
+// 0 - в конец комикса. PublishAt - время выхода страниц в RFC 3339, пустое - выходят сразу.
+type Request struct {
+	Password  string   `json:"password" validate:"required"`
+	TagName   string   `json:"tagName" validate:"required"`
+	Name      string   `json:"name" validate:"required"`
+	Position  int      `json:"position" validate:"min=0"`
+	Uploads   []string `json:"uploads" validate:"required,min=1,max=500"`
+	PublishAt string   `json:"publishAt"`
+}
+
+type Response struct {
+	Status int             `json:"status,omitempty"`
+	Error  string          `json:"error,omitempty"`
+	Pages  []postgres.Page `json:"pages,omitempty"`
+	// Pending - страницы ждут одобрения модератора, ID страниц - id в очереди модерации
+	Pending bool `json:"pending,omitempty"`
+}
+
+type PagesAdder interface {
+	AddPages(tagName string, name string, position int, files []string, publishAt time.Time) ([]postgres.Page, error)
+	AddPendingPages(tagName string, name string, position int, files []string, publishAt time.Time) ([]postgres.Page, error)
+	CheckPass(inputPass string) (bool, error)
+	AddAuditEvent(event postgres.AuditEvent) error
+}
+
+type UploadFinisher interface {
+	Finish(id string) (string, upload.Info, error)
+	Remove(id string) error
+}
+
+// New превращает завершённые загрузки в страницы комикса. Загрузки проверяются и кодируются заново, как страницы
+// из insert_photo, и удаляются только после того, как страницы записаны в базу: при ошибке их можно завершить ещё раз.
+// При включённой модерации страницы ждут одобрения в очереди.
+func New(log *slog.Logger, pagesAdder PagesAdder, uploadFinisher UploadFinisher, mediaRoot *photos.Root, limits images.Limits, moderation bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.finish_upload.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("failed decode request json", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed decode request json"))
+
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Error("failed validate", sl.Err(err))
+
+			render.JSON(w, r, resp.ValidateErrors(validateErr))
+
+			return
+		}
+
+		seen := make(map[string]bool, len(req.Uploads))
+		for _, id := range req.Uploads {
+			if seen[id] {
+				render.JSON(w, r, resp.Error("upload "+id+" is listed twice"))
+
+				return
+			}
+			seen[id] = true
+		}
+
+		publishAt, ok := schedule.Parse(req.PublishAt, time.Now())
+		if !ok {
+			render.JSON(w, r, resp.Error("publishAt must be a RFC 3339 time"))
+
+			return
+		}
+
+		res, err := pagesAdder.CheckPass(req.Password)
+		if err != nil {
+			log.Error("failed password verified", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed password verified"))
+
+			return
+		}
+
+		if !res {
+			log.Warn("incorrect password", slog.String("remote_addr", ratelimit.ClientIP(r)))
+
+			ratelimit.AuthFailed(r.Context())
+
+			render.JSON(w, r, resp.Error("incorrect password"))
+
+			return
+		}
+
+		ratelimit.AuthSucceeded(r.Context())
+
+		comixDir, err := mediaRoot.ComixDir(req.TagName, req.Name)
+		if err != nil {
+			log.Warn("unsafe comix name", sl.Err(err), slog.String("remote_addr", ratelimit.ClientIP(r)))
+
+			render.JSON(w, r, resp.Error("invalid comix name"))
+
+			return
+		}
+
+		_, err = os.Stat(comixDir)
+		if err != nil {
+			log.Error("failed get comix directory", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("Directory does not exist, check if you created the comix?"))
+
+			return
+		}
+
+		names := make([]string, 0, len(req.Uploads))
+
+		// removeWritten убирает уже записанные страницы, сами загрузки остаются
+		removeWritten := func() {
+			for _, name := range names {
+				os.Remove(filepath.Join(comixDir, name))
+			}
+		}
+
+		for _, id := range req.Uploads {
+			path, _, err := uploadFinisher.Finish(id)
+			if err != nil {
+				removeWritten()
+
+				msg, known := finishErrors(err)
+				if !known {
+					log.Error("failed finish upload", sl.Err(err), slog.String("upload", id))
+				}
+
+				render.JSON(w, r, resp.Error(fmt.Sprintf("upload %s: %s", id, msg)))
+
+				return
+			}
+
+			name, err := writePage(comixDir, path, limits)
+			if errors.Is(err, images.ErrNotImage) || errors.Is(err, images.ErrTooLarge) || errors.Is(err, images.ErrTooManyPixels) {
+				removeWritten()
+
+				render.JSON(w, r, resp.Error(fmt.Sprintf("upload %s: %s", id, err)))
+
+				return
+			}
+			if err != nil {
+				removeWritten()
+
+				log.Error("failed write page from upload", sl.Err(err), slog.String("upload", id))
+
+				render.JSON(w, r, resp.Error("failed write file"))
+
+				return
+			}
+
+			names = append(names, name)
+		}
+
+		addPages := pagesAdder.AddPages
+		if moderation {
+			addPages = pagesAdder.AddPendingPages
+		}
+
+		pages, err := addPages(req.TagName, req.Name, req.Position, names, publishAt)
+		if errors.Is(err, storage.ErrComixNotFound) {
+			removeWritten()
+
+			render.JSON(w, r, resp.Error("Comix not exists"))
+
+			return
+		}
+		if err != nil {
+			removeWritten()
+
+			log.Error("failed add pages", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed add pages"))
+
+			return
+		}
+
+		for _, id := range req.Uploads {
+			err := uploadFinisher.Remove(id)
+			if err != nil {
+				log.Error("failed remove finished upload", sl.Err(err), slog.String("upload", id))
+			}
+		}
+
+		after := map[string]interface{}{
+			"pages":    len(pages),
+			"uploads":  req.Uploads,
+			"position": pages[0].Position,
+			"pending":  moderation,
+		}
+		if !publishAt.IsZero() {
+			after["publishAt"] = publishAt
+		}
+
+		err = pagesAdder.AddAuditEvent(audit.NewEvent(r, audit.ActionPagesUpload, audit.ComixTarget(req.TagName, req.Name), nil, after))
+		if err != nil {
+			log.Error("failed write audit event", sl.Err(err))
+		}
+
+		responseOK(w, r, pages, moderation)
+
+	}
+}
+
+// writePage проверяет файл загрузки и сохраняет картинку страницей под новым именем
+func writePage(comixDir string, path string, limits images.Limits) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	img, err := images.Sanitize(f, limits)
+	if err != nil {
+		return "", err
+	}
+
+	name, err := photos.NewPageName(img.Ext)
+	if err != nil {
+		return "", err
+	}
+
+	err = photos.WriteFile(comixDir, name, bytes.NewReader(img.Data))
+	if err != nil {
+		return "", err
+	}
+
+	return name, nil
+}
+
+// finishErrors - текст ошибки завершения загрузки для клиента и признак того, что ошибка ожидаемая
+func finishErrors(err error) (string, bool) {
+	switch {
+	case errors.Is(err, upload.ErrNotFound):
+		return "not found", true
+	case errors.Is(err, upload.ErrIncomplete):
+		return "not all bytes are uploaded", true
+	case errors.Is(err, upload.ErrChecksumMismatch):
+		return "checksum mismatch, upload the file again", true
+	case errors.Is(err, upload.ErrBusy):
+		return "still being written", true
+	}
+
+	return "failed finish upload", false
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request, pages []postgres.Page, pending bool) {
+	render.JSON(w, r, Response{
+		Status:  resp.StatusOK,
+		Pages:   pages,
+		Pending: pending,
+	})
+}
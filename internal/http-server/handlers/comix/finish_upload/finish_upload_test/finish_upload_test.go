@@ -0,0 +1,235 @@
+package finish_upload_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"image"
+	"image/png"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/finish_upload"
+	"jadesheart/comix_back/internal/lib/images"
+	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"jadesheart/comix_back/internal/lib/photos"
+	"jadesheart/comix_back/internal/lib/upload"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type ResponseMock struct {
+	Status  int             `json:"status,omitempty"`
+	Error   string          `json:"error,omitempty"`
+	Pages   []postgres.Page `json:"pages,omitempty"`
+	Pending bool            `json:"pending,omitempty"`
+}
+
+type MockPagesAdder struct {
+	files     []string
+	pending   []string
+	publishAt time.Time
+	err       error
+}
+
+func (m *MockPagesAdder) AddPages(tagName string, name string, position int, files []string, publishAt time.Time) ([]postgres.Page, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.files = files
+	m.publishAt = publishAt
+
+	pages := make([]postgres.Page, 0, len(files))
+	for i, file := range files {
+		pages = append(pages, postgres.Page{ID: i + 1, Position: i + 1, File: file})
+	}
+	return pages, nil
+}
+
+func (m *MockPagesAdder) AddPendingPages(tagName string, name string, position int, files []string, publishAt time.Time) ([]postgres.Page, error) {
+	m.pending = files
+
+	pages := make([]postgres.Page, 0, len(files))
+	for i, file := range files {
+		pages = append(pages, postgres.Page{ID: 100 + i, File: file})
+	}
+	return pages, nil
+}
+
+func (m *MockPagesAdder) CheckPass(inputPass string) (bool, error) {
+	return inputPass == "password", nil
+}
+
+func (m *MockPagesAdder) AddAuditEvent(event postgres.AuditEvent) error {
+	return nil
+}
+
+var limits = images.Limits{MaxFileSize: 1 << 20, MaxRequestSize: 4 << 20, MaxPixels: 1 << 20}
+
+// pngPage - картинка шириной width, по ширине страницы потом видно, из какой загрузки она получилась
+func pngPage(width int) string {
+	buf := &bytes.Buffer{}
+	png.Encode(buf, image.NewGray(image.Rect(0, 0, width, 10)))
+
+	return buf.String()
+}
+
+// setup переходит во временную папку с комиксом Horror/Watchmen и заводит загрузку с содержимым data
+func setup(t *testing.T, data ...string) (*upload.Store, []string, string) {
+	wd, err := os.Getwd()
+	assert.NoError(t, err)
+	assert.NoError(t, os.Chdir(t.TempDir()))
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	comixDir := filepath.Join("internal/storage/web/photos", "Horror", "Watchmen")
+	assert.NoError(t, os.MkdirAll(comixDir, 0755))
+
+	store, err := upload.New("uploads", 1024)
+	assert.NoError(t, err)
+
+	ids := make([]string, 0, len(data))
+	for _, d := range data {
+		info, err := store.Create("page.jpg", int64(len(d)), "")
+		assert.NoError(t, err)
+
+		_, err = store.WriteChunk(info.ID, 0, strings.NewReader(d), nil)
+		assert.NoError(t, err)
+
+		ids = append(ids, info.ID)
+	}
+
+	return store, ids, comixDir
+}
+
+func doRequest(t *testing.T, adder *MockPagesAdder, store *upload.Store, uploads []string, moderation bool) ResponseMock {
+	return doScheduledRequest(t, adder, store, uploads, moderation, "")
+}
+
+func doScheduledRequest(t *testing.T, adder *MockPagesAdder, store *upload.Store, uploads []string, moderation bool, publishAt string) ResponseMock {
+	jsonBody, _ := json.Marshal(map[string]interface{}{
+		"password":  "password",
+		"tagName":   "horror",
+		"name":      "Watchmen",
+		"uploads":   uploads,
+		"publishAt": publishAt,
+	})
+
+	req, err := http.NewRequest("POST", "/uploads/finish", bytes.NewBuffer(jsonBody))
+	assert.NoError(t, err)
+
+	mediaRoot, err := photos.NewRoot("internal/storage/web/photos")
+	assert.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	finish_upload.New(slogdiscard.NewDiscardLogger(), adder, store, mediaRoot, limits, moderation).ServeHTTP(rr, req)
+
+	var responseBody ResponseMock
+
+	if err := json.Unmarshal(rr.Body.Bytes(), &responseBody); err != nil {
+		t.Fatalf("Ошибка при распоковке JSON: %s", err)
+	}
+
+	return responseBody
+}
+
+func TestFinishUpload_Success(t *testing.T) {
+	store, ids, comixDir := setup(t, pngPage(1), pngPage(2))
+	adder := &MockPagesAdder{}
+
+	responseBody := doRequest(t, adder, store, ids, false)
+
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+	assert.Len(t, responseBody.Pages, 2)
+
+	f, err := os.Open(filepath.Join(comixDir, adder.files[1]))
+	assert.NoError(t, err)
+	defer f.Close()
+	config, err := png.DecodeConfig(f)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, config.Width)
+
+	// Завершённые загрузки удалены
+	_, err = store.Get(ids[0])
+	assert.ErrorIs(t, err, upload.ErrNotFound)
+}
+
+func TestFinishUpload_Moderation(t *testing.T) {
+	store, ids, comixDir := setup(t, pngPage(1))
+	adder := &MockPagesAdder{}
+
+	responseBody := doRequest(t, adder, store, ids, true)
+
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+	assert.True(t, responseBody.Pending)
+	assert.Empty(t, adder.files)
+	assert.Len(t, adder.pending, 1)
+
+	// Файл страницы на проверке уже лежит в папке комикса
+	_, err := os.Stat(filepath.Join(comixDir, adder.pending[0]))
+	assert.NoError(t, err)
+}
+
+func TestFinishUpload_Scheduled(t *testing.T) {
+	store, ids, _ := setup(t, pngPage(1))
+	publishAt := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+
+	responseBody := doScheduledRequest(t, &MockPagesAdder{}, store, ids, false, "tomorrow")
+	assert.Equal(t, http.StatusBadRequest, responseBody.Status)
+
+	adder := &MockPagesAdder{}
+	responseBody = doScheduledRequest(t, adder, store, ids, false, publishAt.Format(time.RFC3339))
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+	assert.True(t, publishAt.Equal(adder.publishAt))
+
+	// Время, которое уже прошло, - выход сразу
+	store, ids, _ = setup(t, pngPage(1))
+	adder = &MockPagesAdder{}
+	responseBody = doScheduledRequest(t, adder, store, ids, false, time.Now().Add(-time.Hour).Format(time.RFC3339))
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+	assert.True(t, adder.publishAt.IsZero())
+}
+
+func TestFinishUpload_RollsBack(t *testing.T) {
+	store, ids, comixDir := setup(t, pngPage(1), pngPage(2))
+
+	responseBody := doRequest(t, &MockPagesAdder{err: errors.New("db is down")}, store, ids, false)
+	assert.Equal(t, http.StatusBadRequest, responseBody.Status)
+
+	// Файлы вернулись в загрузки, в папке комикса ничего не осталось
+	entries, err := os.ReadDir(comixDir)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+
+	adder := &MockPagesAdder{}
+	responseBody = doRequest(t, adder, store, ids, false)
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+	assert.Len(t, adder.files, 2)
+}
+
+func TestFinishUpload_InvalidUploads(t *testing.T) {
+	store, ids, _ := setup(t, pngPage(1), "not an image")
+
+	incomplete, err := store.Create("page.jpg", 10, "")
+	assert.NoError(t, err)
+
+	cases := [][]string{
+		{},
+		{ids[0], ids[0]},
+		{ids[0], incomplete.ID},
+		{"0123456789abcdef0123456789abcdef"},
+		{ids[0], ids[1]},
+	}
+
+	for _, c := range cases {
+		responseBody := doRequest(t, &MockPagesAdder{}, store, c, false)
+		assert.Equal(t, http.StatusBadRequest, responseBody.Status, c)
+	}
+
+	// Неудачные попытки не трогают готовую загрузку
+	_, err = store.Get(ids[0])
+	assert.NoError(t, err)
+}
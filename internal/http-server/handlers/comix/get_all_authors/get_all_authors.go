@@ -0,0 +1,63 @@
+package get_all_authors
+
+import (
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type Response struct {
+	Status  int               `json:"status,omitempty"`
+	Error   string            `json:"error,omitempty"`
+	Authors []postgres.Author `json:"authors"`
+}
+
+type AuthorsGetter interface {
+	GetAuthors(pageToDisplay int) ([]postgres.Author, error)
+}
+
+func New(log *slog.Logger, authorsGetter AuthorsGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.get_all_authors.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		pageNumber := 1
+		if page := r.URL.Query().Get("pageNumber"); page != "" {
+			n, err := strconv.Atoi(page)
+			if err != nil || n < 1 {
+				render.JSON(w, r, resp.Error("pageNumber must be a positive number"))
+
+				return
+			}
+			pageNumber = n
+		}
+
+		authors, err := authorsGetter.GetAuthors(pageNumber)
+		if err != nil {
+			log.Error("Cannot get authors from bd", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("Cannot get authors from bd"))
+
+			return
+		}
+
+		responseOK(w, r, authors)
+
+	}
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request, authors []postgres.Author) {
+	render.JSON(w, r, Response{
+		Status:  resp.StatusOK,
+		Authors: authors,
+	})
+}
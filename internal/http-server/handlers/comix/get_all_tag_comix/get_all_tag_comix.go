@@ -5,7 +5,9 @@ import (
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
 	"github.com/go-playground/validator"
+	"jadesheart/comix_back/internal/http-server/middleware/session"
 	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/api/sorting"
 	"jadesheart/comix_back/internal/lib/logger/sl"
 	"jadesheart/comix_back/internal/storage/postgres"
 	"log/slog"
@@ -24,10 +26,13 @@ type Response struct {
 }
 
 type ComixGetter interface {
-	GetAllTagComix(pageToDisplay int, tagName string) ([]postgres.ComixFromAllComix, error)
+	GetAllTagComix(pageToDisplay int, tagName string, order postgres.ComixOrder) ([]postgres.ComixFromAllComix, error)
+	AttachReadingProgress(readerID int, comixList []postgres.ComixFromAllComix) error
 }
 
-func New(log *slog.Logger, comixGetter ComixGetter) http.HandlerFunc {
+// New отдаёт страницу комиксов тэга. Параметр запроса sort=rating сортирует по средней оценке,
+// комиксы меньше чем с minVotes оценками идут в конце.
+func New(log *slog.Logger, comixGetter ComixGetter, minVotes int) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		op := "handlers.comix.get_all_tag_comix_test.New"
 
@@ -68,7 +73,14 @@ func New(log *slog.Logger, comixGetter ComixGetter) http.HandlerFunc {
 			return
 		}
 
-		comix, err := comixGetter.GetAllTagComix(req.PageNumber, req.TagName)
+		order, ok := sorting.Comix(r, minVotes)
+		if !ok {
+			render.JSON(w, r, resp.Error("sort must be new or rating"))
+
+			return
+		}
+
+		comix, err := comixGetter.GetAllTagComix(req.PageNumber, req.TagName, order)
 		if err != nil {
 			log.Error("Cannot get comix from bd", sl.Err(err))
 
@@ -77,6 +89,18 @@ func New(log *slog.Logger, comixGetter ComixGetter) http.HandlerFunc {
 			return
 		}
 
+		// Читателю с сессией отдаётся место чтения; такие запросы не кэшируются
+		if reader, ok := session.Reader(r.Context()); ok {
+			err = comixGetter.AttachReadingProgress(reader.ID, comix)
+			if err != nil {
+				log.Error("Cannot get reading progress from bd", sl.Err(err))
+
+				render.JSON(w, r, resp.Error("Cannot get reading progress from bd"))
+
+				return
+			}
+		}
+
 		responseOK(w, r, comix)
 
 	}
@@ -19,14 +19,18 @@ type ResponseMock struct {
 
 type MockComixGetter struct{}
 
-func (m *MockComixGetter) GetAllTagComix(pageToDisplay int, tagName string) ([]postgres.ComixFromAllComix, error) {
+func (m *MockComixGetter) GetAllTagComix(pageToDisplay int, tagName string, order postgres.ComixOrder) ([]postgres.ComixFromAllComix, error) {
 	return nil, nil
 }
 
+func (m *MockComixGetter) AttachReadingProgress(readerID int, comixList []postgres.ComixFromAllComix) error {
+	return nil
+}
+
 func TestGetAllComix_Success(t *testing.T) {
 	mockLogger := slogdiscard.NewDiscardLogger()
 
-	handler := get_all_tag_comix.New(mockLogger, &MockComixGetter{})
+	handler := get_all_tag_comix.New(mockLogger, &MockComixGetter{}, 5)
 
 	requestBody := map[string]interface{}{
 		"tagName":    "exampleTagName",
@@ -64,7 +68,7 @@ func TestGetAllComix_EmptyValueParams(t *testing.T) {
 	}
 
 	for _, m := range requestsBody {
-		handler := get_all_tag_comix.New(mockLogger, &MockComixGetter{})
+		handler := get_all_tag_comix.New(mockLogger, &MockComixGetter{}, 5)
 
 		jsonBody, _ := json.Marshal(m)
 
@@ -5,18 +5,21 @@ import (
 	"github.com/go-chi/render"
 	resp "jadesheart/comix_back/internal/lib/api/response"
 	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/storage/postgres"
 	"log/slog"
 	"net/http"
 )
 
 type Response struct {
-	Status  int      `json:"status,omitempty"`
-	Error   string   `json:"error,omitempty"`
-	TagList []string `json:"tagList"`
+	Status  int                 `json:"status,omitempty"`
+	Error   string              `json:"error,omitempty"`
+	TagList []string            `json:"tagList"`
+	TagTree []*postgres.TagNode `json:"tagTree,omitempty"`
 }
 
 type ComixGetter interface {
 	GetAllTags() ([]string, error)
+	GetTagTree() ([]*postgres.TagNode, error)
 }
 
 func New(log *slog.Logger, comixGetter ComixGetter) http.HandlerFunc {
@@ -38,14 +41,29 @@ func New(log *slog.Logger, comixGetter ComixGetter) http.HandlerFunc {
 			return
 		}
 
-		responseOK(w, r, tagsList)
+		// ?tree=true - вместе со списком вернуть дерево тэгов с количеством комиксов
+		var tagTree []*postgres.TagNode
+
+		if r.URL.Query().Get("tree") == "true" {
+			tagTree, err = comixGetter.GetTagTree()
+			if err != nil {
+				log.Error("Cannot get tag tree", sl.Err(err))
+
+				render.JSON(w, r, resp.Error("Cannot get tag tree"))
+
+				return
+			}
+		}
+
+		responseOK(w, r, tagsList, tagTree)
 
 	}
 }
 
-func responseOK(w http.ResponseWriter, r *http.Request, tagsList []string) {
+func responseOK(w http.ResponseWriter, r *http.Request, tagsList []string, tagTree []*postgres.TagNode) {
 	render.JSON(w, r, Response{
 		Status:  200,
 		TagList: tagsList,
+		TagTree: tagTree,
 	})
 }
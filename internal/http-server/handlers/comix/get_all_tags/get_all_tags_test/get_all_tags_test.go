@@ -0,0 +1,73 @@
+package get_all_tags_test
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/get_all_tags"
+	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type ResponseMock struct {
+	Status  int                 `json:"status,omitempty"`
+	Error   string              `json:"error,omitempty"`
+	TagList []string            `json:"tagList"`
+	TagTree []*postgres.TagNode `json:"tagTree"`
+}
+
+type MockComixGetter struct{}
+
+func (m *MockComixGetter) GetAllTags() ([]string, error) {
+	return []string{"Horror", "Slasher"}, nil
+}
+
+func (m *MockComixGetter) GetTagTree() ([]*postgres.TagNode, error) {
+	return []*postgres.TagNode{{
+		Tag:             postgres.Tag{ID: 1, Name: "Horror"},
+		ComixCount:      2,
+		TotalComixCount: 5,
+		Children: []*postgres.TagNode{{
+			Tag:             postgres.Tag{ID: 2, Name: "Slasher", ParentID: 1},
+			ComixCount:      3,
+			TotalComixCount: 3,
+		}},
+	}}, nil
+}
+
+func doRequest(t *testing.T, url string) ResponseMock {
+	handler := get_all_tags.New(slogdiscard.NewDiscardLogger(), &MockComixGetter{})
+
+	req, err := http.NewRequest("POST", url, nil)
+	assert.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	var responseBody ResponseMock
+
+	if err := json.Unmarshal(rr.Body.Bytes(), &responseBody); err != nil {
+		t.Fatalf("Ошибка при распоковке JSON: %s", err)
+	}
+
+	return responseBody
+}
+
+func TestGetAllTags_List(t *testing.T) {
+	responseBody := doRequest(t, "/alltags")
+
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+	assert.Equal(t, []string{"Horror", "Slasher"}, responseBody.TagList)
+	assert.Nil(t, responseBody.TagTree)
+}
+
+func TestGetAllTags_Tree(t *testing.T) {
+	responseBody := doRequest(t, "/alltags?tree=true")
+
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+	assert.Len(t, responseBody.TagTree, 1)
+	assert.Equal(t, 5, responseBody.TagTree[0].TotalComixCount)
+	assert.Equal(t, "Slasher", responseBody.TagTree[0].Children[0].Name)
+}
@@ -0,0 +1,142 @@
+package get_audit_events
+
+import (
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"jadesheart/comix_back/internal/http-server/middleware/ratelimit"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+type Request struct {
+	Password   string `json:"password" validate:"required"`
+	Actor      string `json:"actor"`
+	Action     string `json:"action"`
+	Target     string `json:"target"`
+	From       string `json:"from"`
+	To         string `json:"to"`
+	PageNumber int    `json:"pageNumber"`
+}
+
+// Response - ActorVerified всегда false: Actor берётся из заголовка audit.ActorHeader без проверки
+type Response struct {
+	Status        int                   `json:"status,omitempty"`
+	Error         string                `json:"error,omitempty"`
+	Events        []postgres.AuditEvent `json:"events"`
+	ActorVerified bool                  `json:"actorVerified"`
+}
+
+type AuditEventsGetter interface {
+	GetAuditEvents(filter postgres.AuditFilter, pageToDisplay int) ([]postgres.AuditEvent, error)
+	CheckPass(inputPass string) (bool, error)
+}
+
+const dateLayout = "2006-01-02"
+
+func New(log *slog.Logger, auditEventsGetter AuditEventsGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.get_audit_events.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("failed decode request json", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed decode request json"))
+
+			return
+		}
+
+		if req.Password == "" {
+			render.JSON(w, r, resp.Error("password is required"))
+
+			return
+		}
+
+		if req.PageNumber == 0 {
+			req.PageNumber = 1
+		}
+		if req.PageNumber < 0 {
+			render.JSON(w, r, resp.Error("pageNumber must be a positive number"))
+
+			return
+		}
+
+		filter := postgres.AuditFilter{
+			Actor:  req.Actor,
+			Action: req.Action,
+			Target: req.Target,
+		}
+
+		if req.From != "" {
+			filter.From, err = time.Parse(dateLayout, req.From)
+			if err != nil {
+				render.JSON(w, r, resp.Error("from must be a date in format YYYY-MM-DD"))
+
+				return
+			}
+		}
+
+		if req.To != "" {
+			to, err := time.Parse(dateLayout, req.To)
+			if err != nil {
+				render.JSON(w, r, resp.Error("to must be a date in format YYYY-MM-DD"))
+
+				return
+			}
+			// Дата "по" включительно
+			filter.To = to.AddDate(0, 0, 1)
+		}
+
+		res, err := auditEventsGetter.CheckPass(req.Password)
+		if err != nil {
+			log.Error("failed password verified", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed password verified"))
+
+			return
+		}
+
+		if !res {
+			log.Warn("incorrect password", slog.String("remote_addr", ratelimit.ClientIP(r)))
+
+			ratelimit.AuthFailed(r.Context())
+
+			render.JSON(w, r, resp.Error("incorrect password"))
+
+			return
+		}
+
+		ratelimit.AuthSucceeded(r.Context())
+
+		events, err := auditEventsGetter.GetAuditEvents(filter, req.PageNumber)
+		if err != nil {
+			log.Error("Cannot get audit events from bd", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("Cannot get audit events from bd"))
+
+			return
+		}
+
+		responseOK(w, r, events)
+
+	}
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request, events []postgres.AuditEvent) {
+	render.JSON(w, r, Response{
+		Status:        200,
+		Events:        events,
+		ActorVerified: false,
+	})
+}
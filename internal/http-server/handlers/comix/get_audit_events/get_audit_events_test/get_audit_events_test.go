@@ -0,0 +1,95 @@
+package get_audit_events_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/get_audit_events"
+	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type ResponseMock struct {
+	Status        int                   `json:"status,omitempty"`
+	Error         string                `json:"error,omitempty"`
+	Events        []postgres.AuditEvent `json:"events"`
+	ActorVerified *bool                 `json:"actorVerified"`
+}
+
+type MockAuditEventsGetter struct {
+	filter postgres.AuditFilter
+	page   int
+}
+
+func (m *MockAuditEventsGetter) GetAuditEvents(filter postgres.AuditFilter, pageToDisplay int) ([]postgres.AuditEvent, error) {
+	m.filter = filter
+	m.page = pageToDisplay
+	return []postgres.AuditEvent{{ID: 1, Actor: "admin", Action: "comix.delete", Target: "comix:horror/it"}}, nil
+}
+
+func (m *MockAuditEventsGetter) CheckPass(inputPass string) (bool, error) {
+	return inputPass == "password", nil
+}
+
+func doRequest(t *testing.T, handler http.HandlerFunc, body map[string]interface{}) ResponseMock {
+	jsonBody, _ := json.Marshal(body)
+
+	req, err := http.NewRequest("POST", "/auditlog", bytes.NewBuffer(jsonBody))
+	assert.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	var responseBody ResponseMock
+
+	if err := json.Unmarshal(rr.Body.Bytes(), &responseBody); err != nil {
+		t.Fatalf("Ошибка при распоковке JSON: %s", err)
+	}
+
+	return responseBody
+}
+
+func TestGetAuditEvents_Success(t *testing.T) {
+	getter := &MockAuditEventsGetter{}
+	handler := get_audit_events.New(slogdiscard.NewDiscardLogger(), getter)
+
+	responseBody := doRequest(t, handler, map[string]interface{}{
+		"password":   "password",
+		"action":     "comix.delete",
+		"from":       "2024-01-01",
+		"to":         "2024-01-31",
+		"pageNumber": 2,
+	})
+
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+	assert.Len(t, responseBody.Events, 1)
+	// Автор из заголовка не проверяется, и ответ это сообщает
+	if assert.NotNil(t, responseBody.ActorVerified) {
+		assert.False(t, *responseBody.ActorVerified)
+	}
+	assert.Equal(t, "comix.delete", getter.filter.Action)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), getter.filter.From)
+	assert.Equal(t, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), getter.filter.To)
+	assert.Equal(t, 2, getter.page)
+}
+
+func TestGetAuditEvents_InvalidRequest(t *testing.T) {
+	requestsBody := []map[string]interface{}{
+		{},
+		{"password": "wrong_password"},
+		{"password": "password", "from": "01.01.2024"},
+		{"password": "password", "pageNumber": -1},
+	}
+
+	for _, m := range requestsBody {
+		handler := get_audit_events.New(slogdiscard.NewDiscardLogger(), &MockAuditEventsGetter{})
+
+		responseBody := doRequest(t, handler, m)
+
+		assert.Equal(t, http.StatusBadRequest, responseBody.Status)
+	}
+}
@@ -0,0 +1,74 @@
+package get_author_by_slug
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+)
+
+// Path - маршрут, под которым доступна страница автора. По нему же строится редирект со старого slug.
+const Path = "/authors/"
+
+type Response struct {
+	Status int             `json:"status,omitempty"`
+	Error  string          `json:"error,omitempty"`
+	Author postgres.Author `json:"author"`
+}
+
+type AuthorGetter interface {
+	GetAuthorBySlug(authorSlug string) (postgres.Author, error)
+}
+
+func New(log *slog.Logger, authorGetter AuthorGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.get_author_by_slug.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		authorSlug := chi.URLParam(r, "slug")
+
+		author, err := authorGetter.GetAuthorBySlug(authorSlug)
+		if errors.Is(err, storage.ErrAuthorNotFound) {
+			log.Info("author not found", slog.String("slug", authorSlug))
+
+			render.JSON(w, r, resp.Error("Author not exists"))
+
+			return
+		}
+		if err != nil {
+			log.Error("Cannot get author from bd", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("Cannot get author from bd"))
+
+			return
+		}
+
+		if author.Slug != authorSlug {
+			log.Info("redirect from old slug", slog.String("slug", authorSlug), slog.String("current", author.Slug))
+
+			http.Redirect(w, r, Path+author.Slug, http.StatusMovedPermanently)
+
+			return
+		}
+
+		responseOK(w, r, author)
+
+	}
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request, author postgres.Author) {
+	render.JSON(w, r, Response{
+		Status: resp.StatusOK,
+		Author: author,
+	})
+}
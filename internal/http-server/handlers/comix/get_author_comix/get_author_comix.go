@@ -0,0 +1,95 @@
+package get_author_comix
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/get_author_by_slug"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type Response struct {
+	Status int                    `json:"status,omitempty"`
+	Error  string                 `json:"error,omitempty"`
+	Comix  []postgres.AuthorComix `json:"comix"`
+}
+
+type AuthorComixGetter interface {
+	GetAuthorBySlug(authorSlug string) (postgres.Author, error)
+	GetAuthorComix(pageToDisplay int, authorID int) ([]postgres.AuthorComix, error)
+}
+
+func New(log *slog.Logger, authorComixGetter AuthorComixGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.get_author_comix.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		authorSlug := chi.URLParam(r, "slug")
+
+		pageNumber := 1
+		if page := r.URL.Query().Get("pageNumber"); page != "" {
+			n, err := strconv.Atoi(page)
+			if err != nil || n < 1 {
+				render.JSON(w, r, resp.Error("pageNumber must be a positive number"))
+
+				return
+			}
+			pageNumber = n
+		}
+
+		author, err := authorComixGetter.GetAuthorBySlug(authorSlug)
+		if errors.Is(err, storage.ErrAuthorNotFound) {
+			render.JSON(w, r, resp.Error("Author not exists"))
+
+			return
+		}
+		if err != nil {
+			log.Error("Cannot get author from bd", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("Cannot get author from bd"))
+
+			return
+		}
+
+		if author.Slug != authorSlug {
+			target := get_author_by_slug.Path + author.Slug + "/comics"
+			if r.URL.RawQuery != "" {
+				target += "?" + r.URL.RawQuery
+			}
+
+			http.Redirect(w, r, target, http.StatusMovedPermanently)
+
+			return
+		}
+
+		comix, err := authorComixGetter.GetAuthorComix(pageNumber, author.ID)
+		if err != nil {
+			log.Error("Cannot get comix from bd", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("Cannot get comix from bd"))
+
+			return
+		}
+
+		responseOK(w, r, comix)
+
+	}
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request, comix []postgres.AuthorComix) {
+	render.JSON(w, r, Response{
+		Status: resp.StatusOK,
+		Comix:  comix,
+	})
+}
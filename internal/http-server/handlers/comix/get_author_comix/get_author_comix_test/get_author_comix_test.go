@@ -0,0 +1,89 @@
+package get_author_comix_test
+
+import (
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/get_author_by_slug"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/get_author_comix"
+	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type ResponseMock struct {
+	Status int                    `json:"status,omitempty"`
+	Error  string                 `json:"error,omitempty"`
+	Comix  []postgres.AuthorComix `json:"comix"`
+}
+
+type MockAuthorComixGetter struct {
+	page int
+}
+
+func (m *MockAuthorComixGetter) GetAuthorBySlug(authorSlug string) (postgres.Author, error) {
+	switch authorSlug {
+	case "alan-moore", "moore":
+		return postgres.Author{ID: 3, Slug: "alan-moore", Name: "Alan Moore"}, nil
+	}
+	return postgres.Author{}, storage.ErrAuthorNotFound
+}
+
+func (m *MockAuthorComixGetter) GetAuthorComix(pageToDisplay int, authorID int) ([]postgres.AuthorComix, error) {
+	m.page = pageToDisplay
+	comix := postgres.AuthorComix{Roles: []string{postgres.RoleWriter}}
+	comix.ComixName = "Watchmen"
+	return []postgres.AuthorComix{comix}, nil
+}
+
+func doRequest(getter *MockAuthorComixGetter, url string) *httptest.ResponseRecorder {
+	router := chi.NewRouter()
+	router.Get(get_author_by_slug.Path+"{slug}/comics", get_author_comix.New(slogdiscard.NewDiscardLogger(), getter))
+
+	req := httptest.NewRequest("GET", url, nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	return rr
+}
+
+func TestGetAuthorComix_Success(t *testing.T) {
+	getter := &MockAuthorComixGetter{}
+
+	rr := doRequest(getter, "/authors/alan-moore/comics?pageNumber=2")
+
+	var responseBody ResponseMock
+
+	if err := json.Unmarshal(rr.Body.Bytes(), &responseBody); err != nil {
+		t.Fatalf("Ошибка при распоковке JSON: %s", err)
+	}
+
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+	assert.Equal(t, 2, getter.page)
+	assert.Equal(t, "Watchmen", responseBody.Comix[0].ComixName)
+	assert.Equal(t, []string{postgres.RoleWriter}, responseBody.Comix[0].Roles)
+}
+
+func TestGetAuthorComix_OldSlugRedirects(t *testing.T) {
+	rr := doRequest(&MockAuthorComixGetter{}, "/authors/moore/comics?pageNumber=3")
+
+	assert.Equal(t, http.StatusMovedPermanently, rr.Code)
+	assert.Equal(t, "/authors/alan-moore/comics?pageNumber=3", rr.Header().Get("Location"))
+}
+
+func TestGetAuthorComix_InvalidRequest(t *testing.T) {
+	for _, url := range []string{"/authors/missing/comics", "/authors/alan-moore/comics?pageNumber=0"} {
+		rr := doRequest(&MockAuthorComixGetter{}, url)
+
+		var responseBody ResponseMock
+
+		if err := json.Unmarshal(rr.Body.Bytes(), &responseBody); err != nil {
+			t.Fatalf("Ошибка при распоковке JSON: %s", err)
+		}
+
+		assert.Equal(t, http.StatusBadRequest, responseBody.Status)
+	}
+}
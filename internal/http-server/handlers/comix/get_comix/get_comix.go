@@ -1,16 +1,19 @@
 package get_comix
 
 import (
+	"errors"
 	"fmt"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
 	"github.com/go-playground/validator"
 	resp "jadesheart/comix_back/internal/lib/api/response"
 	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/storage"
 	"jadesheart/comix_back/internal/storage/postgres"
 	"log/slog"
 	"net/http"
 	"reflect"
+	"time"
 )
 
 type Request struct {
@@ -18,19 +21,34 @@ type Request struct {
 	Name string `json:"name" validator:"required"`
 }
 type Response struct {
-	Status      int    `json:"status,omitempty"`
-	Error       string `json:"error,omitempty"`
-	Tag         string `json:"tag"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	UploadDate  string `json:"upload_date"`
-	Views       int    `json:"views"`
+	Status      int      `json:"status,omitempty"`
+	Error       string   `json:"error,omitempty"`
+	ID          int      `json:"id"`
+	Slug        string   `json:"slug"`
+	Tag         string   `json:"tag"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	UploadDate  string   `json:"upload_date"`
+	Views       int      `json:"views"`
+	Cover       string   `json:"cover"`
+	Authors     []string `json:"authors"`
+	// PublicationStatus - ongoing, completed или hiatus; status занят статусом ответа
+	PublicationStatus string            `json:"publicationStatus"`
+	Language          string            `json:"language"`
+	AgeRating         string            `json:"ageRating"`
+	UpdatedAt         time.Time         `json:"updatedAt"`
+	Credits           []postgres.Credit `json:"credits"`
+	// Comments - число комментариев вместе с ответами
+	Comments int                    `json:"comments"`
+	Rating   postgres.RatingSummary `json:"rating"`
 }
 
 type ComixGetter interface {
 	GetComixByName(tagName string, name string) (postgres.Comix, error)
 	CheckComixExists(tagName string, name string) (bool, error)
 	TagExist(tagName string) (bool, error)
+	GetComixCredits(comixID int) ([]postgres.Credit, error)
+	GetCommentCount(comixID int) (int, error)
 }
 
 func New(log *slog.Logger, comixGetter ComixGetter) http.HandlerFunc {
@@ -109,6 +127,11 @@ func New(log *slog.Logger, comixGetter ComixGetter) http.HandlerFunc {
 		}
 
 		comix, err := comixGetter.GetComixByName(req.Tag, req.Name)
+		if errors.Is(err, storage.ErrComixNotFound) {
+			render.JSON(w, r, resp.Error("Comix not exists"))
+
+			return
+		}
 		if err != nil {
 			log.Error("Cannot get comix from bd", sl.Err(err))
 
@@ -117,18 +140,48 @@ func New(log *slog.Logger, comixGetter ComixGetter) http.HandlerFunc {
 			return
 		}
 
-		responseOK(w, r, req.Tag, req.Name, comix.Description, comix.UploadDate, comix.Views)
+		credits, err := comixGetter.GetComixCredits(comix.ID)
+		if err != nil {
+			log.Error("Cannot get comix credits from bd", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("Cannot get comix from bd"))
+
+			return
+		}
+
+		comments, err := comixGetter.GetCommentCount(comix.ID)
+		if err != nil {
+			log.Error("Cannot get comment count from bd", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("Cannot get comix from bd"))
+
+			return
+		}
+
+		responseOK(w, r, req.Tag, req.Name, comix, credits, comments)
 
 	}
 }
 
-func responseOK(w http.ResponseWriter, r *http.Request, tag string, name string, description string, uploadDate string, views int) {
+func responseOK(w http.ResponseWriter, r *http.Request, tag string, name string, comix postgres.Comix, credits []postgres.Credit, comments int) {
 	render.JSON(w, r, Response{
 		Status:      200,
+		ID:          comix.ID,
+		Slug:        comix.Slug,
 		Tag:         tag,
 		Name:        name,
-		Description: description,
-		UploadDate:  uploadDate,
-		Views:       views,
+		Description: comix.Description,
+		UploadDate:  comix.UploadDate,
+		Views:       comix.Views,
+		Cover:       comix.Cover,
+		Authors:     comix.Authors,
+
+		PublicationStatus: comix.Status,
+		Language:          comix.Language,
+		AgeRating:         comix.AgeRating,
+		UpdatedAt:         comix.UpdatedAt,
+		Credits:           credits,
+		Comments:          comments,
+		Rating:            comix.Rating,
 	})
 }
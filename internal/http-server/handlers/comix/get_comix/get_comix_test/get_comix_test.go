@@ -13,23 +13,34 @@ import (
 )
 
 type ResponseMock struct {
-	Status int    `json:"status,omitempty"`
-	Error  string `json:"error,omitempty"`
+	Status   int                    `json:"status,omitempty"`
+	Error    string                 `json:"error,omitempty"`
+	Comments int                    `json:"comments"`
+	Rating   postgres.RatingSummary `json:"rating"`
 }
 
 type MockComixGetter struct{}
 
 func (m *MockComixGetter) GetComixByName(tagName string, name string) (postgres.Comix, error) {
-	return postgres.Comix{}, nil
+	return postgres.Comix{ComixMeta: postgres.ComixMeta{Rating: postgres.RatingSummary{
+		Average: 4.5, Votes: 2, Histogram: []int64{0, 0, 0, 1, 1},
+	}}}, nil
 }
 func (m *MockComixGetter) CheckComixExists(tagName string, name string) (bool, error) {
-	if name == "comixIsNotExist" {
+	if name == "comixExist" {
 		return true, nil
 	} else if name == "comixIsNotExist" {
 		return false, nil
 	}
 	return false, nil
 }
+func (m *MockComixGetter) GetComixCredits(comixID int) ([]postgres.Credit, error) {
+	return []postgres.Credit{}, nil
+}
+func (m *MockComixGetter) GetCommentCount(comixID int) (int, error) {
+	return 4, nil
+}
+
 func (m *MockComixGetter) TagExist(tagName string) (bool, error) {
 	if tagName == "tagExist" {
 		return true, nil
@@ -46,7 +57,7 @@ func TestGetComix_Success(t *testing.T) {
 
 	requestBody := map[string]interface{}{
 		"tagName": "tagExist",
-		"name":    "comixIsNotExist",
+		"name":    "comixExist",
 	}
 
 	jsonBody, _ := json.Marshal(requestBody)
@@ -65,6 +76,9 @@ func TestGetComix_Success(t *testing.T) {
 	}
 
 	assert.Equal(t, http.StatusOK, responseBody.Status)
+	assert.Equal(t, 4, responseBody.Comments)
+	assert.Equal(t, 4.5, responseBody.Rating.Average)
+	assert.Equal(t, []int64{0, 0, 0, 1, 1}, responseBody.Rating.Histogram)
 }
 
 func TestGetComix_NotExistParam(t *testing.T) {
@@ -0,0 +1,74 @@
+package get_comix_by_slug
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+)
+
+// Path - маршрут, под которым доступен комикс. По нему же строится редирект со старого slug.
+const Path = "/api/comix/"
+
+type Response struct {
+	Status int                        `json:"status,omitempty"`
+	Error  string                     `json:"error,omitempty"`
+	Comix  postgres.ComixFromAllComix `json:"comix"`
+}
+
+type ComixGetter interface {
+	GetComixBySlug(comixSlug string) (postgres.ComixFromAllComix, error)
+}
+
+func New(log *slog.Logger, comixGetter ComixGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.get_comix_by_slug.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		comixSlug := chi.URLParam(r, "slug")
+
+		comix, err := comixGetter.GetComixBySlug(comixSlug)
+		if errors.Is(err, storage.ErrComixNotFound) {
+			log.Info("comix not found", slog.String("slug", comixSlug))
+
+			render.JSON(w, r, resp.Error("Comix not exists"))
+
+			return
+		}
+		if err != nil {
+			log.Error("Cannot get comix from bd", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("Cannot get comix from bd"))
+
+			return
+		}
+
+		if comix.Slug != comixSlug {
+			log.Info("redirect from old slug", slog.String("slug", comixSlug), slog.String("current", comix.Slug))
+
+			http.Redirect(w, r, Path+comix.Slug, http.StatusMovedPermanently)
+
+			return
+		}
+
+		responseOK(w, r, comix)
+
+	}
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request, comix postgres.ComixFromAllComix) {
+	render.JSON(w, r, Response{
+		Status: resp.StatusOK,
+		Comix:  comix,
+	})
+}
@@ -0,0 +1,75 @@
+package get_comix_by_slug_test
+
+import (
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/get_comix_by_slug"
+	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type ResponseMock struct {
+	Status int                        `json:"status,omitempty"`
+	Error  string                     `json:"error,omitempty"`
+	Comix  postgres.ComixFromAllComix `json:"comix"`
+}
+
+type MockComixGetter struct{}
+
+func (m *MockComixGetter) GetComixBySlug(comixSlug string) (postgres.ComixFromAllComix, error) {
+	switch comixSlug {
+	case "new-name", "old-name":
+		return postgres.ComixFromAllComix{ID: 7, Slug: "new-name", ComixName: "New name"}, nil
+	}
+	return postgres.ComixFromAllComix{}, storage.ErrComixNotFound
+}
+
+func doRequest(url string) *httptest.ResponseRecorder {
+	router := chi.NewRouter()
+	router.Get(get_comix_by_slug.Path+"{slug}", get_comix_by_slug.New(slogdiscard.NewDiscardLogger(), &MockComixGetter{}))
+
+	req := httptest.NewRequest("GET", url, nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	return rr
+}
+
+func TestGetComixBySlug_Success(t *testing.T) {
+	rr := doRequest("/api/comix/new-name")
+
+	var responseBody ResponseMock
+
+	if err := json.Unmarshal(rr.Body.Bytes(), &responseBody); err != nil {
+		t.Fatalf("Ошибка при распоковке JSON: %s", err)
+	}
+
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+	assert.Equal(t, 7, responseBody.Comix.ID)
+	assert.Equal(t, "New name", responseBody.Comix.ComixName)
+}
+
+func TestGetComixBySlug_OldSlugRedirects(t *testing.T) {
+	rr := doRequest("/api/comix/old-name")
+
+	assert.Equal(t, http.StatusMovedPermanently, rr.Code)
+	assert.Equal(t, "/api/comix/new-name", rr.Header().Get("Location"))
+}
+
+func TestGetComixBySlug_NotExist(t *testing.T) {
+	rr := doRequest("/api/comix/missing")
+
+	var responseBody ResponseMock
+
+	if err := json.Unmarshal(rr.Body.Bytes(), &responseBody); err != nil {
+		t.Fatalf("Ошибка при распоковке JSON: %s", err)
+	}
+
+	assert.Equal(t, http.StatusBadRequest, responseBody.Status)
+	assert.Equal(t, "Comix not exists", responseBody.Error)
+}
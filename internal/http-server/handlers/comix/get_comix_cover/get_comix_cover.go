@@ -0,0 +1,80 @@
+package get_comix_cover
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/images"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/lib/photos"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+)
+
+type ComixGetter interface {
+	GetComixBySlug(comixSlug string) (postgres.ComixFromAllComix, error)
+	GetComixPages(tagName string, name string) ([]postgres.Page, error)
+}
+
+func New(log *slog.Logger, comixGetter ComixGetter, mediaRoot *photos.Root) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.get_comix_cover.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		comixSlug := chi.URLParam(r, "slug")
+
+		comix, err := comixGetter.GetComixBySlug(comixSlug)
+		if errors.Is(err, storage.ErrComixNotFound) {
+			render.JSON(w, r, resp.Error("Comix not exists"))
+
+			return
+		}
+		if err != nil {
+			log.Error("Cannot get comix from bd", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("Cannot get comix from bd"))
+
+			return
+		}
+
+		// Если обложку не загружали, обложкой служит первая страница
+		cover := comix.Cover
+		if cover == "" {
+			pages, err := comixGetter.GetComixPages(comix.ComixTag, comix.ComixName)
+			if err != nil {
+				log.Error("Cannot get comix pages from bd", sl.Err(err))
+
+				render.JSON(w, r, resp.Error("Cannot get comix from bd"))
+
+				return
+			}
+
+			if len(pages) == 0 {
+				render.JSON(w, r, resp.Error("Comix has no cover"))
+
+				return
+			}
+
+			cover = pages[0].File
+		}
+
+		coverPath, err := mediaRoot.ComixFile(comix.ComixTag, comix.ComixName, cover)
+		if err != nil {
+			log.Error("unsafe comix cover path", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("Comix has no cover"))
+
+			return
+		}
+
+		images.ServeFile(w, r, coverPath)
+	}
+}
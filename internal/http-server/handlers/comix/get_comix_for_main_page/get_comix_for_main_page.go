@@ -5,7 +5,9 @@ import (
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
 	"github.com/go-playground/validator"
+	"jadesheart/comix_back/internal/http-server/middleware/session"
 	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/api/sorting"
 	"jadesheart/comix_back/internal/lib/logger/sl"
 	"jadesheart/comix_back/internal/storage/postgres"
 	"log/slog"
@@ -23,10 +25,13 @@ type Response struct {
 }
 
 type ComixGetter interface {
-	GetComixForMainPage(pageToDisplay int) ([]postgres.ComixFromAllComix, error)
+	GetComixForMainPage(pageToDisplay int, order postgres.ComixOrder) ([]postgres.ComixFromAllComix, error)
+	AttachReadingProgress(readerID int, comixList []postgres.ComixFromAllComix) error
 }
 
-func New(log *slog.Logger, comixGetter ComixGetter) http.HandlerFunc {
+// New отдаёт страницу комиксов для главной. Параметр запроса sort=rating сортирует по средней оценке,
+// комиксы меньше чем с minVotes оценками идут в конце.
+func New(log *slog.Logger, comixGetter ComixGetter, minVotes int) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		op := "handlers.comix.get_comix_for_main_page_test.New"
 
@@ -67,7 +72,14 @@ func New(log *slog.Logger, comixGetter ComixGetter) http.HandlerFunc {
 			return
 		}
 
-		comix, err := comixGetter.GetComixForMainPage(req.PageNumber)
+		order, ok := sorting.Comix(r, minVotes)
+		if !ok {
+			render.JSON(w, r, resp.Error("sort must be new or rating"))
+
+			return
+		}
+
+		comix, err := comixGetter.GetComixForMainPage(req.PageNumber, order)
 		if err != nil {
 			log.Error("Cannot get comix from bd", sl.Err(err))
 
@@ -76,6 +88,18 @@ func New(log *slog.Logger, comixGetter ComixGetter) http.HandlerFunc {
 			return
 		}
 
+		// Читателю с сессией отдаётся место чтения; такие запросы не кэшируются
+		if reader, ok := session.Reader(r.Context()); ok {
+			err = comixGetter.AttachReadingProgress(reader.ID, comix)
+			if err != nil {
+				log.Error("Cannot get reading progress from bd", sl.Err(err))
+
+				render.JSON(w, r, resp.Error("Cannot get reading progress from bd"))
+
+				return
+			}
+		}
+
 		responseOK(w, r, comix)
 
 	}
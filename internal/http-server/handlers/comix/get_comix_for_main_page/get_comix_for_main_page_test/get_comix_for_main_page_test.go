@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"github.com/stretchr/testify/assert"
 	"jadesheart/comix_back/internal/http-server/handlers/comix/get_comix_for_main_page"
+	"jadesheart/comix_back/internal/http-server/middleware/session"
 	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
 	"jadesheart/comix_back/internal/storage/postgres"
 	"net/http"
@@ -17,16 +18,26 @@ type ResponseMock struct {
 	Error  string `json:"error,omitempty"`
 }
 
-type MockComixGetter struct{}
+type MockComixGetter struct {
+	progressReaderID int
+	orders           []postgres.ComixOrder
+}
+
+func (m *MockComixGetter) GetComixForMainPage(pageToDisplay int, order postgres.ComixOrder) ([]postgres.ComixFromAllComix, error) {
+	m.orders = append(m.orders, order)
+	return []postgres.ComixFromAllComix{{ID: 1, ComixName: "Watchmen"}, {ID: 2, ComixName: "Maus"}}, nil
+}
 
-func (m *MockComixGetter) GetComixForMainPage(pageToDisplay int) ([]postgres.ComixFromAllComix, error) {
-	return []postgres.ComixFromAllComix{}, nil
+func (m *MockComixGetter) AttachReadingProgress(readerID int, comixList []postgres.ComixFromAllComix) error {
+	m.progressReaderID = readerID
+	comixList[0].Progress = &postgres.ReadingProgress{PageID: 10, Position: 12, Pages: 12, Read: true}
+	return nil
 }
 
 func TestGetComix_Success(t *testing.T) {
 	mockLogger := slogdiscard.NewDiscardLogger()
 
-	handler := get_comix_for_main_page.New(mockLogger, &MockComixGetter{})
+	handler := get_comix_for_main_page.New(mockLogger, &MockComixGetter{}, 5)
 
 	requestBody := map[string]interface{}{
 		"pageNumber": 1,
@@ -58,7 +69,7 @@ func TestGetComix_EmptyValueParams(t *testing.T) {
 	}
 
 	for _, m := range requestsBody {
-		handler := get_comix_for_main_page.New(mockLogger, &MockComixGetter{})
+		handler := get_comix_for_main_page.New(mockLogger, &MockComixGetter{}, 5)
 
 		jsonBody, _ := json.Marshal(m)
 
@@ -79,3 +90,67 @@ func TestGetComix_EmptyValueParams(t *testing.T) {
 	}
 
 }
+
+func TestGetComix_ReadingProgress(t *testing.T) {
+	getter := &MockComixGetter{}
+	handler := get_comix_for_main_page.New(slogdiscard.NewDiscardLogger(), getter, 5)
+
+	doRequest := func(reader *postgres.Reader) []map[string]interface{} {
+		req, err := http.NewRequest("POST", "/getmainpagecomix", bytes.NewBufferString(`{"pageNumber":1}`))
+		assert.NoError(t, err)
+
+		if reader != nil {
+			req = req.WithContext(session.WithReader(req.Context(), *reader))
+		}
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		var responseBody struct {
+			Comix []map[string]interface{} `json:"comixFromForMainPage"`
+		}
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &responseBody))
+
+		return responseBody.Comix
+	}
+
+	anonymous := doRequest(nil)
+	assert.Len(t, anonymous, 2)
+	assert.NotContains(t, anonymous[0], "Progress")
+	assert.Equal(t, 0, getter.progressReaderID)
+
+	comix := doRequest(&postgres.Reader{ID: 7, Username: "reader"})
+	assert.Equal(t, 7, getter.progressReaderID)
+	assert.Equal(t, true, comix[0]["Progress"].(map[string]interface{})["Read"])
+	assert.NotContains(t, comix[1], "Progress")
+}
+
+func TestGetComix_Sort(t *testing.T) {
+	getter := &MockComixGetter{}
+	handler := get_comix_for_main_page.New(slogdiscard.NewDiscardLogger(), getter, 5)
+
+	doRequest := func(url string) ResponseMock {
+		req, err := http.NewRequest("POST", url, bytes.NewBufferString(`{"pageNumber":1}`))
+		assert.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		var responseBody ResponseMock
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &responseBody))
+
+		return responseBody
+	}
+
+	assert.Equal(t, http.StatusOK, doRequest("/getmainpagecomix").Status)
+	assert.Equal(t, http.StatusOK, doRequest("/getmainpagecomix?sort=rating").Status)
+
+	responseBody := doRequest("/getmainpagecomix?sort=views")
+	assert.Equal(t, http.StatusBadRequest, responseBody.Status)
+	assert.Equal(t, "sort must be new or rating", responseBody.Error)
+
+	assert.Equal(t, []postgres.ComixOrder{
+		{Sort: postgres.ComixSortNew},
+		{Sort: postgres.ComixSortRating, MinVotes: 5},
+	}, getter.orders)
+}
@@ -2,26 +2,26 @@ package get_comix_photo
 
 import (
 	"encoding/json"
-	"fmt"
+	"errors"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
-	"io/ioutil"
+	"jadesheart/comix_back/internal/http-server/middleware/ratelimit"
 	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/lib/photos"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
 	"log/slog"
 	"net/http"
 	"os"
-	"path/filepath"
-	"sort"
-	"strconv"
-	"strings"
 )
 
-type ViewsAdder interface {
+type ComixPhotoGetter interface {
+	GetComixPages(tagName string, name string) ([]postgres.Page, error)
 	AddViews(tag string, name string) error
 }
 
-func New(log *slog.Logger, viewsAdder ViewsAdder) http.HandlerFunc {
+func New(log *slog.Logger, comixPhotoGetter ComixPhotoGetter, mediaRoot *photos.Root) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.comix.get_comix_photo.New"
 
@@ -33,42 +33,56 @@ func New(log *slog.Logger, viewsAdder ViewsAdder) http.HandlerFunc {
 		tag := chi.URLParam(r, "tag")
 		comixName := chi.URLParam(r, "name")
 
-		filePath := "internal/storage/web/photos/" + tag + "/" + comixName
-
-		files, err := ioutil.ReadDir(filePath)
+		comixDir, err := mediaRoot.ComixDir(tag, comixName)
 		if err != nil {
-			log.Error("failed read files", sl.Err(err))
+			log.Warn("unsafe comix path", sl.Err(err), slog.String("remote_addr", ratelimit.ClientIP(r)))
 
-			render.JSON(w, r, "failed read files")
+			render.JSON(w, r, "comix not found")
 
 			return
 		}
 
-		sortFiles(files)
+		pages, err := comixPhotoGetter.GetComixPages(tag, comixName)
+		if errors.Is(err, storage.ErrComixNotFound) {
+			render.JSON(w, r, "comix not found")
+
+			return
+		}
+		if err != nil {
+			log.Error("failed get comix pages", sl.Err(err))
 
-		var images [][]byte
+			render.JSON(w, r, "failed read files")
 
-		for _, file := range files {
-			if !file.IsDir() && strings.HasSuffix(file.Name(), ".jpg") {
+			return
+		}
 
-				filePath := fmt.Sprintf("%s/%s", filePath, file.Name())
+		images := make([][]byte, 0, len(pages))
 
-				data, err := ioutil.ReadFile(filePath)
+		for _, page := range pages {
+			pagePath, err := mediaRoot.Join(comixDir, page.File)
+			if err != nil {
+				log.Error("unsafe page path", sl.Err(err))
 
-				if err != nil {
-					log.Error("Unable to send file", sl.Err(err))
+				render.JSON(w, r, "Unable to send file")
 
-					render.JSON(w, r, "Unable to send file")
+				return
+			}
 
-					return
-				}
+			data, err := os.ReadFile(pagePath)
+			if err != nil {
+				log.Error("Unable to send file", sl.Err(err))
 
-				images = append(images, data)
+				render.JSON(w, r, "Unable to send file")
+
+				return
 			}
+
+			images = append(images, data)
 		}
 
 		response := map[string]interface{}{
 			"images": images,
+			"pages":  pages,
 		}
 
 		jsonResponse, err := json.Marshal(response)
@@ -81,29 +95,11 @@ func New(log *slog.Logger, viewsAdder ViewsAdder) http.HandlerFunc {
 		w.WriteHeader(http.StatusOK)
 		_, _ = w.Write(jsonResponse)
 
-		err = viewsAdder.AddViews(tag, comixName)
+		err = comixPhotoGetter.AddViews(tag, comixName)
 		if err != nil {
 			log.Error("failed added view", sl.Err(err))
 
-			render.JSON(w, r, "failed added view")
-
 			return
 		}
 	}
 }
-
-func sortFiles(files []os.FileInfo) {
-	sort.Slice(files, func(i, j int) bool {
-		name1 := strings.TrimSuffix(files[i].Name(), filepath.Ext(files[i].Name()))
-		name2 := strings.TrimSuffix(files[j].Name(), filepath.Ext(files[j].Name()))
-
-		num1, err1 := strconv.Atoi(name1)
-		num2, err2 := strconv.Atoi(name2)
-
-		if err1 == nil && err2 == nil {
-			return num1 < num2
-		}
-
-		return name1 < name2
-	})
-}
@@ -1,12 +1,17 @@
 package get_comix_photo_test
 
 import (
+	"github.com/go-chi/chi/v5"
+	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
 	"jadesheart/comix_back/internal/lib/logger/handlers/slogpretty"
+	"jadesheart/comix_back/internal/lib/photos"
+	"jadesheart/comix_back/internal/storage/postgres"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -16,6 +21,10 @@ import (
 
 type MockViewsAdder struct{}
 
+func (m *MockViewsAdder) GetComixPages(tagName string, name string) ([]postgres.Page, error) {
+	return []postgres.Page{}, nil
+}
+
 func (m *MockViewsAdder) AddViews(tag string, name string) error {
 	return nil
 }
@@ -51,7 +60,10 @@ func TestGetComixPhotoHandler(t *testing.T) {
 	recorder := httptest.NewRecorder()
 
 	// Создание хэндлера с передачей фейкового ViewsAdder и логгера
-	handler := get_comix_photo.New(logger, viewsAdder)
+	mediaRoot, err := photos.NewRoot(t.TempDir())
+	assert.NoError(t, err)
+
+	handler := get_comix_photo.New(logger, viewsAdder, mediaRoot)
 
 	// Выполнение запроса
 	handler.ServeHTTP(recorder, req)
@@ -66,6 +78,21 @@ func TestGetComixPhotoHandler(t *testing.T) {
 	// Дополнительные проверки ожидаемых данных в ответе можно добавить с учетом предполагаемой логики
 }
 
+func TestGetComixPhotoHandler_UnsafePath(t *testing.T) {
+	mediaRoot, err := photos.NewRoot(t.TempDir())
+	assert.NoError(t, err)
+
+	router := chi.NewRouter()
+	router.Get("/comix/{tag}/{name}/", get_comix_photo.New(slogdiscard.NewDiscardLogger(), &MockViewsAdder{}, mediaRoot))
+
+	for _, reqURL := range []string{"/comix/../etc/", "/comix/horror/../"} {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, reqURL, nil))
+
+		assert.Equal(t, `"comix not found"`, strings.TrimSpace(recorder.Body.String()), reqURL)
+	}
+}
+
 func setupLogger(env string) *slog.Logger {
 
 	var logger *slog.Logger
@@ -0,0 +1,79 @@
+package get_comment_replies
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type Response struct {
+	Status  int                `json:"status,omitempty"`
+	Error   string             `json:"error,omitempty"`
+	Replies []postgres.Comment `json:"replies"`
+}
+
+type RepliesGetter interface {
+	GetCommentReplies(rootID int64, pageToDisplay int) ([]postgres.Comment, error)
+}
+
+// New отдаёт ответы ветки корневого комментария {id} постранично, в порядке написания.
+func New(log *slog.Logger, repliesGetter RepliesGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.get_comment_replies.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil || id < 1 {
+			render.JSON(w, r, resp.Error("Comment not exists"))
+
+			return
+		}
+
+		pageNumber := 1
+		if page := r.URL.Query().Get("pageNumber"); page != "" {
+			n, err := strconv.Atoi(page)
+			if err != nil || n < 1 {
+				render.JSON(w, r, resp.Error("pageNumber must be a positive number"))
+
+				return
+			}
+			pageNumber = n
+		}
+
+		replies, err := repliesGetter.GetCommentReplies(id, pageNumber)
+		if errors.Is(err, storage.ErrCommentNotFound) {
+			render.JSON(w, r, resp.Error("Comment not exists"))
+
+			return
+		}
+		if err != nil {
+			log.Error("Cannot get comments from bd", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("Cannot get comments from bd"))
+
+			return
+		}
+
+		responseOK(w, r, replies)
+
+	}
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request, replies []postgres.Comment) {
+	render.JSON(w, r, Response{
+		Status:  resp.StatusOK,
+		Replies: replies,
+	})
+}
@@ -0,0 +1,94 @@
+package get_comments
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type Response struct {
+	Status   int                `json:"status,omitempty"`
+	Error    string             `json:"error,omitempty"`
+	Comments []postgres.Comment `json:"comments"`
+}
+
+type CommentsGetter interface {
+	GetComixBySlug(comixSlug string) (postgres.ComixFromAllComix, error)
+	GetComments(comixID int, sort string, pageToDisplay int) ([]postgres.Comment, error)
+}
+
+// New отдаёт корневые комментарии комикса {slug} постранично. sort=new (по умолчанию) - сначала новые,
+// sort=top - сначала самые обсуждаемые. Ответы загружаются отдельно по id корневого комментария.
+func New(log *slog.Logger, commentsGetter CommentsGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.get_comments.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		sort := r.URL.Query().Get("sort")
+		switch sort {
+		case "":
+			sort = postgres.CommentSortNew
+		case postgres.CommentSortNew, postgres.CommentSortTop:
+		default:
+			render.JSON(w, r, resp.Error("sort must be new or top"))
+
+			return
+		}
+
+		pageNumber := 1
+		if page := r.URL.Query().Get("pageNumber"); page != "" {
+			n, err := strconv.Atoi(page)
+			if err != nil || n < 1 {
+				render.JSON(w, r, resp.Error("pageNumber must be a positive number"))
+
+				return
+			}
+			pageNumber = n
+		}
+
+		comix, err := commentsGetter.GetComixBySlug(chi.URLParam(r, "slug"))
+		if errors.Is(err, storage.ErrComixNotFound) {
+			render.JSON(w, r, resp.Error("Comix not exists"))
+
+			return
+		}
+		if err != nil {
+			log.Error("Cannot get comix from bd", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("Cannot get comix from bd"))
+
+			return
+		}
+
+		comments, err := commentsGetter.GetComments(comix.ID, sort, pageNumber)
+		if err != nil {
+			log.Error("Cannot get comments from bd", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("Cannot get comments from bd"))
+
+			return
+		}
+
+		responseOK(w, r, comments)
+
+	}
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request, comments []postgres.Comment) {
+	render.JSON(w, r, Response{
+		Status:   resp.StatusOK,
+		Comments: comments,
+	})
+}
@@ -0,0 +1,98 @@
+package get_comments_test
+
+import (
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/get_comments"
+	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type ResponseMock struct {
+	Status   int                `json:"status,omitempty"`
+	Error    string             `json:"error,omitempty"`
+	Comments []postgres.Comment `json:"comments"`
+}
+
+type request struct {
+	comixID int
+	sort    string
+	page    int
+}
+
+type MockCommentsGetter struct {
+	requests []request
+}
+
+func (m *MockCommentsGetter) GetComixBySlug(comixSlug string) (postgres.ComixFromAllComix, error) {
+	if comixSlug == "watchmen" {
+		return postgres.ComixFromAllComix{ID: 3, Slug: "watchmen"}, nil
+	}
+	return postgres.ComixFromAllComix{}, storage.ErrComixNotFound
+}
+
+func (m *MockCommentsGetter) GetComments(comixID int, sort string, pageToDisplay int) ([]postgres.Comment, error) {
+	m.requests = append(m.requests, request{comixID, sort, pageToDisplay})
+	return []postgres.Comment{{ID: 1, Author: "reader", Body: "comment", Replies: 2}}, nil
+}
+
+func doRequest(t *testing.T, getter *MockCommentsGetter, url string) ResponseMock {
+	router := chi.NewRouter()
+	router.Get("/api/comix/{slug}/comments", get_comments.New(slogdiscard.NewDiscardLogger(), getter))
+
+	req, err := http.NewRequest("GET", url, nil)
+	assert.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	var responseBody ResponseMock
+
+	if err := json.Unmarshal(rr.Body.Bytes(), &responseBody); err != nil {
+		t.Fatalf("Ошибка при распоковке JSON: %s", err)
+	}
+
+	return responseBody
+}
+
+func TestGetComments_Success(t *testing.T) {
+	getter := &MockCommentsGetter{}
+
+	responseBody := doRequest(t, getter, "/api/comix/watchmen/comments")
+
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+	assert.Len(t, responseBody.Comments, 1)
+
+	doRequest(t, getter, "/api/comix/watchmen/comments?sort=top&pageNumber=2")
+
+	assert.Equal(t, []request{
+		{comixID: 3, sort: postgres.CommentSortNew, page: 1},
+		{comixID: 3, sort: postgres.CommentSortTop, page: 2},
+	}, getter.requests)
+}
+
+func TestGetComments_Errors(t *testing.T) {
+	cases := []struct {
+		url   string
+		error string
+	}{
+		{"/api/comix/watchmen/comments?sort=old", "sort must be new or top"},
+		{"/api/comix/watchmen/comments?pageNumber=0", "pageNumber must be a positive number"},
+		{"/api/comix/unknown/comments", "Comix not exists"},
+	}
+
+	for _, c := range cases {
+		getter := &MockCommentsGetter{}
+
+		responseBody := doRequest(t, getter, c.url)
+
+		assert.Equal(t, http.StatusBadRequest, responseBody.Status)
+		assert.Equal(t, c.error, responseBody.Error)
+		assert.Empty(t, getter.requests)
+	}
+}
@@ -0,0 +1,67 @@
+package get_continue_reading
+
+import (
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"jadesheart/comix_back/internal/http-server/middleware/session"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type Response struct {
+	Status int                          `json:"status,omitempty"`
+	Error  string                       `json:"error,omitempty"`
+	Comix  []postgres.ComixFromAllComix `json:"comix"`
+}
+
+type ContinueReadingGetter interface {
+	GetContinueReading(readerID int, pageToDisplay int) ([]postgres.ComixFromAllComix, error)
+}
+
+// New отдаёт начатые и не дочитанные комиксы читателя запроса, последние читанные первыми.
+func New(log *slog.Logger, continueReadingGetter ContinueReadingGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.get_continue_reading.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		pageNumber := 1
+		if page := r.URL.Query().Get("pageNumber"); page != "" {
+			n, err := strconv.Atoi(page)
+			if err != nil || n < 1 {
+				render.JSON(w, r, resp.Error("pageNumber must be a positive number"))
+
+				return
+			}
+			pageNumber = n
+		}
+
+		reader, _ := session.Reader(r.Context())
+
+		comix, err := continueReadingGetter.GetContinueReading(reader.ID, pageNumber)
+		if err != nil {
+			log.Error("Cannot get comix from bd", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("Cannot get comix from bd"))
+
+			return
+		}
+
+		responseOK(w, r, comix)
+
+	}
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request, comix []postgres.ComixFromAllComix) {
+	render.JSON(w, r, Response{
+		Status: resp.StatusOK,
+		Comix:  comix,
+	})
+}
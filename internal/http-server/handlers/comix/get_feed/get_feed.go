@@ -0,0 +1,154 @@
+package get_feed
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/get_comix_by_slug"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/get_tag_by_slug"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/feed"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// Path - лента новых комиксов. Формат задаёт расширение: /feed.xml - Atom, /feed.json - JSON Feed,
+// без расширения - Atom. Лента тэга - Path + "/tags/{slug}" с теми же расширениями.
+const Path = "/feed"
+
+// TagPath - лента тэга, к ней дописывается slug тэга
+const TagPath = Path + "/tags/"
+
+type ComixGetter interface {
+	GetComixForMainPage(pageToDisplay int, order postgres.ComixOrder) ([]postgres.ComixFromAllComix, error)
+	GetAllTagComix(pageToDisplay int, tagName string, order postgres.ComixOrder) ([]postgres.ComixFromAllComix, error)
+	GetTagBySlug(tagSlug string) (postgres.Tag, error)
+}
+
+// New отдаёт ленту первой страницы новых комиксов, а с параметром пути slug - ленту тэга.
+// siteURL - публичный адрес сервиса, от него строятся ссылки на комиксы и обложки.
+func New(log *slog.Logger, comixGetter ComixGetter, siteURL string, siteTitle string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.get_feed.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		format, _ := r.Context().Value(middleware.URLFormatCtxKey).(string)
+		if format == "" {
+			format = feed.FormatAtom
+		}
+		if format != feed.FormatAtom && format != feed.FormatJSON {
+			render.JSON(w, r, resp.Error("feed format must be xml or json"))
+
+			return
+		}
+
+		order := postgres.ComixOrder{Sort: postgres.ComixSortNew}
+
+		f := feed.Feed{
+			Title:   siteTitle,
+			HomeURL: siteURL,
+			FeedURL: siteURL + Path,
+		}
+
+		var comixList []postgres.ComixFromAllComix
+		var tag postgres.Tag
+		var err error
+
+		if tagSlug := chi.URLParam(r, "slug"); tagSlug != "" {
+			tag, err = comixGetter.GetTagBySlug(tagSlug)
+			if errors.Is(err, storage.ErrTagNotFound) {
+				render.JSON(w, r, resp.Error("Tag not exists"))
+
+				return
+			}
+			if err != nil {
+				log.Error("Cannot get tag from bd", sl.Err(err))
+
+				render.JSON(w, r, resp.Error("Cannot get tag from bd"))
+
+				return
+			}
+
+			if tag.Slug != tagSlug {
+				http.Redirect(w, r, TagPath+tag.Slug+"."+format, http.StatusMovedPermanently)
+
+				return
+			}
+
+			f.Title = siteTitle + ": " + tag.Name
+			f.HomeURL = siteURL + get_tag_by_slug.Path + tag.Slug
+			f.FeedURL = siteURL + TagPath + tag.Slug
+
+			comixList, err = comixGetter.GetAllTagComix(1, tag.Name, order)
+		} else {
+			comixList, err = comixGetter.GetComixForMainPage(1, order)
+		}
+		if err != nil {
+			log.Error("Cannot get comix from bd", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("Cannot get comix from bd"))
+
+			return
+		}
+
+		f.Entries = entries(siteURL, comixList)
+
+		body, contentType, err := feed.Encode(f, format)
+		if err != nil {
+			log.Error("failed encode feed", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed encode feed"))
+
+			return
+		}
+
+		feed.Serve(w, r, body, contentType, f.Updated())
+	}
+}
+
+// entries - записи ленты по комиксам. Комиксы без slug пропускаются: на них нельзя сослаться.
+func entries(siteURL string, comixList []postgres.ComixFromAllComix) []feed.Entry {
+	result := make([]feed.Entry, 0, len(comixList))
+
+	for _, comix := range comixList {
+		if comix.Slug == "" {
+			continue
+		}
+
+		url := siteURL + get_comix_by_slug.Path + comix.Slug
+
+		published, err := time.Parse("2006-01-02", comix.ComixDate)
+		if err != nil {
+			published = time.Time{}
+		}
+
+		// updated_at без значения читается как начало эпохи
+		updated := comix.UpdatedAt
+		if updated.Unix() <= 0 {
+			updated = published
+		}
+
+		result = append(result, feed.Entry{
+			ID:        url,
+			URL:       url,
+			Title:     comix.ComixName,
+			Summary:   comix.Description,
+			Image:     url + "/cover",
+			Authors:   comix.Authors,
+			Tags:      []string{comix.ComixTag},
+			Published: published,
+			Updated:   updated,
+		})
+	}
+
+	return result
+}
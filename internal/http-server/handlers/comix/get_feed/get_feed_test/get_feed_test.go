@@ -0,0 +1,160 @@
+package get_feed_test
+
+import (
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/get_feed"
+	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type ResponseMock struct {
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type JSONFeedMock struct {
+	Title   string `json:"title"`
+	FeedURL string `json:"feed_url"`
+	Items   []struct {
+		ID    string   `json:"id"`
+		Image string   `json:"image"`
+		Tags  []string `json:"tags"`
+	} `json:"items"`
+}
+
+var updated = time.Date(2026, 3, 2, 10, 30, 0, 0, time.UTC)
+
+type MockComixGetter struct {
+	tagName string
+}
+
+func (m *MockComixGetter) GetComixForMainPage(pageToDisplay int, order postgres.ComixOrder) ([]postgres.ComixFromAllComix, error) {
+	return []postgres.ComixFromAllComix{
+		{Slug: "watchmen", ComixName: "Watchmen", ComixTag: "horror", ComixDate: "2026-03-01",
+			ComixMeta: postgres.ComixMeta{UpdatedAt: updated}},
+		{Slug: "hellboy", ComixName: "Hellboy", ComixTag: "horror", ComixDate: "2026-02-01",
+			ComixMeta: postgres.ComixMeta{UpdatedAt: time.Unix(0, 0)}},
+	}, nil
+}
+
+func (m *MockComixGetter) GetAllTagComix(pageToDisplay int, tagName string, order postgres.ComixOrder) ([]postgres.ComixFromAllComix, error) {
+	m.tagName = tagName
+	return []postgres.ComixFromAllComix{
+		{Slug: "watchmen", ComixName: "Watchmen", ComixTag: tagName, ComixDate: "2026-03-01"},
+		// Комикс из таблицы тэга без строки в all_comix
+		{ComixName: "Lost", ComixTag: tagName},
+	}, nil
+}
+
+func (m *MockComixGetter) GetTagBySlug(tagSlug string) (postgres.Tag, error) {
+	switch tagSlug {
+	case "horror", "old-horror":
+		return postgres.Tag{Slug: "horror", Name: "Horror"}, nil
+	}
+	return postgres.Tag{}, storage.ErrTagNotFound
+}
+
+func doRequest(getter *MockComixGetter, url string, header http.Header) *httptest.ResponseRecorder {
+	handler := get_feed.New(slogdiscard.NewDiscardLogger(), getter, "https://comix.example", "Comix")
+
+	router := chi.NewRouter()
+	router.Use(middleware.URLFormat)
+	router.Get(get_feed.Path, handler)
+	router.Get(get_feed.TagPath+"{slug}", handler)
+
+	req := httptest.NewRequest("GET", url, nil)
+	for key := range header {
+		req.Header.Set(key, header.Get(key))
+	}
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	return rr
+}
+
+func TestGetFeed_JSON(t *testing.T) {
+	rr := doRequest(&MockComixGetter{}, "/feed.json", nil)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.True(t, strings.HasPrefix(rr.Header().Get("Content-Type"), "application/feed+json"))
+	assert.Equal(t, updated.Format(http.TimeFormat), rr.Header().Get("Last-Modified"))
+
+	var doc JSONFeedMock
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &doc))
+
+	assert.Equal(t, "https://comix.example/feed.json", doc.FeedURL)
+	assert.Len(t, doc.Items, 2)
+	assert.Equal(t, "https://comix.example/api/comix/watchmen", doc.Items[0].ID)
+	assert.Equal(t, "https://comix.example/api/comix/watchmen/cover", doc.Items[0].Image)
+}
+
+func TestGetFeed_Atom(t *testing.T) {
+	for _, url := range []string{"/feed.xml", "/feed"} {
+		rr := doRequest(&MockComixGetter{}, url, nil)
+
+		assert.Equal(t, http.StatusOK, rr.Code, url)
+		assert.True(t, strings.HasPrefix(rr.Header().Get("Content-Type"), "application/atom+xml"), url)
+		assert.Contains(t, rr.Body.String(), "<title>Watchmen</title>", url)
+	}
+}
+
+func TestGetFeed_NotModified(t *testing.T) {
+	rr := doRequest(&MockComixGetter{}, "/feed.json", nil)
+	etag := rr.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+
+	rr = doRequest(&MockComixGetter{}, "/feed.json", http.Header{"If-None-Match": {etag}})
+	assert.Equal(t, http.StatusNotModified, rr.Code)
+	assert.Empty(t, rr.Body.Bytes())
+
+	// У Atom и JSON Feed разное содержимое, значит и разный ETag
+	rr = doRequest(&MockComixGetter{}, "/feed.xml", http.Header{"If-None-Match": {etag}})
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	rr = doRequest(&MockComixGetter{}, "/feed.xml", http.Header{"If-Modified-Since": {updated.Format(http.TimeFormat)}})
+	assert.Equal(t, http.StatusNotModified, rr.Code)
+}
+
+func TestGetFeed_Tag(t *testing.T) {
+	getter := &MockComixGetter{}
+
+	rr := doRequest(getter, "/feed/tags/horror.json", nil)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "Horror", getter.tagName)
+
+	var doc JSONFeedMock
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &doc))
+
+	assert.Equal(t, "Comix: Horror", doc.Title)
+	assert.Equal(t, "https://comix.example/feed/tags/horror.json", doc.FeedURL)
+	assert.Len(t, doc.Items, 1)
+	assert.Equal(t, []string{"Horror"}, doc.Items[0].Tags)
+
+	rr = doRequest(getter, "/feed/tags/old-horror.json", nil)
+	assert.Equal(t, http.StatusMovedPermanently, rr.Code)
+	assert.Equal(t, "/feed/tags/horror.json", rr.Header().Get("Location"))
+}
+
+func TestGetFeed_InvalidRequest(t *testing.T) {
+	for _, url := range []string{"/feed.rss", "/feed/tags/missing.xml"} {
+		rr := doRequest(&MockComixGetter{}, url, nil)
+
+		var responseBody ResponseMock
+
+		if err := json.Unmarshal(rr.Body.Bytes(), &responseBody); err != nil {
+			t.Fatalf("Ошибка при распоковке JSON: %s", err)
+		}
+
+		assert.Equal(t, http.StatusBadRequest, responseBody.Status, url)
+	}
+}
@@ -0,0 +1,166 @@
+package get_meta
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/get_comix_by_slug"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/get_tag_by_slug"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// Path - метаданные страницы для SSR: Path + "comix/{slug}" или Path + "tags/{slug}"
+const Path = "/meta/"
+
+// Что описывают метаданные, параметр пути kind
+const (
+	KindComix = "comix"
+	KindTag   = "tags"
+)
+
+// maxDescription - длиннее описания карточки обрезаются, в рунах
+const maxDescription = 200
+
+// Meta - данные карточки. Image - абсолютный адрес обложки, пустая строка - обложки нет.
+// Tags - готовые мета-тэги Open Graph и Twitter: имя property или name - значение
+type Meta struct {
+	Title       string            `json:"title"`
+	Description string            `json:"description"`
+	URL         string            `json:"url"`
+	Image       string            `json:"image,omitempty"`
+	Tags        map[string]string `json:"tags"`
+}
+
+type Response struct {
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+	Meta   *Meta  `json:"meta,omitempty"`
+}
+
+type MetaGetter interface {
+	GetComixBySlug(comixSlug string) (postgres.ComixFromAllComix, error)
+	GetTagBySlug(tagSlug string) (postgres.Tag, error)
+}
+
+// New отдаёт заголовок, описание и обложку комикса или тэга для карточек Open Graph и Twitter.
+// По старому slug отдаются данные с текущим адресом страницы.
+func New(log *slog.Logger, metaGetter MetaGetter, siteURL string, siteTitle string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.get_meta.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		slug := chi.URLParam(r, "slug")
+
+		var meta Meta
+		var ogType string
+
+		switch chi.URLParam(r, "kind") {
+		case KindComix:
+			comix, err := metaGetter.GetComixBySlug(slug)
+			if errors.Is(err, storage.ErrComixNotFound) {
+				render.JSON(w, r, resp.Error("Comix not exists"))
+
+				return
+			}
+			if err != nil {
+				log.Error("Cannot get comix from bd", sl.Err(err))
+
+				render.JSON(w, r, resp.Error("Cannot get comix from bd"))
+
+				return
+			}
+
+			ogType = "book"
+			meta = Meta{
+				Title:       comix.ComixName,
+				Description: comix.Description,
+				URL:         siteURL + get_comix_by_slug.Path + comix.Slug,
+				// Обложка есть всегда: без загруженной обложки ею служит первая страница
+				Image: siteURL + get_comix_by_slug.Path + comix.Slug + "/cover",
+			}
+		case KindTag:
+			tag, err := metaGetter.GetTagBySlug(slug)
+			if errors.Is(err, storage.ErrTagNotFound) {
+				render.JSON(w, r, resp.Error("Tag not exists"))
+
+				return
+			}
+			if err != nil {
+				log.Error("Cannot get tag from bd", sl.Err(err))
+
+				render.JSON(w, r, resp.Error("Cannot get tag from bd"))
+
+				return
+			}
+
+			ogType = "website"
+			meta = Meta{
+				Title:       tag.Name,
+				Description: tag.Description,
+				URL:         siteURL + get_tag_by_slug.Path + tag.Slug,
+			}
+			if tag.Cover != "" {
+				meta.Image = meta.URL + "/cover"
+			}
+		default:
+			render.JSON(w, r, resp.Error("kind must be comix or tags"))
+
+			return
+		}
+
+		meta.Description = truncate(strings.TrimSpace(meta.Description), maxDescription)
+		meta.Tags = tags(meta, ogType, siteTitle)
+
+		responseOK(w, r, meta)
+	}
+}
+
+// tags - мета-тэги карточек. Без обложки карточка Twitter маленькая.
+func tags(meta Meta, ogType string, siteTitle string) map[string]string {
+	result := map[string]string{
+		"og:type":             ogType,
+		"og:site_name":        siteTitle,
+		"og:title":            meta.Title,
+		"og:description":      meta.Description,
+		"og:url":              meta.URL,
+		"twitter:card":        "summary",
+		"twitter:title":       meta.Title,
+		"twitter:description": meta.Description,
+	}
+
+	if meta.Image != "" {
+		result["og:image"] = meta.Image
+		result["twitter:card"] = "summary_large_image"
+		result["twitter:image"] = meta.Image
+	}
+
+	return result
+}
+
+// truncate обрезает s до limit рун, обрезанное заканчивается многоточием
+func truncate(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+
+	return strings.TrimSpace(string(runes[:limit-1])) + "…"
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request, meta Meta) {
+	render.JSON(w, r, Response{
+		Status: resp.StatusOK,
+		Meta:   &meta,
+	})
+}
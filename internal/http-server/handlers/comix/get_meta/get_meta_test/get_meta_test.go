@@ -0,0 +1,97 @@
+package get_meta_test
+
+import (
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/get_meta"
+	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+type ResponseMock struct {
+	Status int            `json:"status,omitempty"`
+	Error  string         `json:"error,omitempty"`
+	Meta   *get_meta.Meta `json:"meta,omitempty"`
+}
+
+type MockMetaGetter struct{}
+
+func (m *MockMetaGetter) GetComixBySlug(comixSlug string) (postgres.ComixFromAllComix, error) {
+	switch comixSlug {
+	case "watchmen", "the-watchmen":
+		return postgres.ComixFromAllComix{Slug: "watchmen", ComixName: "Watchmen", ComixTag: "horror",
+			Description: strings.Repeat("Кто сторожит сторожей? ", 20)}, nil
+	}
+	return postgres.ComixFromAllComix{}, storage.ErrComixNotFound
+}
+
+func (m *MockMetaGetter) GetTagBySlug(tagSlug string) (postgres.Tag, error) {
+	if tagSlug != "horror" {
+		return postgres.Tag{}, storage.ErrTagNotFound
+	}
+	return postgres.Tag{Slug: "horror", Name: "Horror", Description: "Страшные комиксы"}, nil
+}
+
+func doRequest(t *testing.T, url string) ResponseMock {
+	router := chi.NewRouter()
+	router.Get(get_meta.Path+"{kind}/{slug}", get_meta.New(slogdiscard.NewDiscardLogger(), &MockMetaGetter{}, "https://comix.example", "Comix"))
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("GET", url, nil))
+
+	var responseBody ResponseMock
+
+	if err := json.Unmarshal(rr.Body.Bytes(), &responseBody); err != nil {
+		t.Fatalf("Ошибка при распоковке JSON: %s", err)
+	}
+
+	return responseBody
+}
+
+func TestGetMeta_Comix(t *testing.T) {
+	// По старому slug отдаётся текущий адрес
+	responseBody := doRequest(t, "/meta/comix/the-watchmen")
+
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+
+	meta := responseBody.Meta
+	assert.Equal(t, "Watchmen", meta.Title)
+	assert.Equal(t, "https://comix.example/api/comix/watchmen", meta.URL)
+	assert.Equal(t, "https://comix.example/api/comix/watchmen/cover", meta.Image)
+	assert.Equal(t, 200, utf8.RuneCountInString(meta.Description))
+	assert.True(t, strings.HasSuffix(meta.Description, "…"))
+
+	assert.Equal(t, "book", meta.Tags["og:type"])
+	assert.Equal(t, "Comix", meta.Tags["og:site_name"])
+	assert.Equal(t, meta.Image, meta.Tags["og:image"])
+	assert.Equal(t, "summary_large_image", meta.Tags["twitter:card"])
+}
+
+func TestGetMeta_Tag(t *testing.T) {
+	responseBody := doRequest(t, "/meta/tags/horror")
+
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+
+	meta := responseBody.Meta
+	assert.Equal(t, "Horror", meta.Title)
+	assert.Equal(t, "Страшные комиксы", meta.Description)
+	assert.Equal(t, "https://comix.example/api/tags/horror", meta.URL)
+	// У тэга без обложки карточка маленькая
+	assert.Empty(t, meta.Image)
+	assert.Equal(t, "summary", meta.Tags["twitter:card"])
+	assert.NotContains(t, meta.Tags, "og:image")
+}
+
+func TestGetMeta_InvalidRequest(t *testing.T) {
+	for _, url := range []string{"/meta/comix/hellboy", "/meta/tags/drama", "/meta/authors/moore"} {
+		responseBody := doRequest(t, url)
+		assert.Equal(t, http.StatusBadRequest, responseBody.Status, url)
+	}
+}
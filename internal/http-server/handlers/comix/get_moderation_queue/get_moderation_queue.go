@@ -0,0 +1,114 @@
+package get_moderation_queue
+
+import (
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator"
+	"jadesheart/comix_back/internal/http-server/middleware/ratelimit"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+)
+
+type Request struct {
+	Password   string `json:"password" validate:"required"`
+	PageNumber int    `json:"pageNumber" validate:"required,min=1"`
+}
+
+type Response struct {
+	Status int                     `json:"status,omitempty"`
+	Error  string                  `json:"error,omitempty"`
+	Comix  []postgres.PendingComix `json:"comix"`
+	Pages  []postgres.PendingPage  `json:"pages"`
+}
+
+type QueueGetter interface {
+	GetPendingComix(pageToDisplay int) ([]postgres.PendingComix, error)
+	GetPendingPages(pageToDisplay int) ([]postgres.PendingPage, error)
+	CheckPass(inputPass string) (bool, error)
+}
+
+// New отдаёт очередь модерации: новые комиксы и страницы уже одобренных комиксов, которые ждут проверки.
+func New(log *slog.Logger, queueGetter QueueGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.get_moderation_queue.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("failed decode request json", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed decode request json"))
+
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Error("failed validate", sl.Err(err))
+
+			render.JSON(w, r, resp.ValidateErrors(validateErr))
+
+			return
+		}
+
+		res, err := queueGetter.CheckPass(req.Password)
+		if err != nil {
+			log.Error("failed password verified", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed password verified"))
+
+			return
+		}
+
+		if !res {
+			log.Warn("incorrect password", slog.String("remote_addr", ratelimit.ClientIP(r)))
+
+			ratelimit.AuthFailed(r.Context())
+
+			render.JSON(w, r, resp.Error("incorrect password"))
+
+			return
+		}
+
+		ratelimit.AuthSucceeded(r.Context())
+
+		comix, err := queueGetter.GetPendingComix(req.PageNumber)
+		if err != nil {
+			log.Error("Cannot get pending comix from bd", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("Cannot get moderation queue from bd"))
+
+			return
+		}
+
+		pages, err := queueGetter.GetPendingPages(req.PageNumber)
+		if err != nil {
+			log.Error("Cannot get pending pages from bd", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("Cannot get moderation queue from bd"))
+
+			return
+		}
+
+		responseOK(w, r, comix, pages)
+
+	}
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request, comix []postgres.PendingComix, pages []postgres.PendingPage) {
+	render.JSON(w, r, Response{
+		Status: resp.StatusOK,
+		Comix:  comix,
+		Pages:  pages,
+	})
+}
@@ -1,21 +1,32 @@
 package get_photo
 
 import (
+	"errors"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
-	"io"
+	"jadesheart/comix_back/internal/http-server/middleware/ratelimit"
+	"jadesheart/comix_back/internal/lib/images"
 	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/lib/photos"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
 	"log/slog"
 	"net/http"
 	"os"
 )
 
-func New(log *slog.Logger) http.HandlerFunc {
+type PageGetter interface {
+	GetComixPages(tagName string, name string) ([]postgres.Page, error)
+}
+
+// New отдаёт файл страницы, только если читатели видят и комикс, и страницу: комиксы в корзине,
+// на проверке и ещё не вышедшие, как и страницы на проверке и ещё не вышедшие, отвечают 404.
+func New(log *slog.Logger, pageGetter PageGetter, mediaRoot *photos.Root) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.comix.get_photo.New"
 
-		log = log.With(
+		log := log.With(
 			slog.String("op", op),
 			slog.String("request_id", middleware.GetReqID(r.Context())),
 		)
@@ -24,29 +35,82 @@ func New(log *slog.Logger) http.HandlerFunc {
 		folder2 := chi.URLParam(r, "folder2")
 		fileName := chi.URLParam(r, "fileName")
 
-		filePath := "internal/storage/web/photos/" + folder1 + "/" + folder2[:len(folder2)-1] + "/" + fileName + ".jpg"
+		if folder2 == "" {
+			notFound(w, r)
+
+			return
+		}
+
+		tagName := folder1
+		comixName := folder2[:len(folder2)-1]
+		file := fileName + pageExt(r)
 
-		file, err := os.Open(filePath)
+		pages, err := pageGetter.GetComixPages(tagName, comixName)
+		if errors.Is(err, storage.ErrComixNotFound) {
+			notFound(w, r)
+
+			return
+		}
 		if err != nil {
-			log.Error("fail not found", sl.Err(err))
+			log.Error("Cannot get comix pages from bd", sl.Err(err))
+
+			render.JSON(w, r, "Cannot get comix pages from bd")
+
+			return
+		}
 
-			render.JSON(w, r, "fail not found")
+		if !hasPage(pages, file) {
+			notFound(w, r)
 
 			return
 		}
-		defer file.Close()
 
-		contentType := http.DetectContentType(nil)
-		w.Header().Set("Content-Type", contentType)
+		filePath, err := mediaRoot.ComixFile(tagName, comixName, file)
+		if err != nil {
+			log.Warn("unsafe photo path", sl.Err(err), slog.String("remote_addr", ratelimit.ClientIP(r)))
+
+			notFound(w, r)
 
-		_, err = io.Copy(w, file)
+			return
+		}
+
+		_, err = os.Stat(filePath)
 		if err != nil {
-			log.Error("Unable to send file", sl.Err(err))
+			log.Error("fail not found", sl.Err(err))
 
-			render.JSON(w, r, "Unable to send file")
+			notFound(w, r)
 
 			return
 		}
+
+		images.ServeFile(w, r, filePath)
+	}
+
+}
+
+// pageExt - расширение из адреса, которое отрезал middleware.URLFormat. Без расширения
+// запрашиваются старые страницы 1.jpg...N.jpg.
+func pageExt(r *http.Request) string {
+	format, _ := r.Context().Value(middleware.URLFormatCtxKey).(string)
+	if format == "" {
+		return photos.PageExt
+	}
+
+	return "." + format
+}
+
+// hasPage - file - одна из видимых страниц комикса
+func hasPage(pages []postgres.Page, file string) bool {
+	for _, page := range pages {
+		if page.File == file {
+			return true
+		}
 	}
 
+	return false
+}
+
+func notFound(w http.ResponseWriter, r *http.Request) {
+	render.Status(r, http.StatusNotFound)
+	render.JSON(w, r, "fail not found")
 }
@@ -0,0 +1,88 @@
+package get_photo_test
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/get_photo"
+	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"jadesheart/comix_back/internal/lib/photos"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// MockPageGetter - как GetComixPages: комиксы, которых читатели не видят, не находятся,
+// а страницы на проверке и ещё не вышедшие не входят в список
+type MockPageGetter struct{}
+
+func (m *MockPageGetter) GetComixPages(tagName string, name string) ([]postgres.Page, error) {
+	switch name {
+	case "Watchmen":
+		return []postgres.Page{{ID: 1, Position: 1, File: "1.jpg"}}, nil
+	default:
+		return nil, storage.ErrComixNotFound
+	}
+}
+
+// setupPhotos - папка с файлами всех комиксов, в том числе тех, что читатели не видят
+func setupPhotos(t *testing.T) *photos.Root {
+	dir := t.TempDir()
+
+	files := []string{
+		"horror/Watchmen/1.jpg",
+		"horror/Watchmen/pending.jpg",
+		"horror/Watchmen/scheduled.jpg",
+		"horror/Trashed/1.jpg",
+	}
+	for _, file := range files {
+		path := filepath.Join(dir, file)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte("\xff\xd8\xff\xe0 page"), 0644))
+	}
+
+	mediaRoot, err := photos.NewRoot(dir)
+	assert.NoError(t, err)
+
+	return mediaRoot
+}
+
+func doRequest(t *testing.T, url string) *httptest.ResponseRecorder {
+	router := chi.NewRouter()
+	router.Use(middleware.URLFormat)
+	router.Get("/{folder1}/{folder2}/{fileName}", get_photo.New(slogdiscard.NewDiscardLogger(), &MockPageGetter{}, setupPhotos(t)))
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("GET", url, nil))
+
+	return rr
+}
+
+func TestGetPhoto(t *testing.T) {
+	rr := doRequest(t, "/horror/Watchmen_/1.jpg")
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "image/jpeg", rr.Header().Get("Content-Type"))
+}
+
+func TestGetPhoto_TrashedComix(t *testing.T) {
+	rr := doRequest(t, "/horror/Trashed_/1.jpg")
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestGetPhoto_PendingPage(t *testing.T) {
+	rr := doRequest(t, "/horror/Watchmen_/pending.jpg")
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestGetPhoto_ScheduledPage(t *testing.T) {
+	rr := doRequest(t, "/horror/Watchmen_/scheduled.jpg")
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
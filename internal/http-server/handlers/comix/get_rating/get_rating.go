@@ -0,0 +1,79 @@
+package get_rating
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"jadesheart/comix_back/internal/http-server/middleware/session"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+)
+
+// Response - rating отсутствует, если читатель не оценивал комикс
+type Response struct {
+	Status int              `json:"status,omitempty"`
+	Error  string           `json:"error,omitempty"`
+	Rating *postgres.Rating `json:"rating,omitempty"`
+}
+
+type RatingGetter interface {
+	GetComixBySlug(comixSlug string) (postgres.ComixFromAllComix, error)
+	GetRating(readerID int, comixID int) (postgres.Rating, error)
+}
+
+// New отдаёт оценку и отзыв читателя запроса к комиксу {slug}.
+func New(log *slog.Logger, ratingGetter RatingGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.get_rating.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		reader, _ := session.Reader(r.Context())
+
+		comix, err := ratingGetter.GetComixBySlug(chi.URLParam(r, "slug"))
+		if errors.Is(err, storage.ErrComixNotFound) {
+			render.JSON(w, r, resp.Error("Comix not exists"))
+
+			return
+		}
+		if err != nil {
+			log.Error("Cannot get comix from bd", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("Cannot get comix from bd"))
+
+			return
+		}
+
+		rating, err := ratingGetter.GetRating(reader.ID, comix.ID)
+		if errors.Is(err, storage.ErrRatingNotFound) {
+			responseOK(w, r, nil)
+
+			return
+		}
+		if err != nil {
+			log.Error("Cannot get rating from bd", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("Cannot get rating from bd"))
+
+			return
+		}
+
+		responseOK(w, r, &rating)
+
+	}
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request, rating *postgres.Rating) {
+	render.JSON(w, r, Response{
+		Status: resp.StatusOK,
+		Rating: rating,
+	})
+}
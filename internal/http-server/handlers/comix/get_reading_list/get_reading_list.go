@@ -0,0 +1,118 @@
+package get_reading_list
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"jadesheart/comix_back/internal/http-server/middleware/session"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+// Path - маршрут, под которым доступен список. По нему же строится редирект со старого slug.
+const Path = "/api/lists/"
+
+type Response struct {
+	Status int                          `json:"status,omitempty"`
+	Error  string                       `json:"error,omitempty"`
+	List   *postgres.ReadingList        `json:"list,omitempty"`
+	Comix  []postgres.ComixFromAllComix `json:"comix"`
+}
+
+type ReadingListGetter interface {
+	GetReadingListBySlug(listSlug string) (postgres.ReadingList, error)
+	GetReadingListComix(listID int, pageToDisplay int) ([]postgres.ComixFromAllComix, error)
+	AttachReadingProgress(readerID int, comixList []postgres.ComixFromAllComix) error
+}
+
+// New отдаёт список по slug с его комиксами постранично. Публичный список виден всем,
+// личный - только владельцу; для остальных личного списка нет.
+func New(log *slog.Logger, readingListGetter ReadingListGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.get_reading_list.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		listSlug := chi.URLParam(r, "slug")
+
+		pageNumber := 1
+		if page := r.URL.Query().Get("pageNumber"); page != "" {
+			n, err := strconv.Atoi(page)
+			if err != nil || n < 1 {
+				render.JSON(w, r, resp.Error("pageNumber must be a positive number"))
+
+				return
+			}
+			pageNumber = n
+		}
+
+		list, err := readingListGetter.GetReadingListBySlug(listSlug)
+		if err != nil && !errors.Is(err, storage.ErrListNotFound) {
+			log.Error("Cannot get reading list from bd", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("Cannot get reading list from bd"))
+
+			return
+		}
+
+		reader, signedIn := session.Reader(r.Context())
+		owner := signedIn && reader.ID == list.ReaderID
+
+		if errors.Is(err, storage.ErrListNotFound) || !list.Public && !owner {
+			render.JSON(w, r, resp.Error("List not exists"))
+
+			return
+		}
+
+		if list.Slug != listSlug {
+			target := Path + list.Slug
+			if r.URL.RawQuery != "" {
+				target += "?" + r.URL.RawQuery
+			}
+
+			http.Redirect(w, r, target, http.StatusMovedPermanently)
+
+			return
+		}
+
+		comix, err := readingListGetter.GetReadingListComix(list.ID, pageNumber)
+		if err != nil {
+			log.Error("Cannot get comix from bd", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("Cannot get comix from bd"))
+
+			return
+		}
+
+		if signedIn {
+			err = readingListGetter.AttachReadingProgress(reader.ID, comix)
+			if err != nil {
+				log.Error("Cannot get reading progress from bd", sl.Err(err))
+
+				render.JSON(w, r, resp.Error("Cannot get reading progress from bd"))
+
+				return
+			}
+		}
+
+		responseOK(w, r, list, comix)
+
+	}
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request, list postgres.ReadingList, comix []postgres.ComixFromAllComix) {
+	render.JSON(w, r, Response{
+		Status: resp.StatusOK,
+		List:   &list,
+		Comix:  comix,
+	})
+}
@@ -0,0 +1,120 @@
+package get_reading_list_test
+
+import (
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/get_reading_list"
+	"jadesheart/comix_back/internal/http-server/middleware/session"
+	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type ResponseMock struct {
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+	List   *struct {
+		Slug  string
+		Owner string
+	} `json:"list"`
+	Comix []map[string]interface{} `json:"comix"`
+}
+
+type MockReadingListGetter struct {
+	progressReaderID int
+}
+
+func (m *MockReadingListGetter) GetReadingListBySlug(listSlug string) (postgres.ReadingList, error) {
+	switch listSlug {
+	case "favorites", "old-favorites":
+		return postgres.ReadingList{ID: 1, Slug: "favorites", Name: "Favorites", Public: true, Owner: "alice", ReaderID: 1}, nil
+	case "secret":
+		return postgres.ReadingList{ID: 2, Slug: "secret", Name: "Secret", Owner: "alice", ReaderID: 1}, nil
+	}
+	return postgres.ReadingList{}, storage.ErrListNotFound
+}
+
+func (m *MockReadingListGetter) GetReadingListComix(listID int, pageToDisplay int) ([]postgres.ComixFromAllComix, error) {
+	return []postgres.ComixFromAllComix{{ID: 3, Slug: "watchmen"}}, nil
+}
+
+func (m *MockReadingListGetter) AttachReadingProgress(readerID int, comixList []postgres.ComixFromAllComix) error {
+	m.progressReaderID = readerID
+	return nil
+}
+
+func doRequest(t *testing.T, getter *MockReadingListGetter, url string, reader *postgres.Reader) (*httptest.ResponseRecorder, ResponseMock) {
+	router := chi.NewRouter()
+	router.Get(get_reading_list.Path+"{slug}", get_reading_list.New(slogdiscard.NewDiscardLogger(), getter))
+
+	req, err := http.NewRequest("GET", url, nil)
+	assert.NoError(t, err)
+	if reader != nil {
+		req = req.WithContext(session.WithReader(req.Context(), *reader))
+	}
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	var responseBody ResponseMock
+
+	if rr.Code == http.StatusOK {
+		if err := json.Unmarshal(rr.Body.Bytes(), &responseBody); err != nil {
+			t.Fatalf("Ошибка при распоковке JSON: %s", err)
+		}
+	}
+
+	return rr, responseBody
+}
+
+func TestGetReadingList_Public(t *testing.T) {
+	getter := &MockReadingListGetter{}
+
+	_, responseBody := doRequest(t, getter, "/api/lists/favorites", nil)
+
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+	assert.Equal(t, "alice", responseBody.List.Owner)
+	assert.Len(t, responseBody.Comix, 1)
+	assert.Equal(t, 0, getter.progressReaderID)
+
+	_, responseBody = doRequest(t, getter, "/api/lists/favorites", &postgres.Reader{ID: 2, Username: "bob"})
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+	assert.Equal(t, 2, getter.progressReaderID)
+}
+
+func TestGetReadingList_Private(t *testing.T) {
+	getter := &MockReadingListGetter{}
+
+	_, responseBody := doRequest(t, getter, "/api/lists/secret", &postgres.Reader{ID: 1, Username: "alice"})
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+	assert.Equal(t, "secret", responseBody.List.Slug)
+
+	// Чужой личный список выглядит как несуществующий
+	for _, reader := range []*postgres.Reader{nil, {ID: 2, Username: "bob"}} {
+		_, responseBody = doRequest(t, getter, "/api/lists/secret", reader)
+		assert.Equal(t, http.StatusBadRequest, responseBody.Status)
+		assert.Equal(t, "List not exists", responseBody.Error)
+		assert.Nil(t, responseBody.List)
+	}
+
+	_, responseBody = doRequest(t, getter, "/api/lists/unknown", nil)
+	assert.Equal(t, "List not exists", responseBody.Error)
+}
+
+func TestGetReadingList_OldSlugRedirect(t *testing.T) {
+	rr, _ := doRequest(t, &MockReadingListGetter{}, "/api/lists/old-favorites?pageNumber=2", nil)
+
+	assert.Equal(t, http.StatusMovedPermanently, rr.Code)
+	assert.Equal(t, "/api/lists/favorites?pageNumber=2", rr.Header().Get("Location"))
+}
+
+func TestGetReadingList_InvalidPage(t *testing.T) {
+	_, responseBody := doRequest(t, &MockReadingListGetter{}, "/api/lists/favorites?pageNumber=0", nil)
+
+	assert.Equal(t, http.StatusBadRequest, responseBody.Status)
+	assert.Equal(t, "pageNumber must be a positive number", responseBody.Error)
+}
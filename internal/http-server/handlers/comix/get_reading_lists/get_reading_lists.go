@@ -0,0 +1,55 @@
+package get_reading_lists
+
+import (
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"jadesheart/comix_back/internal/http-server/middleware/session"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+)
+
+type Response struct {
+	Status int                    `json:"status,omitempty"`
+	Error  string                 `json:"error,omitempty"`
+	Lists  []postgres.ReadingList `json:"lists"`
+}
+
+type ReadingListsGetter interface {
+	GetReadingLists(readerID int) ([]postgres.ReadingList, error)
+}
+
+// New отдаёт все списки читателя запроса, публичные и личные.
+func New(log *slog.Logger, readingListsGetter ReadingListsGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.get_reading_lists.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		reader, _ := session.Reader(r.Context())
+
+		lists, err := readingListsGetter.GetReadingLists(reader.ID)
+		if err != nil {
+			log.Error("Cannot get reading lists from bd", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("Cannot get reading lists from bd"))
+
+			return
+		}
+
+		responseOK(w, r, lists)
+
+	}
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request, lists []postgres.ReadingList) {
+	render.JSON(w, r, Response{
+		Status: resp.StatusOK,
+		Lists:  lists,
+	})
+}
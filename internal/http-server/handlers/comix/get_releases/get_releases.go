@@ -0,0 +1,102 @@
+package get_releases
+
+import (
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator"
+	"jadesheart/comix_back/internal/http-server/middleware/ratelimit"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+)
+
+type Request struct {
+	Password   string `json:"password" validate:"required"`
+	PageNumber int    `json:"pageNumber" validate:"required,min=1"`
+}
+
+type Response struct {
+	Status   int                `json:"status,omitempty"`
+	Error    string             `json:"error,omitempty"`
+	Releases []postgres.Release `json:"releases"`
+}
+
+type ReleasesGetter interface {
+	GetScheduledReleases(pageToDisplay int) ([]postgres.Release, error)
+	CheckPass(inputPass string) (bool, error)
+}
+
+// New отдаёт запланированные выпуски комиксов и страниц, ближайшие первыми.
+func New(log *slog.Logger, releasesGetter ReleasesGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.get_releases.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("failed decode request json", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed decode request json"))
+
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Error("failed validate", sl.Err(err))
+
+			render.JSON(w, r, resp.ValidateErrors(validateErr))
+
+			return
+		}
+
+		res, err := releasesGetter.CheckPass(req.Password)
+		if err != nil {
+			log.Error("failed password verified", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed password verified"))
+
+			return
+		}
+
+		if !res {
+			log.Warn("incorrect password", slog.String("remote_addr", ratelimit.ClientIP(r)))
+
+			ratelimit.AuthFailed(r.Context())
+
+			render.JSON(w, r, resp.Error("incorrect password"))
+
+			return
+		}
+
+		ratelimit.AuthSucceeded(r.Context())
+
+		releases, err := releasesGetter.GetScheduledReleases(req.PageNumber)
+		if err != nil {
+			log.Error("Cannot get releases from bd", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("Cannot get releases from bd"))
+
+			return
+		}
+
+		responseOK(w, r, releases)
+
+	}
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request, releases []postgres.Release) {
+	render.JSON(w, r, Response{
+		Status:   resp.StatusOK,
+		Releases: releases,
+	})
+}
@@ -0,0 +1,102 @@
+package get_reports
+
+import (
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator"
+	"jadesheart/comix_back/internal/http-server/middleware/ratelimit"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+)
+
+type Request struct {
+	Password   string `json:"password" validate:"required"`
+	PageNumber int    `json:"pageNumber" validate:"required,min=1"`
+}
+
+type Response struct {
+	Status  int               `json:"status,omitempty"`
+	Error   string            `json:"error,omitempty"`
+	Reports []postgres.Report `json:"reports"`
+}
+
+type ReportsGetter interface {
+	GetReports(pageToDisplay int) ([]postgres.Report, error)
+	CheckPass(inputPass string) (bool, error)
+}
+
+// New отдаёт нерассмотренные жалобы читателей, старые первыми.
+func New(log *slog.Logger, reportsGetter ReportsGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.get_reports.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("failed decode request json", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed decode request json"))
+
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Error("failed validate", sl.Err(err))
+
+			render.JSON(w, r, resp.ValidateErrors(validateErr))
+
+			return
+		}
+
+		res, err := reportsGetter.CheckPass(req.Password)
+		if err != nil {
+			log.Error("failed password verified", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed password verified"))
+
+			return
+		}
+
+		if !res {
+			log.Warn("incorrect password", slog.String("remote_addr", ratelimit.ClientIP(r)))
+
+			ratelimit.AuthFailed(r.Context())
+
+			render.JSON(w, r, resp.Error("incorrect password"))
+
+			return
+		}
+
+		ratelimit.AuthSucceeded(r.Context())
+
+		reports, err := reportsGetter.GetReports(req.PageNumber)
+		if err != nil {
+			log.Error("Cannot get reports from bd", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("Cannot get reports from bd"))
+
+			return
+		}
+
+		responseOK(w, r, reports)
+
+	}
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request, reports []postgres.Report) {
+	render.JSON(w, r, Response{
+		Status:  resp.StatusOK,
+		Reports: reports,
+	})
+}
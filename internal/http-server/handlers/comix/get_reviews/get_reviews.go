@@ -0,0 +1,82 @@
+package get_reviews
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type Response struct {
+	Status  int               `json:"status,omitempty"`
+	Error   string            `json:"error,omitempty"`
+	Reviews []postgres.Review `json:"reviews"`
+}
+
+type ReviewsGetter interface {
+	GetComixBySlug(comixSlug string) (postgres.ComixFromAllComix, error)
+	GetReviews(comixID int, pageToDisplay int) ([]postgres.Review, error)
+}
+
+// New отдаёт отзывы к комиксу {slug} постранично, сначала недавние. Оценки без текста не входят.
+func New(log *slog.Logger, reviewsGetter ReviewsGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.get_reviews.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		pageNumber := 1
+		if page := r.URL.Query().Get("pageNumber"); page != "" {
+			n, err := strconv.Atoi(page)
+			if err != nil || n < 1 {
+				render.JSON(w, r, resp.Error("pageNumber must be a positive number"))
+
+				return
+			}
+			pageNumber = n
+		}
+
+		comix, err := reviewsGetter.GetComixBySlug(chi.URLParam(r, "slug"))
+		if errors.Is(err, storage.ErrComixNotFound) {
+			render.JSON(w, r, resp.Error("Comix not exists"))
+
+			return
+		}
+		if err != nil {
+			log.Error("Cannot get comix from bd", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("Cannot get comix from bd"))
+
+			return
+		}
+
+		reviews, err := reviewsGetter.GetReviews(comix.ID, pageNumber)
+		if err != nil {
+			log.Error("Cannot get reviews from bd", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("Cannot get reviews from bd"))
+
+			return
+		}
+
+		responseOK(w, r, reviews)
+
+	}
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request, reviews []postgres.Review) {
+	render.JSON(w, r, Response{
+		Status:  resp.StatusOK,
+		Reviews: reviews,
+	})
+}
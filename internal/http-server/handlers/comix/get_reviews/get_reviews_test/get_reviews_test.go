@@ -0,0 +1,85 @@
+package get_reviews_test
+
+import (
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/get_reviews"
+	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type ResponseMock struct {
+	Status  int               `json:"status,omitempty"`
+	Error   string            `json:"error,omitempty"`
+	Reviews []postgres.Review `json:"reviews"`
+}
+
+type MockReviewsGetter struct {
+	pages []int
+}
+
+func (m *MockReviewsGetter) GetComixBySlug(comixSlug string) (postgres.ComixFromAllComix, error) {
+	if comixSlug == "watchmen" {
+		return postgres.ComixFromAllComix{ID: 3, Slug: "watchmen"}, nil
+	}
+	return postgres.ComixFromAllComix{}, storage.ErrComixNotFound
+}
+
+func (m *MockReviewsGetter) GetReviews(comixID int, pageToDisplay int) ([]postgres.Review, error) {
+	m.pages = append(m.pages, pageToDisplay)
+	return []postgres.Review{{Author: "reader", Score: 5, Review: "Classic"}}, nil
+}
+
+func doRequest(t *testing.T, getter *MockReviewsGetter, url string) ResponseMock {
+	router := chi.NewRouter()
+	router.Get("/api/comix/{slug}/reviews", get_reviews.New(slogdiscard.NewDiscardLogger(), getter))
+
+	req, err := http.NewRequest("GET", url, nil)
+	assert.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	var responseBody ResponseMock
+
+	if err := json.Unmarshal(rr.Body.Bytes(), &responseBody); err != nil {
+		t.Fatalf("Ошибка при распоковке JSON: %s", err)
+	}
+
+	return responseBody
+}
+
+func TestGetReviews_Success(t *testing.T) {
+	getter := &MockReviewsGetter{}
+
+	responseBody := doRequest(t, getter, "/api/comix/watchmen/reviews?pageNumber=2")
+
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+	assert.Equal(t, "Classic", responseBody.Reviews[0].Review)
+	assert.Equal(t, []int{2}, getter.pages)
+}
+
+func TestGetReviews_Errors(t *testing.T) {
+	cases := []struct {
+		url   string
+		error string
+	}{
+		{"/api/comix/watchmen/reviews?pageNumber=-1", "pageNumber must be a positive number"},
+		{"/api/comix/unknown/reviews", "Comix not exists"},
+	}
+
+	for _, c := range cases {
+		getter := &MockReviewsGetter{}
+
+		responseBody := doRequest(t, getter, c.url)
+
+		assert.Equal(t, http.StatusBadRequest, responseBody.Status)
+		assert.Equal(t, c.error, responseBody.Error)
+		assert.Empty(t, getter.pages)
+	}
+}
@@ -0,0 +1,126 @@
+package get_sitemap
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/get_comix_by_slug"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/get_tag_by_slug"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/feed"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/lib/sitemap"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+// Path - карта сайта, /sitemap.xml: расширение отрезает middleware.URLFormat.
+// Если адресов больше, чем помещается в файл, здесь отдаётся индекс, а сами карты - Path + "/{page}.xml"
+const Path = "/sitemap"
+
+type EntriesGetter interface {
+	GetSitemapEntries() ([]postgres.SitemapEntry, error)
+}
+
+// New отдаёт карту сайта со страницами тэгов и комиксов, а с параметром пути page - одну её часть.
+// perFile - сколько адресов помещается в один файл, обычно sitemap.MaxURLs.
+func New(log *slog.Logger, entriesGetter EntriesGetter, siteURL string, perFile int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.get_sitemap.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		format, _ := r.Context().Value(middleware.URLFormatCtxKey).(string)
+		if format != "" && format != "xml" {
+			render.JSON(w, r, resp.Error("sitemap format must be xml"))
+
+			return
+		}
+
+		page := 0
+		if pageParam := chi.URLParam(r, "page"); pageParam != "" {
+			var err error
+
+			page, err = strconv.Atoi(pageParam)
+			if err != nil || page < 1 {
+				render.JSON(w, r, resp.Error("Sitemap page not exists"))
+
+				return
+			}
+		}
+
+		entries, err := entriesGetter.GetSitemapEntries()
+		if err != nil {
+			log.Error("Cannot get sitemap entries from bd", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("Cannot get sitemap entries from bd"))
+
+			return
+		}
+
+		parts := sitemap.Split(urls(siteURL, entries), perFile)
+
+		var body []byte
+		updated := sitemap.LastMod(parts[0])
+
+		switch {
+		case page > len(parts):
+			render.JSON(w, r, resp.Error("Sitemap page not exists"))
+
+			return
+		case page > 0:
+			updated = sitemap.LastMod(parts[page-1])
+			body, err = sitemap.URLSet(parts[page-1])
+		case len(parts) == 1:
+			body, err = sitemap.URLSet(parts[0])
+		default:
+			index := make([]sitemap.URL, 0, len(parts))
+			for i, part := range parts {
+				lastMod := sitemap.LastMod(part)
+				if lastMod.After(updated) {
+					updated = lastMod
+				}
+
+				index = append(index, sitemap.URL{
+					Loc:     siteURL + Path + "/" + strconv.Itoa(i+1) + ".xml",
+					LastMod: lastMod,
+				})
+			}
+
+			body, err = sitemap.Index(index)
+		}
+		if err != nil {
+			log.Error("failed encode sitemap", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed encode sitemap"))
+
+			return
+		}
+
+		feed.Serve(w, r, body, sitemap.MimeType, updated)
+	}
+}
+
+// urls - адреса страниц тэгов и комиксов, по тем же путям, что и в лентах
+func urls(siteURL string, entries []postgres.SitemapEntry) []sitemap.URL {
+	result := make([]sitemap.URL, 0, len(entries))
+
+	for _, entry := range entries {
+		path := get_comix_by_slug.Path
+		if entry.Kind == postgres.SitemapTag {
+			path = get_tag_by_slug.Path
+		}
+
+		result = append(result, sitemap.URL{
+			Loc:     siteURL + path + entry.Slug,
+			LastMod: entry.UpdatedAt,
+		})
+	}
+
+	return result
+}
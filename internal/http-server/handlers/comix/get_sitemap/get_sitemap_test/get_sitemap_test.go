@@ -0,0 +1,121 @@
+package get_sitemap_test
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/get_sitemap"
+	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type ResponseMock struct {
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type URLSetMock struct {
+	URLs []struct {
+		Loc     string `xml:"loc"`
+		LastMod string `xml:"lastmod"`
+	} `xml:"url"`
+}
+
+type IndexMock struct {
+	XMLName  xml.Name `xml:"sitemapindex"`
+	Sitemaps []struct {
+		Loc     string `xml:"loc"`
+		LastMod string `xml:"lastmod"`
+	} `xml:"sitemap"`
+}
+
+var updated = time.Date(2026, 3, 2, 10, 30, 0, 0, time.UTC)
+
+type MockEntriesGetter struct{}
+
+func (m *MockEntriesGetter) GetSitemapEntries() ([]postgres.SitemapEntry, error) {
+	return []postgres.SitemapEntry{
+		{Kind: postgres.SitemapTag, Slug: "horror", UpdatedAt: updated},
+		// Тэг без комиксов
+		{Kind: postgres.SitemapTag, Slug: "drama"},
+		{Kind: postgres.SitemapComix, Slug: "watchmen", UpdatedAt: updated},
+		{Kind: postgres.SitemapComix, Slug: "hellboy", UpdatedAt: updated.Add(-time.Hour)},
+	}, nil
+}
+
+func doRequest(perFile int, url string, header http.Header) *httptest.ResponseRecorder {
+	handler := get_sitemap.New(slogdiscard.NewDiscardLogger(), &MockEntriesGetter{}, "https://comix.example", perFile)
+
+	router := chi.NewRouter()
+	router.Use(middleware.URLFormat)
+	router.Get(get_sitemap.Path, handler)
+	router.Get(get_sitemap.Path+"/{page}", handler)
+
+	req := httptest.NewRequest("GET", url, nil)
+	for key := range header {
+		req.Header.Set(key, header.Get(key))
+	}
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	return rr
+}
+
+func TestGetSitemap(t *testing.T) {
+	rr := doRequest(10, "/sitemap.xml", nil)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, updated.Format(http.TimeFormat), rr.Header().Get("Last-Modified"))
+
+	var doc URLSetMock
+	assert.NoError(t, xml.Unmarshal(rr.Body.Bytes(), &doc))
+
+	assert.Len(t, doc.URLs, 4)
+	assert.Equal(t, "https://comix.example/api/tags/horror", doc.URLs[0].Loc)
+	assert.Equal(t, "2026-03-02T10:30:00Z", doc.URLs[0].LastMod)
+	assert.Empty(t, doc.URLs[1].LastMod)
+	assert.Equal(t, "https://comix.example/api/comix/watchmen", doc.URLs[2].Loc)
+
+	rr = doRequest(10, "/sitemap.xml", http.Header{"If-None-Match": {rr.Header().Get("ETag")}})
+	assert.Equal(t, http.StatusNotModified, rr.Code)
+}
+
+func TestGetSitemap_Index(t *testing.T) {
+	rr := doRequest(3, "/sitemap.xml", nil)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var index IndexMock
+	assert.NoError(t, xml.Unmarshal(rr.Body.Bytes(), &index))
+
+	assert.Len(t, index.Sitemaps, 2)
+	assert.Equal(t, "https://comix.example/sitemap/1.xml", index.Sitemaps[0].Loc)
+	assert.Equal(t, "https://comix.example/sitemap/2.xml", index.Sitemaps[1].Loc)
+	assert.Equal(t, "2026-03-02T09:30:00Z", index.Sitemaps[1].LastMod)
+
+	rr = doRequest(3, "/sitemap/2.xml", nil)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var doc URLSetMock
+	assert.NoError(t, xml.Unmarshal(rr.Body.Bytes(), &doc))
+
+	assert.Len(t, doc.URLs, 1)
+	assert.Equal(t, "https://comix.example/api/comix/hellboy", doc.URLs[0].Loc)
+}
+
+func TestGetSitemap_InvalidRequest(t *testing.T) {
+	for _, url := range []string{"/sitemap.json", "/sitemap/3.xml", "/sitemap/0.xml", "/sitemap/first.xml"} {
+		rr := doRequest(3, url, nil)
+
+		var responseBody ResponseMock
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &responseBody), url)
+		assert.Equal(t, http.StatusBadRequest, responseBody.Status, url)
+	}
+}
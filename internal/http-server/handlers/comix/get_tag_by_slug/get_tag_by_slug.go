@@ -0,0 +1,74 @@
+package get_tag_by_slug
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+)
+
+// Path - маршрут, под которым доступен тэг. По нему же строится редирект со старого slug.
+const Path = "/api/tags/"
+
+type Response struct {
+	Status int          `json:"status,omitempty"`
+	Error  string       `json:"error,omitempty"`
+	Tag    postgres.Tag `json:"tag"`
+}
+
+type TagGetter interface {
+	GetTagBySlug(tagSlug string) (postgres.Tag, error)
+}
+
+func New(log *slog.Logger, tagGetter TagGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.get_tag_by_slug.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		tagSlug := chi.URLParam(r, "slug")
+
+		tag, err := tagGetter.GetTagBySlug(tagSlug)
+		if errors.Is(err, storage.ErrTagNotFound) {
+			log.Info("tag not found", slog.String("slug", tagSlug))
+
+			render.JSON(w, r, resp.Error("Tag not exists"))
+
+			return
+		}
+		if err != nil {
+			log.Error("Cannot get tag from bd", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("Cannot get tag from bd"))
+
+			return
+		}
+
+		if tag.Slug != tagSlug {
+			log.Info("redirect from old slug", slog.String("slug", tagSlug), slog.String("current", tag.Slug))
+
+			http.Redirect(w, r, Path+tag.Slug, http.StatusMovedPermanently)
+
+			return
+		}
+
+		responseOK(w, r, tag)
+
+	}
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request, tag postgres.Tag) {
+	render.JSON(w, r, Response{
+		Status: resp.StatusOK,
+		Tag:    tag,
+	})
+}
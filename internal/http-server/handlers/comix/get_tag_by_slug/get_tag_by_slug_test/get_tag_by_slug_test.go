@@ -0,0 +1,75 @@
+package get_tag_by_slug_test
+
+import (
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/get_tag_by_slug"
+	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type ResponseMock struct {
+	Status int          `json:"status,omitempty"`
+	Error  string       `json:"error,omitempty"`
+	Tag    postgres.Tag `json:"tag"`
+}
+
+type MockTagGetter struct{}
+
+func (m *MockTagGetter) GetTagBySlug(tagSlug string) (postgres.Tag, error) {
+	switch tagSlug {
+	case "new-name", "old-name":
+		return postgres.Tag{ID: 7, Slug: "new-name", Name: "New name"}, nil
+	}
+	return postgres.Tag{}, storage.ErrTagNotFound
+}
+
+func doRequest(url string) *httptest.ResponseRecorder {
+	router := chi.NewRouter()
+	router.Get(get_tag_by_slug.Path+"{slug}", get_tag_by_slug.New(slogdiscard.NewDiscardLogger(), &MockTagGetter{}))
+
+	req := httptest.NewRequest("GET", url, nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	return rr
+}
+
+func TestGetTagBySlug_Success(t *testing.T) {
+	rr := doRequest("/api/tags/new-name")
+
+	var responseBody ResponseMock
+
+	if err := json.Unmarshal(rr.Body.Bytes(), &responseBody); err != nil {
+		t.Fatalf("Ошибка при распоковке JSON: %s", err)
+	}
+
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+	assert.Equal(t, 7, responseBody.Tag.ID)
+	assert.Equal(t, "New name", responseBody.Tag.Name)
+}
+
+func TestGetTagBySlug_OldSlugRedirects(t *testing.T) {
+	rr := doRequest("/api/tags/old-name")
+
+	assert.Equal(t, http.StatusMovedPermanently, rr.Code)
+	assert.Equal(t, "/api/tags/new-name", rr.Header().Get("Location"))
+}
+
+func TestGetTagBySlug_NotExist(t *testing.T) {
+	rr := doRequest("/api/tags/missing")
+
+	var responseBody ResponseMock
+
+	if err := json.Unmarshal(rr.Body.Bytes(), &responseBody); err != nil {
+		t.Fatalf("Ошибка при распоковке JSON: %s", err)
+	}
+
+	assert.Equal(t, http.StatusBadRequest, responseBody.Status)
+	assert.Equal(t, "Tag not exists", responseBody.Error)
+}
@@ -0,0 +1,64 @@
+package get_tag_cover
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/images"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/lib/photos"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+)
+
+type TagGetter interface {
+	GetTagBySlug(tagSlug string) (postgres.Tag, error)
+}
+
+func New(log *slog.Logger, tagGetter TagGetter, mediaRoot *photos.Root) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.get_tag_cover.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		tagSlug := chi.URLParam(r, "slug")
+
+		tag, err := tagGetter.GetTagBySlug(tagSlug)
+		if errors.Is(err, storage.ErrTagNotFound) {
+			render.JSON(w, r, resp.Error("Tag not exists"))
+
+			return
+		}
+		if err != nil {
+			log.Error("Cannot get tag from bd", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("Cannot get tag from bd"))
+
+			return
+		}
+
+		if tag.Cover == "" {
+			render.JSON(w, r, resp.Error("Tag has no cover"))
+
+			return
+		}
+
+		coverPath, err := mediaRoot.TagFile(tag.Name, tag.Cover)
+		if err != nil {
+			log.Error("unsafe tag cover path", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("Tag has no cover"))
+
+			return
+		}
+
+		images.ServeFile(w, r, coverPath)
+	}
+}
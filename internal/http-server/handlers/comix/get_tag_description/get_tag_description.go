@@ -68,7 +68,7 @@ func New(log *slog.Logger, tagDescriptionGetter TagDescriptionGetter) http.Handl
 
 		description, err := tagDescriptionGetter.GetTagDescription(req.TagName)
 		if err != nil {
-			log.Error("INCORRECT PASSWORD", slog.Any("Failed get tag description ", sl.Err(err)))
+			log.Error("Failed get tag description", sl.Err(err))
 
 			render.JSON(w, r, resp.Error("Failed get tag description"))
 
@@ -0,0 +1,114 @@
+package get_trash
+
+import (
+	"fmt"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator"
+	"jadesheart/comix_back/internal/http-server/middleware/ratelimit"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+	"reflect"
+)
+
+type Request struct {
+	Password   string `json:"password" validate:"required"`
+	PageNumber int    `json:"pageNumber" validate:"required"`
+}
+
+type Response struct {
+	Status       int                     `json:"status,omitempty"`
+	Error        string                  `json:"error,omitempty"`
+	DeletedComix []postgres.DeletedComix `json:"deletedComix"`
+}
+
+type TrashGetter interface {
+	GetDeletedComix(pageToDisplay int) ([]postgres.DeletedComix, error)
+	CheckPass(inputPass string) (bool, error)
+}
+
+func New(log *slog.Logger, trashGetter TrashGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.get_trash.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("failed decode request json", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed decode request json"))
+
+			return
+		}
+
+		reqType := reflect.TypeOf(req)
+		for i := 0; i < reqType.NumField(); i++ {
+			field := reqType.Field(i)
+			fieldValue := reflect.ValueOf(req).FieldByName(field.Name)
+			if fieldValue.IsZero() {
+				errorMsg := fmt.Sprintf("zero point value: %s", field.Name)
+				render.JSON(w, r, resp.Error(errorMsg))
+				return
+			}
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Error("failed validate", sl.Err(err))
+
+			render.JSON(w, r, resp.ValidateErrors(validateErr))
+
+			return
+		}
+
+		res, err := trashGetter.CheckPass(req.Password)
+		if err != nil {
+			log.Error("failed password verified", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed password verified"))
+
+			return
+		}
+
+		if !res {
+			log.Warn("incorrect password", slog.String("remote_addr", ratelimit.ClientIP(r)))
+
+			ratelimit.AuthFailed(r.Context())
+
+			render.JSON(w, r, resp.Error("incorrect password"))
+
+			return
+		}
+
+		ratelimit.AuthSucceeded(r.Context())
+
+		comix, err := trashGetter.GetDeletedComix(req.PageNumber)
+		if err != nil {
+			log.Error("Cannot get deleted comix from bd", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("Cannot get deleted comix from bd"))
+
+			return
+		}
+
+		responseOK(w, r, comix)
+
+	}
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request, comix []postgres.DeletedComix) {
+	render.JSON(w, r, Response{
+		Status:       200,
+		DeletedComix: comix,
+	})
+}
@@ -0,0 +1,73 @@
+package get_trash_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/get_trash"
+	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type ResponseMock struct {
+	Status       int                     `json:"status,omitempty"`
+	Error        string                  `json:"error,omitempty"`
+	DeletedComix []postgres.DeletedComix `json:"deletedComix"`
+}
+
+type MockTrashGetter struct{}
+
+func (m *MockTrashGetter) GetDeletedComix(pageToDisplay int) ([]postgres.DeletedComix, error) {
+	return []postgres.DeletedComix{{ComixFromAllComix: postgres.ComixFromAllComix{ComixName: "deleted"}}}, nil
+}
+
+func (m *MockTrashGetter) CheckPass(inputPass string) (bool, error) {
+	return inputPass == "password", nil
+}
+
+func doRequest(t *testing.T, body map[string]interface{}) ResponseMock {
+	handler := get_trash.New(slogdiscard.NewDiscardLogger(), &MockTrashGetter{})
+
+	jsonBody, _ := json.Marshal(body)
+
+	req, err := http.NewRequest("POST", "/trash", bytes.NewBuffer(jsonBody))
+	assert.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	var responseBody ResponseMock
+
+	if err := json.Unmarshal(rr.Body.Bytes(), &responseBody); err != nil {
+		t.Fatalf("Ошибка при распоковке JSON: %s", err)
+	}
+
+	return responseBody
+}
+
+func TestGetTrash_Success(t *testing.T) {
+	responseBody := doRequest(t, map[string]interface{}{
+		"password":   "password",
+		"pageNumber": 1,
+	})
+
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+	assert.Len(t, responseBody.DeletedComix, 1)
+}
+
+func TestGetTrash_InvalidRequest(t *testing.T) {
+	requestsBody := []map[string]interface{}{
+		{"pageNumber": 1},
+		{"password": "password"},
+		{"password": "wrong_password", "pageNumber": 1},
+	}
+
+	for _, m := range requestsBody {
+		responseBody := doRequest(t, m)
+
+		assert.Equal(t, http.StatusBadRequest, responseBody.Status)
+	}
+}
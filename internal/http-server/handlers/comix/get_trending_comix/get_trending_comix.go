@@ -0,0 +1,125 @@
+package get_trending_comix
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Response struct {
+	Status            int                      `json:"status,omitempty"`
+	Error             string                   `json:"error,omitempty"`
+	Window            string                   `json:"window"`
+	ComixFromAllComix []postgres.TrendingComix `json:"comixFromForMainPage"`
+}
+
+type TrendingGetter interface {
+	GetTrendingComix(days int, tagName string, halfLifeDays float64, pageToDisplay int) ([]postgres.TrendingComix, error)
+}
+
+type Cache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte, ttl time.Duration)
+}
+
+// windows - поддерживаемые окна популярности и их размер в днях.
+var windows = map[string]int{
+	"24h": 1,
+	"7d":  7,
+	"30d": 30,
+	"all": 0,
+}
+
+const defaultWindow = "7d"
+
+func New(log *slog.Logger, trendingGetter TrendingGetter, cache Cache, cacheTTL time.Duration, decayHalfLife time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.get_trending_comix.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		query := r.URL.Query()
+
+		window := query.Get("window")
+		if window == "" {
+			window = defaultWindow
+		}
+
+		days, ok := windows[window]
+		if !ok {
+			log.Info("unknown trending window", slog.String("window", window))
+
+			render.JSON(w, r, resp.Error("unknown window, use one of: 24h, 7d, 30d, all"))
+
+			return
+		}
+
+		pageNumber := 1
+		if page := query.Get("pageNumber"); page != "" {
+			n, err := strconv.Atoi(page)
+			if err != nil || n < 1 {
+				render.JSON(w, r, resp.Error("pageNumber must be a positive number"))
+
+				return
+			}
+			pageNumber = n
+		}
+
+		var halfLifeDays float64
+		if decay, _ := strconv.ParseBool(query.Get("decay")); decay {
+			halfLifeDays = decayHalfLife.Hours() / 24
+		}
+
+		tag := strings.ToLower(strings.TrimSpace(query.Get("tag")))
+
+		key := fmt.Sprintf("trending:%s:%s:%g:%d", window, tag, halfLifeDays, pageNumber)
+
+		if cached, ok := cache.Get(key); ok {
+			var comix []postgres.TrendingComix
+			if err := json.Unmarshal(cached, &comix); err == nil {
+				responseOK(w, r, window, comix)
+
+				return
+			}
+		}
+
+		comix, err := trendingGetter.GetTrendingComix(days, tag, halfLifeDays, pageNumber)
+		if err != nil {
+			log.Error("Cannot get trending comix from bd", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("Cannot get trending comix from bd"))
+
+			return
+		}
+
+		data, err := json.Marshal(comix)
+		if err != nil {
+			log.Error("failed encode trending comix for cache", sl.Err(err))
+		} else {
+			cache.Set(key, data, cacheTTL)
+		}
+
+		responseOK(w, r, window, comix)
+
+	}
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request, window string, comix []postgres.TrendingComix) {
+	render.JSON(w, r, Response{
+		Status:            200,
+		Window:            window,
+		ComixFromAllComix: comix,
+	})
+}
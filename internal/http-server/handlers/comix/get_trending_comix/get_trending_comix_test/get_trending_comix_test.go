@@ -0,0 +1,110 @@
+package get_trending_comix_test
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/get_trending_comix"
+	"jadesheart/comix_back/internal/lib/cache"
+	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type ResponseMock struct {
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+	Window string `json:"window"`
+}
+
+type MockTrendingGetter struct {
+	calls        int
+	days         int
+	tagName      string
+	halfLifeDays float64
+}
+
+func (m *MockTrendingGetter) GetTrendingComix(days int, tagName string, halfLifeDays float64, pageToDisplay int) ([]postgres.TrendingComix, error) {
+	m.calls++
+	m.days = days
+	m.tagName = tagName
+	m.halfLifeDays = halfLifeDays
+	return []postgres.TrendingComix{{ComixFromAllComix: postgres.ComixFromAllComix{ComixName: "comix"}, Score: 10}}, nil
+}
+
+func doRequest(t *testing.T, handler http.HandlerFunc, url string) ResponseMock {
+	req, err := http.NewRequest("GET", url, nil)
+	assert.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	var responseBody ResponseMock
+
+	if err := json.Unmarshal(rr.Body.Bytes(), &responseBody); err != nil {
+		t.Fatalf("Ошибка при распоковке JSON: %s", err)
+	}
+
+	return responseBody
+}
+
+func TestGetTrendingComix_Success(t *testing.T) {
+	mockLogger := slogdiscard.NewDiscardLogger()
+	getter := &MockTrendingGetter{}
+
+	handler := get_trending_comix.New(mockLogger, getter, cache.NewMemory(), time.Minute, 72*time.Hour)
+
+	responseBody := doRequest(t, handler, "/api/trending?window=30d&tag=Horror&decay=true")
+
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+	assert.Equal(t, "30d", responseBody.Window)
+	assert.Equal(t, 30, getter.days)
+	assert.Equal(t, "horror", getter.tagName)
+	assert.Equal(t, float64(3), getter.halfLifeDays)
+}
+
+func TestGetTrendingComix_DefaultWindow(t *testing.T) {
+	mockLogger := slogdiscard.NewDiscardLogger()
+	getter := &MockTrendingGetter{}
+
+	handler := get_trending_comix.New(mockLogger, getter, cache.NewMemory(), time.Minute, 72*time.Hour)
+
+	responseBody := doRequest(t, handler, "/api/trending")
+
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+	assert.Equal(t, "7d", responseBody.Window)
+	assert.Equal(t, float64(0), getter.halfLifeDays)
+}
+
+func TestGetTrendingComix_Cached(t *testing.T) {
+	mockLogger := slogdiscard.NewDiscardLogger()
+	getter := &MockTrendingGetter{}
+
+	handler := get_trending_comix.New(mockLogger, getter, cache.NewMemory(), time.Minute, 72*time.Hour)
+
+	doRequest(t, handler, "/api/trending?window=24h")
+	doRequest(t, handler, "/api/trending?window=24h")
+	doRequest(t, handler, "/api/trending?window=all")
+
+	assert.Equal(t, 2, getter.calls)
+}
+
+func TestGetTrendingComix_InvalidParams(t *testing.T) {
+	mockLogger := slogdiscard.NewDiscardLogger()
+
+	urls := []string{
+		"/api/trending?window=1y",
+		"/api/trending?pageNumber=0",
+		"/api/trending?pageNumber=abc",
+	}
+
+	for _, url := range urls {
+		handler := get_trending_comix.New(mockLogger, &MockTrendingGetter{}, cache.NewMemory(), time.Minute, 72*time.Hour)
+
+		responseBody := doRequest(t, handler, url)
+
+		assert.Equal(t, http.StatusBadRequest, responseBody.Status)
+	}
+}
@@ -0,0 +1,50 @@
+package get_upload
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/lib/upload"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type UploadGetter interface {
+	Get(id string) (upload.Info, error)
+}
+
+// New отвечает на HEAD по протоколу tus: сколько байт загрузки уже получено. С этого offset клиент продолжает после обрыва.
+func New(log *slog.Logger, uploadGetter UploadGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.get_upload.New"
+
+		id := chi.URLParam(r, "id")
+
+		w.Header().Set(upload.HeaderResumable, upload.TusVersion)
+		w.Header().Set("Cache-Control", "no-store")
+
+		info, err := uploadGetter.Get(id)
+		if errors.Is(err, upload.ErrNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+		if err != nil {
+			log.Error("failed get upload", sl.Err(err),
+				slog.String("op", op),
+				slog.String("request_id", middleware.GetReqID(r.Context())),
+				slog.String("upload", id),
+			)
+
+			w.WriteHeader(http.StatusInternalServerError)
+
+			return
+		}
+
+		w.Header().Set(upload.HeaderOffset, strconv.FormatInt(info.Offset, 10))
+		w.Header().Set(upload.HeaderLength, strconv.FormatInt(info.Size, 10))
+		w.WriteHeader(http.StatusOK)
+	}
+}
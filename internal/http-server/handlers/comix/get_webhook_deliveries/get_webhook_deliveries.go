@@ -0,0 +1,110 @@
+package get_webhook_deliveries
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator"
+	"jadesheart/comix_back/internal/http-server/middleware/ratelimit"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+)
+
+type Request struct {
+	Password   string `json:"password" validate:"required"`
+	WebhookID  int    `json:"webhookId" validate:"required,min=1"`
+	PageNumber int    `json:"pageNumber" validate:"required,min=1"`
+}
+
+type Response struct {
+	Status     int                        `json:"status,omitempty"`
+	Error      string                     `json:"error,omitempty"`
+	Deliveries []postgres.WebhookDelivery `json:"deliveries"`
+}
+
+type DeliveriesGetter interface {
+	GetWebhookDeliveries(webhookID int, pageToDisplay int) ([]postgres.WebhookDelivery, error)
+	CheckPass(inputPass string) (bool, error)
+}
+
+// New отдаёт журнал доставок вебхука: состояние, число попыток и итог последней попытки.
+func New(log *slog.Logger, deliveriesGetter DeliveriesGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.get_webhook_deliveries.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("failed decode request json", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed decode request json"))
+
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Error("failed validate", sl.Err(err))
+
+			render.JSON(w, r, resp.ValidateErrors(validateErr))
+
+			return
+		}
+
+		res, err := deliveriesGetter.CheckPass(req.Password)
+		if err != nil {
+			log.Error("failed password verified", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed password verified"))
+
+			return
+		}
+
+		if !res {
+			log.Warn("incorrect password", slog.String("remote_addr", ratelimit.ClientIP(r)))
+
+			ratelimit.AuthFailed(r.Context())
+
+			render.JSON(w, r, resp.Error("incorrect password"))
+
+			return
+		}
+
+		ratelimit.AuthSucceeded(r.Context())
+
+		deliveries, err := deliveriesGetter.GetWebhookDeliveries(req.WebhookID, req.PageNumber)
+		if errors.Is(err, storage.ErrWebhookNotFound) {
+			render.JSON(w, r, resp.Error("Webhook not exists"))
+
+			return
+		}
+		if err != nil {
+			log.Error("Cannot get webhook deliveries from bd", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("Cannot get webhook deliveries from bd"))
+
+			return
+		}
+
+		responseOK(w, r, deliveries)
+
+	}
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request, deliveries []postgres.WebhookDelivery) {
+	render.JSON(w, r, Response{
+		Status:     resp.StatusOK,
+		Deliveries: deliveries,
+	})
+}
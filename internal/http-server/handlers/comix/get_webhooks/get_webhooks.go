@@ -0,0 +1,101 @@
+package get_webhooks
+
+import (
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator"
+	"jadesheart/comix_back/internal/http-server/middleware/ratelimit"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+)
+
+type Request struct {
+	Password string `json:"password" validate:"required"`
+}
+
+type Response struct {
+	Status   int                `json:"status,omitempty"`
+	Error    string             `json:"error,omitempty"`
+	Webhooks []postgres.Webhook `json:"webhooks"`
+}
+
+type WebhooksGetter interface {
+	GetWebhooks() ([]postgres.Webhook, error)
+	CheckPass(inputPass string) (bool, error)
+}
+
+// New отдаёт зарегистрированные вебхуки без секретов.
+func New(log *slog.Logger, webhooksGetter WebhooksGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.get_webhooks.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("failed decode request json", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed decode request json"))
+
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Error("failed validate", sl.Err(err))
+
+			render.JSON(w, r, resp.ValidateErrors(validateErr))
+
+			return
+		}
+
+		res, err := webhooksGetter.CheckPass(req.Password)
+		if err != nil {
+			log.Error("failed password verified", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed password verified"))
+
+			return
+		}
+
+		if !res {
+			log.Warn("incorrect password", slog.String("remote_addr", ratelimit.ClientIP(r)))
+
+			ratelimit.AuthFailed(r.Context())
+
+			render.JSON(w, r, resp.Error("incorrect password"))
+
+			return
+		}
+
+		ratelimit.AuthSucceeded(r.Context())
+
+		webhooks, err := webhooksGetter.GetWebhooks()
+		if err != nil {
+			log.Error("Cannot get webhooks from bd", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("Cannot get webhooks from bd"))
+
+			return
+		}
+
+		responseOK(w, r, webhooks)
+
+	}
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request, webhooks []postgres.Webhook) {
+	render.JSON(w, r, Response{
+		Status:   resp.StatusOK,
+		Webhooks: webhooks,
+	})
+}
@@ -0,0 +1,419 @@
+package import_comix
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"io"
+	"jadesheart/comix_back/internal/http-server/middleware/ratelimit"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/api/schedule"
+	"jadesheart/comix_back/internal/lib/archive"
+	"jadesheart/comix_back/internal/lib/audit"
+	"jadesheart/comix_back/internal/lib/cache"
+	"jadesheart/comix_back/internal/lib/images"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/lib/photos"
+	"jadesheart/comix_back/internal/lib/upload"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// maxFormMemory - сколько multipart-формы держится в памяти, остальное архива уходит во временный файл
+const maxFormMemory = 8 << 20
+
+// Request - multipart-форма. Архив передаётся файлом archive или id завершённой загрузки upload,
+// если он слишком велик для одного запроса. Name и Description берутся из ComicInfo.xml, если не переданы.
+// PublishAt - время выхода в RFC 3339, не передано - комикс выходит сразу.
+type Request struct {
+	Password    string `form:"password"`
+	TagName     string `form:"tag"`
+	Name        string `form:"name"`
+	Description string `form:"description"`
+	Upload      string `form:"upload"`
+	PublishAt   string `form:"publishAt"`
+}
+
+type Response struct {
+	Status int             `json:"status,omitempty"`
+	Error  string          `json:"error,omitempty"`
+	Slug   string          `json:"slug,omitempty"`
+	Pages  []postgres.Page `json:"pages,omitempty"`
+	// Pending - комикс ждёт одобрения модератора и пока не виден читателям
+	Pending bool `json:"pending,omitempty"`
+	// PublishAt - когда комикс выйдет, если выход запланирован
+	PublishAt *time.Time `json:"publishAt,omitempty"`
+}
+
+type ComixImporter interface {
+	ImportComix(comix postgres.NewComix) (string, []postgres.Page, error)
+	CheckPass(inputPass string) (bool, error)
+	AddAuditEvent(event postgres.AuditEvent) error
+}
+
+type UploadFinisher interface {
+	Finish(id string) (string, upload.Info, error)
+	Remove(id string) error
+}
+
+type CacheInvalidator interface {
+	Invalidate(groups ...string)
+}
+
+// source - открытый архив и то, чем его закрыть
+type source struct {
+	file interface {
+		io.ReaderAt
+		io.Closer
+	}
+	size int64
+	name string
+}
+
+// New создаёт комикс из zip/cbz архива: страницы распаковываются в естественном порядке имён,
+// сведения о комиксе берутся из ComicInfo.xml. maxSize - наибольший размер архива в одном запросе.
+// Страницы проверяются и кодируются заново с ограничением imageLimits.MaxPixels, как загруженные по одной.
+// При включённой модерации комикс вместе со страницами ждёт одобрения.
+func New(log *slog.Logger, comixImporter ComixImporter, uploadFinisher UploadFinisher, cacheInvalidator CacheInvalidator,
+	mediaRoot *photos.Root, limits archive.Limits, imageLimits images.Limits, maxSize int64, moderation bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.import_comix.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxSize+maxFormMemory)
+
+		err := r.ParseMultipartForm(maxFormMemory)
+		if err != nil {
+			log.Error("failed parse multipart form", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed parse multipart form, archive may be too large"))
+
+			return
+		}
+
+		req := Request{
+			Password:    r.FormValue("password"),
+			TagName:     strings.TrimSpace(r.FormValue("tag")),
+			Name:        strings.TrimSpace(r.FormValue("name")),
+			Description: strings.TrimSpace(r.FormValue("description")),
+			Upload:      r.FormValue("upload"),
+			PublishAt:   r.FormValue(schedule.Param),
+		}
+
+		if req.Password == "" || req.TagName == "" {
+			render.JSON(w, r, resp.Error("field password and tag is a required field"))
+
+			return
+		}
+
+		publishAt, ok := schedule.Parse(req.PublishAt, time.Now())
+		if !ok {
+			render.JSON(w, r, resp.Error("publishAt must be a RFC 3339 time"))
+
+			return
+		}
+
+		res, err := comixImporter.CheckPass(req.Password)
+		if err != nil {
+			log.Error("failed password verified", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed password verified"))
+
+			return
+		}
+
+		if !res {
+			log.Warn("incorrect password", slog.String("remote_addr", ratelimit.ClientIP(r)))
+
+			ratelimit.AuthFailed(r.Context())
+
+			render.JSON(w, r, resp.Error("incorrect password"))
+
+			return
+		}
+
+		ratelimit.AuthSucceeded(r.Context())
+
+		src, msg := openSource(r, req, uploadFinisher)
+		if msg != "" {
+			render.JSON(w, r, resp.Error(msg))
+
+			return
+		}
+		defer src.file.Close()
+
+		comic, err := archive.Read(src.file, src.size, limits)
+		if err != nil {
+			log.Warn("failed read archive", sl.Err(err), slog.String("archive", src.name))
+
+			render.JSON(w, r, resp.Error(archiveError(err)))
+
+			return
+		}
+
+		comix := postgres.NewComix{
+			TagName:     req.TagName,
+			Name:        req.Name,
+			Description: req.Description,
+			UploadDate:  schedule.UploadDate(publishAt),
+			Pending:     moderation,
+			PublishAt:   publishAt,
+		}
+
+		if info := comic.Info; info != nil {
+			if comix.Name == "" {
+				comix.Name = info.Name()
+			}
+			if comix.Description == "" {
+				comix.Description = strings.TrimSpace(info.Summary)
+			}
+			if authors := info.Authors(); len(authors) > 0 {
+				comix.Meta.Authors = &authors
+			}
+			if language := info.Language(); language != "" {
+				comix.Meta.Language = &language
+			}
+			if rating := info.Rating(); rating != "" {
+				comix.Meta.AgeRating = &rating
+			}
+		}
+
+		if msg := validateName(comix.Name); msg != "" {
+			render.JSON(w, r, resp.Error(msg))
+
+			return
+		}
+
+		tagDir, err := mediaRoot.TagDir(req.TagName)
+		if err != nil {
+			log.Warn("unsafe tag name", sl.Err(err), slog.String("remote_addr", ratelimit.ClientIP(r)))
+
+			render.JSON(w, r, resp.Error("invalid tag name"))
+
+			return
+		}
+
+		_, err = os.Stat(tagDir)
+		if err != nil {
+			log.Error("failed get tag directory", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("Tag not exists"))
+
+			return
+		}
+
+		comixDir, err := mediaRoot.Join(tagDir, comix.Name)
+		if err != nil {
+			log.Warn("unsafe comix name", sl.Err(err), slog.String("remote_addr", ratelimit.ClientIP(r)))
+
+			render.JSON(w, r, resp.Error("invalid comix name"))
+
+			return
+		}
+
+		err = os.Mkdir(comixDir, 0755)
+		if os.IsExist(err) {
+			render.JSON(w, r, resp.Error("Comix already exists"))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed create comix directory", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed create comix directory"))
+
+			return
+		}
+
+		pageLimits := images.Limits{MaxFileSize: limits.MaxPageSize, MaxPixels: imageLimits.MaxPixels}
+
+		for _, page := range comic.Pages {
+			name, err := extractPage(comic, page, comixDir, pageLimits)
+			if err != nil {
+				os.RemoveAll(comixDir)
+
+				if errors.Is(err, archive.ErrTooLarge) {
+					render.JSON(w, r, resp.Error(archiveError(err)))
+
+					return
+				}
+				if errors.Is(err, images.ErrNotImage) || errors.Is(err, images.ErrTooLarge) || errors.Is(err, images.ErrTooManyPixels) {
+					render.JSON(w, r, resp.Error(page.Name+": "+err.Error()))
+
+					return
+				}
+
+				log.Error("failed extract page", sl.Err(err), slog.String("page", page.Name))
+
+				render.JSON(w, r, resp.Error("failed extract page "+page.Name))
+
+				return
+			}
+
+			comix.Pages = append(comix.Pages, name)
+		}
+
+		comixSlug, pages, err := comixImporter.ImportComix(comix)
+		if err != nil {
+			os.RemoveAll(comixDir)
+
+			switch {
+			case errors.Is(err, storage.ErrTagNotFound):
+				render.JSON(w, r, resp.Error("Tag not exists"))
+			case errors.Is(err, storage.ErrComixExists):
+				render.JSON(w, r, resp.Error("Comix already exists, check the trash"))
+			default:
+				log.Error("failed import comix", sl.Err(err))
+
+				render.JSON(w, r, resp.Error("failed import comix"))
+			}
+
+			return
+		}
+
+		if req.Upload != "" {
+			err = uploadFinisher.Remove(req.Upload)
+			if err != nil {
+				log.Error("failed remove imported upload", sl.Err(err), slog.String("upload", req.Upload))
+			}
+		}
+
+		if !moderation && publishAt.IsZero() {
+			cacheInvalidator.Invalidate(cache.KeyMainPage, cache.KeySearch, cache.KeyAllTags, cache.KeyTagComix(req.TagName))
+		}
+
+		after := map[string]interface{}{
+			"tagName":     req.TagName,
+			"name":        comix.Name,
+			"description": comix.Description,
+			"uploadDate":  comix.UploadDate,
+			"archive":     src.name,
+			"pages":       len(pages),
+			"pending":     moderation,
+		}
+		if !publishAt.IsZero() {
+			after["publishAt"] = publishAt
+		}
+
+		err = comixImporter.AddAuditEvent(audit.NewEvent(r, audit.ActionComixImport, audit.ComixTarget(req.TagName, comix.Name), nil, after))
+		if err != nil {
+			log.Error("failed write audit event", sl.Err(err))
+		}
+
+		responseOK(w, r, comixSlug, pages, moderation, publishAt)
+
+	}
+}
+
+// openSource открывает архив из формы или из завершённой загрузки. Вторым значением - текст ошибки для клиента.
+func openSource(r *http.Request, req Request, uploadFinisher UploadFinisher) (source, string) {
+	if req.Upload != "" {
+		path, info, err := uploadFinisher.Finish(req.Upload)
+		switch {
+		case errors.Is(err, upload.ErrNotFound):
+			return source{}, "upload " + req.Upload + ": not found"
+		case errors.Is(err, upload.ErrIncomplete):
+			return source{}, "upload " + req.Upload + ": not all bytes are uploaded"
+		case errors.Is(err, upload.ErrChecksumMismatch):
+			return source{}, "upload " + req.Upload + ": checksum mismatch, upload the file again"
+		case errors.Is(err, upload.ErrBusy):
+			return source{}, "upload " + req.Upload + ": still being written"
+		case err != nil:
+			return source{}, "upload " + req.Upload + ": failed finish upload"
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return source{}, "upload " + req.Upload + ": failed open"
+		}
+
+		return source{file: f, size: info.Size, name: info.FileName}, ""
+	}
+
+	f, header, err := r.FormFile("archive")
+	if err != nil {
+		return source{}, "field archive or upload is a required field"
+	}
+
+	return source{file: f, size: header.Size, name: header.Filename}, ""
+}
+
+// validateName - название комикса становится именем папки, поэтому в нём не должно быть разделителей пути
+func validateName(name string) string {
+	switch {
+	case name == "":
+		return "field name is a required field, the archive has no ComicInfo.xml with a title"
+	case name == "." || name == ".." || strings.ContainsAny(name, `/\'`):
+		return "comix name must not contain / \\ ' and must not be . or .."
+	}
+
+	return ""
+}
+
+// extractPage распаковывает страницу, проверяет её и сохраняет в папку комикса под новым именем
+func extractPage(comic *archive.Archive, page *zip.File, comixDir string, limits images.Limits) (string, error) {
+	src, err := comic.Open(page)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	img, err := images.Sanitize(src, limits)
+	if err != nil {
+		return "", err
+	}
+
+	name, err := photos.NewPageName(img.Ext)
+	if err != nil {
+		return "", err
+	}
+
+	err = photos.WriteFile(comixDir, name, bytes.NewReader(img.Data))
+	if err != nil {
+		return "", err
+	}
+
+	return name, nil
+}
+
+// archiveError - текст ошибки чтения архива для клиента
+func archiveError(err error) string {
+	switch {
+	case errors.Is(err, archive.ErrUnsafePath):
+		return "archive contains unsafe paths"
+	case errors.Is(err, archive.ErrTooLarge):
+		return "archive unpacks to too much data"
+	case errors.Is(err, archive.ErrTooMany):
+		return "archive has too many pages"
+	case errors.Is(err, archive.ErrNoPages):
+		return "archive has no images"
+	}
+
+	return "failed read archive, is it a zip or cbz file?"
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request, comixSlug string, pages []postgres.Page, pending bool, publishAt time.Time) {
+	var scheduled *time.Time
+	if !publishAt.IsZero() {
+		scheduled = &publishAt
+	}
+
+	render.JSON(w, r, Response{
+		Status:    resp.StatusOK,
+		Slug:      comixSlug,
+		Pages:     pages,
+		Pending:   pending,
+		PublishAt: scheduled,
+	})
+}
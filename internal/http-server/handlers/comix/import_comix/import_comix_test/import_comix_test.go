@@ -0,0 +1,231 @@
+package import_comix_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"image"
+	"image/png"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/import_comix"
+	"jadesheart/comix_back/internal/lib/archive"
+	"jadesheart/comix_back/internal/lib/images"
+	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"jadesheart/comix_back/internal/lib/photos"
+	"jadesheart/comix_back/internal/lib/upload"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type ResponseMock struct {
+	Status int             `json:"status,omitempty"`
+	Error  string          `json:"error,omitempty"`
+	Slug   string          `json:"slug,omitempty"`
+	Pages  []postgres.Page `json:"pages,omitempty"`
+}
+
+type MockComixImporter struct {
+	comix postgres.NewComix
+	err   error
+}
+
+func (m *MockComixImporter) ImportComix(comix postgres.NewComix) (string, []postgres.Page, error) {
+	if m.err != nil {
+		return "", nil, m.err
+	}
+	m.comix = comix
+
+	pages := make([]postgres.Page, 0, len(comix.Pages))
+	for i, file := range comix.Pages {
+		pages = append(pages, postgres.Page{ID: i + 1, Position: i + 1, File: file})
+	}
+	return "watchmen", pages, nil
+}
+
+func (m *MockComixImporter) CheckPass(inputPass string) (bool, error) {
+	return inputPass == "password", nil
+}
+
+func (m *MockComixImporter) AddAuditEvent(event postgres.AuditEvent) error {
+	return nil
+}
+
+type MockUploadFinisher struct{}
+
+func (m *MockUploadFinisher) Finish(id string) (string, upload.Info, error) {
+	return "", upload.Info{}, upload.ErrNotFound
+}
+
+func (m *MockUploadFinisher) Remove(id string) error {
+	return nil
+}
+
+type MockCacheInvalidator struct{}
+
+func (m *MockCacheInvalidator) Invalidate(groups ...string) {}
+
+const tagDir = "internal/storage/web/photos/Horror"
+
+// setup переходит во временную папку с тэгом Horror
+func setup(t *testing.T) {
+	wd, err := os.Getwd()
+	assert.NoError(t, err)
+	assert.NoError(t, os.Chdir(t.TempDir()))
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	assert.NoError(t, os.MkdirAll(tagDir, 0755))
+}
+
+// pngPage - картинка шириной width, по ширине потом видно, какая это страница
+func pngPage(width int) string {
+	buf := &bytes.Buffer{}
+	png.Encode(buf, image.NewGray(image.Rect(0, 0, width, 10)))
+
+	return buf.String()
+}
+
+func makeArchive(t *testing.T, files map[string]string) []byte {
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+
+	for name, data := range files {
+		w, err := zw.Create(name)
+		assert.NoError(t, err)
+		w.Write([]byte(data))
+	}
+	assert.NoError(t, zw.Close())
+
+	return buf.Bytes()
+}
+
+func doRequest(t *testing.T, importer *MockComixImporter, fields map[string]string, archiveData []byte) ResponseMock {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for key, value := range fields {
+		_ = writer.WriteField(key, value)
+	}
+	if archiveData != nil {
+		part, _ := writer.CreateFormFile("archive", "watchmen.cbz")
+		part.Write(archiveData)
+	}
+	writer.Close()
+
+	req, err := http.NewRequest("POST", "/importcomix", body)
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	limits := archive.Limits{MaxPages: 100, MaxPageSize: 1 << 20, MaxTotalSize: 4 << 20}
+	imageLimits := images.Limits{MaxFileSize: 1 << 20, MaxRequestSize: 4 << 20, MaxPixels: 1 << 20}
+
+	mediaRoot, err := photos.NewRoot("internal/storage/web/photos")
+	assert.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	import_comix.New(slogdiscard.NewDiscardLogger(), importer, &MockUploadFinisher{}, &MockCacheInvalidator{}, mediaRoot, limits, imageLimits, 8<<20, false).ServeHTTP(rr, req)
+
+	var responseBody ResponseMock
+
+	if err := json.Unmarshal(rr.Body.Bytes(), &responseBody); err != nil {
+		t.Fatalf("Ошибка при распоковке JSON: %s", err)
+	}
+
+	return responseBody
+}
+
+func TestImportComix_Success(t *testing.T) {
+	setup(t)
+
+	importer := &MockComixImporter{}
+
+	responseBody := doRequest(t, importer, map[string]string{"password": "password", "tag": "horror"}, makeArchive(t, map[string]string{
+		"Watchmen/10.jpg":     pngPage(10),
+		"Watchmen/2.jpg":      pngPage(2),
+		"Watchmen/1.jpg":      pngPage(1),
+		"Watchmen/readme.txt": "not a page",
+		"ComicInfo.xml": `<ComicInfo><Series>Watchmen</Series><Summary>Heroes</Summary>
+			<Writer>Alan Moore</Writer><LanguageISO>en</LanguageISO><AgeRating>Teen</AgeRating></ComicInfo>`,
+	}))
+
+	assert.Equal(t, http.StatusOK, responseBody.Status, responseBody.Error)
+	assert.Equal(t, "watchmen", responseBody.Slug)
+	assert.Len(t, responseBody.Pages, 3)
+
+	assert.Equal(t, "Watchmen", importer.comix.Name)
+	assert.Equal(t, "Heroes", importer.comix.Description)
+	assert.Equal(t, []string{"Alan Moore"}, *importer.comix.Meta.Authors)
+	assert.Equal(t, "en", *importer.comix.Meta.Language)
+	assert.Equal(t, "16+", *importer.comix.Meta.AgeRating)
+
+	// Страницы распакованы в естественном порядке
+	comixDir := filepath.Join(tagDir, "Watchmen")
+	for i, want := range []int{1, 2, 10} {
+		f, err := os.Open(filepath.Join(comixDir, importer.comix.Pages[i]))
+		assert.NoError(t, err)
+		config, err := png.DecodeConfig(f)
+		f.Close()
+		assert.NoError(t, err)
+		assert.Equal(t, want, config.Width)
+	}
+}
+
+func TestImportComix_SkipsWebpPages(t *testing.T) {
+	setup(t)
+
+	importer := &MockComixImporter{}
+
+	responseBody := doRequest(t, importer, map[string]string{"password": "password", "tag": "horror", "name": "Watchmen"},
+		makeArchive(t, map[string]string{
+			"1.png":  pngPage(1),
+			"2.webp": "RIFF\x1a\x00\x00\x00WEBPVP8 ",
+		}))
+
+	assert.Equal(t, http.StatusOK, responseBody.Status, responseBody.Error)
+	assert.Len(t, importer.comix.Pages, 1)
+}
+
+func TestImportComix_RollsBack(t *testing.T) {
+	setup(t)
+
+	importer := &MockComixImporter{err: storage.ErrComixExists}
+
+	responseBody := doRequest(t, importer, map[string]string{"password": "password", "tag": "horror", "name": "Watchmen"},
+		makeArchive(t, map[string]string{"1.jpg": pngPage(1)}))
+	assert.Equal(t, http.StatusBadRequest, responseBody.Status)
+
+	_, err := os.Stat(filepath.Join(tagDir, "Watchmen"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestImportComix_InvalidRequest(t *testing.T) {
+	setup(t)
+
+	cases := []struct {
+		fields  map[string]string
+		archive []byte
+	}{
+		{map[string]string{"password": "wrong_password", "tag": "horror", "name": "Watchmen"}, makeArchive(t, map[string]string{"1.jpg": pngPage(1)})},
+		{map[string]string{"password": "password", "tag": "horror", "name": "Watchmen"}, nil},
+		{map[string]string{"password": "password", "tag": "horror", "name": "Watchmen"}, []byte("not a zip")},
+		{map[string]string{"password": "password", "tag": "horror", "name": "Watchmen"}, makeArchive(t, map[string]string{"../../1.jpg": pngPage(1)})},
+		{map[string]string{"password": "password", "tag": "horror"}, makeArchive(t, map[string]string{"1.jpg": pngPage(1)})},
+		{map[string]string{"password": "password", "tag": "horror", "name": "../Watchmen"}, makeArchive(t, map[string]string{"1.jpg": pngPage(1)})},
+		{map[string]string{"password": "password", "tag": "horror", "name": ".."}, makeArchive(t, map[string]string{"1.jpg": pngPage(1)})},
+		{map[string]string{"password": "password", "tag": "horror", "upload": "0123456789abcdef0123456789abcdef"}, nil},
+		{map[string]string{"password": "password", "tag": "horror", "name": "Watchmen"}, makeArchive(t, map[string]string{"1.jpg": "<html></html>"})},
+	}
+
+	for _, c := range cases {
+		responseBody := doRequest(t, &MockComixImporter{}, c.fields, c.archive)
+		assert.Equal(t, http.StatusBadRequest, responseBody.Status, c.fields)
+	}
+
+	entries, err := os.ReadDir(tagDir)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
@@ -5,8 +5,14 @@ import (
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
 	"github.com/go-playground/validator"
+	"jadesheart/comix_back/internal/http-server/middleware/ratelimit"
 	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/api/schedule"
+	"jadesheart/comix_back/internal/lib/audit"
+	"jadesheart/comix_back/internal/lib/cache"
 	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/lib/photos"
+	"jadesheart/comix_back/internal/storage/postgres"
 	"log/slog"
 	"net/http"
 	"os"
@@ -24,17 +30,29 @@ type Request struct {
 type Response struct {
 	Status int    `json:"status,omitempty"`
 	Error  string `json:"error,omitempty"`
+	// Pending - комикс ждёт одобрения модератора и пока не виден читателям
+	Pending bool `json:"pending,omitempty"`
+	// PublishAt - когда комикс выйдет, если выход запланирован
+	PublishAt *time.Time `json:"publishAt,omitempty"`
 }
 
 type ComixAdder interface {
 	AddComixByTagName(tagName string, name string, description string, currentDate string) error
-	AddComixToAllComixTable(tagName string, name string, description string, currentDate string) error
+	AddComixToAllComixTable(tagName string, name string, description string, currentDate string, pending bool, publishAt time.Time) error
 	CheckComixExists(tagName string, name string) (bool, error)
+	ComixInTrash(tagName string, name string) (bool, error)
 	TagExist(tagName string) (bool, error)
 	CheckPass(inputPass string) (bool, error)
+	AddAuditEvent(event postgres.AuditEvent) error
 }
 
-func New(log *slog.Logger, comixAdder ComixAdder) http.HandlerFunc {
+type CacheInvalidator interface {
+	Invalidate(groups ...string)
+}
+
+// New создаёт комикс и его папку. При включённой модерации комикс не виден читателям, пока его не одобрят.
+// Время выхода передаётся параметром запроса publishAt, без него комикс выходит сразу.
+func New(log *slog.Logger, comixAdder ComixAdder, cacheInvalidator CacheInvalidator, mediaRoot *photos.Root, moderation bool) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 
 		const op = "handlers.comix.insert.New"
@@ -75,6 +93,22 @@ func New(log *slog.Logger, comixAdder ComixAdder) http.HandlerFunc {
 			return
 		}
 
+		publishAt, ok := schedule.FromRequest(r)
+		if !ok {
+			render.JSON(w, r, resp.Error("publishAt must be a RFC 3339 time"))
+
+			return
+		}
+
+		comixDir, err := mediaRoot.ComixDir(req.TagName, req.Name)
+		if err != nil {
+			log.Warn("unsafe comix name", sl.Err(err), slog.String("remote_addr", ratelimit.ClientIP(r)))
+
+			render.JSON(w, r, resp.Error("invalid comix name"))
+
+			return
+		}
+
 		res, err := comixAdder.CheckPass(req.Password)
 		if err != nil {
 			log.Error("failed password verified", sl.Err(err))
@@ -85,13 +119,17 @@ func New(log *slog.Logger, comixAdder ComixAdder) http.HandlerFunc {
 		}
 
 		if !res {
-			log.Info("INCORRECT PASSWORD", slog.Any("Trying input password: ", req.Password))
+			log.Warn("incorrect password", slog.String("remote_addr", ratelimit.ClientIP(r)))
+
+			ratelimit.AuthFailed(r.Context())
 
 			render.JSON(w, r, resp.Error("incorrect password"))
 
 			return
 		}
 
+		ratelimit.AuthSucceeded(r.Context())
+
 		result, err := comixAdder.TagExist(req.TagName)
 		if err != nil {
 			log.Error("failed get table by tag", sl.Err(err))
@@ -125,7 +163,24 @@ func New(log *slog.Logger, comixAdder ComixAdder) http.HandlerFunc {
 
 			return
 		}
-		currentDate := time.Now().Format("2006-01-02")
+
+		result, err = comixAdder.ComixInTrash(req.TagName, req.Name)
+		if err != nil {
+			log.Error("Cannot check comix in trash", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("Cannot check comix in trash"))
+
+			return
+		}
+
+		if result {
+			log.Info("Comix is in trash", slog.String("name", req.Name))
+
+			render.JSON(w, r, resp.Error("Comix with this name is in trash, restore it or wait for purge"))
+
+			return
+		}
+		currentDate := schedule.UploadDate(publishAt)
 		err = comixAdder.AddComixByTagName(req.TagName, req.Name, req.Description, currentDate)
 		if err != nil {
 			log.Error("can not added comix", sl.Err(err))
@@ -135,7 +190,7 @@ func New(log *slog.Logger, comixAdder ComixAdder) http.HandlerFunc {
 			return
 		}
 
-		err = comixAdder.AddComixToAllComixTable(req.TagName, req.Name, req.Description, currentDate)
+		err = comixAdder.AddComixToAllComixTable(req.TagName, req.Name, req.Description, currentDate, moderation, publishAt)
 		if err != nil {
 			log.Error("can not added comix", sl.Err(err))
 
@@ -144,8 +199,7 @@ func New(log *slog.Logger, comixAdder ComixAdder) http.HandlerFunc {
 			return
 		}
 
-		path := fmt.Sprintf("internal/storage/web/photos/%s/%s", req.TagName, req.Name)
-		err = os.Mkdir(path, 0755)
+		err = os.Mkdir(comixDir, 0755)
 		if err != nil {
 			log.Error("failed create tag-name folder,check if you created the tag?", sl.Err(err))
 
@@ -154,13 +208,42 @@ func New(log *slog.Logger, comixAdder ComixAdder) http.HandlerFunc {
 			return
 		}
 
-		responseOK(w, r)
+		if !moderation && publishAt.IsZero() {
+			cacheInvalidator.Invalidate(cache.KeyMainPage, cache.KeySearch, cache.KeyAllTags, cache.KeyTagComix(req.TagName))
+		}
+
+		after := map[string]interface{}{
+			"tagName":     req.TagName,
+			"name":        req.Name,
+			"description": req.Description,
+			"uploadDate":  currentDate,
+		}
+		if !publishAt.IsZero() {
+			after["publishAt"] = publishAt.Format(time.RFC3339)
+		}
+		if moderation {
+			after["pending"] = true
+		}
+
+		err = comixAdder.AddAuditEvent(audit.NewEvent(r, audit.ActionComixCreate, audit.ComixTarget(req.TagName, req.Name), nil, after))
+		if err != nil {
+			log.Error("failed write audit event", sl.Err(err))
+		}
+
+		responseOK(w, r, moderation, publishAt)
 
 	}
 }
 
-func responseOK(w http.ResponseWriter, r *http.Request) {
+func responseOK(w http.ResponseWriter, r *http.Request, pending bool, publishAt time.Time) {
+	var scheduled *time.Time
+	if !publishAt.IsZero() {
+		scheduled = &publishAt
+	}
+
 	render.JSON(w, r, Response{
-		Status: 200,
+		Status:    200,
+		Pending:   pending,
+		PublishAt: scheduled,
 	})
 }
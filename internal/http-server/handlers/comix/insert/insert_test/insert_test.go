@@ -5,18 +5,24 @@ import (
 	"encoding/json"
 	"github.com/stretchr/testify/assert"
 	"jadesheart/comix_back/internal/http-server/handlers/comix/insert"
+	"jadesheart/comix_back/internal/lib/cache"
 	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
 	"jadesheart/comix_back/internal/lib/logger/handlers/slogpretty"
+	"jadesheart/comix_back/internal/lib/photos/photostest"
+	"jadesheart/comix_back/internal/storage/postgres"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 )
 
 type ResponseMock struct {
-	Status int    `json:"status,omitempty"`
-	Error  string `json:"error,omitempty"`
+	Status  int    `json:"status,omitempty"`
+	Error   string `json:"error,omitempty"`
+	Pending bool   `json:"pending,omitempty"`
 }
 
 type ComixAdderMock struct{}
@@ -24,7 +30,7 @@ type ComixAdderMock struct{}
 func (c *ComixAdderMock) AddComixByTagName(tagName string, name string, description string, currentDate string) error {
 	return nil
 }
-func (c *ComixAdderMock) AddComixToAllComixTable(tagName string, name string, description string, currentDate string) error {
+func (c *ComixAdderMock) AddComixToAllComixTable(tagName string, name string, description string, currentDate string, pending bool, publishAt time.Time) error {
 	return nil
 }
 func (c *ComixAdderMock) CheckComixExists(tagName string, name string) (bool, error) {
@@ -35,6 +41,9 @@ func (c *ComixAdderMock) CheckComixExists(tagName string, name string) (bool, er
 	}
 	return false, nil
 }
+func (c *ComixAdderMock) ComixInTrash(tagName string, name string) (bool, error) {
+	return name == "comixInTrash", nil
+}
 func (c *ComixAdderMock) TagExist(tagName string) (bool, error) {
 	if tagName == "tagExist" {
 		return true, nil
@@ -50,10 +59,17 @@ func (c *ComixAdderMock) CheckPass(inputPass string) (bool, error) {
 	return false, nil
 }
 
+func (c *ComixAdderMock) AddAuditEvent(event postgres.AuditEvent) error {
+	return nil
+}
+
 func TestGetTagDescription_Success(t *testing.T) {
 	mockLogger := setupLogger("local")
 
-	handler := insert.New(mockLogger, &ComixAdderMock{})
+	mediaRoot := photostest.NewRoot(t)
+	assert.NoError(t, os.Mkdir(filepath.Join(mediaRoot.Dir(), "tagExist"), 0755))
+
+	handler := insert.New(mockLogger, &ComixAdderMock{}, cache.New(cache.NewMemory(), time.Minute), mediaRoot, false)
 
 	requestBody := map[string]interface{}{
 		"password":    "password",
@@ -78,6 +94,66 @@ func TestGetTagDescription_Success(t *testing.T) {
 	}
 
 	assert.Equal(t, http.StatusOK, responseBody.Status)
+
+	_, err = os.Stat(filepath.Join(mediaRoot.Dir(), "tagExist", "comixIsNotExist"))
+	assert.NoError(t, err)
+}
+
+func TestInsert_Moderation(t *testing.T) {
+	mediaRoot := photostest.NewRoot(t)
+	assert.NoError(t, os.Mkdir(filepath.Join(mediaRoot.Dir(), "tagExist"), 0755))
+
+	handler := insert.New(slogdiscard.NewDiscardLogger(), &ComixAdderMock{}, cache.New(cache.NewMemory(), time.Minute), mediaRoot, true)
+
+	jsonBody, _ := json.Marshal(map[string]interface{}{
+		"password":    "password",
+		"tagName":     "tagExist",
+		"name":        "comixIsNotExist",
+		"description": "someText",
+	})
+
+	req, err := http.NewRequest("POST", "/newcomix", bytes.NewBuffer(jsonBody))
+	assert.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	var responseBody ResponseMock
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &responseBody))
+
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+	assert.True(t, responseBody.Pending)
+}
+
+func TestInsert_UnsafeName(t *testing.T) {
+	mediaRoot := photostest.NewRoot(t)
+	assert.NoError(t, os.Mkdir(filepath.Join(mediaRoot.Dir(), "tagExist"), 0755))
+
+	handler := insert.New(slogdiscard.NewDiscardLogger(), &ComixAdderMock{}, cache.New(cache.NewMemory(), time.Minute), mediaRoot, false)
+
+	for _, name := range []string{"..", "../../escaped", `..\escaped`} {
+		jsonBody, _ := json.Marshal(map[string]interface{}{
+			"password":    "password",
+			"tagName":     "tagExist",
+			"name":        name,
+			"description": "someText",
+		})
+
+		req, err := http.NewRequest("POST", "/newcomix", bytes.NewBuffer(jsonBody))
+		assert.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		var responseBody ResponseMock
+
+		if err := json.Unmarshal(rr.Body.Bytes(), &responseBody); err != nil {
+			t.Fatalf("Ошибка при распоковке JSON: %s", err)
+		}
+
+		assert.Equal(t, http.StatusBadRequest, responseBody.Status)
+		assert.Equal(t, "invalid comix name", responseBody.Error)
+	}
 }
 
 func TestGetTagDescription_EmptyValueParams(t *testing.T) {
@@ -104,7 +180,7 @@ func TestGetTagDescription_EmptyValueParams(t *testing.T) {
 	}
 
 	for _, m := range requestsBody {
-		handler := insert.New(mockLogger, &ComixAdderMock{})
+		handler := insert.New(mockLogger, &ComixAdderMock{}, cache.New(cache.NewMemory(), time.Minute), photostest.NewRoot(t), false)
 
 		jsonBody, _ := json.Marshal(m)
 
@@ -0,0 +1,189 @@
+package insert_comix_cover
+
+import (
+	"bytes"
+	"errors"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"jadesheart/comix_back/internal/http-server/middleware/ratelimit"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/audit"
+	"jadesheart/comix_back/internal/lib/cache"
+	"jadesheart/comix_back/internal/lib/images"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/lib/photos"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// coverName - имя файла обложки в папке комикса. Страницы называются числами, поэтому не пересекаются с ним.
+const coverName = "cover"
+
+const maxCoverSize = 10 << 20
+
+var tagNamePattern = regexp.MustCompile(`^[a-zA-Z]+$`)
+
+type Response struct {
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+	Cover  string `json:"cover,omitempty"`
+}
+
+type ComixCoverSetter interface {
+	SetComixCover(tagName string, name string, cover string) (string, error)
+	CheckPass(inputPass string) (bool, error)
+	AddAuditEvent(event postgres.AuditEvent) error
+}
+
+type CacheInvalidator interface {
+	Invalidate(groups ...string)
+}
+
+func New(log *slog.Logger, comixCoverSetter ComixCoverSetter, cacheInvalidator CacheInvalidator, mediaRoot *photos.Root, limits images.Limits) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.insert_comix_cover.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxCoverSize+1<<20)
+
+		tagName := strings.TrimSpace(r.FormValue("tag"))
+		name := r.FormValue("name")
+		password := r.FormValue("password")
+
+		if password == "" || name == "" || !tagNamePattern.MatchString(tagName) {
+			render.JSON(w, r, resp.Error("password, tag and name are required, tag must contain only latin letters"))
+
+			return
+		}
+
+		comixDir, err := mediaRoot.ComixDir(tagName, name)
+		if err != nil {
+			log.Warn("unsafe comix name", sl.Err(err), slog.String("remote_addr", ratelimit.ClientIP(r)))
+
+			render.JSON(w, r, resp.Error("invalid comix name"))
+
+			return
+		}
+
+		file, header, err := r.FormFile("cover")
+		if err != nil {
+			log.Error("failed get cover file", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("cover file is required"))
+
+			return
+		}
+		defer file.Close()
+
+		if header.Size > maxCoverSize {
+			render.JSON(w, r, resp.Error("cover is too large"))
+
+			return
+		}
+
+		res, err := comixCoverSetter.CheckPass(password)
+		if err != nil {
+			log.Error("failed password verified", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed password verified"))
+
+			return
+		}
+
+		if !res {
+			log.Warn("incorrect password", slog.String("remote_addr", ratelimit.ClientIP(r)))
+
+			ratelimit.AuthFailed(r.Context())
+
+			render.JSON(w, r, resp.Error("incorrect password"))
+
+			return
+		}
+
+		ratelimit.AuthSucceeded(r.Context())
+
+		_, err = os.Stat(comixDir)
+		if err != nil {
+			log.Error("failed get comix directory", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("Directory does not exist, check if you created the comix?"))
+
+			return
+		}
+
+		// Обложка меньше страницы, поэтому её размер ограничен сильнее, чем у страниц
+		img, err := images.Sanitize(file, images.Limits{MaxFileSize: maxCoverSize, MaxPixels: limits.MaxPixels})
+		if err != nil {
+			log.Warn("rejected cover file", sl.Err(err), slog.String("file", header.Filename))
+
+			render.JSON(w, r, resp.Error(header.Filename+": "+err.Error()))
+
+			return
+		}
+
+		cover := coverName + img.Ext
+
+		err = photos.WriteFile(comixDir, cover, bytes.NewReader(img.Data))
+		if err != nil {
+			log.Error("failed write cover file", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed write file"))
+
+			return
+		}
+
+		oldCover, err := comixCoverSetter.SetComixCover(tagName, name, cover)
+		if errors.Is(err, storage.ErrComixNotFound) {
+			render.JSON(w, r, resp.Error("Comix not exists"))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed set comix cover", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed set comix cover"))
+
+			return
+		}
+
+		if oldCover != "" && oldCover != cover {
+			oldCoverPath, err := mediaRoot.Join(comixDir, oldCover)
+			if err == nil {
+				err = os.Remove(oldCoverPath)
+			}
+			if err != nil {
+				log.Error("failed remove old cover", sl.Err(err))
+			}
+		}
+
+		cacheInvalidator.Invalidate(cache.KeyMainPage, cache.KeySearch, cache.KeyTagComix(tagName))
+
+		err = comixCoverSetter.AddAuditEvent(audit.NewEvent(r, audit.ActionComixEdit, audit.ComixTarget(tagName, name), map[string]string{
+			"cover": oldCover,
+		}, map[string]string{
+			"cover": cover,
+		}))
+		if err != nil {
+			log.Error("failed write audit event", sl.Err(err))
+		}
+
+		responseOK(w, r, cover)
+
+	}
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request, cover string) {
+	render.JSON(w, r, Response{
+		Status: resp.StatusOK,
+		Cover:  cover,
+	})
+}
@@ -1,13 +1,20 @@
 package insert_photo
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
-	"io/ioutil"
+	"jadesheart/comix_back/internal/http-server/middleware/ratelimit"
 	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/api/schedule"
+	"jadesheart/comix_back/internal/lib/audit"
+	"jadesheart/comix_back/internal/lib/images"
 	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/lib/photos"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
 	"log/slog"
 	"mime/multipart"
 	"net/http"
@@ -17,6 +24,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type Request struct {
@@ -24,19 +32,36 @@ type Request struct {
 	TagName   string                  `form:"tag" validate:"required"`
 	ComixName string                  `form:"name" validate:"required"`
 	Photo     []*multipart.FileHeader `form:"photo" validate:"required"`
+	// Position - куда вставить первую страницу, начиная с 1. Не передана - страницы добавляются в конец
+	Position int `form:"position"`
+	// PublishAt - когда страницы выйдут. Не передано - выходят сразу
+	PublishAt time.Time `form:"publishAt"`
 }
 
 type Response struct {
 	resp.Response
-	Status string `json:"status,omitempty"`
-	Error  string `json:"error,omitempty"`
+	Status string          `json:"status,omitempty"`
+	Error  string          `json:"error,omitempty"`
+	Pages  []postgres.Page `json:"pages,omitempty"`
+	// Pending - страницы ждут одобрения модератора, ID страниц - id в очереди модерации
+	Pending bool `json:"pending,omitempty"`
 }
 
-type PasswordVerifier interface {
+// maxFormMemory - сколько формы держится в памяти, остальные файлы уходят во временные файлы на диске
+const maxFormMemory = 32 << 20
+
+// maxFieldsSize - запас на текстовые поля и разметку multipart сверх самих картинок
+const maxFieldsSize = 1 << 20
+
+type PhotoInserter interface {
 	CheckPass(inputPass string) (bool, error)
+	AddAuditEvent(event postgres.AuditEvent) error
+	AddPages(tagName string, name string, position int, files []string, publishAt time.Time) ([]postgres.Page, error)
+	AddPendingPages(tagName string, name string, position int, files []string, publishAt time.Time) ([]postgres.Page, error)
 }
 
-func New(log *slog.Logger, passwordVerifier PasswordVerifier) http.HandlerFunc {
+// New добавляет страницы в комикс. При включённой модерации страницы ждут одобрения в очереди.
+func New(log *slog.Logger, photoInserter PhotoInserter, mediaRoot *photos.Root, limits images.Limits, moderation bool) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.comix.insert_photo.New"
 
@@ -45,15 +70,44 @@ func New(log *slog.Logger, passwordVerifier PasswordVerifier) http.HandlerFunc {
 			slog.String("request_id", middleware.GetReqID(r.Context())),
 		)
 
+		r.Body = http.MaxBytesReader(w, r.Body, limits.MaxRequestSize+maxFieldsSize)
+
+		err := r.ParseMultipartForm(maxFormMemory)
+		if err != nil {
+			log.Error("failed parse multipart form", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed parse multipart form, request may be too large"))
+
+			return
+		}
+
 		var req Request
 		req.TagName = strings.TrimSpace(r.FormValue("tag"))
 		req.Password = r.FormValue("password")
 		req.ComixName = r.FormValue("name")
 		req.Photo = r.MultipartForm.File["photo"]
 
+		if position := r.FormValue("position"); position != "" {
+			n, err := strconv.Atoi(position)
+			if err != nil || n < 1 {
+				render.JSON(w, r, resp.Error("position must be a positive number"))
+
+				return
+			}
+			req.Position = n
+		}
+
+		publishAt, ok := schedule.FromRequest(r)
+		if !ok {
+			render.JSON(w, r, resp.Error("publishAt must be a RFC 3339 time"))
+
+			return
+		}
+		req.PublishAt = publishAt
+
 		log.Info("request body decoded", slog.Any("tag", req.TagName))
 
-		validate := ValidateComixImg(req)
+		validate := ValidateComixImg(req, limits)
 		if validate.Status == resp.StatusError {
 			log.Error("failed validate", sl.Err(errors.New(validate.Error)))
 
@@ -64,7 +118,7 @@ func New(log *slog.Logger, passwordVerifier PasswordVerifier) http.HandlerFunc {
 
 		log.Info("All data valid", slog.Any("name", req.ComixName))
 
-		res, err := passwordVerifier.CheckPass(req.Password)
+		res, err := photoInserter.CheckPass(req.Password)
 		if err != nil {
 			log.Error("failed password verified", sl.Err(err))
 
@@ -74,71 +128,156 @@ func New(log *slog.Logger, passwordVerifier PasswordVerifier) http.HandlerFunc {
 		}
 
 		if !res {
-			log.Info("INCORRECT PASSWORD", slog.Any("Trying input password: ", req.Password))
+			log.Warn("incorrect password", slog.String("remote_addr", ratelimit.ClientIP(r)))
+
+			ratelimit.AuthFailed(r.Context())
 
 			render.JSON(w, r, resp.Error("incorrect password"))
 
 			return
 		}
 
-		files := req.Photo
-		for i, file := range files {
-			f, err := file.Open()
-			if err != nil {
-				log.Error("failed open file", sl.Err(err))
-
-				render.JSON(w, r, resp.Error("failed open file"))
+		ratelimit.AuthSucceeded(r.Context())
 
-				return
-			}
-			defer f.Close()
+		comixDir, err := mediaRoot.ComixDir(req.TagName, req.ComixName)
+		if err != nil {
+			log.Warn("unsafe comix name", sl.Err(err), slog.String("remote_addr", ratelimit.ClientIP(r)))
 
-			data, err := ioutil.ReadAll(f)
-			if err != nil {
-				log.Error("failed read file", sl.Err(err))
+			render.JSON(w, r, resp.Error("invalid comix name"))
 
-				render.JSON(w, r, resp.Error("failed read file"))
+			return
+		}
 
-				return
-			}
+		_, err = os.Stat(comixDir)
+		if os.IsNotExist(err) {
 
-			path := fmt.Sprintf("internal/storage/web/photos/%s/%s/", req.TagName, req.ComixName)
+			log.Error("Directory does not exist", sl.Err(err))
 
-			_, err = os.Stat(path)
-			if os.IsNotExist(err) {
+			render.JSON(w, r, resp.Error("Directory does not exist, check if you created the tag?"))
 
-				log.Error("Directory does not exist", sl.Err(err))
+			return
 
-				render.JSON(w, r, resp.Error("Directory does not exist, check if you created the tag?"))
+		} else if err != nil {
 
-				return
+			log.Error("failed get directory: ", sl.Err(err))
 
-			} else if err != nil {
+			render.JSON(w, r, resp.Error("failed get directory"))
 
-				log.Error("failed get directory: ", sl.Err(err))
+			return
 
-				render.JSON(w, r, resp.Error("failed get directory"))
+		}
 
-				return
+		files := req.Photo
+		written := make([]string, 0, len(files))
 
+		// removeWritten убирает уже записанные файлы, если страницы не удалось сохранить целиком
+		removeWritten := func() {
+			for _, name := range written {
+				os.Remove(filepath.Join(comixDir, name))
 			}
+		}
+
+		for _, file := range files {
+			err := writePage(comixDir, file, limits, &written)
+			if errors.Is(err, images.ErrNotImage) || errors.Is(err, images.ErrTooLarge) || errors.Is(err, images.ErrTooManyPixels) {
+				log.Warn("rejected page file", sl.Err(err), slog.String("file", file.Filename))
+
+				removeWritten()
 
-			err = ioutil.WriteFile(path+strconv.Itoa(i+1)+".jpg", data, 0666)
+				render.JSON(w, r, resp.Error(fmt.Sprintf("%s: %s", file.Filename, err)))
+
+				return
+			}
 			if err != nil {
 				log.Error("failed write file", sl.Err(err))
 
+				removeWritten()
+
 				render.JSON(w, r, resp.Error("failed write file"))
 
 				return
 			}
 		}
 
-		responseOK(w, r)
+		addPages := photoInserter.AddPages
+		if moderation {
+			addPages = photoInserter.AddPendingPages
+		}
+
+		pages, err := addPages(req.TagName, req.ComixName, req.Position, written, req.PublishAt)
+		if errors.Is(err, storage.ErrComixNotFound) {
+			removeWritten()
+
+			render.JSON(w, r, resp.Error("Comix not exists"))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed add pages", sl.Err(err))
+
+			removeWritten()
+
+			render.JSON(w, r, resp.Error("failed add pages"))
+
+			return
+		}
+
+		fileNames := make([]string, 0, len(files))
+		for _, file := range files {
+			fileNames = append(fileNames, file.Filename)
+		}
+
+		after := map[string]interface{}{
+			"pages":    len(files),
+			"files":    fileNames,
+			"position": pages[0].Position,
+			"pending":  moderation,
+		}
+		if !req.PublishAt.IsZero() {
+			after["publishAt"] = req.PublishAt
+		}
+
+		err = photoInserter.AddAuditEvent(audit.NewEvent(r, audit.ActionPagesUpload, audit.ComixTarget(req.TagName, req.ComixName), nil, after))
+		if err != nil {
+			log.Error("failed write audit event", sl.Err(err))
+		}
+
+		responseOK(w, r, pages, moderation)
 
 	}
 }
 
-func ValidateComixImg(req Request) resp.Response {
+// writePage проверяет загруженную страницу, сохраняет её заново закодированной под новым именем
+// с расширением по формату картинки и дописывает имя в written
+func writePage(comixDir string, file *multipart.FileHeader, limits images.Limits, written *[]string) error {
+	f, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	img, err := images.Sanitize(f, limits)
+	if err != nil {
+		return err
+	}
+
+	name, err := photos.NewPageName(img.Ext)
+	if err != nil {
+		return err
+	}
+
+	err = photos.WriteFile(comixDir, name, bytes.NewReader(img.Data))
+	if err != nil {
+		return err
+	}
+
+	*written = append(*written, name)
+
+	return nil
+}
+
+// ValidateComixImg проверяет поля формы и размеры файлов. Что файлы - картинки, проверяется по содержимому при записи.
+func ValidateComixImg(req Request, limits images.Limits) resp.Response {
 	var errMsg []string
 
 	tag := req.TagName
@@ -159,13 +298,13 @@ func ValidateComixImg(req Request) resp.Response {
 	}
 
 	files := req.Photo
-	for _, file := range files {
+	if len(files) == 0 {
+		errMsg = append(errMsg, "photo is required")
+	}
 
-		ext := strings.ToLower(filepath.Ext(file.Filename))
-		if ext == ".jpg" || ext == ".jpeg" || ext == ".png" {
-			continue
-		} else {
-			errMsg = append(errMsg, fmt.Sprintf("file is not a image or not supported: %s", file.Filename))
+	for _, file := range files {
+		if file.Size > limits.MaxFileSize {
+			errMsg = append(errMsg, fmt.Sprintf("file is too large: %s", file.Filename))
 		}
 	}
 
@@ -181,9 +320,11 @@ func ValidateComixImg(req Request) resp.Response {
 	}
 }
 
-func responseOK(w http.ResponseWriter, r *http.Request) {
+func responseOK(w http.ResponseWriter, r *http.Request, pages []postgres.Page, pending bool) {
 	render.JSON(w, r, Response{
 		Response: resp.OK(),
 		Status:   "successful insert comix photo",
+		Pages:    pages,
+		Pending:  pending,
 	})
 }
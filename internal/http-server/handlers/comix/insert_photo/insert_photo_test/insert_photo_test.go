@@ -2,17 +2,31 @@ package insert_photo_test
 
 import (
 	"bytes"
+	"image"
+	"image/png"
+	"jadesheart/comix_back/internal/lib/images"
 	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"jadesheart/comix_back/internal/lib/photos"
+	"jadesheart/comix_back/internal/storage/postgres"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
 	"github.com/stretchr/testify/assert"
 
+	"jadesheart/comix_back/internal/http-server/handlers/comix/get_photo"
 	"jadesheart/comix_back/internal/http-server/handlers/comix/insert_photo"
+	"jadesheart/comix_back/internal/lib/photos/photostest"
 )
 
+var limits = images.Limits{MaxFileSize: 1 << 20, MaxRequestSize: 4 << 20, MaxPixels: 1 << 20}
+
 type ComixSaverMock struct {
 }
 
@@ -20,6 +34,18 @@ func (c *ComixSaverMock) CheckPass(inputPass string) (bool, error) {
 	return false, nil
 }
 
+func (c *ComixSaverMock) AddPages(tagName string, name string, position int, files []string, publishAt time.Time) ([]postgres.Page, error) {
+	return nil, nil
+}
+
+func (c *ComixSaverMock) AddPendingPages(tagName string, name string, position int, files []string, publishAt time.Time) ([]postgres.Page, error) {
+	return nil, nil
+}
+
+func (c *ComixSaverMock) AddAuditEvent(event postgres.AuditEvent) error {
+	return nil
+}
+
 func TestInsertPhotoHandler(t *testing.T) {
 	mockPasswordVerifier := &ComixSaverMock{}
 	mockLogger := slogdiscard.NewDiscardLogger()
@@ -37,9 +63,168 @@ func TestInsertPhotoHandler(t *testing.T) {
 	}
 	req.Header.Set("Content-Type", writer.FormDataContentType())
 	recorder := httptest.NewRecorder()
-	handler := insert_photo.New(mockLogger, mockPasswordVerifier)
+	mediaRoot, err := photos.NewRoot(t.TempDir())
+	assert.NoError(t, err)
+	handler := insert_photo.New(mockLogger, mockPasswordVerifier, mediaRoot, limits, false)
 	handler.ServeHTTP(recorder, req)
 	assert.Equal(t, http.StatusOK, recorder.Code, "статус должен быть 200")
 	expectedContentType := "application/json"
 	assert.Equal(t, expectedContentType, recorder.Header().Get("Content-Type"), "")
 }
+
+type MockPageAdder struct {
+	ComixSaverMock
+	position int
+	files    []string
+}
+
+func (m *MockPageAdder) CheckPass(inputPass string) (bool, error) {
+	return inputPass == "password", nil
+}
+
+func (m *MockPageAdder) AddPages(tagName string, name string, position int, files []string, publishAt time.Time) ([]postgres.Page, error) {
+	m.position = position
+	m.files = files
+
+	pages := make([]postgres.Page, 0, len(files))
+	for i, file := range files {
+		pages = append(pages, postgres.Page{ID: 10 + i, Position: position + i, File: file})
+	}
+	return pages, nil
+}
+
+func TestInsertPhoto_InsertsAtPosition(t *testing.T) {
+	wd, err := os.Getwd()
+	assert.NoError(t, err)
+	assert.NoError(t, os.Chdir(t.TempDir()))
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	comixDir := filepath.Join("internal/storage/web/photos", "Horror", "Watchmen")
+	assert.NoError(t, os.MkdirAll(comixDir, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(comixDir, "1.jpg"), []byte("first page"), 0644))
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	_ = writer.WriteField("password", "password")
+	_ = writer.WriteField("tag", "horror")
+	_ = writer.WriteField("name", "Watchmen")
+	_ = writer.WriteField("position", "1")
+	for _, name := range []string{"a.jpg", "b.png"} {
+		part, _ := writer.CreateFormFile("photo", name)
+		png.Encode(part, image.NewGray(image.Rect(0, 0, 10, 10)))
+	}
+	writer.Close()
+
+	req, err := http.NewRequest("POST", "/insertphoto", body)
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	mediaRoot, err := photos.NewRoot("internal/storage/web/photos")
+	assert.NoError(t, err)
+
+	adder := &MockPageAdder{}
+	recorder := httptest.NewRecorder()
+	insert_photo.New(slogdiscard.NewDiscardLogger(), adder, mediaRoot, limits, false).ServeHTTP(recorder, req)
+
+	assert.Equal(t, 1, adder.position)
+	assert.Len(t, adder.files, 2)
+
+	// Старая страница не перезаписана, новые лежат под своими именами
+	data, err := os.ReadFile(filepath.Join(comixDir, "1.jpg"))
+	assert.NoError(t, err)
+	assert.Equal(t, "first page", string(data))
+
+	data, err = os.ReadFile(filepath.Join(comixDir, adder.files[1]))
+	assert.NoError(t, err)
+	_, err = png.Decode(bytes.NewReader(data))
+	assert.NoError(t, err)
+}
+
+func TestInsertPhoto_RejectsNotImages(t *testing.T) {
+	wd, err := os.Getwd()
+	assert.NoError(t, err)
+	assert.NoError(t, os.Chdir(t.TempDir()))
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	comixDir := filepath.Join("internal/storage/web/photos", "Horror", "Watchmen")
+	assert.NoError(t, os.MkdirAll(comixDir, 0755))
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	_ = writer.WriteField("password", "password")
+	_ = writer.WriteField("tag", "horror")
+	_ = writer.WriteField("name", "Watchmen")
+	part, _ := writer.CreateFormFile("photo", "page.jpg")
+	png.Encode(part, image.NewGray(image.Rect(0, 0, 10, 10)))
+	// Расширение картинки не делает файл картинкой
+	part, _ = writer.CreateFormFile("photo", "evil.jpg")
+	part.Write([]byte("<html><script>alert(1)</script></html>"))
+	writer.Close()
+
+	req, err := http.NewRequest("POST", "/insertphoto", body)
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	mediaRoot, err := photos.NewRoot("internal/storage/web/photos")
+	assert.NoError(t, err)
+
+	adder := &MockPageAdder{}
+	recorder := httptest.NewRecorder()
+	insert_photo.New(slogdiscard.NewDiscardLogger(), adder, mediaRoot, limits, false).ServeHTTP(recorder, req)
+
+	assert.Contains(t, recorder.Body.String(), "evil.jpg")
+	assert.Nil(t, adder.files)
+
+	// Уже записанная первая страница убрана
+	entries, err := os.ReadDir(comixDir)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+type MockPageGetter struct {
+	files []string
+}
+
+func (m *MockPageGetter) GetComixPages(tagName string, name string) ([]postgres.Page, error) {
+	pages := make([]postgres.Page, 0, len(m.files))
+	for i, file := range m.files {
+		pages = append(pages, postgres.Page{ID: i + 1, Position: i + 1, File: file})
+	}
+	return pages, nil
+}
+
+func TestInsertPhoto_KeepsPngExtension(t *testing.T) {
+	mediaRoot := photostest.NewRoot(t)
+	assert.NoError(t, os.MkdirAll(filepath.Join(mediaRoot.Dir(), "horror", "Watchmen"), 0755))
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	_ = writer.WriteField("password", "password")
+	_ = writer.WriteField("tag", "horror")
+	_ = writer.WriteField("name", "Watchmen")
+	part, _ := writer.CreateFormFile("photo", "page.png")
+	part.Write(photostest.FileData("page.png"))
+	writer.Close()
+
+	req, err := http.NewRequest("POST", "/insertphoto", body)
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	adder := &MockPageAdder{}
+	recorder := httptest.NewRecorder()
+	insert_photo.New(slogdiscard.NewDiscardLogger(), adder, mediaRoot, limits, false).ServeHTTP(recorder, req)
+
+	assert.Len(t, adder.files, 1)
+	assert.Equal(t, ".png", filepath.Ext(adder.files[0]))
+
+	// Страница отдаётся по своему имени и с типом png
+	router := chi.NewRouter()
+	router.Use(middleware.URLFormat)
+	router.Get("/{folder1}/{folder2}/{fileName}", get_photo.New(slogdiscard.NewDiscardLogger(), &MockPageGetter{files: adder.files}, mediaRoot))
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/horror/Watchmen_/"+adder.files[0], nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "image/png", recorder.Header().Get("Content-Type"))
+}
@@ -0,0 +1,189 @@
+package insert_tag_cover
+
+import (
+	"bytes"
+	"errors"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"jadesheart/comix_back/internal/http-server/middleware/ratelimit"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/audit"
+	"jadesheart/comix_back/internal/lib/cache"
+	"jadesheart/comix_back/internal/lib/images"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/lib/photos"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// coverName - имя файла обложки в папке тэга, к нему добавляется расширение по содержимому файла
+const coverName = "cover"
+
+// maxCoverSize - обложка загружается одним файлом, 10 МБ хватает с запасом
+const maxCoverSize = 10 << 20
+
+var tagNamePattern = regexp.MustCompile(`^[a-zA-Z]+$`)
+
+type Response struct {
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+	Cover  string `json:"cover,omitempty"`
+}
+
+type TagCoverSetter interface {
+	SetTagCover(tagName string, cover string) (string, error)
+	CheckPass(inputPass string) (bool, error)
+	AddAuditEvent(event postgres.AuditEvent) error
+}
+
+type CacheInvalidator interface {
+	Invalidate(groups ...string)
+}
+
+func New(log *slog.Logger, tagCoverSetter TagCoverSetter, cacheInvalidator CacheInvalidator, mediaRoot *photos.Root, limits images.Limits) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.insert_tag_cover.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxCoverSize+1<<20)
+
+		tagName := strings.TrimSpace(r.FormValue("tag"))
+		password := r.FormValue("password")
+
+		if password == "" || !tagNamePattern.MatchString(tagName) {
+			render.JSON(w, r, resp.Error("password and tag are required, tag must contain only latin letters"))
+
+			return
+		}
+
+		file, header, err := r.FormFile("cover")
+		if err != nil {
+			log.Error("failed get cover file", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("cover file is required"))
+
+			return
+		}
+		defer file.Close()
+
+		if header.Size > maxCoverSize {
+			render.JSON(w, r, resp.Error("cover is too large"))
+
+			return
+		}
+
+		res, err := tagCoverSetter.CheckPass(password)
+		if err != nil {
+			log.Error("failed password verified", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed password verified"))
+
+			return
+		}
+
+		if !res {
+			log.Warn("incorrect password", slog.String("remote_addr", ratelimit.ClientIP(r)))
+
+			ratelimit.AuthFailed(r.Context())
+
+			render.JSON(w, r, resp.Error("incorrect password"))
+
+			return
+		}
+
+		ratelimit.AuthSucceeded(r.Context())
+
+		tagDir, err := mediaRoot.TagDir(tagName)
+		if err != nil {
+			log.Warn("unsafe tag name", sl.Err(err), slog.String("remote_addr", ratelimit.ClientIP(r)))
+
+			render.JSON(w, r, resp.Error("invalid tag name"))
+
+			return
+		}
+
+		_, err = os.Stat(tagDir)
+		if err != nil {
+			log.Error("failed get tag directory", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("Directory does not exist, check if you created the tag?"))
+
+			return
+		}
+
+		// Обложка меньше страницы, поэтому её размер ограничен сильнее, чем у страниц
+		img, err := images.Sanitize(file, images.Limits{MaxFileSize: maxCoverSize, MaxPixels: limits.MaxPixels})
+		if err != nil {
+			log.Warn("rejected cover file", sl.Err(err), slog.String("file", header.Filename))
+
+			render.JSON(w, r, resp.Error(header.Filename+": "+err.Error()))
+
+			return
+		}
+
+		cover := coverName + img.Ext
+
+		err = photos.WriteFile(tagDir, cover, bytes.NewReader(img.Data))
+		if err != nil {
+			log.Error("failed write cover file", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed write file"))
+
+			return
+		}
+
+		oldCover, err := tagCoverSetter.SetTagCover(tagName, cover)
+		if errors.Is(err, storage.ErrTagNotFound) {
+			render.JSON(w, r, resp.Error("Tag not exists"))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed set tag cover", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed set tag cover"))
+
+			return
+		}
+
+		if oldCover != "" && oldCover != cover {
+			oldCoverPath, err := mediaRoot.Join(tagDir, oldCover)
+			if err == nil {
+				err = os.Remove(oldCoverPath)
+			}
+			if err != nil {
+				log.Error("failed remove old cover", sl.Err(err))
+			}
+		}
+
+		cacheInvalidator.Invalidate(cache.KeyAllTags)
+
+		err = tagCoverSetter.AddAuditEvent(audit.NewEvent(r, audit.ActionTagEdit, audit.TagTarget(tagName), map[string]string{
+			"cover": oldCover,
+		}, map[string]string{
+			"cover": cover,
+		}))
+		if err != nil {
+			log.Error("failed write audit event", sl.Err(err))
+		}
+
+		responseOK(w, r, cover)
+
+	}
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request, cover string) {
+	render.JSON(w, r, Response{
+		Status: resp.StatusOK,
+		Cover:  cover,
+	})
+}
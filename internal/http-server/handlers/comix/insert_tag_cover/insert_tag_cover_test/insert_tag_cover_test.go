@@ -0,0 +1,136 @@
+package insert_tag_cover_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"image/png"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/insert_tag_cover"
+	"jadesheart/comix_back/internal/lib/cache"
+	"jadesheart/comix_back/internal/lib/images"
+	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"jadesheart/comix_back/internal/lib/photos"
+	"jadesheart/comix_back/internal/lib/photos/photostest"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type ResponseMock struct {
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+	Cover  string `json:"cover,omitempty"`
+}
+
+type MockTagCoverSetter struct {
+	oldCover string
+}
+
+func (m *MockTagCoverSetter) SetTagCover(tagName string, cover string) (string, error) {
+	return m.oldCover, nil
+}
+
+func (m *MockTagCoverSetter) CheckPass(inputPass string) (bool, error) {
+	return inputPass == "password", nil
+}
+
+func (m *MockTagCoverSetter) AddAuditEvent(event postgres.AuditEvent) error {
+	return nil
+}
+
+const photosDir = "internal/storage/web/photos"
+
+var limits = images.Limits{MaxFileSize: 1 << 20, MaxRequestSize: 4 << 20, MaxPixels: 1 << 20}
+
+func doRequest(t *testing.T, setter *MockTagCoverSetter, fields map[string]string, fileName string) ResponseMock {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	for key, value := range fields {
+		assert.NoError(t, writer.WriteField(key, value))
+	}
+
+	if fileName != "" {
+		part, err := writer.CreateFormFile("cover", fileName)
+		assert.NoError(t, err)
+		_, err = part.Write(photostest.FileData(fileName))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, writer.Close())
+
+	req, err := http.NewRequest("POST", "/tagcover", body)
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	mediaRoot, err := photos.NewRoot(photosDir)
+	assert.NoError(t, err)
+
+	handler := insert_tag_cover.New(slogdiscard.NewDiscardLogger(), setter, cache.New(cache.NewMemory(), time.Minute), mediaRoot, limits)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	var responseBody ResponseMock
+
+	if err := json.Unmarshal(rr.Body.Bytes(), &responseBody); err != nil {
+		t.Fatalf("Ошибка при распоковке JSON: %s", err)
+	}
+
+	return responseBody
+}
+
+func setupPhotos(t *testing.T) {
+	wd, err := os.Getwd()
+	assert.NoError(t, err)
+	assert.NoError(t, os.Chdir(t.TempDir()))
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	assert.NoError(t, os.MkdirAll(filepath.Join(photosDir, "Horror"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(photosDir, "Horror", "cover.jpg"), []byte("old"), 0644))
+}
+
+func TestInsertTagCover_ReplacesOldCover(t *testing.T) {
+	setupPhotos(t)
+
+	responseBody := doRequest(t, &MockTagCoverSetter{oldCover: "cover.jpg"}, map[string]string{
+		"password": "password",
+		"tag":      "horror",
+	}, "new.png")
+
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+	assert.Equal(t, "cover.png", responseBody.Cover)
+
+	data, err := os.ReadFile(filepath.Join(photosDir, "Horror", "cover.png"))
+	assert.NoError(t, err)
+	_, err = png.Decode(bytes.NewReader(data))
+	assert.NoError(t, err)
+
+	_, err = os.Stat(filepath.Join(photosDir, "Horror", "cover.jpg"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestInsertTagCover_InvalidRequest(t *testing.T) {
+	setupPhotos(t)
+
+	cases := []struct {
+		fields   map[string]string
+		fileName string
+	}{
+		{map[string]string{"password": "password", "tag": "Horror"}, ""},
+		{map[string]string{"password": "password", "tag": "Horror"}, "fake.jpg"},
+		{map[string]string{"password": "password", "tag": "../etc"}, "cover.jpg"},
+		{map[string]string{"password": "wrong_password", "tag": "Horror"}, "cover.jpg"},
+		{map[string]string{"password": "password", "tag": "Comedy"}, "cover.jpg"},
+	}
+
+	for _, c := range cases {
+		responseBody := doRequest(t, &MockTagCoverSetter{}, c.fields, c.fileName)
+
+		assert.Equal(t, http.StatusBadRequest, responseBody.Status)
+	}
+}
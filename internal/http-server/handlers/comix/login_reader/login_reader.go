@@ -0,0 +1,126 @@
+package login_reader
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator"
+	"jadesheart/comix_back/internal/http-server/middleware/ratelimit"
+	"jadesheart/comix_back/internal/lib/account"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+type Request struct {
+	Username string `json:"username" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+type Response struct {
+	Status   int    `json:"status,omitempty"`
+	Error    string `json:"error,omitempty"`
+	Username string `json:"username,omitempty"`
+	Token    string `json:"token,omitempty"`
+}
+
+type ReaderAuthenticator interface {
+	GetReaderByName(username string) (postgres.Reader, string, error)
+	CreateSession(readerID int, tokenHash string, ttl time.Duration) error
+}
+
+// New проверяет имя и пароль читателя и открывает новую сессию на sessionTTL.
+// Неизвестное имя и неверный пароль неотличимы для клиента и одинаково считаются неудачной попыткой.
+func New(log *slog.Logger, readerAuthenticator ReaderAuthenticator, sessionTTL time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.login_reader.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("failed decode request json", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed decode request json"))
+
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Error("failed validate", sl.Err(err))
+
+			render.JSON(w, r, resp.ValidateErrors(validateErr))
+
+			return
+		}
+
+		reader, passwordHash, err := readerAuthenticator.GetReaderByName(req.Username)
+		if err != nil && !errors.Is(err, storage.ErrReaderNotFound) {
+			log.Error("failed get reader", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed password verified"))
+
+			return
+		}
+
+		found := err == nil
+		if !found {
+			passwordHash = account.DummyHash
+		}
+
+		ok, err := account.CheckPassword(passwordHash, req.Password)
+		if err != nil {
+			log.Error("failed password verified", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed password verified"))
+
+			return
+		}
+
+		if !found || !ok {
+			log.Warn("incorrect reader credentials", slog.String("remote_addr", ratelimit.ClientIP(r)))
+
+			ratelimit.AuthFailed(r.Context())
+
+			render.JSON(w, r, resp.Error("incorrect username or password"))
+
+			return
+		}
+
+		ratelimit.AuthSucceeded(r.Context())
+
+		token, tokenHash, err := account.NewToken()
+		if err == nil {
+			err = readerAuthenticator.CreateSession(reader.ID, tokenHash, sessionTTL)
+		}
+		if err != nil {
+			log.Error("failed create session", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed create session"))
+
+			return
+		}
+
+		responseOK(w, r, reader.Username, token)
+
+	}
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request, username string, token string) {
+	render.JSON(w, r, Response{
+		Status:   resp.StatusOK,
+		Username: username,
+		Token:    token,
+	})
+}
@@ -0,0 +1,115 @@
+package login_reader_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/login_reader"
+	"jadesheart/comix_back/internal/http-server/middleware/ratelimit"
+	"jadesheart/comix_back/internal/lib/account"
+	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type ResponseMock struct {
+	Status   int    `json:"status,omitempty"`
+	Error    string `json:"error,omitempty"`
+	Username string `json:"username,omitempty"`
+	Token    string `json:"token,omitempty"`
+}
+
+type MockReaderAuthenticator struct {
+	passwordHash string
+	sessions     []string
+}
+
+func (m *MockReaderAuthenticator) GetReaderByName(username string) (postgres.Reader, string, error) {
+	if username != "night_owl" {
+		return postgres.Reader{}, "", storage.ErrReaderNotFound
+	}
+	return postgres.Reader{ID: 1, Username: "night_owl"}, m.passwordHash, nil
+}
+
+func (m *MockReaderAuthenticator) CreateSession(readerID int, tokenHash string, ttl time.Duration) error {
+	m.sessions = append(m.sessions, tokenHash)
+	return nil
+}
+
+func newAuthenticator(t *testing.T) *MockReaderAuthenticator {
+	hash, err := account.HashPassword("long password")
+	assert.NoError(t, err)
+
+	return &MockReaderAuthenticator{passwordHash: hash}
+}
+
+func doRequest(t *testing.T, handler http.Handler, body map[string]interface{}) (int, ResponseMock) {
+	jsonBody, _ := json.Marshal(body)
+
+	req, err := http.NewRequest("POST", "/api/readers/login", bytes.NewBuffer(jsonBody))
+	assert.NoError(t, err)
+	req.RemoteAddr = "10.0.0.1:1000"
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	var responseBody ResponseMock
+
+	if err := json.Unmarshal(rr.Body.Bytes(), &responseBody); err != nil {
+		t.Fatalf("Ошибка при распоковке JSON: %s", err)
+	}
+
+	return rr.Code, responseBody
+}
+
+func TestLoginReader_Success(t *testing.T) {
+	authenticator := newAuthenticator(t)
+	handler := login_reader.New(slogdiscard.NewDiscardLogger(), authenticator, time.Hour)
+
+	_, responseBody := doRequest(t, handler, map[string]interface{}{"username": "night_owl", "password": "long password"})
+
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+	assert.Equal(t, "night_owl", responseBody.Username)
+	assert.Equal(t, []string{account.HashToken(responseBody.Token)}, authenticator.sessions)
+}
+
+func TestLoginReader_WrongCredentials(t *testing.T) {
+	authenticator := newAuthenticator(t)
+	handler := login_reader.New(slogdiscard.NewDiscardLogger(), authenticator, time.Hour)
+
+	cases := []map[string]interface{}{
+		{"username": "night_owl", "password": "wrong password"},
+		{"username": "nobody", "password": "long password"},
+	}
+
+	for _, body := range cases {
+		_, responseBody := doRequest(t, handler, body)
+
+		assert.Equal(t, http.StatusBadRequest, responseBody.Status)
+		assert.Equal(t, "incorrect username or password", responseBody.Error)
+	}
+
+	assert.Empty(t, authenticator.sessions)
+}
+
+func TestLoginReader_Lockout(t *testing.T) {
+	authenticator := newAuthenticator(t)
+	limiter := ratelimit.NewLimiter(time.Minute, time.Minute, 1)
+	budget := ratelimit.Budget{Name: "reader_auth", PerMinute: 600, Burst: 100, Protected: true}
+
+	handler := ratelimit.New(slogdiscard.NewDiscardLogger(), limiter, budget)(
+		login_reader.New(slogdiscard.NewDiscardLogger(), authenticator, time.Hour))
+
+	wrong := map[string]interface{}{"username": "night_owl", "password": "wrong password"}
+	doRequest(t, handler, wrong)
+	doRequest(t, handler, wrong)
+
+	code, _ := doRequest(t, handler, map[string]interface{}{"username": "night_owl", "password": "long password"})
+
+	assert.Equal(t, http.StatusTooManyRequests, code)
+	assert.Empty(t, authenticator.sessions)
+}
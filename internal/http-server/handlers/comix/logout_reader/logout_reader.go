@@ -0,0 +1,40 @@
+package logout_reader
+
+import (
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"jadesheart/comix_back/internal/http-server/middleware/session"
+	"jadesheart/comix_back/internal/lib/account"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"log/slog"
+	"net/http"
+)
+
+type SessionDeleter interface {
+	DeleteSession(tokenHash string) error
+}
+
+// New закрывает сессию, токен которой пришёл в заголовке Authorization.
+func New(log *slog.Logger, sessionDeleter SessionDeleter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.logout_reader.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		err := sessionDeleter.DeleteSession(account.HashToken(session.Token(r)))
+		if err != nil {
+			log.Error("failed delete session", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed delete session"))
+
+			return
+		}
+
+		render.JSON(w, r, resp.OK())
+
+	}
+}
@@ -0,0 +1,235 @@
+package merge_tags
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator"
+	"jadesheart/comix_back/internal/http-server/middleware/ratelimit"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/audit"
+	"jadesheart/comix_back/internal/lib/cache"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/lib/photos"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+type Request struct {
+	Password  string `json:"password" validate:"required"`
+	TagName   string `json:"tagName" validate:"required"`
+	TargetTag string `json:"targetTag" validate:"required"`
+}
+
+type Response struct {
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type TagMerger interface {
+	MergeTags(sourceTag string, targetTag string) error
+	CheckPass(inputPass string) (bool, error)
+	AddAuditEvent(event postgres.AuditEvent) error
+}
+
+type CacheInvalidator interface {
+	Invalidate(groups ...string)
+}
+
+func New(log *slog.Logger, tagMerger TagMerger, cacheInvalidator CacheInvalidator, mediaRoot *photos.Root) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.merge_tags.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("failed decode request json", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed decode request json"))
+
+			return
+		}
+
+		reqType := reflect.TypeOf(req)
+		for i := 0; i < reqType.NumField(); i++ {
+			field := reqType.Field(i)
+			fieldValue := reflect.ValueOf(req).FieldByName(field.Name)
+			if fieldValue.IsZero() {
+				errorMsg := fmt.Sprintf("zero point value: %s", field.Name)
+				render.JSON(w, r, resp.Error(errorMsg))
+				return
+			}
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Error("failed validate", sl.Err(err))
+
+			render.JSON(w, r, resp.ValidateErrors(validateErr))
+
+			return
+		}
+
+		if strings.EqualFold(req.TagName, req.TargetTag) {
+			render.JSON(w, r, resp.Error("cannot merge tag into itself"))
+
+			return
+		}
+
+		sourceDir, err := mediaRoot.TagDir(req.TagName)
+		if err != nil {
+			log.Warn("unsafe tag name", sl.Err(err), slog.String("remote_addr", ratelimit.ClientIP(r)))
+
+			render.JSON(w, r, resp.Error("invalid tag name"))
+
+			return
+		}
+
+		targetDir, err := mediaRoot.TagDir(req.TargetTag)
+		if err != nil {
+			log.Warn("unsafe tag name", sl.Err(err), slog.String("remote_addr", ratelimit.ClientIP(r)))
+
+			render.JSON(w, r, resp.Error("invalid tag name"))
+
+			return
+		}
+
+		res, err := tagMerger.CheckPass(req.Password)
+		if err != nil {
+			log.Error("failed password verified", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed password verified"))
+
+			return
+		}
+
+		if !res {
+			log.Warn("incorrect password", slog.String("remote_addr", ratelimit.ClientIP(r)))
+
+			ratelimit.AuthFailed(r.Context())
+
+			render.JSON(w, r, resp.Error("incorrect password"))
+
+			return
+		}
+
+		ratelimit.AuthSucceeded(r.Context())
+
+		moved, err := moveComixDirs(sourceDir, targetDir)
+		if err != nil {
+			log.Error("failed move comix photo dirs", sl.Err(err))
+
+			moveBack(log, moved, sourceDir, targetDir)
+
+			render.JSON(w, r, resp.Error("failed move comix photo dirs"))
+
+			return
+		}
+
+		err = tagMerger.MergeTags(req.TagName, req.TargetTag)
+		if err != nil {
+			log.Error("failed merge tags", sl.Err(err))
+
+			moveBack(log, moved, sourceDir, targetDir)
+
+			switch {
+			case errors.Is(err, storage.ErrTagNotFound):
+				render.JSON(w, r, resp.Error("Tag not exists"))
+			case errors.Is(err, storage.ErrComixExists):
+				render.JSON(w, r, resp.Error("both tags have comix with the same name"))
+			default:
+				render.JSON(w, r, resp.Error("failed merge tags"))
+			}
+
+			return
+		}
+
+		err = os.RemoveAll(sourceDir)
+		if err != nil {
+			log.Error("failed remove source tag folder", sl.Err(err))
+		}
+
+		cacheInvalidator.Invalidate(cache.KeyAllTags, cache.KeyMainPage, cache.KeySearch,
+			cache.KeyTagDescription(req.TagName), cache.KeyTagComix(req.TagName), cache.KeyTagComix(req.TargetTag))
+
+		err = tagMerger.AddAuditEvent(audit.NewEvent(r, audit.ActionTagMerge, audit.TagTarget(req.TagName), map[string]interface{}{
+			"tagName": req.TagName,
+			"comix":   moved,
+		}, map[string]string{
+			"tagName": req.TargetTag,
+		}))
+		if err != nil {
+			log.Error("failed write audit event", sl.Err(err))
+		}
+
+		responseOK(w, r)
+
+	}
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request) {
+	render.JSON(w, r, Response{
+		Status: resp.StatusOK,
+	})
+}
+
+// moveComixDirs переносит папки комиксов из sourceDir в targetDir и возвращает названия перенесённых.
+// Папки проверяются на совпадение заранее, чтобы не начинать перенос, который всё равно откатится.
+func moveComixDirs(sourceDir string, targetDir string) ([]string, error) {
+	entries, err := os.ReadDir(sourceDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(targetDir, entry.Name())); err == nil {
+			return nil, fmt.Errorf("comix folder %s exists in both tags", entry.Name())
+		}
+	}
+
+	var moved []string
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		err := photos.MoveDir(filepath.Join(sourceDir, entry.Name()), filepath.Join(targetDir, entry.Name()))
+		if err != nil {
+			return moved, err
+		}
+
+		moved = append(moved, entry.Name())
+	}
+
+	return moved, nil
+}
+
+func moveBack(log *slog.Logger, moved []string, sourceDir string, targetDir string) {
+	for _, name := range moved {
+		err := photos.MoveDir(filepath.Join(targetDir, name), filepath.Join(sourceDir, name))
+		if err != nil {
+			log.Error("failed move comix photo dir back", slog.String("name", name), sl.Err(err))
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package merge_tags_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/merge_tags"
+	"jadesheart/comix_back/internal/lib/cache"
+	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"jadesheart/comix_back/internal/lib/photos"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type ResponseMock struct {
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type MockTagMerger struct {
+	err error
+}
+
+func (m *MockTagMerger) MergeTags(sourceTag string, targetTag string) error {
+	return m.err
+}
+
+func (m *MockTagMerger) CheckPass(inputPass string) (bool, error) {
+	return inputPass == "password", nil
+}
+
+func (m *MockTagMerger) AddAuditEvent(event postgres.AuditEvent) error {
+	return nil
+}
+
+const photosDir = "internal/storage/web/photos"
+
+func doRequest(t *testing.T, merger *MockTagMerger, body map[string]interface{}) ResponseMock {
+	mediaRoot, err := photos.NewRoot(photosDir)
+	assert.NoError(t, err)
+
+	handler := merge_tags.New(slogdiscard.NewDiscardLogger(), merger, cache.New(cache.NewMemory(), time.Minute), mediaRoot)
+
+	jsonBody, _ := json.Marshal(body)
+
+	req, err := http.NewRequest("POST", "/mergetags", bytes.NewBuffer(jsonBody))
+	assert.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	var responseBody ResponseMock
+
+	if err := json.Unmarshal(rr.Body.Bytes(), &responseBody); err != nil {
+		t.Fatalf("Ошибка при распоковке JSON: %s", err)
+	}
+
+	return responseBody
+}
+
+func setupPhotos(t *testing.T) {
+	wd, err := os.Getwd()
+	assert.NoError(t, err)
+	assert.NoError(t, os.Chdir(t.TempDir()))
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	assert.NoError(t, os.MkdirAll(filepath.Join(photosDir, "Humor", "first"), 0755))
+	assert.NoError(t, os.MkdirAll(filepath.Join(photosDir, "Humor", "second"), 0755))
+	assert.NoError(t, os.MkdirAll(filepath.Join(photosDir, "Comedy", "third"), 0755))
+}
+
+func TestMergeTags_Success(t *testing.T) {
+	setupPhotos(t)
+
+	responseBody := doRequest(t, &MockTagMerger{}, map[string]interface{}{
+		"password":  "password",
+		"tagName":   "humor",
+		"targetTag": "comedy",
+	})
+
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+
+	for _, name := range []string{"first", "second", "third"} {
+		_, err := os.Stat(filepath.Join(photosDir, "Comedy", name))
+		assert.NoError(t, err, name)
+	}
+
+	_, err := os.Stat(filepath.Join(photosDir, "Humor"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestMergeTags_StorageErrorMovesFoldersBack(t *testing.T) {
+	setupPhotos(t)
+
+	responseBody := doRequest(t, &MockTagMerger{err: storage.ErrComixExists}, map[string]interface{}{
+		"password":  "password",
+		"tagName":   "Humor",
+		"targetTag": "Comedy",
+	})
+
+	assert.Equal(t, http.StatusBadRequest, responseBody.Status)
+
+	for _, name := range []string{"first", "second"} {
+		_, err := os.Stat(filepath.Join(photosDir, "Humor", name))
+		assert.NoError(t, err, name)
+	}
+
+	_, err := os.Stat(filepath.Join(photosDir, "Comedy", "first"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestMergeTags_InvalidRequest(t *testing.T) {
+	requestsBody := []map[string]interface{}{
+		{"password": "password", "tagName": "Humor", "targetTag": "humor"},
+		{"password": "password", "tagName": "Humor"},
+		{"password": "wrong_password", "tagName": "Humor", "targetTag": "Comedy"},
+		{"password": "password", "tagName": "Humor", "targetTag": ".."},
+	}
+
+	setupPhotos(t)
+
+	for _, m := range requestsBody {
+		responseBody := doRequest(t, &MockTagMerger{}, m)
+
+		assert.Equal(t, http.StatusBadRequest, responseBody.Status)
+	}
+}
@@ -0,0 +1,109 @@
+package rate_comix
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator"
+	"jadesheart/comix_back/internal/http-server/middleware/session"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// Request - review необязателен, пустой отзыв убирает прежний текст и оставляет оценку
+type Request struct {
+	Score  int    `json:"score" validate:"required,min=1,max=5"`
+	Review string `json:"review" validate:"max=5000"`
+}
+
+type Response struct {
+	Status int                    `json:"status,omitempty"`
+	Error  string                 `json:"error,omitempty"`
+	Rating postgres.RatingSummary `json:"rating"`
+}
+
+type ComixRater interface {
+	GetComixBySlug(comixSlug string) (postgres.ComixFromAllComix, error)
+	RateComix(readerID int, comixID int, score int, review string) (postgres.RatingSummary, error)
+}
+
+// New ставит или меняет оценку читателя запроса комиксу {slug} и отдаёт новую сводку оценок.
+func New(log *slog.Logger, comixRater ComixRater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.rate_comix.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("failed decode request json", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed decode request json"))
+
+			return
+		}
+
+		req.Review = strings.TrimSpace(req.Review)
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Error("failed validate", sl.Err(err))
+
+			render.JSON(w, r, resp.ValidateErrors(validateErr))
+
+			return
+		}
+
+		reader, _ := session.Reader(r.Context())
+
+		comix, err := comixRater.GetComixBySlug(chi.URLParam(r, "slug"))
+		if errors.Is(err, storage.ErrComixNotFound) {
+			render.JSON(w, r, resp.Error("Comix not exists"))
+
+			return
+		}
+		if err != nil {
+			log.Error("Cannot get comix from bd", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("Cannot get comix from bd"))
+
+			return
+		}
+
+		summary, err := comixRater.RateComix(reader.ID, comix.ID, req.Score, req.Review)
+		if errors.Is(err, storage.ErrComixNotFound) {
+			render.JSON(w, r, resp.Error("Comix not exists"))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed rate comix", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed rate comix"))
+
+			return
+		}
+
+		responseOK(w, r, summary)
+
+	}
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request, summary postgres.RatingSummary) {
+	render.JSON(w, r, Response{
+		Status: resp.StatusOK,
+		Rating: summary,
+	})
+}
@@ -0,0 +1,112 @@
+package rate_comix_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/rate_comix"
+	"jadesheart/comix_back/internal/http-server/middleware/session"
+	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type ResponseMock struct {
+	Status int                    `json:"status,omitempty"`
+	Error  string                 `json:"error,omitempty"`
+	Rating postgres.RatingSummary `json:"rating"`
+}
+
+type rated struct {
+	readerID int
+	comixID  int
+	score    int
+	review   string
+}
+
+type MockComixRater struct {
+	rated []rated
+}
+
+func (m *MockComixRater) GetComixBySlug(comixSlug string) (postgres.ComixFromAllComix, error) {
+	if comixSlug == "watchmen" {
+		return postgres.ComixFromAllComix{ID: 3, Slug: "watchmen"}, nil
+	}
+	return postgres.ComixFromAllComix{}, storage.ErrComixNotFound
+}
+
+func (m *MockComixRater) RateComix(readerID int, comixID int, score int, review string) (postgres.RatingSummary, error) {
+	m.rated = append(m.rated, rated{readerID, comixID, score, review})
+
+	histogram := []int64{0, 0, 0, 0, 1}
+	histogram[score-1]++
+
+	return postgres.RatingSummary{Average: float64(score+5) / 2, Votes: 2, Histogram: histogram}, nil
+}
+
+func doRequest(t *testing.T, rater *MockComixRater, comixSlug string, body map[string]interface{}) ResponseMock {
+	router := chi.NewRouter()
+	router.With(session.Required).Put("/api/me/ratings/{slug}", rate_comix.New(slogdiscard.NewDiscardLogger(), rater))
+
+	jsonBody, _ := json.Marshal(body)
+
+	req, err := http.NewRequest("PUT", "/api/me/ratings/"+comixSlug, bytes.NewBuffer(jsonBody))
+	assert.NoError(t, err)
+	req = req.WithContext(session.WithReader(req.Context(), postgres.Reader{ID: 5, Username: "reader"}))
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	var responseBody ResponseMock
+
+	if err := json.Unmarshal(rr.Body.Bytes(), &responseBody); err != nil {
+		t.Fatalf("Ошибка при распоковке JSON: %s", err)
+	}
+
+	return responseBody
+}
+
+func TestRateComix_Success(t *testing.T) {
+	rater := &MockComixRater{}
+
+	responseBody := doRequest(t, rater, "watchmen", map[string]interface{}{"score": 4, "review": "  Dense, but worth it  "})
+
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+	assert.Equal(t, 4.5, responseBody.Rating.Average)
+	assert.Equal(t, []int64{0, 0, 0, 1, 1}, responseBody.Rating.Histogram)
+	assert.Equal(t, []rated{{readerID: 5, comixID: 3, score: 4, review: "Dense, but worth it"}}, rater.rated)
+
+	responseBody = doRequest(t, rater, "watchmen", map[string]interface{}{"score": 1})
+
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+	assert.Equal(t, "", rater.rated[1].review)
+}
+
+func TestRateComix_Errors(t *testing.T) {
+	cases := []struct {
+		slug  string
+		body  map[string]interface{}
+		error string
+	}{
+		{"watchmen", map[string]interface{}{}, "Score is not valid"},
+		{"watchmen", map[string]interface{}{"score": 6}, "Score is not valid"},
+		{"watchmen", map[string]interface{}{"score": -1}, "Score is not valid"},
+		{"watchmen", map[string]interface{}{"score": 3, "review": strings.Repeat("a", 5001)}, "Review is not valid"},
+		{"unknown", map[string]interface{}{"score": 3}, "Comix not exists"},
+	}
+
+	for _, c := range cases {
+		rater := &MockComixRater{}
+
+		responseBody := doRequest(t, rater, c.slug, c.body)
+
+		assert.Equal(t, http.StatusBadRequest, responseBody.Status)
+		assert.Equal(t, c.error, responseBody.Error)
+		assert.Empty(t, rater.rated)
+	}
+}
@@ -0,0 +1,124 @@
+package register_reader
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator"
+	"jadesheart/comix_back/internal/lib/account"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"time"
+)
+
+// usernamePattern - имя читателя: латиница, цифры и подчёркивание
+var usernamePattern = regexp.MustCompile(`^[a-zA-Z0-9_]{3,32}$`)
+
+type Request struct {
+	Username string `json:"username" validate:"required"`
+	Password string `json:"password" validate:"required,min=8,max=128"`
+}
+
+type Response struct {
+	Status   int    `json:"status,omitempty"`
+	Error    string `json:"error,omitempty"`
+	Username string `json:"username,omitempty"`
+	Token    string `json:"token,omitempty"`
+}
+
+type ReaderCreator interface {
+	CreateReader(username string, passwordHash string) (postgres.Reader, error)
+	CreateSession(readerID int, tokenHash string, ttl time.Duration) error
+}
+
+// New регистрирует читателя и сразу открывает ему сессию на sessionTTL.
+func New(log *slog.Logger, readerCreator ReaderCreator, sessionTTL time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.register_reader.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("failed decode request json", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed decode request json"))
+
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Error("failed validate", sl.Err(err))
+
+			render.JSON(w, r, resp.ValidateErrors(validateErr))
+
+			return
+		}
+
+		if !usernamePattern.MatchString(req.Username) {
+			render.JSON(w, r, resp.Error("username must be 3-32 latin letters, digits or underscores"))
+
+			return
+		}
+
+		passwordHash, err := account.HashPassword(req.Password)
+		if err != nil {
+			log.Error("failed hash password", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed create reader"))
+
+			return
+		}
+
+		reader, err := readerCreator.CreateReader(req.Username, passwordHash)
+		if errors.Is(err, storage.ErrReaderExists) {
+			render.JSON(w, r, resp.Error("username is taken"))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed create reader", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed create reader"))
+
+			return
+		}
+
+		token, tokenHash, err := account.NewToken()
+		if err == nil {
+			err = readerCreator.CreateSession(reader.ID, tokenHash, sessionTTL)
+		}
+		if err != nil {
+			log.Error("failed create session", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed create session"))
+
+			return
+		}
+
+		log.Info("reader registered", slog.Int("reader_id", reader.ID))
+
+		responseOK(w, r, reader.Username, token)
+
+	}
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request, username string, token string) {
+	render.JSON(w, r, Response{
+		Status:   resp.StatusOK,
+		Username: username,
+		Token:    token,
+	})
+}
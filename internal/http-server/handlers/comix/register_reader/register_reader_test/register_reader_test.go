@@ -0,0 +1,103 @@
+package register_reader_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/register_reader"
+	"jadesheart/comix_back/internal/lib/account"
+	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type ResponseMock struct {
+	Status   int    `json:"status,omitempty"`
+	Error    string `json:"error,omitempty"`
+	Username string `json:"username,omitempty"`
+	Token    string `json:"token,omitempty"`
+}
+
+type MockReaderCreator struct {
+	passwordHash string
+	tokenHash    string
+	ttl          time.Duration
+}
+
+func (m *MockReaderCreator) CreateReader(username string, passwordHash string) (postgres.Reader, error) {
+	if strings.EqualFold(username, "taken") {
+		return postgres.Reader{}, storage.ErrReaderExists
+	}
+	m.passwordHash = passwordHash
+	return postgres.Reader{ID: 1, Username: username}, nil
+}
+
+func (m *MockReaderCreator) CreateSession(readerID int, tokenHash string, ttl time.Duration) error {
+	m.tokenHash = tokenHash
+	m.ttl = ttl
+	return nil
+}
+
+func doRequest(t *testing.T, creator *MockReaderCreator, body map[string]interface{}) ResponseMock {
+	handler := register_reader.New(slogdiscard.NewDiscardLogger(), creator, time.Hour)
+
+	jsonBody, _ := json.Marshal(body)
+
+	req, err := http.NewRequest("POST", "/api/readers/register", bytes.NewBuffer(jsonBody))
+	assert.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	var responseBody ResponseMock
+
+	if err := json.Unmarshal(rr.Body.Bytes(), &responseBody); err != nil {
+		t.Fatalf("Ошибка при распоковке JSON: %s", err)
+	}
+
+	return responseBody
+}
+
+func TestRegisterReader_Success(t *testing.T) {
+	creator := &MockReaderCreator{}
+
+	responseBody := doRequest(t, creator, map[string]interface{}{"username": "night_owl", "password": "long password"})
+
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+	assert.Equal(t, "night_owl", responseBody.Username)
+	assert.NotEmpty(t, responseBody.Token)
+	assert.Equal(t, account.HashToken(responseBody.Token), creator.tokenHash)
+	assert.Equal(t, time.Hour, creator.ttl)
+
+	ok, err := account.CheckPassword(creator.passwordHash, "long password")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestRegisterReader_InvalidRequest(t *testing.T) {
+	cases := []struct {
+		body  map[string]interface{}
+		error string
+	}{
+		{map[string]interface{}{"username": "night_owl", "password": "short"}, "Password is not valid"},
+		{map[string]interface{}{"username": "no", "password": "long password"}, "username must be 3-32 latin letters, digits or underscores"},
+		{map[string]interface{}{"username": "night owl", "password": "long password"}, "username must be 3-32 latin letters, digits or underscores"},
+		{map[string]interface{}{"username": "Taken", "password": "long password"}, "username is taken"},
+	}
+
+	for _, c := range cases {
+		creator := &MockReaderCreator{}
+
+		responseBody := doRequest(t, creator, c.body)
+
+		assert.Equal(t, http.StatusBadRequest, responseBody.Status)
+		assert.Equal(t, c.error, responseBody.Error)
+		assert.Empty(t, responseBody.Token)
+		assert.Empty(t, creator.tokenHash)
+	}
+}
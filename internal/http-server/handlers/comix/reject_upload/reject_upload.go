@@ -0,0 +1,173 @@
+package reject_upload
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator"
+	"jadesheart/comix_back/internal/http-server/middleware/ratelimit"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/audit"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/lib/photos"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Что можно отклонить
+const (
+	KindComix = "comix"
+	KindPage  = "page"
+)
+
+// Request - id - id комикса на проверке или страницы в очереди модерации, reason - причина отказа для журнала
+type Request struct {
+	Password string `json:"password" validate:"required"`
+	Kind     string `json:"kind" validate:"required,oneof=comix page"`
+	ID       int    `json:"id" validate:"required,min=1"`
+	Reason   string `json:"reason" validate:"required,max=1000"`
+}
+
+type Response struct {
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type UploadRejecter interface {
+	RejectComix(id int) (postgres.PendingComix, error)
+	RejectPendingPage(id int) (postgres.PendingPage, error)
+	CheckPass(inputPass string) (bool, error)
+	AddAuditEvent(event postgres.AuditEvent) error
+}
+
+// New отклоняет комикс или страницу из очереди модерации. Строки удаляются из базы, файлы - с диска:
+// у комикса вся папка, у страницы только её файл.
+func New(log *slog.Logger, uploadRejecter UploadRejecter, mediaRoot *photos.Root) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.reject_upload.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("failed decode request json", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed decode request json"))
+
+			return
+		}
+
+		req.Reason = strings.TrimSpace(req.Reason)
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Error("failed validate", sl.Err(err))
+
+			render.JSON(w, r, resp.ValidateErrors(validateErr))
+
+			return
+		}
+
+		res, err := uploadRejecter.CheckPass(req.Password)
+		if err != nil {
+			log.Error("failed password verified", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed password verified"))
+
+			return
+		}
+
+		if !res {
+			log.Warn("incorrect password", slog.String("remote_addr", ratelimit.ClientIP(r)))
+
+			ratelimit.AuthFailed(r.Context())
+
+			render.JSON(w, r, resp.Error("incorrect password"))
+
+			return
+		}
+
+		ratelimit.AuthSucceeded(r.Context())
+
+		if req.Kind == KindComix {
+			comix, err := uploadRejecter.RejectComix(req.ID)
+			if errors.Is(err, storage.ErrComixNotFound) {
+				render.JSON(w, r, resp.Error("Comix is not pending review"))
+
+				return
+			}
+			if err != nil {
+				log.Error("Cannot reject comix", sl.Err(err))
+
+				render.JSON(w, r, resp.Error("Cannot reject comix"))
+
+				return
+			}
+
+			comixDir, err := mediaRoot.ComixDir(comix.ComixTag, comix.ComixName)
+			if err == nil {
+				err = os.RemoveAll(comixDir)
+			}
+			if err != nil {
+				log.Error("failed remove comix photo folder", sl.Err(err))
+			}
+
+			err = uploadRejecter.AddAuditEvent(audit.NewEvent(r, audit.ActionComixReject, audit.ComixTarget(comix.ComixTag, comix.ComixName),
+				comix, map[string]string{"reason": req.Reason}))
+			if err != nil {
+				log.Error("failed write audit event", sl.Err(err))
+			}
+
+			responseOK(w, r)
+
+			return
+		}
+
+		page, err := uploadRejecter.RejectPendingPage(req.ID)
+		if errors.Is(err, storage.ErrPageNotFound) {
+			render.JSON(w, r, resp.Error("Page is not pending review"))
+
+			return
+		}
+		if err != nil {
+			log.Error("Cannot reject page", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("Cannot reject page"))
+
+			return
+		}
+
+		pagePath, err := mediaRoot.ComixFile(page.ComixTag, page.ComixName, page.File)
+		if err == nil {
+			err = os.Remove(pagePath)
+		}
+		if err != nil && !os.IsNotExist(err) {
+			log.Error("failed remove page file", sl.Err(err))
+		}
+
+		err = uploadRejecter.AddAuditEvent(audit.NewEvent(r, audit.ActionPageReject, audit.ComixTarget(page.ComixTag, page.ComixName),
+			page, map[string]string{"reason": req.Reason}))
+		if err != nil {
+			log.Error("failed write audit event", sl.Err(err))
+		}
+
+		responseOK(w, r)
+
+	}
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request) {
+	render.JSON(w, r, Response{
+		Status: resp.StatusOK,
+	})
+}
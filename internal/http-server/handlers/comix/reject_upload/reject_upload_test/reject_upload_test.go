@@ -0,0 +1,151 @@
+package reject_upload_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/reject_upload"
+	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"jadesheart/comix_back/internal/lib/photos"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type ResponseMock struct {
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type MockUploadRejecter struct {
+	events []postgres.AuditEvent
+}
+
+func (m *MockUploadRejecter) RejectComix(id int) (postgres.PendingComix, error) {
+	if id != 3 {
+		return postgres.PendingComix{}, storage.ErrComixNotFound
+	}
+	return postgres.PendingComix{ComixFromAllComix: postgres.ComixFromAllComix{ID: 3, ComixTag: "horror", ComixName: "Watchmen"}}, nil
+}
+
+func (m *MockUploadRejecter) RejectPendingPage(id int) (postgres.PendingPage, error) {
+	if id != 9 {
+		return postgres.PendingPage{}, storage.ErrPageNotFound
+	}
+	return postgres.PendingPage{ID: 9, ComixID: 4, ComixTag: "horror", ComixName: "Hellboy", File: "new.jpg"}, nil
+}
+
+func (m *MockUploadRejecter) CheckPass(inputPass string) (bool, error) {
+	return inputPass == "password", nil
+}
+
+func (m *MockUploadRejecter) AddAuditEvent(event postgres.AuditEvent) error {
+	m.events = append(m.events, event)
+	return nil
+}
+
+// setup создаёт папку медиа с комиксом на проверке Watchmen и комиксом Hellboy, у которого страница new.jpg на проверке
+func setup(t *testing.T) *photos.Root {
+	dir := t.TempDir()
+
+	for _, name := range []string{"Watchmen", "Hellboy"} {
+		comixDir := filepath.Join(dir, "horror", name)
+		assert.NoError(t, os.MkdirAll(comixDir, 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(comixDir, "old.jpg"), []byte("page"), 0644))
+	}
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "horror", "Hellboy", "new.jpg"), []byte("page"), 0644))
+
+	mediaRoot, err := photos.NewRoot(dir)
+	assert.NoError(t, err)
+
+	return mediaRoot
+}
+
+func doRequest(t *testing.T, rejecter *MockUploadRejecter, mediaRoot *photos.Root, body map[string]interface{}) ResponseMock {
+	handler := reject_upload.New(slogdiscard.NewDiscardLogger(), rejecter, mediaRoot)
+
+	jsonBody, _ := json.Marshal(body)
+
+	req, err := http.NewRequest("POST", "/moderation/reject", bytes.NewBuffer(jsonBody))
+	assert.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	var responseBody ResponseMock
+
+	if err := json.Unmarshal(rr.Body.Bytes(), &responseBody); err != nil {
+		t.Fatalf("Ошибка при распоковке JSON: %s", err)
+	}
+
+	return responseBody
+}
+
+func TestRejectUpload_Comix(t *testing.T) {
+	mediaRoot := setup(t)
+	rejecter := &MockUploadRejecter{}
+
+	responseBody := doRequest(t, rejecter, mediaRoot, map[string]interface{}{
+		"password": "password", "kind": "comix", "id": 3, "reason": "duplicate",
+	})
+
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+
+	_, err := os.Stat(filepath.Join(mediaRoot.Dir(), "horror", "Watchmen"))
+	assert.True(t, os.IsNotExist(err))
+
+	assert.Len(t, rejecter.events, 1)
+	assert.Equal(t, "comix.reject", rejecter.events[0].Action)
+	assert.Equal(t, "comix:horror/Watchmen", rejecter.events[0].Target)
+	assert.Contains(t, string(rejecter.events[0].After), "duplicate")
+}
+
+func TestRejectUpload_Page(t *testing.T) {
+	mediaRoot := setup(t)
+	rejecter := &MockUploadRejecter{}
+
+	responseBody := doRequest(t, rejecter, mediaRoot, map[string]interface{}{
+		"password": "password", "kind": "page", "id": 9, "reason": "wrong comix",
+	})
+
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+
+	// Убран только файл отклонённой страницы
+	_, err := os.Stat(filepath.Join(mediaRoot.Dir(), "horror", "Hellboy", "new.jpg"))
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(mediaRoot.Dir(), "horror", "Hellboy", "old.jpg"))
+	assert.NoError(t, err)
+
+	assert.Len(t, rejecter.events, 1)
+	assert.Equal(t, "comix.pages.reject", rejecter.events[0].Action)
+}
+
+func TestRejectUpload_InvalidRequest(t *testing.T) {
+	requestsBody := []map[string]interface{}{
+		{"kind": "comix", "id": 3, "reason": "duplicate"},
+		{"password": "password", "kind": "comix", "id": 3},
+		{"password": "password", "kind": "comix", "id": 3, "reason": "  "},
+		{"password": "password", "kind": "tag", "id": 3, "reason": "duplicate"},
+		{"password": "password", "kind": "comix", "reason": "duplicate"},
+		{"password": "wrong_password", "kind": "comix", "id": 3, "reason": "duplicate"},
+		{"password": "password", "kind": "comix", "id": 4, "reason": "duplicate"},
+		{"password": "password", "kind": "page", "id": 3, "reason": "duplicate"},
+	}
+
+	mediaRoot := setup(t)
+	rejecter := &MockUploadRejecter{}
+
+	for _, body := range requestsBody {
+		responseBody := doRequest(t, rejecter, mediaRoot, body)
+		assert.Equal(t, http.StatusBadRequest, responseBody.Status, body)
+	}
+
+	assert.Empty(t, rejecter.events)
+
+	_, err := os.Stat(filepath.Join(mediaRoot.Dir(), "horror", "Watchmen", "old.jpg"))
+	assert.NoError(t, err)
+}
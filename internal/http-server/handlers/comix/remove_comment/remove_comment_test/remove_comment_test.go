@@ -0,0 +1,115 @@
+package remove_comment_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/remove_comment"
+	"jadesheart/comix_back/internal/http-server/middleware/ratelimit"
+	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type ResponseMock struct {
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type MockCommentRemover struct {
+	events []postgres.AuditEvent
+}
+
+func (m *MockCommentRemover) RemoveComment(id int64) (postgres.Comment, error) {
+	if id != 7 {
+		return postgres.Comment{}, storage.ErrCommentNotFound
+	}
+	return postgres.Comment{ID: 7, Author: "troll", Body: "spam"}, nil
+}
+
+func (m *MockCommentRemover) CheckPass(inputPass string) (bool, error) {
+	return inputPass == "password", nil
+}
+
+func (m *MockCommentRemover) AddAuditEvent(event postgres.AuditEvent) error {
+	m.events = append(m.events, event)
+	return nil
+}
+
+func doRequest(t *testing.T, remover *MockCommentRemover, body map[string]interface{}) ResponseMock {
+	handler := remove_comment.New(slogdiscard.NewDiscardLogger(), remover)
+
+	jsonBody, _ := json.Marshal(body)
+
+	req, err := http.NewRequest("POST", "/removecomment", bytes.NewBuffer(jsonBody))
+	assert.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	var responseBody ResponseMock
+
+	if err := json.Unmarshal(rr.Body.Bytes(), &responseBody); err != nil {
+		t.Fatalf("Ошибка при распоковке JSON: %s", err)
+	}
+
+	return responseBody
+}
+
+func TestRemoveComment_Success(t *testing.T) {
+	remover := &MockCommentRemover{}
+
+	responseBody := doRequest(t, remover, map[string]interface{}{"password": "password", "commentId": 7})
+
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+	assert.Len(t, remover.events, 1)
+	assert.Equal(t, "comment.remove", remover.events[0].Action)
+	assert.Equal(t, "comment:7", remover.events[0].Target)
+	assert.Contains(t, string(remover.events[0].Before), "spam")
+}
+
+func TestRemoveComment_AuditsClientBehindProxy(t *testing.T) {
+	remover := &MockCommentRemover{}
+
+	proxies, err := ratelimit.ParseProxies("X-Forwarded-For", []string{"10.0.0.0/8"})
+	assert.NoError(t, err)
+
+	limiter := ratelimit.NewLimiter(time.Minute, time.Hour, 0)
+	limiter.TrustProxies(proxies)
+
+	handler := ratelimit.New(slogdiscard.NewDiscardLogger(), limiter, ratelimit.Budget{Name: "write", PerMinute: 60, Burst: 60, Protected: true})(
+		remove_comment.New(slogdiscard.NewDiscardLogger(), remover))
+
+	jsonBody, _ := json.Marshal(map[string]interface{}{"password": "password", "commentId": 7})
+
+	req := httptest.NewRequest("POST", "/removecomment", bytes.NewBuffer(jsonBody))
+	req.RemoteAddr = "10.0.0.1:1000"
+	req.Header.Set("X-Forwarded-For", "203.0.113.5")
+
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Len(t, remover.events, 1)
+	assert.Equal(t, "203.0.113.5", remover.events[0].RemoteAddr)
+}
+
+func TestRemoveComment_InvalidRequest(t *testing.T) {
+	requestsBody := []map[string]interface{}{
+		{"commentId": 7},
+		{"password": "password"},
+		{"password": "wrong_password", "commentId": 7},
+		{"password": "password", "commentId": 8},
+	}
+
+	for _, m := range requestsBody {
+		remover := &MockCommentRemover{}
+
+		responseBody := doRequest(t, remover, m)
+
+		assert.Equal(t, http.StatusBadRequest, responseBody.Status)
+		assert.Empty(t, remover.events)
+	}
+}
@@ -0,0 +1,123 @@
+package reorder_pages
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator"
+	"jadesheart/comix_back/internal/http-server/middleware/ratelimit"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/audit"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+)
+
+// Request - pageIds перечисляет все страницы комикса в новом порядке
+type Request struct {
+	Password string `json:"password" validate:"required"`
+	TagName  string `json:"tagName" validate:"required"`
+	Name     string `json:"name" validate:"required"`
+	PageIDs  []int  `json:"pageIds" validate:"required,min=1"`
+}
+
+type Response struct {
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type PagesReorderer interface {
+	ReorderPages(tagName string, name string, pageIDs []int) error
+	CheckPass(inputPass string) (bool, error)
+	AddAuditEvent(event postgres.AuditEvent) error
+}
+
+func New(log *slog.Logger, pagesReorderer PagesReorderer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.reorder_pages.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("failed decode request json", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed decode request json"))
+
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Error("failed validate", sl.Err(err))
+
+			render.JSON(w, r, resp.ValidateErrors(validateErr))
+
+			return
+		}
+
+		res, err := pagesReorderer.CheckPass(req.Password)
+		if err != nil {
+			log.Error("failed password verified", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed password verified"))
+
+			return
+		}
+
+		if !res {
+			log.Warn("incorrect password", slog.String("remote_addr", ratelimit.ClientIP(r)))
+
+			ratelimit.AuthFailed(r.Context())
+
+			render.JSON(w, r, resp.Error("incorrect password"))
+
+			return
+		}
+
+		ratelimit.AuthSucceeded(r.Context())
+
+		err = pagesReorderer.ReorderPages(req.TagName, req.Name, req.PageIDs)
+		if errors.Is(err, storage.ErrComixNotFound) {
+			render.JSON(w, r, resp.Error("Comix not exists"))
+
+			return
+		}
+		if errors.Is(err, storage.ErrPagesMismatch) {
+			render.JSON(w, r, resp.Error("pageIds must list every page of the comix exactly once"))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed reorder pages", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed reorder pages"))
+
+			return
+		}
+
+		err = pagesReorderer.AddAuditEvent(audit.NewEvent(r, audit.ActionPagesReorder, audit.ComixTarget(req.TagName, req.Name), nil, map[string]interface{}{
+			"pageIds": req.PageIDs,
+		}))
+		if err != nil {
+			log.Error("failed write audit event", sl.Err(err))
+		}
+
+		responseOK(w, r)
+
+	}
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request) {
+	render.JSON(w, r, Response{
+		Status: resp.StatusOK,
+	})
+}
@@ -0,0 +1,85 @@
+package reorder_pages_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/reorder_pages"
+	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type ResponseMock struct {
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type MockPagesReorderer struct {
+	pageIDs []int
+}
+
+func (m *MockPagesReorderer) ReorderPages(tagName string, name string, pageIDs []int) error {
+	if len(pageIDs) != 3 {
+		return storage.ErrPagesMismatch
+	}
+	m.pageIDs = pageIDs
+	return nil
+}
+
+func (m *MockPagesReorderer) CheckPass(inputPass string) (bool, error) {
+	return inputPass == "password", nil
+}
+
+func (m *MockPagesReorderer) AddAuditEvent(event postgres.AuditEvent) error {
+	return nil
+}
+
+func doRequest(t *testing.T, reorderer *MockPagesReorderer, body map[string]interface{}) ResponseMock {
+	jsonBody, _ := json.Marshal(body)
+
+	req, err := http.NewRequest("POST", "/reorderpages", bytes.NewBuffer(jsonBody))
+	assert.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	reorder_pages.New(slogdiscard.NewDiscardLogger(), reorderer).ServeHTTP(rr, req)
+
+	var responseBody ResponseMock
+
+	if err := json.Unmarshal(rr.Body.Bytes(), &responseBody); err != nil {
+		t.Fatalf("Ошибка при распоковке JSON: %s", err)
+	}
+
+	return responseBody
+}
+
+func TestReorderPages_Success(t *testing.T) {
+	reorderer := &MockPagesReorderer{}
+
+	responseBody := doRequest(t, reorderer, map[string]interface{}{
+		"password": "password",
+		"tagName":  "Horror",
+		"name":     "Watchmen",
+		"pageIds":  []int{3, 1, 2},
+	})
+
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+	assert.Equal(t, []int{3, 1, 2}, reorderer.pageIDs)
+}
+
+func TestReorderPages_InvalidRequest(t *testing.T) {
+	cases := []map[string]interface{}{
+		{"password": "password", "tagName": "Horror", "name": "Watchmen", "pageIds": []int{}},
+		{"password": "password", "tagName": "Horror", "name": "Watchmen", "pageIds": []int{1, 2}},
+		{"password": "wrong_password", "tagName": "Horror", "name": "Watchmen", "pageIds": []int{3, 1, 2}},
+	}
+
+	for _, c := range cases {
+		responseBody := doRequest(t, &MockPagesReorderer{}, c)
+
+		assert.Equal(t, http.StatusBadRequest, responseBody.Status)
+	}
+}
@@ -0,0 +1,81 @@
+package reorder_reading_list
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator"
+	"jadesheart/comix_back/internal/http-server/middleware/session"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/storage"
+	"log/slog"
+	"net/http"
+)
+
+// Request - comixIds перечисляет все комиксы списка в новом порядке
+type Request struct {
+	ComixIDs []int `json:"comixIds" validate:"required,min=1"`
+}
+
+type ReadingListReorderer interface {
+	ReorderReadingList(readerID int, listSlug string, comixIDs []int) error
+}
+
+// New задаёт новый порядок комиксов в списке {slug} читателя запроса.
+func New(log *slog.Logger, readingListReorderer ReadingListReorderer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.reorder_reading_list.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("failed decode request json", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed decode request json"))
+
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Error("failed validate", sl.Err(err))
+
+			render.JSON(w, r, resp.ValidateErrors(validateErr))
+
+			return
+		}
+
+		reader, _ := session.Reader(r.Context())
+
+		err = readingListReorderer.ReorderReadingList(reader.ID, chi.URLParam(r, "slug"), req.ComixIDs)
+		if errors.Is(err, storage.ErrListNotFound) {
+			render.JSON(w, r, resp.Error("List not exists"))
+
+			return
+		}
+		if errors.Is(err, storage.ErrListMismatch) {
+			render.JSON(w, r, resp.Error("comixIds must list every comix of the list exactly once"))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed reorder reading list", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed reorder reading list"))
+
+			return
+		}
+
+		render.JSON(w, r, resp.OK())
+
+	}
+}
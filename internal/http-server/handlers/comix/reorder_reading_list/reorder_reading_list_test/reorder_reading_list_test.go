@@ -0,0 +1,92 @@
+package reorder_reading_list_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/reorder_reading_list"
+	"jadesheart/comix_back/internal/http-server/middleware/session"
+	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type ResponseMock struct {
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// MockReadingListReorderer - у читателя 1 есть список "favorites" с комиксами 1, 2 и 3
+type MockReadingListReorderer struct {
+	order []int
+}
+
+func (m *MockReadingListReorderer) ReorderReadingList(readerID int, listSlug string, comixIDs []int) error {
+	if readerID != 1 || listSlug != "favorites" {
+		return storage.ErrListNotFound
+	}
+	if len(comixIDs) != 3 {
+		return storage.ErrListMismatch
+	}
+	m.order = comixIDs
+	return nil
+}
+
+func doRequest(t *testing.T, reorderer *MockReadingListReorderer, listSlug string, readerID int, body map[string]interface{}) ResponseMock {
+	router := chi.NewRouter()
+	router.Put("/api/me/lists/{slug}/order", reorder_reading_list.New(slogdiscard.NewDiscardLogger(), reorderer))
+
+	jsonBody, _ := json.Marshal(body)
+
+	req, err := http.NewRequest("PUT", "/api/me/lists/"+listSlug+"/order", bytes.NewBuffer(jsonBody))
+	assert.NoError(t, err)
+	req = req.WithContext(session.WithReader(req.Context(), postgres.Reader{ID: readerID}))
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	var responseBody ResponseMock
+
+	if err := json.Unmarshal(rr.Body.Bytes(), &responseBody); err != nil {
+		t.Fatalf("Ошибка при распоковке JSON: %s", err)
+	}
+
+	return responseBody
+}
+
+func TestReorderReadingList_Success(t *testing.T) {
+	reorderer := &MockReadingListReorderer{}
+
+	responseBody := doRequest(t, reorderer, "favorites", 1, map[string]interface{}{"comixIds": []int{3, 1, 2}})
+
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+	assert.Equal(t, []int{3, 1, 2}, reorderer.order)
+}
+
+func TestReorderReadingList_Errors(t *testing.T) {
+	cases := []struct {
+		slug     string
+		readerID int
+		body     map[string]interface{}
+		error    string
+	}{
+		{"favorites", 1, map[string]interface{}{}, "ComixIDs is not valid"},
+		{"favorites", 1, map[string]interface{}{"comixIds": []int{3, 1}}, "comixIds must list every comix of the list exactly once"},
+		{"favorites", 2, map[string]interface{}{"comixIds": []int{3, 1, 2}}, "List not exists"},
+		{"unknown", 1, map[string]interface{}{"comixIds": []int{3, 1, 2}}, "List not exists"},
+	}
+
+	for _, c := range cases {
+		reorderer := &MockReadingListReorderer{}
+
+		responseBody := doRequest(t, reorderer, c.slug, c.readerID, c.body)
+
+		assert.Equal(t, http.StatusBadRequest, responseBody.Status)
+		assert.Equal(t, c.error, responseBody.Error)
+		assert.Nil(t, reorderer.order)
+	}
+}
@@ -0,0 +1,203 @@
+package replace_page
+
+import (
+	"bytes"
+	"errors"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"jadesheart/comix_back/internal/http-server/middleware/ratelimit"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/audit"
+	"jadesheart/comix_back/internal/lib/images"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/lib/photos"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// maxFieldsSize - запас на текстовые поля и разметку multipart сверх самой картинки
+const maxFieldsSize = 1 << 20
+
+var tagNamePattern = regexp.MustCompile(`^[a-zA-Z]+$`)
+
+type Response struct {
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+	File   string `json:"file,omitempty"`
+}
+
+type PageReplacer interface {
+	ReplacePage(tagName string, name string, pageID int, file string) (string, error)
+	CheckPass(inputPass string) (bool, error)
+	AddAuditEvent(event postgres.AuditEvent) error
+}
+
+func New(log *slog.Logger, pageReplacer PageReplacer, mediaRoot *photos.Root, limits images.Limits) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.replace_page.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		r.Body = http.MaxBytesReader(w, r.Body, limits.MaxFileSize+maxFieldsSize)
+
+		tagName := strings.TrimSpace(r.FormValue("tag"))
+		name := r.FormValue("name")
+		password := r.FormValue("password")
+
+		if password == "" || name == "" || !tagNamePattern.MatchString(tagName) {
+			render.JSON(w, r, resp.Error("password, tag and name are required, tag must contain only latin letters"))
+
+			return
+		}
+
+		comixDir, err := mediaRoot.ComixDir(tagName, name)
+		if err != nil {
+			log.Warn("unsafe comix name", sl.Err(err), slog.String("remote_addr", ratelimit.ClientIP(r)))
+
+			render.JSON(w, r, resp.Error("invalid comix name"))
+
+			return
+		}
+
+		pageID, err := strconv.Atoi(r.FormValue("pageId"))
+		if err != nil || pageID < 1 {
+			render.JSON(w, r, resp.Error("pageId must be a positive number"))
+
+			return
+		}
+
+		file, header, err := r.FormFile("photo")
+		if err != nil {
+			log.Error("failed get page file", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("photo file is required"))
+
+			return
+		}
+		defer file.Close()
+
+		if header.Size > limits.MaxFileSize {
+			render.JSON(w, r, resp.Error("file is too large: "+header.Filename))
+
+			return
+		}
+
+		res, err := pageReplacer.CheckPass(password)
+		if err != nil {
+			log.Error("failed password verified", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed password verified"))
+
+			return
+		}
+
+		if !res {
+			log.Warn("incorrect password", slog.String("remote_addr", ratelimit.ClientIP(r)))
+
+			ratelimit.AuthFailed(r.Context())
+
+			render.JSON(w, r, resp.Error("incorrect password"))
+
+			return
+		}
+
+		ratelimit.AuthSucceeded(r.Context())
+
+		_, err = os.Stat(comixDir)
+		if err != nil {
+			log.Error("failed get comix directory", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("Directory does not exist, check if you created the comix?"))
+
+			return
+		}
+
+		img, err := images.Sanitize(file, limits)
+		if err != nil {
+			log.Warn("rejected page file", sl.Err(err), slog.String("file", header.Filename))
+
+			render.JSON(w, r, resp.Error(header.Filename+": "+err.Error()))
+
+			return
+		}
+
+		pageFile, err := photos.NewPageName(img.Ext)
+		if err != nil {
+			log.Error("failed make page name", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed write file"))
+
+			return
+		}
+
+		err = photos.WriteFile(comixDir, pageFile, bytes.NewReader(img.Data))
+		if err != nil {
+			log.Error("failed write page file", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed write file"))
+
+			return
+		}
+
+		oldFile, err := pageReplacer.ReplacePage(tagName, name, pageID, pageFile)
+		if err != nil {
+			os.Remove(filepath.Join(comixDir, pageFile))
+		}
+		if errors.Is(err, storage.ErrComixNotFound) {
+			render.JSON(w, r, resp.Error("Comix not exists"))
+
+			return
+		}
+		if errors.Is(err, storage.ErrPageNotFound) {
+			render.JSON(w, r, resp.Error("Page not exists"))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed replace page", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed replace page"))
+
+			return
+		}
+
+		oldPath, err := mediaRoot.Join(comixDir, oldFile)
+		if err == nil {
+			err = os.Remove(oldPath)
+		}
+		if err != nil && !os.IsNotExist(err) {
+			log.Error("failed remove old page file", sl.Err(err))
+		}
+
+		err = pageReplacer.AddAuditEvent(audit.NewEvent(r, audit.ActionPageReplace, audit.ComixTarget(tagName, name), map[string]interface{}{
+			"page": pageID,
+			"file": oldFile,
+		}, map[string]interface{}{
+			"page": pageID,
+			"file": pageFile,
+		}))
+		if err != nil {
+			log.Error("failed write audit event", sl.Err(err))
+		}
+
+		responseOK(w, r, pageFile)
+
+	}
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request, file string) {
+	render.JSON(w, r, Response{
+		Status: resp.StatusOK,
+		File:   file,
+	})
+}
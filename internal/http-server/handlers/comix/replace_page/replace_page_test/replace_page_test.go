@@ -0,0 +1,158 @@
+package replace_page_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"image/png"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/replace_page"
+	"jadesheart/comix_back/internal/lib/images"
+	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"jadesheart/comix_back/internal/lib/photos"
+	"jadesheart/comix_back/internal/lib/photos/photostest"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type ResponseMock struct {
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+	File   string `json:"file,omitempty"`
+}
+
+type MockPageReplacer struct{}
+
+func (m *MockPageReplacer) ReplacePage(tagName string, name string, pageID int, file string) (string, error) {
+	if pageID != 7 {
+		return "", storage.ErrPageNotFound
+	}
+	return "7.jpg", nil
+}
+
+func (m *MockPageReplacer) CheckPass(inputPass string) (bool, error) {
+	return inputPass == "password", nil
+}
+
+func (m *MockPageReplacer) AddAuditEvent(event postgres.AuditEvent) error {
+	return nil
+}
+
+const photosDir = "internal/storage/web/photos"
+
+var limits = images.Limits{MaxFileSize: 1 << 20, MaxRequestSize: 4 << 20, MaxPixels: 1 << 20}
+
+func doRequest(t *testing.T, fields map[string]string, fileName string) ResponseMock {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	for key, value := range fields {
+		assert.NoError(t, writer.WriteField(key, value))
+	}
+
+	if fileName != "" {
+		part, err := writer.CreateFormFile("photo", fileName)
+		assert.NoError(t, err)
+		_, err = part.Write(photostest.FileData(fileName))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, writer.Close())
+
+	req, err := http.NewRequest("POST", "/replacepage", body)
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	rr := httptest.NewRecorder()
+	mediaRoot, err := photos.NewRoot(photosDir)
+	assert.NoError(t, err)
+
+	replace_page.New(slogdiscard.NewDiscardLogger(), &MockPageReplacer{}, mediaRoot, limits).ServeHTTP(rr, req)
+
+	var responseBody ResponseMock
+
+	if err := json.Unmarshal(rr.Body.Bytes(), &responseBody); err != nil {
+		t.Fatalf("Ошибка при распоковке JSON: %s", err)
+	}
+
+	return responseBody
+}
+
+func setupPhotos(t *testing.T) string {
+	wd, err := os.Getwd()
+	assert.NoError(t, err)
+	assert.NoError(t, os.Chdir(t.TempDir()))
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	comixDir := filepath.Join(photosDir, "Horror", "Watchmen")
+	assert.NoError(t, os.MkdirAll(comixDir, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(comixDir, "7.jpg"), []byte("old page"), 0644))
+
+	return comixDir
+}
+
+func TestReplacePage_Success(t *testing.T) {
+	comixDir := setupPhotos(t)
+
+	responseBody := doRequest(t, map[string]string{
+		"password": "password",
+		"tag":      "horror",
+		"name":     "Watchmen",
+		"pageId":   "7",
+	}, "page.png")
+
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+
+	data, err := os.ReadFile(filepath.Join(comixDir, responseBody.File))
+	assert.NoError(t, err)
+	_, err = png.Decode(bytes.NewReader(data))
+	assert.NoError(t, err)
+
+	_, err = os.Stat(filepath.Join(comixDir, "7.jpg"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestReplacePage_PageNotExists(t *testing.T) {
+	comixDir := setupPhotos(t)
+
+	responseBody := doRequest(t, map[string]string{
+		"password": "password",
+		"tag":      "Horror",
+		"name":     "Watchmen",
+		"pageId":   "8",
+	}, "page.jpg")
+
+	assert.Equal(t, http.StatusBadRequest, responseBody.Status)
+	assert.Equal(t, "Page not exists", responseBody.Error)
+
+	// Новый файл убирается, старая страница остаётся на месте
+	entries, err := os.ReadDir(comixDir)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "7.jpg", entries[0].Name())
+}
+
+func TestReplacePage_InvalidRequest(t *testing.T) {
+	setupPhotos(t)
+
+	cases := []struct {
+		fields   map[string]string
+		fileName string
+	}{
+		{map[string]string{"password": "password", "tag": "Horror", "name": "Watchmen", "pageId": "7"}, ""},
+		{map[string]string{"password": "password", "tag": "Horror", "name": "Watchmen", "pageId": "7"}, "fake.jpg"},
+		{map[string]string{"password": "password", "tag": "Horror", "name": "Watchmen", "pageId": "first"}, "page.jpg"},
+		{map[string]string{"password": "password", "tag": "Horror", "name": "../Watchmen", "pageId": "7"}, "page.jpg"},
+		{map[string]string{"password": "wrong_password", "tag": "Horror", "name": "Watchmen", "pageId": "7"}, "page.jpg"},
+	}
+
+	for _, c := range cases {
+		responseBody := doRequest(t, c.fields, c.fileName)
+
+		assert.Equal(t, http.StatusBadRequest, responseBody.Status)
+	}
+}
@@ -0,0 +1,122 @@
+package replay_webhook_delivery
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator"
+	"jadesheart/comix_back/internal/http-server/middleware/ratelimit"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/audit"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+)
+
+type Request struct {
+	Password   string `json:"password" validate:"required"`
+	DeliveryID int64  `json:"deliveryId" validate:"required,min=1"`
+}
+
+type Response struct {
+	Status   int                       `json:"status,omitempty"`
+	Error    string                    `json:"error,omitempty"`
+	Delivery *postgres.WebhookDelivery `json:"delivery,omitempty"`
+}
+
+type DeliveryReplayer interface {
+	ReplayWebhookDelivery(id int64) (postgres.WebhookDelivery, error)
+	CheckPass(inputPass string) (bool, error)
+	AddAuditEvent(event postgres.AuditEvent) error
+}
+
+// New ставит доставку в очередь ещё раз. Старая доставка и её попытки остаются в журнале.
+func New(log *slog.Logger, deliveryReplayer DeliveryReplayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.replay_webhook_delivery.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("failed decode request json", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed decode request json"))
+
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Error("failed validate", sl.Err(err))
+
+			render.JSON(w, r, resp.ValidateErrors(validateErr))
+
+			return
+		}
+
+		res, err := deliveryReplayer.CheckPass(req.Password)
+		if err != nil {
+			log.Error("failed password verified", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed password verified"))
+
+			return
+		}
+
+		if !res {
+			log.Warn("incorrect password", slog.String("remote_addr", ratelimit.ClientIP(r)))
+
+			ratelimit.AuthFailed(r.Context())
+
+			render.JSON(w, r, resp.Error("incorrect password"))
+
+			return
+		}
+
+		ratelimit.AuthSucceeded(r.Context())
+
+		delivery, err := deliveryReplayer.ReplayWebhookDelivery(req.DeliveryID)
+		if errors.Is(err, storage.ErrDeliveryNotFound) {
+			render.JSON(w, r, resp.Error("Delivery not exists"))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed replay webhook delivery", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed replay webhook delivery"))
+
+			return
+		}
+
+		after := map[string]interface{}{
+			"replayOf": req.DeliveryID,
+			"delivery": delivery.ID,
+			"event":    delivery.Event,
+		}
+
+		err = deliveryReplayer.AddAuditEvent(audit.NewEvent(r, audit.ActionWebhookReplay, audit.WebhookTarget(delivery.WebhookID), nil, after))
+		if err != nil {
+			log.Error("failed write audit event", sl.Err(err))
+		}
+
+		responseOK(w, r, delivery)
+
+	}
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request, delivery postgres.WebhookDelivery) {
+	render.JSON(w, r, Response{
+		Status:   resp.StatusOK,
+		Delivery: &delivery,
+	})
+}
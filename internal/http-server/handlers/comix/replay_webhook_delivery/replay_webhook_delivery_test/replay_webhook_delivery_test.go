@@ -0,0 +1,93 @@
+package replay_webhook_delivery_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/replay_webhook_delivery"
+	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type ResponseMock struct {
+	Status   int                       `json:"status,omitempty"`
+	Error    string                    `json:"error,omitempty"`
+	Delivery *postgres.WebhookDelivery `json:"delivery,omitempty"`
+}
+
+type MockDeliveryReplayer struct {
+	events []postgres.AuditEvent
+}
+
+func (m *MockDeliveryReplayer) ReplayWebhookDelivery(id int64) (postgres.WebhookDelivery, error) {
+	if id != 7 {
+		return postgres.WebhookDelivery{}, storage.ErrDeliveryNotFound
+	}
+	return postgres.WebhookDelivery{ID: 12, WebhookID: 2, Event: "comix.created", State: postgres.DeliveryPending}, nil
+}
+
+func (m *MockDeliveryReplayer) CheckPass(inputPass string) (bool, error) {
+	return inputPass == "password", nil
+}
+
+func (m *MockDeliveryReplayer) AddAuditEvent(event postgres.AuditEvent) error {
+	m.events = append(m.events, event)
+	return nil
+}
+
+func doRequest(t *testing.T, replayer *MockDeliveryReplayer, body map[string]interface{}) ResponseMock {
+	handler := replay_webhook_delivery.New(slogdiscard.NewDiscardLogger(), replayer)
+
+	jsonBody, _ := json.Marshal(body)
+
+	req, err := http.NewRequest("POST", "/webhooks/deliveries/replay", bytes.NewBuffer(jsonBody))
+	assert.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	var responseBody ResponseMock
+
+	if err := json.Unmarshal(rr.Body.Bytes(), &responseBody); err != nil {
+		t.Fatalf("Ошибка при распоковке JSON: %s", err)
+	}
+
+	return responseBody
+}
+
+func TestReplayWebhookDelivery(t *testing.T) {
+	replayer := &MockDeliveryReplayer{}
+
+	responseBody := doRequest(t, replayer, map[string]interface{}{"password": "password", "deliveryId": 7})
+
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+	assert.Equal(t, int64(12), responseBody.Delivery.ID)
+	assert.Equal(t, postgres.DeliveryPending, responseBody.Delivery.State)
+
+	assert.Len(t, replayer.events, 1)
+	assert.Equal(t, "webhook.replay", replayer.events[0].Action)
+	assert.Equal(t, "webhook:2", replayer.events[0].Target)
+	assert.JSONEq(t, `{"replayOf":7,"delivery":12,"event":"comix.created"}`, string(replayer.events[0].After))
+}
+
+func TestReplayWebhookDelivery_InvalidRequest(t *testing.T) {
+	requestsBody := []map[string]interface{}{
+		{"deliveryId": 7},
+		{"password": "password"},
+		{"password": "wrong_password", "deliveryId": 7},
+		{"password": "password", "deliveryId": 8},
+	}
+
+	replayer := &MockDeliveryReplayer{}
+
+	for _, body := range requestsBody {
+		responseBody := doRequest(t, replayer, body)
+		assert.Equal(t, http.StatusBadRequest, responseBody.Status, body)
+	}
+
+	assert.Empty(t, replayer.events)
+}
@@ -0,0 +1,123 @@
+package report_content
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator"
+	"jadesheart/comix_back/internal/http-server/middleware/session"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// Request - для kind=comix нужен slug комикса, для kind=comment - commentId
+type Request struct {
+	Kind      string `json:"kind" validate:"required,oneof=comix comment"`
+	Slug      string `json:"slug"`
+	CommentID int64  `json:"commentId" validate:"min=0"`
+	Reason    string `json:"reason" validate:"required,max=1000"`
+}
+
+type Response struct {
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type ContentReporter interface {
+	GetComixBySlug(comixSlug string) (postgres.ComixFromAllComix, error)
+	AddReport(readerID int, kind string, targetID int64, reason string) error
+}
+
+// New отправляет модераторам жалобу читателя запроса на комикс или комментарий.
+func New(log *slog.Logger, contentReporter ContentReporter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.report_content.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("failed decode request json", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed decode request json"))
+
+			return
+		}
+
+		req.Reason = strings.TrimSpace(req.Reason)
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Error("failed validate", sl.Err(err))
+
+			render.JSON(w, r, resp.ValidateErrors(validateErr))
+
+			return
+		}
+
+		reader, _ := session.Reader(r.Context())
+
+		targetID := req.CommentID
+
+		if req.Kind == postgres.ReportComix {
+			if req.Slug == "" {
+				render.JSON(w, r, resp.Error("slug is required to report a comix"))
+
+				return
+			}
+
+			comix, err := contentReporter.GetComixBySlug(req.Slug)
+			if errors.Is(err, storage.ErrComixNotFound) {
+				render.JSON(w, r, resp.Error("Comix not exists"))
+
+				return
+			}
+			if err != nil {
+				log.Error("Cannot get comix from bd", sl.Err(err))
+
+				render.JSON(w, r, resp.Error("Cannot get comix from bd"))
+
+				return
+			}
+
+			targetID = int64(comix.ID)
+		} else if targetID == 0 {
+			render.JSON(w, r, resp.Error("commentId is required to report a comment"))
+
+			return
+		}
+
+		err = contentReporter.AddReport(reader.ID, req.Kind, targetID, req.Reason)
+		if errors.Is(err, storage.ErrComixNotFound) {
+			render.JSON(w, r, resp.Error("Comix not exists"))
+
+			return
+		}
+		if errors.Is(err, storage.ErrCommentNotFound) {
+			render.JSON(w, r, resp.Error("Comment not exists"))
+
+			return
+		}
+		if err != nil {
+			log.Error("Cannot add report", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("Cannot add report"))
+
+			return
+		}
+
+		render.JSON(w, r, resp.OK())
+
+	}
+}
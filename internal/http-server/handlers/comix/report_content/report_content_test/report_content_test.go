@@ -0,0 +1,120 @@
+package report_content_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/report_content"
+	"jadesheart/comix_back/internal/http-server/middleware/session"
+	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type ResponseMock struct {
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type report struct {
+	readerID int
+	kind     string
+	targetID int64
+	reason   string
+}
+
+type MockContentReporter struct {
+	reports []report
+}
+
+func (m *MockContentReporter) GetComixBySlug(comixSlug string) (postgres.ComixFromAllComix, error) {
+	if comixSlug == "watchmen" {
+		return postgres.ComixFromAllComix{ID: 3, Slug: "watchmen"}, nil
+	}
+	return postgres.ComixFromAllComix{}, storage.ErrComixNotFound
+}
+
+func (m *MockContentReporter) AddReport(readerID int, kind string, targetID int64, reason string) error {
+	if kind == postgres.ReportComment && targetID > 100 {
+		return storage.ErrCommentNotFound
+	}
+	m.reports = append(m.reports, report{readerID: readerID, kind: kind, targetID: targetID, reason: reason})
+	return nil
+}
+
+func doRequest(t *testing.T, reporter *MockContentReporter, body map[string]interface{}) ResponseMock {
+	router := chi.NewRouter()
+	router.With(session.Required).Post("/api/me/reports", report_content.New(slogdiscard.NewDiscardLogger(), reporter))
+
+	jsonBody, _ := json.Marshal(body)
+
+	req, err := http.NewRequest("POST", "/api/me/reports", bytes.NewBuffer(jsonBody))
+	assert.NoError(t, err)
+	req = req.WithContext(session.WithReader(req.Context(), postgres.Reader{ID: 5, Username: "reader"}))
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	var responseBody ResponseMock
+
+	if err := json.Unmarshal(rr.Body.Bytes(), &responseBody); err != nil {
+		t.Fatalf("Ошибка при распоковке JSON: %s", err)
+	}
+
+	return responseBody
+}
+
+func TestReportContent_Success(t *testing.T) {
+	reporter := &MockContentReporter{}
+
+	responseBody := doRequest(t, reporter, map[string]interface{}{"kind": "comix", "slug": "watchmen", "reason": " stolen art "})
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+
+	responseBody = doRequest(t, reporter, map[string]interface{}{"kind": "comment", "commentId": 7, "reason": "spam"})
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+
+	assert.Equal(t, []report{
+		{readerID: 5, kind: "comix", targetID: 3, reason: "stolen art"},
+		{readerID: 5, kind: "comment", targetID: 7, reason: "spam"},
+	}, reporter.reports)
+}
+
+func TestReportContent_InvalidRequest(t *testing.T) {
+	requestsBody := []map[string]interface{}{
+		{"kind": "comix", "slug": "watchmen"},
+		{"kind": "comix", "slug": "watchmen", "reason": "   "},
+		{"kind": "author", "slug": "watchmen", "reason": "spam"},
+		{"kind": "comix", "reason": "spam"},
+		{"kind": "comix", "slug": "missing", "reason": "spam"},
+		{"kind": "comment", "reason": "spam"},
+		{"kind": "comment", "commentId": 101, "reason": "spam"},
+		{"kind": "comment", "commentId": 7, "reason": strings.Repeat("a", 1001)},
+	}
+
+	reporter := &MockContentReporter{}
+
+	for _, body := range requestsBody {
+		responseBody := doRequest(t, reporter, body)
+		assert.Equal(t, http.StatusBadRequest, responseBody.Status, body)
+	}
+
+	assert.Empty(t, reporter.reports)
+}
+
+func TestReportContent_Anonymous(t *testing.T) {
+	router := chi.NewRouter()
+	router.With(session.Required).Post("/api/me/reports", report_content.New(slogdiscard.NewDiscardLogger(), &MockContentReporter{}))
+
+	req, err := http.NewRequest("POST", "/api/me/reports", strings.NewReader(`{"kind":"comment","commentId":7,"reason":"spam"}`))
+	assert.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+}
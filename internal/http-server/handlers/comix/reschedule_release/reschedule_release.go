@@ -0,0 +1,174 @@
+package reschedule_release
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator"
+	"jadesheart/comix_back/internal/http-server/middleware/ratelimit"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/api/schedule"
+	"jadesheart/comix_back/internal/lib/audit"
+	"jadesheart/comix_back/internal/lib/cache"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// Request - kind - postgres.ReleaseComix или postgres.ReleasePages. У страниц выпуск определяется временем from,
+// под которым они запланированы сейчас. PublishAt - новое время выхода в RFC 3339, пустое - выпустить сразу.
+type Request struct {
+	Password  string `json:"password" validate:"required"`
+	Kind      string `json:"kind" validate:"required,oneof=comix pages"`
+	TagName   string `json:"tagName" validate:"required"`
+	Name      string `json:"name" validate:"required"`
+	From      string `json:"from"`
+	PublishAt string `json:"publishAt"`
+}
+
+type Response struct {
+	Status    int        `json:"status,omitempty"`
+	Error     string     `json:"error,omitempty"`
+	PublishAt *time.Time `json:"publishAt,omitempty"`
+	Pages     int        `json:"pages,omitempty"`
+}
+
+type ReleaseRescheduler interface {
+	RescheduleComix(tagName string, name string, publishAt time.Time) (time.Time, error)
+	ReschedulePages(tagName string, name string, from time.Time, publishAt time.Time) (int, error)
+	CheckPass(inputPass string) (bool, error)
+	AddAuditEvent(event postgres.AuditEvent) error
+}
+
+type CacheInvalidator interface {
+	Invalidate(groups ...string)
+}
+
+// New переносит запланированный выпуск комикса или его страниц. Выпуск без нового времени выходит сразу.
+func New(log *slog.Logger, releaseRescheduler ReleaseRescheduler, cacheInvalidator CacheInvalidator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.reschedule_release.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("failed decode request json", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed decode request json"))
+
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Error("failed validate", sl.Err(err))
+
+			render.JSON(w, r, resp.ValidateErrors(validateErr))
+
+			return
+		}
+
+		publishAt, ok := schedule.Parse(req.PublishAt, time.Now())
+		if !ok {
+			render.JSON(w, r, resp.Error("publishAt must be a RFC 3339 time"))
+
+			return
+		}
+
+		var from time.Time
+		if req.Kind == postgres.ReleasePages {
+			from, err = time.Parse(time.RFC3339, req.From)
+			if err != nil {
+				render.JSON(w, r, resp.Error("from must be a RFC 3339 time of the scheduled pages"))
+
+				return
+			}
+		}
+
+		res, err := releaseRescheduler.CheckPass(req.Password)
+		if err != nil {
+			log.Error("failed password verified", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed password verified"))
+
+			return
+		}
+
+		if !res {
+			log.Warn("incorrect password", slog.String("remote_addr", ratelimit.ClientIP(r)))
+
+			ratelimit.AuthFailed(r.Context())
+
+			render.JSON(w, r, resp.Error("incorrect password"))
+
+			return
+		}
+
+		ratelimit.AuthSucceeded(r.Context())
+
+		before := from
+		pages := 0
+
+		if req.Kind == postgres.ReleaseComix {
+			before, err = releaseRescheduler.RescheduleComix(req.TagName, req.Name, publishAt)
+		} else {
+			pages, err = releaseRescheduler.ReschedulePages(req.TagName, req.Name, from, publishAt)
+		}
+		if errors.Is(err, storage.ErrReleaseNotFound) {
+			render.JSON(w, r, resp.Error("Release is not scheduled"))
+
+			return
+		}
+		if err != nil {
+			log.Error("Cannot reschedule release", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("Cannot reschedule release"))
+
+			return
+		}
+
+		if publishAt.IsZero() {
+			cacheInvalidator.Invalidate(cache.KeyMainPage, cache.KeySearch, cache.KeyAllTags, cache.KeyTagComix(req.TagName))
+		}
+
+		after := map[string]interface{}{"kind": req.Kind, "publishAt": nil}
+		if !publishAt.IsZero() {
+			after["publishAt"] = publishAt
+		}
+		if req.Kind == postgres.ReleasePages {
+			after["pages"] = pages
+		}
+
+		err = releaseRescheduler.AddAuditEvent(audit.NewEvent(r, audit.ActionReschedule, audit.ComixTarget(req.TagName, req.Name),
+			map[string]interface{}{"kind": req.Kind, "publishAt": before}, after))
+		if err != nil {
+			log.Error("failed write audit event", sl.Err(err))
+		}
+
+		responseOK(w, r, publishAt, pages)
+
+	}
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request, publishAt time.Time, pages int) {
+	var scheduled *time.Time
+	if !publishAt.IsZero() {
+		scheduled = &publishAt
+	}
+
+	render.JSON(w, r, Response{
+		Status:    resp.StatusOK,
+		PublishAt: scheduled,
+		Pages:     pages,
+	})
+}
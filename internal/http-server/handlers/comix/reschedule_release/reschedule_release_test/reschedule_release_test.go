@@ -0,0 +1,149 @@
+package reschedule_release_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/reschedule_release"
+	"jadesheart/comix_back/internal/lib/cache"
+	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type ResponseMock struct {
+	Status    int        `json:"status,omitempty"`
+	Error     string     `json:"error,omitempty"`
+	PublishAt *time.Time `json:"publishAt,omitempty"`
+	Pages     int        `json:"pages,omitempty"`
+}
+
+// scheduled - время, под которым в моке запланированы комикс Watchmen и страницы Hellboy
+var scheduled = time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+
+type MockReleaseRescheduler struct {
+	publishAt time.Time
+	events    []postgres.AuditEvent
+}
+
+func (m *MockReleaseRescheduler) RescheduleComix(tagName string, name string, publishAt time.Time) (time.Time, error) {
+	if name != "Watchmen" {
+		return time.Time{}, storage.ErrReleaseNotFound
+	}
+	m.publishAt = publishAt
+	return scheduled, nil
+}
+
+func (m *MockReleaseRescheduler) ReschedulePages(tagName string, name string, from time.Time, publishAt time.Time) (int, error) {
+	if name != "Hellboy" || !from.Equal(scheduled) {
+		return 0, storage.ErrReleaseNotFound
+	}
+	m.publishAt = publishAt
+	return 3, nil
+}
+
+func (m *MockReleaseRescheduler) CheckPass(inputPass string) (bool, error) {
+	return inputPass == "password", nil
+}
+
+func (m *MockReleaseRescheduler) AddAuditEvent(event postgres.AuditEvent) error {
+	m.events = append(m.events, event)
+	return nil
+}
+
+type MockCacheInvalidator struct {
+	groups []string
+}
+
+func (m *MockCacheInvalidator) Invalidate(groups ...string) {
+	m.groups = append(m.groups, groups...)
+}
+
+func doRequest(t *testing.T, rescheduler *MockReleaseRescheduler, invalidator *MockCacheInvalidator, body map[string]interface{}) ResponseMock {
+	handler := reschedule_release.New(slogdiscard.NewDiscardLogger(), rescheduler, invalidator)
+
+	jsonBody, _ := json.Marshal(body)
+
+	req, err := http.NewRequest("POST", "/releases/reschedule", bytes.NewBuffer(jsonBody))
+	assert.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	var responseBody ResponseMock
+
+	if err := json.Unmarshal(rr.Body.Bytes(), &responseBody); err != nil {
+		t.Fatalf("Ошибка при распоковке JSON: %s", err)
+	}
+
+	return responseBody
+}
+
+func TestRescheduleRelease_Comix(t *testing.T) {
+	rescheduler := &MockReleaseRescheduler{}
+	invalidator := &MockCacheInvalidator{}
+	publishAt := time.Now().Add(48 * time.Hour).UTC().Truncate(time.Second)
+
+	responseBody := doRequest(t, rescheduler, invalidator, map[string]interface{}{
+		"password": "password", "kind": "comix", "tagName": "horror", "name": "Watchmen", "publishAt": publishAt.Format(time.RFC3339),
+	})
+
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+	assert.True(t, publishAt.Equal(*responseBody.PublishAt))
+	assert.True(t, publishAt.Equal(rescheduler.publishAt))
+
+	// Выпуск только перенесён, читатели его пока не видят
+	assert.Empty(t, invalidator.groups)
+
+	assert.Len(t, rescheduler.events, 1)
+	assert.Equal(t, "comix.reschedule", rescheduler.events[0].Action)
+	assert.Equal(t, "comix:horror/Watchmen", rescheduler.events[0].Target)
+}
+
+func TestRescheduleRelease_PublishPagesNow(t *testing.T) {
+	rescheduler := &MockReleaseRescheduler{publishAt: scheduled}
+	invalidator := &MockCacheInvalidator{}
+
+	responseBody := doRequest(t, rescheduler, invalidator, map[string]interface{}{
+		"password": "password", "kind": "pages", "tagName": "horror", "name": "Hellboy", "from": scheduled.Format(time.RFC3339),
+	})
+
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+	assert.Nil(t, responseBody.PublishAt)
+	assert.Equal(t, 3, responseBody.Pages)
+	assert.True(t, rescheduler.publishAt.IsZero())
+
+	assert.Contains(t, invalidator.groups, cache.KeyMainPage)
+	assert.Contains(t, invalidator.groups, cache.KeyTagComix("horror"))
+}
+
+func TestRescheduleRelease_InvalidRequest(t *testing.T) {
+	from := scheduled.Format(time.RFC3339)
+
+	requestsBody := []map[string]interface{}{
+		{"kind": "comix", "tagName": "horror", "name": "Watchmen"},
+		{"password": "password", "kind": "chapter", "tagName": "horror", "name": "Watchmen"},
+		{"password": "password", "kind": "comix", "name": "Watchmen"},
+		{"password": "password", "kind": "comix", "tagName": "horror", "name": "Watchmen", "publishAt": "tomorrow"},
+		{"password": "password", "kind": "pages", "tagName": "horror", "name": "Hellboy"},
+		{"password": "wrong_password", "kind": "comix", "tagName": "horror", "name": "Watchmen"},
+		{"password": "password", "kind": "comix", "tagName": "horror", "name": "Hellboy"},
+		{"password": "password", "kind": "pages", "tagName": "horror", "name": "Hellboy", "from": "2031-01-01T12:00:00Z"},
+		{"password": "password", "kind": "pages", "tagName": "horror", "name": "Watchmen", "from": from},
+	}
+
+	rescheduler := &MockReleaseRescheduler{}
+	invalidator := &MockCacheInvalidator{}
+
+	for _, body := range requestsBody {
+		responseBody := doRequest(t, rescheduler, invalidator, body)
+		assert.Equal(t, http.StatusBadRequest, responseBody.Status, body)
+	}
+
+	assert.Empty(t, rescheduler.events)
+	assert.Empty(t, invalidator.groups)
+}
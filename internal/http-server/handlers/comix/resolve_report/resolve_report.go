@@ -0,0 +1,120 @@
+package resolve_report
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator"
+	"jadesheart/comix_back/internal/http-server/middleware/ratelimit"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/audit"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// Request - resolution - решение модератора, например что комментарий убран или жалоба не подтвердилась
+type Request struct {
+	Password   string `json:"password" validate:"required"`
+	ReportID   int    `json:"reportId" validate:"required,min=1"`
+	Resolution string `json:"resolution" validate:"required,max=1000"`
+}
+
+type Response struct {
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type ReportResolver interface {
+	ResolveReport(id int, resolution string) (postgres.Report, error)
+	CheckPass(inputPass string) (bool, error)
+	AddAuditEvent(event postgres.AuditEvent) error
+}
+
+// New закрывает жалобу читателя с решением модератора. Сам контент не меняется: его убирают remove_comment или delete_comix.
+func New(log *slog.Logger, reportResolver ReportResolver) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.resolve_report.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("failed decode request json", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed decode request json"))
+
+			return
+		}
+
+		req.Resolution = strings.TrimSpace(req.Resolution)
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Error("failed validate", sl.Err(err))
+
+			render.JSON(w, r, resp.ValidateErrors(validateErr))
+
+			return
+		}
+
+		res, err := reportResolver.CheckPass(req.Password)
+		if err != nil {
+			log.Error("failed password verified", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed password verified"))
+
+			return
+		}
+
+		if !res {
+			log.Warn("incorrect password", slog.String("remote_addr", ratelimit.ClientIP(r)))
+
+			ratelimit.AuthFailed(r.Context())
+
+			render.JSON(w, r, resp.Error("incorrect password"))
+
+			return
+		}
+
+		ratelimit.AuthSucceeded(r.Context())
+
+		before, err := reportResolver.ResolveReport(req.ReportID, req.Resolution)
+		if errors.Is(err, storage.ErrReportNotFound) {
+			render.JSON(w, r, resp.Error("Report not exists or already resolved"))
+
+			return
+		}
+		if err != nil {
+			log.Error("Cannot resolve report", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("Cannot resolve report"))
+
+			return
+		}
+
+		err = reportResolver.AddAuditEvent(audit.NewEvent(r, audit.ActionReportResolve, audit.ReportTarget(req.ReportID),
+			before, map[string]string{"resolution": req.Resolution}))
+		if err != nil {
+			log.Error("failed write audit event", sl.Err(err))
+		}
+
+		responseOK(w, r)
+
+	}
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request) {
+	render.JSON(w, r, Response{
+		Status: resp.StatusOK,
+	})
+}
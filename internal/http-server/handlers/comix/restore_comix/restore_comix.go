@@ -0,0 +1,136 @@
+package restore_comix
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator"
+	"jadesheart/comix_back/internal/http-server/middleware/ratelimit"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/audit"
+	"jadesheart/comix_back/internal/lib/cache"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+	"reflect"
+)
+
+type Request struct {
+	Password string `json:"password" validate:"required"`
+	TagName  string `json:"tagName" validate:"required"`
+	Name     string `json:"name" validate:"required"`
+}
+
+type Response struct {
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type ComixRestorer interface {
+	RestoreComix(tagName string, name string) error
+	CheckPass(inputPass string) (bool, error)
+	AddAuditEvent(event postgres.AuditEvent) error
+}
+
+type CacheInvalidator interface {
+	Invalidate(groups ...string)
+}
+
+func New(log *slog.Logger, comixRestorer ComixRestorer, cacheInvalidator CacheInvalidator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.restore_comix.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("failed decode request json", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed decode request json"))
+
+			return
+		}
+
+		reqType := reflect.TypeOf(req)
+		for i := 0; i < reqType.NumField(); i++ {
+			field := reqType.Field(i)
+			fieldValue := reflect.ValueOf(req).FieldByName(field.Name)
+			if fieldValue.IsZero() {
+				errorMsg := fmt.Sprintf("zero point value: %s", field.Name)
+				render.JSON(w, r, resp.Error(errorMsg))
+				return
+			}
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Error("failed validate", sl.Err(err))
+
+			render.JSON(w, r, resp.ValidateErrors(validateErr))
+
+			return
+		}
+
+		res, err := comixRestorer.CheckPass(req.Password)
+		if err != nil {
+			log.Error("failed password verified", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed password verified"))
+
+			return
+		}
+
+		if !res {
+			log.Warn("incorrect password", slog.String("remote_addr", ratelimit.ClientIP(r)))
+
+			ratelimit.AuthFailed(r.Context())
+
+			render.JSON(w, r, resp.Error("incorrect password"))
+
+			return
+		}
+
+		ratelimit.AuthSucceeded(r.Context())
+
+		err = comixRestorer.RestoreComix(req.TagName, req.Name)
+		if errors.Is(err, storage.ErrComixNotFound) {
+			log.Info("comix not in trash", slog.String("tagName", req.TagName), slog.String("name", req.Name))
+
+			render.JSON(w, r, resp.Error("Comix not in trash"))
+
+			return
+		}
+		if err != nil {
+			log.Error("Cannot restore comix", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("Cannot restore comix"))
+
+			return
+		}
+
+		cacheInvalidator.Invalidate(cache.KeyMainPage, cache.KeySearch, cache.KeyAllTags, cache.KeyTagComix(req.TagName))
+
+		err = comixRestorer.AddAuditEvent(audit.NewEvent(r, audit.ActionComixRestore, audit.ComixTarget(req.TagName, req.Name), nil, nil))
+		if err != nil {
+			log.Error("failed write audit event", sl.Err(err))
+		}
+
+		responseOK(w, r)
+
+	}
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request) {
+	render.JSON(w, r, Response{
+		Status: resp.StatusOK,
+	})
+}
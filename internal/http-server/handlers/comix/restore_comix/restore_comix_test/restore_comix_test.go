@@ -0,0 +1,90 @@
+package restore_comix_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/restore_comix"
+	"jadesheart/comix_back/internal/lib/cache"
+	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type ResponseMock struct {
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type MockComixRestorer struct {
+	events []postgres.AuditEvent
+}
+
+func (m *MockComixRestorer) RestoreComix(tagName string, name string) error {
+	if name == "comixNotInTrash" {
+		return storage.ErrComixNotFound
+	}
+	return nil
+}
+
+func (m *MockComixRestorer) CheckPass(inputPass string) (bool, error) {
+	return inputPass == "password", nil
+}
+
+func (m *MockComixRestorer) AddAuditEvent(event postgres.AuditEvent) error {
+	m.events = append(m.events, event)
+	return nil
+}
+
+func doRequest(t *testing.T, restorer *MockComixRestorer, body map[string]interface{}) ResponseMock {
+	handler := restore_comix.New(slogdiscard.NewDiscardLogger(), restorer, cache.New(cache.NewMemory(), time.Minute))
+
+	jsonBody, _ := json.Marshal(body)
+
+	req, err := http.NewRequest("POST", "/trash/restore", bytes.NewBuffer(jsonBody))
+	assert.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	var responseBody ResponseMock
+
+	if err := json.Unmarshal(rr.Body.Bytes(), &responseBody); err != nil {
+		t.Fatalf("Ошибка при распоковке JSON: %s", err)
+	}
+
+	return responseBody
+}
+
+func TestRestoreComix_Success(t *testing.T) {
+	restorer := &MockComixRestorer{}
+
+	responseBody := doRequest(t, restorer, map[string]interface{}{
+		"password": "password",
+		"tagName":  "horror",
+		"name":     "comix",
+	})
+
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+	assert.Len(t, restorer.events, 1)
+	assert.Equal(t, "comix.restore", restorer.events[0].Action)
+}
+
+func TestRestoreComix_InvalidRequest(t *testing.T) {
+	requestsBody := []map[string]interface{}{
+		{"tagName": "horror", "name": "comix"},
+		{"password": "password", "name": "comix"},
+		{"password": "wrong_password", "tagName": "horror", "name": "comix"},
+		{"password": "password", "tagName": "horror", "name": "comixNotInTrash"},
+	}
+
+	for _, m := range requestsBody {
+		responseBody := doRequest(t, &MockComixRestorer{}, m)
+
+		assert.Equal(t, http.StatusBadRequest, responseBody.Status)
+	}
+}
@@ -5,8 +5,13 @@ import (
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
 	"github.com/go-playground/validator"
+	"jadesheart/comix_back/internal/http-server/middleware/ratelimit"
 	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/audit"
+	"jadesheart/comix_back/internal/lib/cache"
 	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/lib/photos"
+	"jadesheart/comix_back/internal/storage/postgres"
 	"log/slog"
 	"net/http"
 	"os"
@@ -30,9 +35,14 @@ type ComixSaver interface {
 	CheckPass(inputPass string) (bool, error)
 	AddComixTagToAllTags(tagName string) error
 	AddTagDescription(tag string, description string) error
+	AddAuditEvent(event postgres.AuditEvent) error
 }
 
-func New(log *slog.Logger, comixSaver ComixSaver) http.HandlerFunc {
+type CacheInvalidator interface {
+	Invalidate(groups ...string)
+}
+
+func New(log *slog.Logger, comixSaver ComixSaver, cacheInvalidator CacheInvalidator, mediaRoot *photos.Root) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.comix.save.New"
 
@@ -63,7 +73,7 @@ func New(log *slog.Logger, comixSaver ComixSaver) http.HandlerFunc {
 			}
 		}
 
-		log.Info("request body decoded", slog.Any("request", req))
+		log.Info("request body decoded", slog.String("tagName", req.TagName))
 
 		if err := validator.New().Struct(req); err != nil {
 
@@ -75,6 +85,15 @@ func New(log *slog.Logger, comixSaver ComixSaver) http.HandlerFunc {
 			return
 		}
 
+		tagDir, err := mediaRoot.Resolve(req.TagName)
+		if err != nil {
+			log.Warn("unsafe tag name", sl.Err(err), slog.String("remote_addr", ratelimit.ClientIP(r)))
+
+			render.JSON(w, r, resp.Error("invalid tag name"))
+
+			return
+		}
+
 		res, err := comixSaver.CheckPass(req.Password)
 		if err != nil {
 			log.Error("failed password verified", sl.Err(err))
@@ -85,13 +104,17 @@ func New(log *slog.Logger, comixSaver ComixSaver) http.HandlerFunc {
 		}
 
 		if !res {
-			log.Info("INCORRECT PASSWORD", slog.Any("Trying input password: ", req.Password))
+			log.Warn("incorrect password", slog.String("remote_addr", ratelimit.ClientIP(r)))
+
+			ratelimit.AuthFailed(r.Context())
 
 			render.JSON(w, r, resp.Error("incorrect password"))
 
 			return
 		}
 
+		ratelimit.AuthSucceeded(r.Context())
+
 		result, err := comixSaver.TagExist(req.TagName)
 		if err != nil {
 
@@ -131,8 +154,7 @@ func New(log *slog.Logger, comixSaver ComixSaver) http.HandlerFunc {
 			return
 		}
 
-		path := fmt.Sprintf("internal/storage/web/photos/%s", req.TagName)
-		err = os.Mkdir(path, 0755)
+		err = os.Mkdir(tagDir, 0755)
 		if err != nil {
 			log.Error("failed create tag folder", sl.Err(err))
 
@@ -150,7 +172,17 @@ func New(log *slog.Logger, comixSaver ComixSaver) http.HandlerFunc {
 			return
 		}
 
-		log.Info("tag added and folder successful created", slog.Any(path+"TagName", req.TagName))
+		cacheInvalidator.Invalidate(cache.KeyAllTags, cache.KeyTagDescription(req.TagName))
+
+		err = comixSaver.AddAuditEvent(audit.NewEvent(r, audit.ActionTagCreate, audit.TagTarget(req.TagName), nil, map[string]string{
+			"tagName":     req.TagName,
+			"description": req.Description,
+		}))
+		if err != nil {
+			log.Error("failed write audit event", sl.Err(err))
+		}
+
+		log.Info("tag added and folder successful created", slog.String("dir", tagDir), slog.Any("TagName", req.TagName))
 
 		responseOK(w, r)
 
@@ -5,13 +5,18 @@ import (
 	"encoding/json"
 	"github.com/stretchr/testify/assert"
 	"jadesheart/comix_back/internal/http-server/handlers/comix/save"
+	"jadesheart/comix_back/internal/lib/cache"
 	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
 	"jadesheart/comix_back/internal/lib/logger/handlers/slogpretty"
+	"jadesheart/comix_back/internal/lib/photos/photostest"
+	"jadesheart/comix_back/internal/storage/postgres"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 )
 
 type ResponseMock struct {
@@ -38,6 +43,10 @@ func (c *ComixSaverMock) CheckPass(inputPass string) (bool, error) {
 	}
 	return false, nil
 }
+
+func (c *ComixSaverMock) AddAuditEvent(event postgres.AuditEvent) error {
+	return nil
+}
 func (c *ComixSaverMock) AddComixTagToAllTags(tagName string) error {
 	return nil
 }
@@ -48,7 +57,9 @@ func (c *ComixSaverMock) AddTagDescription(tag string, description string) error
 func TestGetTagDescription_Success(t *testing.T) {
 	mockLogger := setupLogger("local")
 
-	handler := save.New(mockLogger, &ComixSaverMock{})
+	mediaRoot := photostest.NewRoot(t)
+
+	handler := save.New(mockLogger, &ComixSaverMock{}, cache.New(cache.NewMemory(), time.Minute), mediaRoot)
 
 	requestBody := map[string]interface{}{
 		"tagName":     "someTag",
@@ -72,6 +83,9 @@ func TestGetTagDescription_Success(t *testing.T) {
 	}
 
 	assert.Equal(t, http.StatusOK, responseBody.Status)
+
+	_, err = os.Stat(filepath.Join(mediaRoot.Dir(), "someTag"))
+	assert.NoError(t, err)
 }
 
 func TestGetTagDescription_EmptyValueParams(t *testing.T) {
@@ -91,7 +105,7 @@ func TestGetTagDescription_EmptyValueParams(t *testing.T) {
 	}
 
 	for _, m := range requestsBody {
-		handler := save.New(mockLogger, &ComixSaverMock{})
+		handler := save.New(mockLogger, &ComixSaverMock{}, cache.New(cache.NewMemory(), time.Minute), photostest.NewRoot(t))
 
 		jsonBody, _ := json.Marshal(m)
 
@@ -0,0 +1,92 @@
+package save_progress
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator"
+	"jadesheart/comix_back/internal/http-server/middleware/session"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+)
+
+// Request - pageId - id страницы из списка страниц комикса, а не её позиция
+type Request struct {
+	PageID int `json:"pageId" validate:"required,min=1"`
+}
+
+type ProgressSaver interface {
+	GetComixBySlug(comixSlug string) (postgres.ComixFromAllComix, error)
+	SaveProgress(readerID int, comixID int, pageID int) error
+}
+
+// New запоминает страницу, на которой читатель запроса остановился в комиксе.
+func New(log *slog.Logger, progressSaver ProgressSaver) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.save_progress.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("failed decode request json", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed decode request json"))
+
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Error("failed validate", sl.Err(err))
+
+			render.JSON(w, r, resp.ValidateErrors(validateErr))
+
+			return
+		}
+
+		reader, _ := session.Reader(r.Context())
+
+		comix, err := progressSaver.GetComixBySlug(chi.URLParam(r, "slug"))
+		if errors.Is(err, storage.ErrComixNotFound) {
+			render.JSON(w, r, resp.Error("Comix not exists"))
+
+			return
+		}
+		if err != nil {
+			log.Error("Cannot get comix from bd", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("Cannot get comix from bd"))
+
+			return
+		}
+
+		err = progressSaver.SaveProgress(reader.ID, comix.ID, req.PageID)
+		if errors.Is(err, storage.ErrPageNotFound) {
+			render.JSON(w, r, resp.Error("Page not exists"))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed save progress", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed save progress"))
+
+			return
+		}
+
+		render.JSON(w, r, resp.OK())
+
+	}
+}
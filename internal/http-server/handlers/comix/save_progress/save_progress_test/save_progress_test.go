@@ -0,0 +1,99 @@
+package save_progress_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/save_progress"
+	"jadesheart/comix_back/internal/http-server/middleware/session"
+	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type ResponseMock struct {
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type saved struct {
+	readerID int
+	comixID  int
+	pageID   int
+}
+
+type MockProgressSaver struct {
+	saved []saved
+}
+
+func (m *MockProgressSaver) GetComixBySlug(comixSlug string) (postgres.ComixFromAllComix, error) {
+	if comixSlug == "watchmen" {
+		return postgres.ComixFromAllComix{ID: 3, Slug: "watchmen"}, nil
+	}
+	return postgres.ComixFromAllComix{}, storage.ErrComixNotFound
+}
+
+func (m *MockProgressSaver) SaveProgress(readerID int, comixID int, pageID int) error {
+	if pageID > 12 {
+		return storage.ErrPageNotFound
+	}
+	m.saved = append(m.saved, saved{readerID, comixID, pageID})
+	return nil
+}
+
+func doRequest(t *testing.T, saver *MockProgressSaver, comixSlug string, body map[string]interface{}) ResponseMock {
+	router := chi.NewRouter()
+	router.With(session.Required).Put("/api/me/progress/{slug}", save_progress.New(slogdiscard.NewDiscardLogger(), saver))
+
+	jsonBody, _ := json.Marshal(body)
+
+	req, err := http.NewRequest("PUT", "/api/me/progress/"+comixSlug, bytes.NewBuffer(jsonBody))
+	assert.NoError(t, err)
+	req = req.WithContext(session.WithReader(req.Context(), postgres.Reader{ID: 5, Username: "reader"}))
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	var responseBody ResponseMock
+
+	if err := json.Unmarshal(rr.Body.Bytes(), &responseBody); err != nil {
+		t.Fatalf("Ошибка при распоковке JSON: %s", err)
+	}
+
+	return responseBody
+}
+
+func TestSaveProgress_Success(t *testing.T) {
+	saver := &MockProgressSaver{}
+
+	responseBody := doRequest(t, saver, "watchmen", map[string]interface{}{"pageId": 7})
+
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+	assert.Equal(t, []saved{{readerID: 5, comixID: 3, pageID: 7}}, saver.saved)
+}
+
+func TestSaveProgress_Errors(t *testing.T) {
+	cases := []struct {
+		slug  string
+		body  map[string]interface{}
+		error string
+	}{
+		{"watchmen", map[string]interface{}{}, "PageID is not valid"},
+		{"watchmen", map[string]interface{}{"pageId": 40}, "Page not exists"},
+		{"unknown", map[string]interface{}{"pageId": 7}, "Comix not exists"},
+	}
+
+	for _, c := range cases {
+		saver := &MockProgressSaver{}
+
+		responseBody := doRequest(t, saver, c.slug, c.body)
+
+		assert.Equal(t, http.StatusBadRequest, responseBody.Status)
+		assert.Equal(t, c.error, responseBody.Error)
+		assert.Empty(t, saver.saved)
+	}
+}
@@ -0,0 +1,143 @@
+package set_comix_credits
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator"
+	"jadesheart/comix_back/internal/http-server/middleware/ratelimit"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/audit"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net/http"
+)
+
+var roles = map[string]bool{
+	postgres.RoleWriter:     true,
+	postgres.RoleArtist:     true,
+	postgres.RoleColorist:   true,
+	postgres.RoleTranslator: true,
+}
+
+type Credit struct {
+	Author string `json:"author" validate:"required"`
+	Role   string `json:"role" validate:"required"`
+}
+
+// Request - credits полностью заменяет список авторов комикса, пустой список его очищает
+type Request struct {
+	Password string   `json:"password" validate:"required"`
+	TagName  string   `json:"tagName" validate:"required"`
+	Name     string   `json:"name" validate:"required"`
+	Credits  []Credit `json:"credits" validate:"max=50,dive"`
+}
+
+type Response struct {
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type CreditsSetter interface {
+	SetComixCredits(tagName string, name string, credits []postgres.Credit) error
+	CheckPass(inputPass string) (bool, error)
+	AddAuditEvent(event postgres.AuditEvent) error
+}
+
+func New(log *slog.Logger, creditsSetter CreditsSetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.set_comix_credits.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("failed decode request json", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed decode request json"))
+
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Error("failed validate", sl.Err(err))
+
+			render.JSON(w, r, resp.ValidateErrors(validateErr))
+
+			return
+		}
+
+		credits := make([]postgres.Credit, 0, len(req.Credits))
+		for _, credit := range req.Credits {
+			if !roles[credit.Role] {
+				render.JSON(w, r, resp.Error("role must be writer, artist, colorist or translator"))
+
+				return
+			}
+			credits = append(credits, postgres.Credit{AuthorSlug: credit.Author, Role: credit.Role})
+		}
+
+		res, err := creditsSetter.CheckPass(req.Password)
+		if err != nil {
+			log.Error("failed password verified", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed password verified"))
+
+			return
+		}
+
+		if !res {
+			log.Warn("incorrect password", slog.String("remote_addr", ratelimit.ClientIP(r)))
+
+			ratelimit.AuthFailed(r.Context())
+
+			render.JSON(w, r, resp.Error("incorrect password"))
+
+			return
+		}
+
+		ratelimit.AuthSucceeded(r.Context())
+
+		err = creditsSetter.SetComixCredits(req.TagName, req.Name, credits)
+		if errors.Is(err, storage.ErrComixNotFound) {
+			render.JSON(w, r, resp.Error("Comix not exists"))
+
+			return
+		}
+		if errors.Is(err, storage.ErrAuthorNotFound) {
+			render.JSON(w, r, resp.Error("Author not exists"))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed set comix credits", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed set comix credits"))
+
+			return
+		}
+
+		err = creditsSetter.AddAuditEvent(audit.NewEvent(r, audit.ActionComixCredits, audit.ComixTarget(req.TagName, req.Name), nil, req.Credits))
+		if err != nil {
+			log.Error("failed write audit event", sl.Err(err))
+		}
+
+		responseOK(w, r)
+
+	}
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request) {
+	render.JSON(w, r, Response{
+		Status: resp.StatusOK,
+	})
+}
@@ -0,0 +1,111 @@
+package set_comix_credits_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/set_comix_credits"
+	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type ResponseMock struct {
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type MockCreditsSetter struct {
+	credits []postgres.Credit
+}
+
+func (m *MockCreditsSetter) SetComixCredits(tagName string, name string, credits []postgres.Credit) error {
+	if name != "Watchmen" {
+		return storage.ErrComixNotFound
+	}
+	for _, credit := range credits {
+		if credit.AuthorSlug != "alan-moore" && credit.AuthorSlug != "dave-gibbons" {
+			return storage.ErrAuthorNotFound
+		}
+	}
+	m.credits = credits
+	return nil
+}
+
+func (m *MockCreditsSetter) CheckPass(inputPass string) (bool, error) {
+	return inputPass == "password", nil
+}
+
+func (m *MockCreditsSetter) AddAuditEvent(event postgres.AuditEvent) error {
+	return nil
+}
+
+func doRequest(t *testing.T, setter *MockCreditsSetter, body map[string]interface{}) ResponseMock {
+	handler := set_comix_credits.New(slogdiscard.NewDiscardLogger(), setter)
+
+	jsonBody, _ := json.Marshal(body)
+
+	req, err := http.NewRequest("POST", "/comixcredits", bytes.NewBuffer(jsonBody))
+	assert.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	var responseBody ResponseMock
+
+	if err := json.Unmarshal(rr.Body.Bytes(), &responseBody); err != nil {
+		t.Fatalf("Ошибка при распоковке JSON: %s", err)
+	}
+
+	return responseBody
+}
+
+func TestSetComixCredits_Success(t *testing.T) {
+	setter := &MockCreditsSetter{}
+
+	responseBody := doRequest(t, setter, map[string]interface{}{
+		"password": "password",
+		"tagName":  "Horror",
+		"name":     "Watchmen",
+		"credits": []map[string]string{
+			{"author": "alan-moore", "role": "writer"},
+			{"author": "dave-gibbons", "role": "artist"},
+		},
+	})
+
+	assert.Equal(t, http.StatusOK, responseBody.Status)
+	assert.Equal(t, []postgres.Credit{
+		{AuthorSlug: "alan-moore", Role: postgres.RoleWriter},
+		{AuthorSlug: "dave-gibbons", Role: postgres.RoleArtist},
+	}, setter.credits)
+}
+
+func TestSetComixCredits_InvalidRequest(t *testing.T) {
+	cases := []struct {
+		body  map[string]interface{}
+		error string
+	}{
+		{map[string]interface{}{"password": "password", "tagName": "Horror", "name": "Watchmen",
+			"credits": []map[string]string{{"author": "alan-moore", "role": "editor"}}},
+			"role must be writer, artist, colorist or translator"},
+		{map[string]interface{}{"password": "password", "tagName": "Horror", "name": "Watchmen",
+			"credits": []map[string]string{{"author": "missing", "role": "writer"}}},
+			"Author not exists"},
+		{map[string]interface{}{"password": "password", "tagName": "Horror", "name": "Sandman",
+			"credits": []map[string]string{}},
+			"Comix not exists"},
+		{map[string]interface{}{"password": "wrong_password", "tagName": "Horror", "name": "Watchmen",
+			"credits": []map[string]string{}},
+			"incorrect password"},
+	}
+
+	for _, c := range cases {
+		responseBody := doRequest(t, &MockCreditsSetter{}, c.body)
+
+		assert.Equal(t, http.StatusBadRequest, responseBody.Status)
+		assert.Equal(t, c.error, responseBody.Error)
+	}
+}
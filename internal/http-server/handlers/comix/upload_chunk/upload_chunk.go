@@ -0,0 +1,90 @@
+package upload_chunk
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"io"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/lib/upload"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type ChunkWriter interface {
+	WriteChunk(id string, offset int64, src io.Reader, chunkSum []byte) (int64, error)
+}
+
+// New принимает часть загрузки по протоколу tus: PATCH с Upload-Offset и телом application/offset+octet-stream.
+// Ответы - коды HTTP, как ждут tus-клиенты: 204 - часть принята, 409 - offset не совпал с загруженным объёмом,
+// 460 - часть не совпала с Upload-Checksum. maxChunk - наибольший размер части в одном запросе.
+func New(log *slog.Logger, chunkWriter ChunkWriter, maxChunk int64) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.comix.upload_chunk.New"
+
+		id := chi.URLParam(r, "id")
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			slog.String("upload", id),
+		)
+
+		w.Header().Set(upload.HeaderResumable, upload.TusVersion)
+
+		if r.Header.Get("Content-Type") != upload.ContentType {
+			http.Error(w, "Content-Type must be "+upload.ContentType, http.StatusUnsupportedMediaType)
+
+			return
+		}
+
+		offset, err := strconv.ParseInt(r.Header.Get(upload.HeaderOffset), 10, 64)
+		if err != nil || offset < 0 {
+			http.Error(w, "invalid Upload-Offset", http.StatusBadRequest)
+
+			return
+		}
+
+		chunkSum, err := upload.ParseChecksum(r.Header.Get(upload.HeaderChecksum))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
+		if r.ContentLength > maxChunk {
+			http.Error(w, "chunk is too large", http.StatusRequestEntityTooLarge)
+
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxChunk)
+
+		newOffset, err := chunkWriter.WriteChunk(id, offset, r.Body, chunkSum)
+		if newOffset > 0 || err == nil {
+			w.Header().Set(upload.HeaderOffset, strconv.FormatInt(newOffset, 10))
+		}
+
+		var maxBytesErr *http.MaxBytesError
+
+		switch {
+		case err == nil:
+			w.WriteHeader(http.StatusNoContent)
+		case errors.Is(err, upload.ErrNotFound):
+			http.Error(w, "upload not found", http.StatusNotFound)
+		case errors.Is(err, upload.ErrOffsetMismatch):
+			http.Error(w, "Upload-Offset does not match uploaded size", http.StatusConflict)
+		case errors.Is(err, upload.ErrBusy):
+			http.Error(w, "upload is being written by another request", http.StatusLocked)
+		case errors.Is(err, upload.ErrTooLarge), errors.As(err, &maxBytesErr):
+			http.Error(w, "chunk is too large", http.StatusRequestEntityTooLarge)
+		case errors.Is(err, upload.ErrChecksumMismatch):
+			http.Error(w, "checksum mismatch", upload.StatusChecksumMismatch)
+		default:
+			log.Error("failed write upload chunk", sl.Err(err))
+
+			http.Error(w, "failed write upload chunk", http.StatusInternalServerError)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package upload_chunk_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"jadesheart/comix_back/internal/http-server/handlers/comix/upload_chunk"
+	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"jadesheart/comix_back/internal/lib/upload"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func newRouter(t *testing.T, maxChunk int64) (*upload.Store, http.Handler) {
+	store, err := upload.New(t.TempDir(), 1024)
+	assert.NoError(t, err)
+
+	router := chi.NewRouter()
+	router.Patch("/uploads/{id}", upload_chunk.New(slogdiscard.NewDiscardLogger(), store, maxChunk))
+
+	return store, router
+}
+
+func patch(router http.Handler, id string, offset int64, chunk []byte, checksum string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPatch, "/uploads/"+id, bytes.NewReader(chunk))
+	req.Header.Set("Content-Type", upload.ContentType)
+	req.Header.Set(upload.HeaderOffset, strconv.FormatInt(offset, 10))
+	if checksum != "" {
+		req.Header.Set(upload.HeaderChecksum, checksum)
+	}
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	return rr
+}
+
+func sha256Header(data []byte) string {
+	sum := sha256.Sum256(data)
+
+	return "sha256 " + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func TestUploadChunk_Resume(t *testing.T) {
+	store, router := newRouter(t, 16)
+
+	info, err := store.Create("page.jpg", 10, "")
+	assert.NoError(t, err)
+
+	rr := patch(router, info.ID, 0, []byte("hello"), sha256Header([]byte("hello")))
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+	assert.Equal(t, "5", rr.Header().Get(upload.HeaderOffset))
+	assert.Equal(t, upload.TusVersion, rr.Header().Get(upload.HeaderResumable))
+
+	// Повтор уже принятой части - клиент должен спросить offset через HEAD
+	rr = patch(router, info.ID, 0, []byte("hello"), "")
+	assert.Equal(t, http.StatusConflict, rr.Code)
+
+	rr = patch(router, info.ID, 5, []byte("world"), "")
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+	assert.Equal(t, "10", rr.Header().Get(upload.HeaderOffset))
+}
+
+func TestUploadChunk_Errors(t *testing.T) {
+	store, router := newRouter(t, 8)
+
+	info, err := store.Create("page.jpg", 20, "")
+	assert.NoError(t, err)
+
+	rr := patch(router, info.ID, 0, []byte("hello"), sha256Header([]byte("other")))
+	assert.Equal(t, upload.StatusChecksumMismatch, rr.Code)
+
+	rr = patch(router, info.ID, 0, []byte("too large chunk"), "")
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+
+	rr = patch(router, "0123456789abcdef0123456789abcdef", 0, []byte("hello"), "")
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	rr = patch(router, "../../etc", 0, []byte("hello"), "")
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	req := httptest.NewRequest(http.MethodPatch, "/uploads/"+info.ID, bytes.NewReader([]byte("hello")))
+	req.Header.Set(upload.HeaderOffset, "0")
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusUnsupportedMediaType, rr.Code)
+
+	// После ошибок загрузка осталась пустой
+	got, err := store.Get(info.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), got.Offset)
+}
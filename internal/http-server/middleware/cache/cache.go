@@ -0,0 +1,142 @@
+package cache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+type ResponseCache interface {
+	Get(group string, key string) ([]byte, bool)
+	Set(group string, key string, value []byte)
+}
+
+// GroupFunc возвращает группу кэша для запроса по его телу.
+// Пустая строка - запрос не кэшируется.
+type GroupFunc func(body []byte) string
+
+// maxBodySize - тела больше этого размера не кэшируются и проходят к обработчику как есть.
+const maxBodySize = 64 << 10
+
+// New кэширует успешные JSON-ответы обработчика в группе, которую вернул group.
+// Ключ записи строится из пути и тела запроса. Запросы с заголовком Authorization
+// не кэшируются: ответ на них может зависеть от читателя.
+func New(log *slog.Logger, responseCache ResponseCache, group GroupFunc) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		log := log.With(
+			slog.String("component", "middleware/cache"),
+		)
+
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "" {
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			body, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize+1))
+			if err != nil {
+				log.Error("failed read request body",
+					slog.String("request_id", middleware.GetReqID(r.Context())),
+					sl.Err(err),
+				)
+
+				http.Error(w, "failed read request body", http.StatusBadRequest)
+
+				return
+			}
+			r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), r.Body))
+
+			g := ""
+			if len(body) <= maxBodySize {
+				g = group(body)
+			}
+			if g == "" {
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			sum := sha256.Sum256(append([]byte(r.URL.RequestURI()+"\n"), body...))
+			key := hex.EncodeToString(sum[:])
+
+			if cached, ok := responseCache.Get(g, key); ok {
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("X-Cache", "HIT")
+				_, _ = w.Write(cached)
+
+				return
+			}
+
+			w.Header().Set("X-Cache", "MISS")
+
+			rec := &recorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rec, r)
+
+			if rec.status == http.StatusOK && isStatusOK(rec.body.Bytes()) {
+				responseCache.Set(g, key, rec.body.Bytes())
+			}
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// Group - все запросы маршрута попадают в одну группу.
+func Group(group string) GroupFunc {
+	return func(_ []byte) string {
+		return group
+	}
+}
+
+// TagGroup - группа зависит от поля tagName тела запроса.
+func TagGroup(group func(tag string) string) GroupFunc {
+	return func(body []byte) string {
+		var req struct {
+			TagName string `json:"tagName"`
+		}
+
+		if err := json.Unmarshal(body, &req); err != nil || req.TagName == "" {
+			return ""
+		}
+
+		return group(req.TagName)
+	}
+}
+
+// isStatusOK проверяет поле status ответа: обработчики отдают ошибки с HTTP 200.
+func isStatusOK(body []byte) bool {
+	var res struct {
+		Status int `json:"status"`
+	}
+
+	if err := json.Unmarshal(body, &res); err != nil {
+		return false
+	}
+
+	return res.Status == http.StatusOK
+}
+
+type recorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *recorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+
+	return r.ResponseWriter.Write(b)
+}
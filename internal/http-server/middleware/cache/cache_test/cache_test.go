@@ -0,0 +1,99 @@
+package cache_test
+
+import (
+	"bytes"
+	"github.com/go-chi/render"
+	"github.com/stretchr/testify/assert"
+	mwCache "jadesheart/comix_back/internal/http-server/middleware/cache"
+	"jadesheart/comix_back/internal/lib/cache"
+	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type countingHandler struct {
+	calls  int
+	status int
+}
+
+func (h *countingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.calls++
+	render.JSON(w, r, map[string]int{"status": h.status, "calls": h.calls})
+}
+
+func doRequest(handler http.Handler, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/gettagdescription", bytes.NewBufferString(body))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	return rr
+}
+
+func TestCacheMiddleware_HitAndInvalidate(t *testing.T) {
+	responseCache := cache.New(cache.NewMemory(), time.Minute)
+	next := &countingHandler{status: http.StatusOK}
+
+	handler := mwCache.New(slogdiscard.NewDiscardLogger(), responseCache, mwCache.TagGroup(cache.KeyTagDescription))(next)
+
+	first := doRequest(handler, `{"tagName":"horror"}`)
+	second := doRequest(handler, `{"tagName":"horror"}`)
+
+	assert.Equal(t, 1, next.calls)
+	assert.Equal(t, "MISS", first.Header().Get("X-Cache"))
+	assert.Equal(t, "HIT", second.Header().Get("X-Cache"))
+	assert.Equal(t, first.Body.String(), second.Body.String())
+
+	doRequest(handler, `{"tagName":"comedy"}`)
+	assert.Equal(t, 2, next.calls)
+
+	responseCache.Invalidate(cache.KeyTagDescription("Horror"))
+
+	doRequest(handler, `{"tagName":"horror"}`)
+	assert.Equal(t, 3, next.calls)
+}
+
+func TestCacheMiddleware_SkipsErrors(t *testing.T) {
+	responseCache := cache.New(cache.NewMemory(), time.Minute)
+	next := &countingHandler{status: http.StatusBadRequest}
+
+	handler := mwCache.New(slogdiscard.NewDiscardLogger(), responseCache, mwCache.Group(cache.KeyAllTags))(next)
+
+	doRequest(handler, `{}`)
+	doRequest(handler, `{}`)
+
+	assert.Equal(t, 2, next.calls)
+}
+
+func TestCacheMiddleware_NoGroup(t *testing.T) {
+	responseCache := cache.New(cache.NewMemory(), time.Minute)
+	next := &countingHandler{status: http.StatusOK}
+
+	handler := mwCache.New(slogdiscard.NewDiscardLogger(), responseCache, mwCache.TagGroup(cache.KeyTagDescription))(next)
+
+	doRequest(handler, `{}`)
+	doRequest(handler, `{}`)
+
+	assert.Equal(t, 2, next.calls)
+}
+
+func TestCacheMiddleware_SkipsAuthorized(t *testing.T) {
+	responseCache := cache.New(cache.NewMemory(), time.Minute)
+	next := &countingHandler{status: http.StatusOK}
+
+	handler := mwCache.New(slogdiscard.NewDiscardLogger(), responseCache, mwCache.Group(cache.KeyMainPage))(next)
+
+	doRequest(handler, `{"pageNumber":1}`)
+
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest("POST", "/gettagdescription", bytes.NewBufferString(`{"pageNumber":1}`))
+		req.Header.Set("Authorization", "Bearer token")
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		assert.Empty(t, rr.Header().Get("X-Cache"))
+	}
+
+	assert.Equal(t, 3, next.calls)
+}
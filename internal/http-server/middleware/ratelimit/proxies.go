@@ -0,0 +1,108 @@
+package ratelimit
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Proxies - обратные прокси или CDN перед сервисом. Запрос, пришедший с адреса из CIDRs,
+// считается пересланным: адрес клиента берётся из заголовка Header, например X-Forwarded-For.
+// Без прокси клиент - r.RemoteAddr, а заголовку не верят: его может поставить кто угодно.
+type Proxies struct {
+	Header string
+	CIDRs  []*net.IPNet
+}
+
+// ParseProxies разбирает подсети прокси из конфига. Отдельный адрес считается подсетью из одного адреса.
+func ParseProxies(header string, cidrs []string) (Proxies, error) {
+	proxies := Proxies{Header: header}
+
+	for _, cidr := range cidrs {
+		if !strings.Contains(cidr, "/") {
+			ip := net.ParseIP(cidr)
+			if ip == nil {
+				return Proxies{}, fmt.Errorf("invalid trusted proxy %q", cidr)
+			}
+
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				bits = 8 * net.IPv4len
+			}
+			cidr = fmt.Sprintf("%s/%d", cidr, bits)
+		}
+
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return Proxies{}, fmt.Errorf("invalid trusted proxy %q: %w", cidr, err)
+		}
+
+		proxies.CIDRs = append(proxies.CIDRs, network)
+	}
+
+	if len(proxies.CIDRs) > 0 && header == "" {
+		return Proxies{}, fmt.Errorf("trusted proxies need a client address header")
+	}
+
+	return proxies, nil
+}
+
+// ClientIP - адрес клиента запроса. Заголовок читается справа налево: правые адреса дописали
+// доверенные прокси, а первый недоверенный адрес - тот, кто подключился к ним.
+// Всё, что левее, клиент мог написать сам.
+func (p Proxies) ClientIP(r *http.Request) string {
+	ip := remoteIP(r)
+
+	if p.Header == "" || !p.trusted(ip) {
+		return ip
+	}
+
+	values := r.Header.Values(p.Header)
+
+	var hops []string
+	for _, value := range values {
+		for _, hop := range strings.Split(value, ",") {
+			hops = append(hops, strings.TrimSpace(hop))
+		}
+	}
+
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := net.ParseIP(hops[i])
+		if hop == nil {
+			// Мусор в заголовке: дальше адресам верить нельзя, клиент - последний известный хоп
+			return ip
+		}
+
+		ip = hop.String()
+		if !p.trusted(ip) {
+			return ip
+		}
+	}
+
+	return ip
+}
+
+func (p Proxies) trusted(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+
+	for _, network := range p.CIDRs {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
@@ -0,0 +1,278 @@
+package ratelimit
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+
+	resp "jadesheart/comix_back/internal/lib/api/response"
+)
+
+// Budget - бюджет запросов для группы маршрутов.
+type Budget struct {
+	Name      string
+	PerMinute int
+	Burst     int
+	// Protected - маршруты защищены паролем, и на них действует блокировка
+	// после неудачных попыток ввода пароля.
+	Protected bool
+	// Key - ключ бюджета вместо IP, например id читателя. nil или пустая строка - бюджет по IP.
+	Key func(r *http.Request) string
+}
+
+// Limiter хранит корзины токенов по ключу "IP + маршрут" и счётчики неудачных
+// попыток ввода пароля по IP.
+type Limiter struct {
+	mu           sync.Mutex
+	buckets      map[string]*bucket
+	lockouts     map[string]*lockout
+	lockoutBase  time.Duration
+	lockoutMax   time.Duration
+	freeFailures int
+	requests     int
+	proxies      Proxies
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+type lockout struct {
+	failures    int
+	lockedUntil time.Time
+	last        time.Time
+}
+
+// sweepEvery - раз в сколько запросов удалять устаревшие корзины и блокировки.
+const sweepEvery = 1024
+
+// NewLimiter создаёт лимитер. После freeFailures неудачных попыток подряд IP блокируется
+// на lockoutBase, и каждая следующая неудача удваивает блокировку, но не больше lockoutMax.
+func NewLimiter(lockoutBase time.Duration, lockoutMax time.Duration, freeFailures int) *Limiter {
+	return &Limiter{
+		buckets:      make(map[string]*bucket),
+		lockouts:     make(map[string]*lockout),
+		lockoutBase:  lockoutBase,
+		lockoutMax:   lockoutMax,
+		freeFailures: freeFailures,
+	}
+}
+
+// TrustProxies задаёт прокси, за которыми работает сервис. Вызывается до начала обработки запросов.
+func (l *Limiter) TrustProxies(proxies Proxies) {
+	l.proxies = proxies
+}
+
+type ctxKey struct{}
+
+type client struct {
+	limiter *Limiter
+	ip      string
+}
+
+func New(log *slog.Logger, limiter *Limiter, budget Budget) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		log := log.With(
+			slog.String("component", "middleware/ratelimit"),
+			slog.String("budget", budget.Name),
+		)
+
+		log.Info("rate limit middleware enabled", slog.Int("per_minute", budget.PerMinute), slog.Int("burst", budget.Burst))
+
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			ip := limiter.proxies.ClientIP(r)
+
+			route := r.URL.Path
+			if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+				route = rctx.RoutePattern()
+			}
+
+			if budget.Protected {
+				if wait, locked := limiter.locked(ip); locked {
+					log.Warn("client locked out after failed authentication",
+						slog.String("remote_addr", ip),
+						slog.String("route", route),
+						slog.String("request_id", middleware.GetReqID(r.Context())),
+					)
+
+					tooManyRequests(w, r, wait, "too many failed password attempts, try again later")
+
+					return
+				}
+			}
+
+			key := ip
+			if budget.Key != nil {
+				if k := budget.Key(r); k != "" {
+					key = k
+				}
+			}
+
+			if wait, ok := limiter.allow(key+" "+budget.Name+" "+route, budget); !ok {
+				log.Info("rate limit exceeded",
+					slog.String("remote_addr", ip),
+					slog.String("route", route),
+					slog.String("request_id", middleware.GetReqID(r.Context())),
+				)
+
+				tooManyRequests(w, r, wait, "too many requests")
+
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), ctxKey{}, &client{limiter: limiter, ip: ip})
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// ClientIP - адрес клиента запроса, найденный middleware с учётом доверенных прокси.
+// Без middleware - адрес из r.RemoteAddr.
+func ClientIP(r *http.Request) string {
+	if c, ok := r.Context().Value(ctxKey{}).(*client); ok {
+		return c.ip
+	}
+
+	return remoteIP(r)
+}
+
+// AuthFailed отмечает неудачную попытку ввода пароля для клиента запроса.
+// Без middleware ничего не делает.
+func AuthFailed(ctx context.Context) {
+	if c, ok := ctx.Value(ctxKey{}).(*client); ok {
+		c.limiter.fail(c.ip)
+	}
+}
+
+// AuthSucceeded сбрасывает счётчик неудачных попыток клиента запроса.
+func AuthSucceeded(ctx context.Context) {
+	if c, ok := ctx.Value(ctxKey{}).(*client); ok {
+		c.limiter.reset(c.ip)
+	}
+}
+
+func (l *Limiter) allow(key string, budget Budget) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.sweep(now)
+
+	rate := float64(budget.PerMinute) / 60
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(budget.Burst), last: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = math.Min(float64(budget.Burst), b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+
+	if b.tokens < 1 {
+		if rate <= 0 {
+			return time.Minute, false
+		}
+
+		return time.Duration((1 - b.tokens) / rate * float64(time.Second)), false
+	}
+
+	b.tokens--
+
+	return 0, true
+}
+
+func (l *Limiter) locked(ip string) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	lo, ok := l.lockouts[ip]
+	if !ok {
+		return 0, false
+	}
+
+	wait := time.Until(lo.lockedUntil)
+
+	return wait, wait > 0
+}
+
+func (l *Limiter) fail(ip string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+
+	lo, ok := l.lockouts[ip]
+	if !ok {
+		lo = &lockout{}
+		l.lockouts[ip] = lo
+	}
+
+	lo.failures++
+	lo.last = now
+
+	if lo.failures <= l.freeFailures {
+		return
+	}
+
+	duration := l.lockoutBase << (lo.failures - l.freeFailures - 1)
+	if duration <= 0 || duration > l.lockoutMax {
+		duration = l.lockoutMax
+	}
+
+	lo.lockedUntil = now.Add(duration)
+}
+
+func (l *Limiter) reset(ip string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.lockouts, ip)
+}
+
+// sweep удаляет полные корзины и забытые блокировки. Вызывается под мьютексом.
+func (l *Limiter) sweep(now time.Time) {
+	l.requests++
+	if l.requests%sweepEvery != 0 {
+		return
+	}
+
+	for key, b := range l.buckets {
+		if now.Sub(b.last) > time.Hour {
+			delete(l.buckets, key)
+		}
+	}
+
+	for ip, lo := range l.lockouts {
+		if now.After(lo.lockedUntil) && now.Sub(lo.last) > l.lockoutMax {
+			delete(l.lockouts, ip)
+		}
+	}
+}
+
+func tooManyRequests(w http.ResponseWriter, r *http.Request, wait time.Duration, msg string) {
+	seconds := int(math.Ceil(wait.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+
+	render.Status(r, http.StatusTooManyRequests)
+	render.JSON(w, r, resp.Response{
+		Status: http.StatusTooManyRequests,
+		Error:  msg,
+	})
+}
@@ -0,0 +1,207 @@
+package ratelimit_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"jadesheart/comix_back/internal/http-server/middleware/ratelimit"
+	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func okHandler(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+// authHandler имитирует обработчик с паролем: "password" - верный пароль.
+func authHandler(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("password") != "password" {
+		ratelimit.AuthFailed(r.Context())
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	ratelimit.AuthSucceeded(r.Context())
+	w.WriteHeader(http.StatusOK)
+}
+
+func doRequest(handler http.Handler, url string, remoteAddr string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", url, nil)
+	req.RemoteAddr = remoteAddr
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	return rr
+}
+
+func TestRateLimit_BudgetExceeded(t *testing.T) {
+	limiter := ratelimit.NewLimiter(time.Second, time.Minute, 3)
+	budget := ratelimit.Budget{Name: "read", PerMinute: 1, Burst: 2}
+
+	handler := ratelimit.New(slogdiscard.NewDiscardLogger(), limiter, budget)(http.HandlerFunc(okHandler))
+
+	assert.Equal(t, http.StatusOK, doRequest(handler, "/alltags", "10.0.0.1:1000").Code)
+	assert.Equal(t, http.StatusOK, doRequest(handler, "/alltags", "10.0.0.1:1001").Code)
+
+	rr := doRequest(handler, "/alltags", "10.0.0.1:1002")
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.NotEmpty(t, rr.Header().Get("Retry-After"))
+
+	// Другой маршрут и другой IP имеют свои бюджеты
+	assert.Equal(t, http.StatusOK, doRequest(handler, "/getcomix", "10.0.0.1:1003").Code)
+	assert.Equal(t, http.StatusOK, doRequest(handler, "/alltags", "10.0.0.2:1000").Code)
+}
+
+func TestRateLimit_SeparateReadAndWriteBudgets(t *testing.T) {
+	limiter := ratelimit.NewLimiter(time.Second, time.Minute, 3)
+	read := ratelimit.New(slogdiscard.NewDiscardLogger(), limiter, ratelimit.Budget{Name: "read", PerMinute: 60, Burst: 1})
+	write := ratelimit.New(slogdiscard.NewDiscardLogger(), limiter, ratelimit.Budget{Name: "write", PerMinute: 60, Burst: 1, Protected: true})
+
+	assert.Equal(t, http.StatusOK, doRequest(read(http.HandlerFunc(okHandler)), "/route", "10.0.0.1:1").Code)
+	assert.Equal(t, http.StatusOK, doRequest(write(http.HandlerFunc(okHandler)), "/route", "10.0.0.1:1").Code)
+}
+
+func TestRateLimit_LockoutAfterFailedAuth(t *testing.T) {
+	limiter := ratelimit.NewLimiter(time.Minute, time.Hour, 2)
+	budget := ratelimit.Budget{Name: "write", PerMinute: 600, Burst: 100, Protected: true}
+
+	handler := ratelimit.New(slogdiscard.NewDiscardLogger(), limiter, budget)(http.HandlerFunc(authHandler))
+
+	// Две бесплатные ошибки, третья включает блокировку
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusOK, doRequest(handler, "/deletecomix?password=wrong", "10.0.0.1:1").Code)
+	}
+
+	rr := doRequest(handler, "/deletecomix?password=password", "10.0.0.1:1")
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "60", rr.Header().Get("Retry-After"))
+
+	// Блокировка действует на IP, а не на маршрут
+	assert.Equal(t, http.StatusTooManyRequests, doRequest(handler, "/editcomix", "10.0.0.1:1").Code)
+	assert.Equal(t, http.StatusOK, doRequest(handler, "/deletecomix?password=password", "10.0.0.2:1").Code)
+}
+
+func TestRateLimit_SuccessResetsFailures(t *testing.T) {
+	limiter := ratelimit.NewLimiter(time.Minute, time.Hour, 2)
+	budget := ratelimit.Budget{Name: "write", PerMinute: 600, Burst: 100, Protected: true}
+
+	handler := ratelimit.New(slogdiscard.NewDiscardLogger(), limiter, budget)(http.HandlerFunc(authHandler))
+
+	doRequest(handler, "/newtag?password=wrong", "10.0.0.1:1")
+	doRequest(handler, "/newtag?password=wrong", "10.0.0.1:1")
+	doRequest(handler, "/newtag?password=password", "10.0.0.1:1")
+	doRequest(handler, "/newtag?password=wrong", "10.0.0.1:1")
+
+	assert.Equal(t, http.StatusOK, doRequest(handler, "/newtag?password=password", "10.0.0.1:1").Code)
+}
+
+func TestRateLimit_BudgetKey(t *testing.T) {
+	limiter := ratelimit.NewLimiter(time.Second, time.Minute, 3)
+	budget := ratelimit.Budget{Name: "comment", PerMinute: 1, Burst: 1, Key: func(r *http.Request) string {
+		return r.URL.Query().Get("reader")
+	}}
+
+	handler := ratelimit.New(slogdiscard.NewDiscardLogger(), limiter, budget)(http.HandlerFunc(okHandler))
+
+	// Бюджет по ключу не зависит от IP
+	assert.Equal(t, http.StatusOK, doRequest(handler, "/comments?reader=1", "10.0.0.1:1").Code)
+	assert.Equal(t, http.StatusTooManyRequests, doRequest(handler, "/comments?reader=1", "10.0.0.2:1").Code)
+	assert.Equal(t, http.StatusOK, doRequest(handler, "/comments?reader=2", "10.0.0.2:1").Code)
+
+	// Без ключа бюджет считается по IP
+	assert.Equal(t, http.StatusOK, doRequest(handler, "/comments", "10.0.0.3:1").Code)
+	assert.Equal(t, http.StatusTooManyRequests, doRequest(handler, "/comments", "10.0.0.3:1").Code)
+}
+
+func doForwardedRequest(handler http.Handler, url string, remoteAddr string, forwardedFor string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", url, nil)
+	req.RemoteAddr = remoteAddr
+	req.Header.Set("X-Forwarded-For", forwardedFor)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	return rr
+}
+
+func TestRateLimit_DirectClientIgnoresForwardedHeader(t *testing.T) {
+	proxies, err := ratelimit.ParseProxies("X-Forwarded-For", []string{"10.0.0.0/8"})
+	assert.NoError(t, err)
+
+	limiter := ratelimit.NewLimiter(time.Minute, time.Hour, 0)
+	limiter.TrustProxies(proxies)
+	handler := ratelimit.New(slogdiscard.NewDiscardLogger(), limiter, ratelimit.Budget{Name: "write", PerMinute: 60, Burst: 60, Protected: true})(http.HandlerFunc(authHandler))
+
+	// Клиент не за прокси и сам пишет чужой адрес в заголовок - блокируется его собственный адрес
+	assert.Equal(t, http.StatusOK, doForwardedRequest(handler, "/delete?password=wrong", "203.0.113.5:1000", "198.51.100.7").Code)
+	assert.Equal(t, http.StatusTooManyRequests, doForwardedRequest(handler, "/delete?password=password", "203.0.113.5:1001", "198.51.100.8").Code)
+	assert.Equal(t, http.StatusOK, doRequest(handler, "/delete?password=password", "198.51.100.7:1000").Code)
+}
+
+func TestRateLimit_ProxiedClientsHaveSeparateLockouts(t *testing.T) {
+	proxies, err := ratelimit.ParseProxies("X-Forwarded-For", []string{"10.0.0.0/8", "192.0.2.1"})
+	assert.NoError(t, err)
+
+	limiter := ratelimit.NewLimiter(time.Minute, time.Hour, 0)
+	limiter.TrustProxies(proxies)
+	handler := ratelimit.New(slogdiscard.NewDiscardLogger(), limiter, ratelimit.Budget{Name: "write", PerMinute: 60, Burst: 60, Protected: true})(http.HandlerFunc(authHandler))
+
+	// Все запросы приходят от прокси 10.0.0.1, клиент - из X-Forwarded-For
+	assert.Equal(t, http.StatusOK, doForwardedRequest(handler, "/delete?password=wrong", "10.0.0.1:1000", "203.0.113.5").Code)
+	assert.Equal(t, http.StatusTooManyRequests, doForwardedRequest(handler, "/delete?password=password", "10.0.0.1:1001", "203.0.113.5").Code)
+
+	// Другой клиент за тем же прокси не заблокирован
+	assert.Equal(t, http.StatusOK, doForwardedRequest(handler, "/delete?password=password", "10.0.0.1:1002", "198.51.100.7").Code)
+
+	// Адрес, подставленный клиентом левее, не помогает: справа налево первый недоверенный - 203.0.113.5
+	assert.Equal(t, http.StatusTooManyRequests, doForwardedRequest(handler, "/delete?password=password", "10.0.0.1:1003", "198.51.100.9, 203.0.113.5, 192.0.2.1").Code)
+}
+
+func TestClientIP(t *testing.T) {
+	proxies, err := ratelimit.ParseProxies("X-Forwarded-For", []string{"10.0.0.0/8"})
+	assert.NoError(t, err)
+
+	limiter := ratelimit.NewLimiter(time.Minute, time.Hour, 0)
+	limiter.TrustProxies(proxies)
+
+	var clientIP string
+	handler := ratelimit.New(slogdiscard.NewDiscardLogger(), limiter, ratelimit.Budget{Name: "read", PerMinute: 60, Burst: 60})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			clientIP = ratelimit.ClientIP(r)
+		}))
+
+	doForwardedRequest(handler, "/photo", "10.0.0.1:1000", "203.0.113.5")
+	assert.Equal(t, "203.0.113.5", clientIP)
+
+	// Без middleware - адрес подключения без порта
+	req := httptest.NewRequest("GET", "/photo", nil)
+	req.RemoteAddr = "198.51.100.7:1000"
+	assert.Equal(t, "198.51.100.7", ratelimit.ClientIP(req))
+}
+
+func TestParseProxies_Invalid(t *testing.T) {
+	_, err := ratelimit.ParseProxies("X-Forwarded-For", []string{"not-an-ip"})
+	assert.Error(t, err)
+
+	_, err = ratelimit.ParseProxies("", []string{"10.0.0.0/8"})
+	assert.Error(t, err)
+}
+
+func TestRateLimit_ReaderAndAdminLockoutsAreSeparate(t *testing.T) {
+	adminLimiter := ratelimit.NewLimiter(time.Minute, time.Hour, 0)
+	readerLimiter := ratelimit.NewLimiter(time.Minute, time.Hour, 0)
+
+	admin := ratelimit.New(slogdiscard.NewDiscardLogger(), adminLimiter, ratelimit.Budget{Name: "write", PerMinute: 60, Burst: 60, Protected: true})(http.HandlerFunc(authHandler))
+	reader := ratelimit.New(slogdiscard.NewDiscardLogger(), readerLimiter, ratelimit.Budget{Name: "reader_auth", PerMinute: 60, Burst: 60, Protected: true})(http.HandlerFunc(authHandler))
+
+	// Читатель ошибся паролем - заблокирован только вход читателей с этого IP
+	assert.Equal(t, http.StatusOK, doRequest(reader, "/api/readers/login?password=wrong", "10.0.0.1:1000").Code)
+	assert.Equal(t, http.StatusTooManyRequests, doRequest(reader, "/api/readers/login?password=password", "10.0.0.1:1001").Code)
+	assert.Equal(t, http.StatusOK, doRequest(admin, "/delete?password=password", "10.0.0.1:1002").Code)
+
+	// И наоборот
+	assert.Equal(t, http.StatusOK, doRequest(admin, "/delete?password=wrong", "10.0.0.2:1000").Code)
+	assert.Equal(t, http.StatusTooManyRequests, doRequest(admin, "/delete?password=password", "10.0.0.2:1001").Code)
+	assert.Equal(t, http.StatusOK, doRequest(reader, "/api/readers/login?password=password", "10.0.0.2:1002").Code)
+}
@@ -0,0 +1,118 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+
+	"jadesheart/comix_back/internal/lib/account"
+	resp "jadesheart/comix_back/internal/lib/api/response"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+)
+
+type ReaderGetter interface {
+	GetReaderBySession(tokenHash string) (postgres.Reader, error)
+}
+
+type ctxKey struct{}
+
+const bearerPrefix = "Bearer "
+
+// New находит читателя по токену из заголовка "Authorization: Bearer <токен>" и кладёт его в контекст.
+// Без заголовка или с недействительным токеном запрос идёт дальше как анонимный.
+func New(log *slog.Logger, readerGetter ReaderGetter) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		log := log.With(
+			slog.String("component", "middleware/session"),
+		)
+
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			token := Token(r)
+			if token == "" {
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			reader, err := readerGetter.GetReaderBySession(account.HashToken(token))
+			if err != nil {
+				if !errors.Is(err, storage.ErrSessionNotFound) {
+					log.Error("failed get reader session",
+						slog.String("request_id", middleware.GetReqID(r.Context())),
+						sl.Err(err),
+					)
+				}
+
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(WithReader(r.Context(), reader)))
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// Required пропускает только запросы с действующей сессией читателя, остальным отвечает 401.
+func Required(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := Reader(r.Context()); !ok {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, resp.Response{
+				Status: http.StatusUnauthorized,
+				Error:  "authorization required",
+			})
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+// Reader - читатель запроса, false если запрос анонимный.
+func Reader(ctx context.Context) (postgres.Reader, bool) {
+	reader, ok := ctx.Value(ctxKey{}).(postgres.Reader)
+
+	return reader, ok
+}
+
+// WithReader кладёт читателя в контекст, как это делает New.
+func WithReader(ctx context.Context, reader postgres.Reader) context.Context {
+	return context.WithValue(ctx, ctxKey{}, reader)
+}
+
+// ReaderKey - ключ для ratelimit.Budget: бюджет считается по читателю, а не по IP.
+// Для анонимного запроса пустая строка.
+func ReaderKey(r *http.Request) string {
+	reader, ok := Reader(r.Context())
+	if !ok {
+		return ""
+	}
+
+	return "reader:" + strconv.Itoa(reader.ID)
+}
+
+// Token - токен сессии из заголовка Authorization, пустая строка если его нет.
+func Token(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	if len(header) <= len(bearerPrefix) || !strings.EqualFold(header[:len(bearerPrefix)], bearerPrefix) {
+		return ""
+	}
+
+	return strings.TrimSpace(header[len(bearerPrefix):])
+}
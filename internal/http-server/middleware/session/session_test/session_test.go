@@ -0,0 +1,94 @@
+package session_test
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"jadesheart/comix_back/internal/http-server/middleware/session"
+	"jadesheart/comix_back/internal/lib/account"
+	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"jadesheart/comix_back/internal/storage"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type MockReaderGetter struct {
+	sessions map[string]postgres.Reader
+}
+
+func (m *MockReaderGetter) GetReaderBySession(tokenHash string) (postgres.Reader, error) {
+	if reader, ok := m.sessions[tokenHash]; ok {
+		return reader, nil
+	}
+	return postgres.Reader{}, storage.ErrSessionNotFound
+}
+
+// readerHandler пишет в ответ имя читателя запроса, "-" для анонимного запроса
+func readerHandler(w http.ResponseWriter, r *http.Request) {
+	reader, ok := session.Reader(r.Context())
+	if !ok {
+		_, _ = w.Write([]byte("-"))
+		return
+	}
+	_, _ = w.Write([]byte(reader.Username))
+}
+
+func doRequest(handler http.Handler, authorization string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", "/api/me/bookmarks", nil)
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	return rr
+}
+
+func newGetter() *MockReaderGetter {
+	return &MockReaderGetter{sessions: map[string]postgres.Reader{
+		account.HashToken("good-token"): {ID: 1, Username: "reader"},
+	}}
+}
+
+func TestSession_Optional(t *testing.T) {
+	handler := session.New(slogdiscard.NewDiscardLogger(), newGetter())(http.HandlerFunc(readerHandler))
+
+	assert.Equal(t, "reader", doRequest(handler, "Bearer good-token").Body.String())
+	assert.Equal(t, "reader", doRequest(handler, "bearer good-token").Body.String())
+	assert.Equal(t, "-", doRequest(handler, "").Body.String())
+	assert.Equal(t, "-", doRequest(handler, "Bearer bad-token").Body.String())
+	assert.Equal(t, "-", doRequest(handler, "Basic good-token").Body.String())
+}
+
+type failingGetter struct{}
+
+func (failingGetter) GetReaderBySession(tokenHash string) (postgres.Reader, error) {
+	return postgres.Reader{}, errors.New("db is down")
+}
+
+func TestSession_StorageErrorIsAnonymous(t *testing.T) {
+	handler := session.New(slogdiscard.NewDiscardLogger(), failingGetter{})(http.HandlerFunc(readerHandler))
+
+	rr := doRequest(handler, "Bearer good-token")
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "-", rr.Body.String())
+}
+
+func TestSession_Required(t *testing.T) {
+	handler := session.New(slogdiscard.NewDiscardLogger(), newGetter())(session.Required(http.HandlerFunc(readerHandler)))
+
+	rr := doRequest(handler, "Bearer good-token")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "reader", rr.Body.String())
+
+	rr = doRequest(handler, "Bearer bad-token")
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	assert.Equal(t, "Bearer", rr.Header().Get("WWW-Authenticate"))
+	assert.Contains(t, rr.Body.String(), "authorization required")
+
+	rr = doRequest(handler, "")
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+}
@@ -0,0 +1,94 @@
+package account
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"golang.org/x/crypto/pbkdf2"
+	"strconv"
+	"strings"
+)
+
+// ErrMalformedHash - сохранённый хэш пароля не в формате HashPassword
+var ErrMalformedHash = errors.New("malformed password hash")
+
+const (
+	scheme = "pbkdf2-sha256"
+	// iterations - число итераций PBKDF2, хватает чтобы перебор утёкших хэшей был дорогим
+	iterations = 120000
+	saltSize   = 16
+	keySize    = 32
+	tokenSize  = 32
+)
+
+// DummyHash - хэш в формате HashPassword с теми же параметрами. Им проверяют пароль,
+// когда читатель не найден, чтобы ответ по неизвестному имени шёл столько же, сколько по известному.
+var DummyHash = fmt.Sprintf("%s$%d$%s$%s", scheme, iterations,
+	base64.RawStdEncoding.EncodeToString(make([]byte, saltSize)), base64.RawStdEncoding.EncodeToString(make([]byte, keySize)))
+
+// HashPassword хэширует пароль читателя через PBKDF2-HMAC-SHA256 из golang.org/x/crypto со случайной солью.
+// Результат - строка "pbkdf2-sha256$<итерации>$<соль>$<хэш>", соль и хэш в base64.
+func HashPassword(password string) (string, error) {
+	salt := make([]byte, saltSize)
+
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := pbkdf2.Key([]byte(password), salt, iterations, keySize, sha256.New)
+
+	return fmt.Sprintf("%s$%d$%s$%s", scheme, iterations,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// CheckPassword сравнивает пароль с хэшем из HashPassword за постоянное время.
+func CheckPassword(hash string, password string) (bool, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 4 || parts[0] != scheme {
+		return false, ErrMalformedHash
+	}
+
+	iter, err := strconv.Atoi(parts[1])
+	if err != nil || iter < 1 {
+		return false, ErrMalformedHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return false, ErrMalformedHash
+	}
+
+	expected, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil || len(expected) == 0 {
+		return false, ErrMalformedHash
+	}
+
+	key := pbkdf2.Key([]byte(password), salt, iter, len(expected), sha256.New)
+
+	return subtle.ConstantTimeCompare(key, expected) == 1, nil
+}
+
+// NewToken создаёт токен сессии читателя. Читатель получает token,
+// в базе хранится только hash, чтобы утечка базы не давала войти под читателем.
+func NewToken() (token string, hash string, err error) {
+	b := make([]byte, tokenSize)
+
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+
+	token = hex.EncodeToString(b)
+
+	return token, HashToken(token), nil
+}
+
+// HashToken - хэш токена сессии, по которому сессия ищется в базе.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
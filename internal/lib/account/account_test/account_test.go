@@ -0,0 +1,84 @@
+package account_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"jadesheart/comix_back/internal/lib/account"
+	"strings"
+	"testing"
+)
+
+func TestCheckPassword_StoredHash(t *testing.T) {
+	// Хэш PBKDF2-HMAC-SHA256 посчитан независимо от пакета: сохранённые пароли должны проверяться и дальше
+	hash := "pbkdf2-sha256$1000$MDEyMzQ1Njc4OWFiY2RlZg$cBg8D2DungRB9k76szThf5ehfyBz991ay6PT8Srwk4M"
+
+	ok, err := account.CheckPassword(hash, "correct horse")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = account.CheckPassword(hash, "wrong horse")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestHashPassword_Check(t *testing.T) {
+	hash, err := account.HashPassword("correct horse")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hash, "pbkdf2-sha256$"))
+
+	ok, err := account.CheckPassword(hash, "correct horse")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = account.CheckPassword(hash, "wrong horse")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	other, err := account.HashPassword("correct horse")
+	assert.NoError(t, err)
+	assert.NotEqual(t, hash, other, "salt must be random")
+}
+
+func TestDummyHash_SameParams(t *testing.T) {
+	ok, err := account.CheckPassword(account.DummyHash, "password")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	hash, err := account.HashPassword("password")
+	assert.NoError(t, err)
+
+	dummy := strings.Split(account.DummyHash, "$")
+	hashed := strings.Split(hash, "$")
+	assert.Equal(t, hashed[1], dummy[1], "iterations")
+	assert.Equal(t, len(hashed[2]), len(dummy[2]), "salt size")
+	assert.Equal(t, len(hashed[3]), len(dummy[3]), "key size")
+}
+
+func TestCheckPassword_Malformed(t *testing.T) {
+	cases := []string{
+		"",
+		"plain",
+		"bcrypt$10$c2FsdA$aGFzaA",
+		"pbkdf2-sha256$x$c2FsdA$aGFzaA",
+		"pbkdf2-sha256$0$c2FsdA$aGFzaA",
+		"pbkdf2-sha256$1$!!$aGFzaA",
+		"pbkdf2-sha256$1$c2FsdA$",
+	}
+
+	for _, hash := range cases {
+		_, err := account.CheckPassword(hash, "password")
+		assert.ErrorIs(t, err, account.ErrMalformedHash, hash)
+	}
+}
+
+func TestNewToken(t *testing.T) {
+	token, hash, err := account.NewToken()
+	assert.NoError(t, err)
+
+	assert.Len(t, token, 64)
+	assert.Equal(t, account.HashToken(token), hash)
+	assert.NotEqual(t, token, hash)
+
+	other, _, err := account.NewToken()
+	assert.NoError(t, err)
+	assert.NotEqual(t, token, other)
+}
@@ -35,9 +35,9 @@ func ValidateErrors(errors validator.ValidationErrors) Response {
 	for _, err := range errors {
 		switch err.ActualTag() {
 		case "tagName":
-			errMsg = append(errMsg, fmt.Sprintf("filed %s is a name failed"))
+			errMsg = append(errMsg, fmt.Sprintf("filed %s is a name failed", err.Field()))
 		default:
-			errMsg = append(errMsg, fmt.Sprintf("%s is not valid"))
+			errMsg = append(errMsg, fmt.Sprintf("%s is not valid", err.Field()))
 		}
 	}
 	return Response{
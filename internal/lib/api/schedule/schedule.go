@@ -0,0 +1,42 @@
+package schedule
+
+import (
+	"net/http"
+	"time"
+)
+
+// Param - параметр запроса или поле формы со временем выхода
+const Param = "publishAt"
+
+// Parse разбирает время выхода в RFC 3339. Пустая строка и время не позже now означают выход сразу
+// и дают нулевое время. ok равен false, если время не разобрать.
+func Parse(value string, now time.Time) (publishAt time.Time, ok bool) {
+	if value == "" {
+		return time.Time{}, true
+	}
+
+	publishAt, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	if !publishAt.After(now) {
+		return time.Time{}, true
+	}
+
+	return publishAt, true
+}
+
+// FromRequest читает время выхода из параметра запроса или поля формы publishAt.
+func FromRequest(r *http.Request) (time.Time, bool) {
+	return Parse(r.FormValue(Param), time.Now())
+}
+
+// UploadDate - дата загрузки, которая показывается у комикса: день выхода или сегодняшний день.
+func UploadDate(publishAt time.Time) string {
+	if publishAt.IsZero() {
+		return time.Now().Format("2006-01-02")
+	}
+
+	return publishAt.Format("2006-01-02")
+}
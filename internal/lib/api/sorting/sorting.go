@@ -0,0 +1,23 @@
+package sorting
+
+import (
+	"jadesheart/comix_back/internal/storage/postgres"
+	"net/http"
+)
+
+// Param - параметр запроса с порядком списка
+const Param = "sort"
+
+// Comix читает порядок списка комиксов из параметра sort: new (по умолчанию) или rating.
+// minVotes - сколько оценок нужно комиксу, чтобы встать в список по рейтингу.
+// ok равен false, если порядок неизвестен.
+func Comix(r *http.Request, minVotes int) (order postgres.ComixOrder, ok bool) {
+	switch sort := r.URL.Query().Get(Param); sort {
+	case "", postgres.ComixSortNew:
+		return postgres.ComixOrder{Sort: postgres.ComixSortNew}, true
+	case postgres.ComixSortRating:
+		return postgres.ComixOrder{Sort: postgres.ComixSortRating, MinVotes: minVotes}, true
+	default:
+		return postgres.ComixOrder{}, false
+	}
+}
@@ -0,0 +1,200 @@
+package archive
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrUnsafePath = errors.New("archive entry has unsafe path")
+	ErrTooLarge   = errors.New("archive unpacks to too much data")
+	ErrTooMany    = errors.New("archive has too many pages")
+	ErrNoPages    = errors.New("archive has no pages")
+)
+
+// maxRatio - во сколько раз страница может быть больше своего сжатого размера. Картинки почти не сжимаются,
+// поэтому большое отношение - признак zip-бомбы.
+const maxRatio = 100
+
+// comicInfoName - файл с метаданными комикса в корне архива
+const comicInfoName = "comicinfo.xml"
+
+// imageExts - файлы, которые считаются страницами. Остальное (txt, nfo, миниатюры ОС) пропускается.
+// Здесь только форматы, которые умеет декодировать images.Sanitize: иначе одна страница webp
+// уронила бы весь импорт.
+var imageExts = map[string]bool{
+	".jpg":  true,
+	".jpeg": true,
+	".png":  true,
+	".gif":  true,
+}
+
+// Limits - ограничения на распаковку архива
+type Limits struct {
+	MaxPages     int
+	MaxPageSize  int64
+	MaxTotalSize int64
+}
+
+// Archive - архив комикса: страницы в порядке чтения и метаданные, если они есть
+type Archive struct {
+	Pages []*zip.File
+	Info  *ComicInfo
+
+	limits Limits
+	// unpacked - сколько байт уже распаковано через Open
+	unpacked int64
+}
+
+/*
+*
+  - Читает оглавление zip/cbz архива и проверяет его до распаковки: пути записей, число страниц,
+  - заявленные размеры и степень сжатия. Страницы сортируются по именам в естественном порядке: 2.jpg раньше 10.jpg
+    @param
+  - r - архив
+  - size - размер архива
+  - limits - ограничения на распаковку
+    @return
+  - err - ошибка, ErrUnsafePath, ErrTooLarge, ErrTooMany, ErrNoPages
+  - *Archive - архив
+    *
+*/
+func Read(r io.ReaderAt, size int64, limits Limits) (*Archive, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &Archive{limits: limits}
+
+	var total uint64
+
+	for _, f := range zr.File {
+		name, err := cleanName(f.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		if f.FileInfo().IsDir() || skipped(name) {
+			continue
+		}
+
+		if !f.Mode().IsRegular() {
+			return nil, fmt.Errorf("%w: %s is not a regular file", ErrUnsafePath, f.Name)
+		}
+
+		if strings.EqualFold(name, comicInfoName) {
+			info, err := readComicInfo(f)
+			if err != nil {
+				return nil, err
+			}
+			a.Info = info
+
+			continue
+		}
+
+		if !imageExts[strings.ToLower(path.Ext(name))] {
+			continue
+		}
+
+		if f.UncompressedSize64 > uint64(limits.MaxPageSize) {
+			return nil, fmt.Errorf("%w: page %s is %d bytes", ErrTooLarge, f.Name, f.UncompressedSize64)
+		}
+		if f.CompressedSize64 > 0 && f.UncompressedSize64/f.CompressedSize64 > maxRatio {
+			return nil, fmt.Errorf("%w: page %s is compressed too well", ErrTooLarge, f.Name)
+		}
+
+		total += f.UncompressedSize64
+		if total > uint64(limits.MaxTotalSize) {
+			return nil, ErrTooLarge
+		}
+
+		a.Pages = append(a.Pages, f)
+		if len(a.Pages) > limits.MaxPages {
+			return nil, ErrTooMany
+		}
+	}
+
+	if len(a.Pages) == 0 {
+		return nil, ErrNoPages
+	}
+
+	sort.SliceStable(a.Pages, func(i, j int) bool {
+		return naturalLess(strings.ToLower(a.Pages[i].Name), strings.ToLower(a.Pages[j].Name))
+	})
+
+	return a, nil
+}
+
+// Open открывает страницу для распаковки. Заявленным в архиве размерам не верит:
+// чтение обрывается с ErrTooLarge, как только страница или весь архив превышают ограничения.
+func (a *Archive) Open(f *zip.File) (io.ReadCloser, error) {
+	src, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+
+	left := a.limits.MaxPageSize
+	if rest := a.limits.MaxTotalSize - a.unpacked; rest < left {
+		left = rest
+	}
+
+	return &pageReader{ReadCloser: src, archive: a, name: f.Name, left: left}, nil
+}
+
+// pageReader считает распакованные байты страницы и всего архива
+type pageReader struct {
+	io.ReadCloser
+	archive *Archive
+	name    string
+	left    int64
+}
+
+func (p *pageReader) Read(b []byte) (int, error) {
+	// Читаем на байт больше остатка, чтобы заметить превышение, не распаковывая лишнего
+	if int64(len(b)) > p.left+1 {
+		b = b[:p.left+1]
+	}
+
+	n, err := p.ReadCloser.Read(b)
+	p.left -= int64(n)
+	p.archive.unpacked += int64(n)
+
+	if p.left < 0 {
+		return n, fmt.Errorf("%w: page %s", ErrTooLarge, p.name)
+	}
+
+	return n, err
+}
+
+// cleanName проверяет путь записи: zip-slip возможен только через абсолютные пути и "..".
+// Страницы всё равно сохраняются под новыми именами, но такой архив явно собран со злым умыслом.
+func cleanName(name string) (string, error) {
+	name = strings.ReplaceAll(name, `\`, "/")
+
+	if name == "" || strings.HasPrefix(name, "/") || strings.Contains(name, ":") {
+		return "", fmt.Errorf("%w: %s", ErrUnsafePath, name)
+	}
+
+	for _, part := range strings.Split(name, "/") {
+		if part == ".." {
+			return "", fmt.Errorf("%w: %s", ErrUnsafePath, name)
+		}
+	}
+
+	return path.Clean(name), nil
+}
+
+// skipped - служебные файлы, которые добавляют архиваторы и ОС
+func skipped(name string) bool {
+	if strings.HasPrefix(name, "__MACOSX/") {
+		return true
+	}
+
+	return strings.HasPrefix(path.Base(name), ".")
+}
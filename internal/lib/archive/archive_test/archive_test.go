@@ -0,0 +1,156 @@
+package archive_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"github.com/stretchr/testify/assert"
+	"hash/crc32"
+	"io"
+	"jadesheart/comix_back/internal/lib/archive"
+	"strings"
+	"testing"
+)
+
+var limits = archive.Limits{MaxPages: 10, MaxPageSize: 1 << 20, MaxTotalSize: 4 << 20}
+
+type entry struct {
+	name string
+	data []byte
+}
+
+func makeZip(t *testing.T, entries ...entry) *bytes.Reader {
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+
+	for _, e := range entries {
+		w, err := zw.Create(e.name)
+		assert.NoError(t, err)
+		_, err = w.Write(e.data)
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, zw.Close())
+
+	return bytes.NewReader(buf.Bytes())
+}
+
+func read(t *testing.T, entries ...entry) (*archive.Archive, error) {
+	r := makeZip(t, entries...)
+
+	return archive.Read(r, r.Size(), limits)
+}
+
+func TestRead_NaturalOrder(t *testing.T) {
+	a, err := read(t,
+		entry{"Chapter/page10.jpg", []byte("10")},
+		entry{"Chapter/page2.png", []byte("2")},
+		entry{"Chapter/page1.JPG", []byte("1")},
+		entry{"Chapter/notes.txt", []byte("notes")},
+		entry{"__MACOSX/Chapter/._page1.jpg", []byte("junk")},
+		entry{"Chapter/.thumb.jpg", []byte("junk")},
+	)
+	assert.NoError(t, err)
+
+	var names []string
+	for _, page := range a.Pages {
+		names = append(names, page.Name)
+	}
+	assert.Equal(t, []string{"Chapter/page1.JPG", "Chapter/page2.png", "Chapter/page10.jpg"}, names)
+	assert.Nil(t, a.Info)
+}
+
+func TestRead_SkipsWebp(t *testing.T) {
+	a, err := read(t,
+		entry{"1.jpg", []byte("1")},
+		entry{"2.webp", []byte("RIFF\x1a\x00\x00\x00WEBPVP8 ")},
+	)
+	assert.NoError(t, err)
+
+	if assert.Len(t, a.Pages, 1) {
+		assert.Equal(t, "1.jpg", a.Pages[0].Name)
+	}
+}
+
+func TestRead_RejectsUnsafePaths(t *testing.T) {
+	for _, name := range []string{"../evil.jpg", "pages/../../evil.jpg", "/etc/evil.jpg", `..\evil.jpg`, "C:/evil.jpg"} {
+		_, err := read(t, entry{"1.jpg", []byte("ok")}, entry{name, []byte("evil")})
+		assert.ErrorIs(t, err, archive.ErrUnsafePath, name)
+	}
+}
+
+func TestRead_Limits(t *testing.T) {
+	// Страница из нулей сжимается в тысячи раз - так выглядит zip-бомба
+	_, err := read(t, entry{"1.jpg", make([]byte, 512<<10)})
+	assert.ErrorIs(t, err, archive.ErrTooLarge)
+
+	entries := make([]entry, 0, 11)
+	for i := 0; i < 11; i++ {
+		entries = append(entries, entry{strings.Repeat("a", i+1) + ".jpg", []byte("page")})
+	}
+	_, err = read(t, entries...)
+	assert.ErrorIs(t, err, archive.ErrTooMany)
+
+	_, err = read(t, entry{"readme.txt", []byte("no pages")})
+	assert.ErrorIs(t, err, archive.ErrNoPages)
+}
+
+func TestOpen_DoesNotTrustHeader(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), 100)
+
+	compressed := &bytes.Buffer{}
+	fw, err := flate.NewWriter(compressed, flate.BestCompression)
+	assert.NoError(t, err)
+	fw.Write(data)
+	fw.Close()
+
+	// Заголовок врёт, что страница занимает 10 байт
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	w, err := zw.CreateRaw(&zip.FileHeader{
+		Name:               "1.jpg",
+		Method:             zip.Deflate,
+		CRC32:              crc32.ChecksumIEEE(data),
+		CompressedSize64:   uint64(compressed.Len()),
+		UncompressedSize64: 10,
+	})
+	assert.NoError(t, err)
+	w.Write(compressed.Bytes())
+	assert.NoError(t, zw.Close())
+
+	r := bytes.NewReader(buf.Bytes())
+	a, err := archive.Read(r, r.Size(), archive.Limits{MaxPages: 1, MaxPageSize: 100, MaxTotalSize: 100})
+	assert.NoError(t, err)
+
+	src, err := a.Open(a.Pages[0])
+	assert.NoError(t, err)
+	defer src.Close()
+
+	n, err := io.Copy(io.Discard, src)
+	assert.Error(t, err)
+	assert.LessOrEqual(t, n, int64(101))
+}
+
+func TestRead_ComicInfo(t *testing.T) {
+	a, err := read(t,
+		entry{"1.jpg", []byte("page")},
+		entry{"ComicInfo.xml", []byte(`<?xml version="1.0"?>
+<ComicInfo>
+  <Title>Issue 1</Title>
+  <Series>Watchmen</Series>
+  <Summary>Who watches the watchmen?</Summary>
+  <Writer>Alan Moore</Writer>
+  <Penciller>Dave Gibbons, Alan Moore</Penciller>
+  <Colorist>John Higgins</Colorist>
+  <LanguageISO>en-US</LanguageISO>
+  <AgeRating>Mature 17+</AgeRating>
+</ComicInfo>`)},
+	)
+	assert.NoError(t, err)
+	assert.NotNil(t, a.Info)
+	assert.Len(t, a.Pages, 1)
+
+	assert.Equal(t, "Watchmen", a.Info.Name())
+	assert.Equal(t, []string{"Alan Moore", "Dave Gibbons", "John Higgins"}, a.Info.Authors())
+	assert.Equal(t, "en", a.Info.Language())
+	assert.Equal(t, "18+", a.Info.Rating())
+}
@@ -0,0 +1,113 @@
+package archive
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// maxComicInfoSize - ComicInfo.xml занимает несколько килобайт, больший файл не читается
+const maxComicInfoSize = 1 << 20
+
+// ComicInfo - метаданные комикса из ComicInfo.xml (формат ComicRack). Читаются и пишутся только поля, которые есть у комикса в базе.
+type ComicInfo struct {
+	XMLName     xml.Name `xml:"ComicInfo"`
+	Title       string   `xml:"Title,omitempty"`
+	Series      string   `xml:"Series,omitempty"`
+	Summary     string   `xml:"Summary,omitempty"`
+	Writer      string   `xml:"Writer,omitempty"`
+	Penciller   string   `xml:"Penciller,omitempty"`
+	Inker       string   `xml:"Inker,omitempty"`
+	Colorist    string   `xml:"Colorist,omitempty"`
+	Letterer    string   `xml:"Letterer,omitempty"`
+	CoverArtist string   `xml:"CoverArtist,omitempty"`
+	Tags        string   `xml:"Tags,omitempty"`
+	PageCount   int      `xml:"PageCount,omitempty"`
+	LanguageISO string   `xml:"LanguageISO,omitempty"`
+	AgeRating   string   `xml:"AgeRating,omitempty"`
+}
+
+// languagePattern - начало кода языка: en из en-US
+var languagePattern = regexp.MustCompile(`^([a-zA-Z]{2})(?:[-_].*)?$`)
+
+// ageRatings - рейтинги ComicInfo в рейтингах комиксов. Промежуточные возрасты округляются вверх:
+// Teen (13+) становится 16+, а не 12+.
+var ageRatings = map[string]string{
+	"early childhood": "0+",
+	"everyone":        "0+",
+	"g":               "0+",
+	"kids to adults":  "0+",
+	"pg":              "6+",
+	"everyone 10+":    "12+",
+	"teen":            "16+",
+	"m":               "16+",
+	"ma15+":           "16+",
+	"mature 17+":      "18+",
+	"r18+":            "18+",
+	"x18+":            "18+",
+	"adults only 18+": "18+",
+}
+
+func readComicInfo(f *zip.File) (*ComicInfo, error) {
+	src, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	var info ComicInfo
+
+	err = xml.NewDecoder(io.LimitReader(src, maxComicInfoSize)).Decode(&info)
+	if err != nil {
+		return nil, fmt.Errorf("ComicInfo.xml: %w", err)
+	}
+
+	return &info, nil
+}
+
+// Name - название комикса: серия, а если её нет - заголовок выпуска
+func (c *ComicInfo) Name() string {
+	if name := strings.TrimSpace(c.Series); name != "" {
+		return name
+	}
+
+	return strings.TrimSpace(c.Title)
+}
+
+// Authors - все создатели комикса без повторов, в порядке: сценарист, художники, колорист, леттерер, автор обложки
+func (c *ComicInfo) Authors() []string {
+	authors := []string{}
+	seen := map[string]bool{}
+
+	for _, field := range []string{c.Writer, c.Penciller, c.Inker, c.Colorist, c.Letterer, c.CoverArtist} {
+		for _, author := range strings.Split(field, ",") {
+			author = strings.TrimSpace(author)
+			if author == "" || seen[strings.ToLower(author)] {
+				continue
+			}
+
+			seen[strings.ToLower(author)] = true
+			authors = append(authors, author)
+		}
+	}
+
+	return authors
+}
+
+// Language - код языка ISO 639-1 или пустая строка, если язык не указан или не распознан
+func (c *ComicInfo) Language() string {
+	m := languagePattern.FindStringSubmatch(strings.TrimSpace(c.LanguageISO))
+	if m == nil {
+		return ""
+	}
+
+	return strings.ToLower(m[1])
+}
+
+// Rating - возрастной рейтинг комикса или пустая строка, если рейтинг не указан или неизвестен
+func (c *ComicInfo) Rating() string {
+	return ageRatings[strings.ToLower(strings.TrimSpace(c.AgeRating))]
+}
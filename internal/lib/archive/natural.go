@@ -0,0 +1,56 @@
+package archive
+
+// naturalLess сравнивает строки так, что числа внутри них сравниваются по значению: page2 < page10.
+// При равных значениях число с меньшим количеством ведущих нулей идёт раньше, чтобы порядок был строгим.
+func naturalLess(a string, b string) bool {
+	for a != "" && b != "" {
+		if isDigit(a[0]) && isDigit(b[0]) {
+			numA, restA := splitNumber(a)
+			numB, restB := splitNumber(b)
+
+			trimmedA, trimmedB := trimZeros(numA), trimZeros(numB)
+			if len(trimmedA) != len(trimmedB) {
+				return len(trimmedA) < len(trimmedB)
+			}
+			if trimmedA != trimmedB {
+				return trimmedA < trimmedB
+			}
+			if len(numA) != len(numB) {
+				return len(numA) < len(numB)
+			}
+
+			a, b = restA, restB
+
+			continue
+		}
+
+		if a[0] != b[0] {
+			return a[0] < b[0]
+		}
+
+		a, b = a[1:], b[1:]
+	}
+
+	return len(a) < len(b)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func splitNumber(s string) (string, string) {
+	i := 0
+	for i < len(s) && isDigit(s[i]) {
+		i++
+	}
+
+	return s[:i], s[i:]
+}
+
+func trimZeros(s string) string {
+	for len(s) > 1 && s[0] == '0' {
+		s = s[1:]
+	}
+
+	return s
+}
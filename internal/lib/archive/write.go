@@ -0,0 +1,93 @@
+package archive
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+/*
+*
+  - Пишет комикс в w как cbz: ComicInfo.xml и страницы 001.jpg, 002.jpg... в порядке pages.
+  - Архив собирается по ходу записи, страницы читаются с диска по одной и не сжимаются повторно
+    @param
+  - w - куда писать архив
+  - info - метаданные комикса
+  - pages - пути к файлам страниц по порядку
+    @return
+  - err - ошибка
+    *
+*/
+func WriteCBZ(w io.Writer, info ComicInfo, pages []string) error {
+	zw := zip.NewWriter(w)
+
+	info.PageCount = len(pages)
+
+	f, err := zw.Create("ComicInfo.xml")
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(f, xml.Header)
+	if err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(f)
+	enc.Indent("", "  ")
+
+	err = enc.Encode(info)
+	if err != nil {
+		return err
+	}
+
+	// Ведущие нули, чтобы читалки без естественной сортировки тоже видели правильный порядок
+	width := len(fmt.Sprint(len(pages)))
+	if width < 3 {
+		width = 3
+	}
+
+	for i, page := range pages {
+		name := fmt.Sprintf("%0*d%s", width, i+1, strings.ToLower(filepath.Ext(page)))
+
+		err = writeStored(zw, name, page)
+		if err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+// writeStored кладёт файл в архив без сжатия: картинки уже сжаты
+func writeStored(zw *zip.Writer, name string, path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	stat, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	header := &zip.FileHeader{
+		Name:     name,
+		Method:   zip.Store,
+		Modified: stat.ModTime(),
+	}
+
+	dst, err := zw.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(dst, src)
+
+	return err
+}
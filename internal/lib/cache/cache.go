@@ -0,0 +1,165 @@
+package cache
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Store - хранилище кэша: в памяти процесса (Memory) или Redis (Redis).
+type Store interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte, ttl time.Duration)
+	Delete(keys ...string)
+}
+
+// Группы ключей кэша. Запись в группу инвалидирует все ответы, закэшированные в ней.
+const (
+	KeyMainPage = "main_page"
+	KeyAllTags  = "all_tags"
+	KeySearch   = "search"
+)
+
+func KeyTagDescription(tag string) string {
+	return "tag_description:" + strings.ToLower(tag)
+}
+
+func KeyTagComix(tag string) string {
+	return "tag_comix:" + strings.ToLower(tag)
+}
+
+// generationTTL - сколько живёт поколение группы, если его никто не инвалидировал.
+const generationTTL = 24 * time.Hour
+
+// Cache - кэш ответов, разбитый на группы.
+// Каждая группа имеет своё поколение, которое входит в ключ записи,
+// поэтому инвалидация группы - это удаление одного ключа поколения в любом хранилище.
+type Cache struct {
+	store Store
+	ttl   time.Duration
+}
+
+func New(store Store, ttl time.Duration) *Cache {
+	return &Cache{
+		store: store,
+		ttl:   ttl,
+	}
+}
+
+func (c *Cache) Get(group string, key string) ([]byte, bool) {
+	gen, ok := c.store.Get(generationKey(group))
+	if !ok {
+		return nil, false
+	}
+
+	return c.store.Get(entryKey(group, string(gen), key))
+}
+
+func (c *Cache) Set(group string, key string, value []byte) {
+	gen, ok := c.store.Get(generationKey(group))
+	if !ok {
+		gen = newGeneration()
+		c.store.Set(generationKey(group), gen, generationTTL)
+	}
+
+	c.store.Set(entryKey(group, string(gen), key), value, c.ttl)
+}
+
+// Invalidate сбрасывает все записи переданных групп.
+func (c *Cache) Invalidate(groups ...string) {
+	keys := make([]string, 0, len(groups))
+	for _, group := range groups {
+		keys = append(keys, generationKey(group))
+	}
+
+	c.store.Delete(keys...)
+}
+
+func generationKey(group string) string {
+	return "gen:" + group
+}
+
+func entryKey(group string, gen string, key string) string {
+	return group + ":" + gen + ":" + key
+}
+
+func newGeneration() []byte {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+
+	return []byte(hex.EncodeToString(b))
+}
+
+type item struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+// Memory - простой потокобезопасный кэш в памяти процесса с TTL на каждый ключ.
+type Memory struct {
+	mu    sync.RWMutex
+	items map[string]item
+	sets  int
+}
+
+// sweepEvery - раз в сколько записей удалять просроченные ключи,
+// которые никто больше не читает (например, записи старых поколений).
+const sweepEvery = 1024
+
+func NewMemory() *Memory {
+	return &Memory{
+		items: make(map[string]item),
+	}
+}
+
+func (m *Memory) Get(key string) ([]byte, bool) {
+	m.mu.RLock()
+	it, ok := m.items[key]
+	m.mu.RUnlock()
+
+	if !ok {
+		return nil, false
+	}
+
+	if time.Now().After(it.expiresAt) {
+		m.mu.Lock()
+		delete(m.items, key)
+		m.mu.Unlock()
+
+		return nil, false
+	}
+
+	return it.value, true
+}
+
+func (m *Memory) Set(key string, value []byte, ttl time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+
+	m.items[key] = item{
+		value:     value,
+		expiresAt: now.Add(ttl),
+	}
+
+	m.sets++
+	if m.sets%sweepEvery == 0 {
+		for k, it := range m.items {
+			if now.After(it.expiresAt) {
+				delete(m.items, k)
+			}
+		}
+	}
+}
+
+func (m *Memory) Delete(keys ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, key := range keys {
+		delete(m.items, key)
+	}
+}
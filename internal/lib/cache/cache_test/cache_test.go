@@ -0,0 +1,105 @@
+package cache_test
+
+import (
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"jadesheart/comix_back/internal/lib/cache"
+	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"testing"
+	"time"
+)
+
+// newRedis поднимает Redis в памяти процесса и подключает к нему Store
+func newRedis(t *testing.T) (cache.Store, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+
+	store := cache.NewRedis(slogdiscard.NewDiscardLogger(), server.Addr(), "", 0, time.Second)
+	t.Cleanup(func() { store.Close() })
+
+	return store, server
+}
+
+func TestRedis_GetSetDelete(t *testing.T) {
+	store, _ := newRedis(t)
+
+	key := "cache_test:" + t.Name()
+
+	_, ok := store.Get(key)
+	assert.False(t, ok)
+
+	store.Set(key, []byte("value\r\nwith newline"), time.Minute)
+
+	value, ok := store.Get(key)
+	assert.True(t, ok)
+	assert.Equal(t, "value\r\nwith newline", string(value))
+
+	store.Delete(key)
+
+	_, ok = store.Get(key)
+	assert.False(t, ok)
+}
+
+func TestRedis_TTL(t *testing.T) {
+	store, server := newRedis(t)
+
+	key := "cache_test:" + t.Name()
+
+	store.Set(key, []byte("value"), time.Minute)
+
+	_, ok := store.Get(key)
+	assert.True(t, ok)
+
+	server.FastForward(time.Minute)
+
+	_, ok = store.Get(key)
+	assert.False(t, ok)
+}
+
+func TestRedis_Unavailable(t *testing.T) {
+	store := cache.NewRedis(slogdiscard.NewDiscardLogger(), "127.0.0.1:1", "", 0, 100*time.Millisecond)
+
+	store.Set("key", []byte("value"), time.Minute)
+
+	_, ok := store.Get("key")
+	assert.False(t, ok)
+}
+
+func TestCache_Invalidate(t *testing.T) {
+	redis, _ := newRedis(t)
+
+	stores := map[string]cache.Store{
+		"memory": cache.NewMemory(),
+		"redis":  redis,
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			c := cache.New(store, time.Minute)
+
+			c.Set(cache.KeyMainPage, "page1", []byte("main"))
+			c.Set(cache.KeyTagComix("Horror"), "page1", []byte("horror"))
+
+			value, ok := c.Get(cache.KeyMainPage, "page1")
+			assert.True(t, ok)
+			assert.Equal(t, "main", string(value))
+
+			c.Invalidate(cache.KeyMainPage, cache.KeyTagComix("horror"))
+
+			_, ok = c.Get(cache.KeyMainPage, "page1")
+			assert.False(t, ok)
+
+			_, ok = c.Get(cache.KeyTagComix("Horror"), "page1")
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestMemory_TTL(t *testing.T) {
+	store := cache.NewMemory()
+
+	store.Set("key", []byte("value"), time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+
+	_, ok := store.Get("key")
+	assert.False(t, ok)
+}
@@ -0,0 +1,87 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"github.com/redis/go-redis/v9"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"log/slog"
+	"time"
+)
+
+// Redis - хранилище кэша в Redis через клиент go-redis.
+// Ошибки Redis не ломают запросы: чтение считается промахом, запись пропускается.
+type Redis struct {
+	log     *slog.Logger
+	client  *redis.Client
+	timeout time.Duration
+}
+
+// NewRedis - timeout ограничивает подключение и каждую команду, чтобы недоступный Redis не задерживал ответы
+func NewRedis(log *slog.Logger, addr string, password string, db int, timeout time.Duration) *Redis {
+	return &Redis{
+		log: log.With(
+			slog.String("component", "cache/redis"),
+		),
+		client: redis.NewClient(&redis.Options{
+			Addr:         addr,
+			Password:     password,
+			DB:           db,
+			DialTimeout:  timeout,
+			ReadTimeout:  timeout,
+			WriteTimeout: timeout,
+		}),
+		timeout: timeout,
+	}
+}
+
+func (r *Redis) Get(key string) ([]byte, bool) {
+	ctx, cancel := r.context()
+	defer cancel()
+
+	value, err := r.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false
+	}
+	if err != nil {
+		r.log.Error("failed get key", slog.String("key", key), sl.Err(err))
+
+		return nil, false
+	}
+
+	return value, true
+}
+
+func (r *Redis) Set(key string, value []byte, ttl time.Duration) {
+	ctx, cancel := r.context()
+	defer cancel()
+
+	err := r.client.Set(ctx, key, value, ttl).Err()
+	if err != nil {
+		r.log.Error("failed set key", slog.String("key", key), sl.Err(err))
+	}
+}
+
+func (r *Redis) Delete(keys ...string) {
+	if len(keys) == 0 {
+		return
+	}
+
+	ctx, cancel := r.context()
+	defer cancel()
+
+	err := r.client.Del(ctx, keys...).Err()
+	if err != nil {
+		r.log.Error("failed delete keys", slog.Any("keys", keys), sl.Err(err))
+	}
+}
+
+// Close закрывает соединения с Redis
+func (r *Redis) Close() error {
+	return r.client.Close()
+}
+
+// context - команда вместе с ожиданием свободного соединения укладывается в timeout
+func (r *Redis) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), r.timeout)
+}
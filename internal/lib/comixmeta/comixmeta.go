@@ -0,0 +1,38 @@
+package comixmeta
+
+import (
+	"jadesheart/comix_back/internal/storage/postgres"
+	"regexp"
+)
+
+// languagePattern - язык комикса в виде кода ISO 639-1: ru, en, ja
+var languagePattern = regexp.MustCompile(`^[a-z]{2}$`)
+
+var statuses = map[string]bool{
+	postgres.StatusOngoing:   true,
+	postgres.StatusCompleted: true,
+	postgres.StatusHiatus:    true,
+}
+
+var ageRatings = map[string]bool{
+	"0+":  true,
+	"6+":  true,
+	"12+": true,
+	"16+": true,
+	"18+": true,
+}
+
+// ValidStatus - status один из статусов публикации
+func ValidStatus(status string) bool {
+	return statuses[status]
+}
+
+// ValidLanguage - language пустой или код ISO 639-1
+func ValidLanguage(language string) bool {
+	return language == "" || languagePattern.MatchString(language)
+}
+
+// ValidAgeRating - ageRating пустой или один из возрастных рейтингов
+func ValidAgeRating(ageRating string) bool {
+	return ageRating == "" || ageRatings[ageRating]
+}
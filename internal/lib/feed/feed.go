@@ -0,0 +1,250 @@
+package feed
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"time"
+)
+
+var ErrUnknownFormat = errors.New("unknown feed format")
+
+// Форматы ленты, они же расширения в адресе: /feed.xml, /feed.json
+const (
+	FormatAtom = "xml"
+	FormatJSON = "json"
+)
+
+const (
+	atomNS       = "http://www.w3.org/2005/Atom"
+	jsonVersion  = "https://jsonfeed.org/version/1.1"
+	atomMimeType = "application/atom+xml; charset=utf-8"
+	jsonMimeType = "application/feed+json; charset=utf-8"
+)
+
+// Feed - лента в виде, общем для Atom и JSON Feed. Все ссылки абсолютные.
+// FeedURL - адрес самой ленты без расширения, к нему дописывается формат.
+type Feed struct {
+	Title   string
+	HomeURL string
+	FeedURL string
+	Entries []Entry
+}
+
+// Entry - запись ленты. Image - обложка, пустая строка - обложки нет.
+type Entry struct {
+	ID        string
+	URL       string
+	Title     string
+	Summary   string
+	Image     string
+	Authors   []string
+	Tags      []string
+	Published time.Time
+	Updated   time.Time
+}
+
+// Updated - время последнего изменения ленты, нулевое для пустой ленты
+func (f Feed) Updated() time.Time {
+	var updated time.Time
+
+	for _, entry := range f.Entries {
+		if entry.Updated.After(updated) {
+			updated = entry.Updated
+		}
+	}
+
+	return updated
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"feed"`
+	NS      string      `xml:"xmlns,attr"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Author  *atomAuthor `xml:"author"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Updated    string         `xml:"updated"`
+	Published  string         `xml:"published,omitempty"`
+	Authors    []atomAuthor   `xml:"author"`
+	Summary    string         `xml:"summary,omitempty"`
+	Links      []atomLink     `xml:"link"`
+	Categories []atomCategory `xml:"category"`
+}
+
+// Atom - лента в формате Atom. У записей без авторов автором считается название ленты.
+func Atom(f Feed) ([]byte, error) {
+	doc := atomFeed{
+		NS:      atomNS,
+		ID:      f.FeedURL,
+		Title:   f.Title,
+		Updated: atomTime(f.Updated()),
+		Author:  &atomAuthor{Name: f.Title},
+		Links: []atomLink{
+			{Rel: "self", Type: "application/atom+xml", Href: f.FeedURL + "." + FormatAtom},
+			{Rel: "alternate", Href: f.HomeURL},
+		},
+		Entries: make([]atomEntry, 0, len(f.Entries)),
+	}
+
+	for _, entry := range f.Entries {
+		item := atomEntry{
+			ID:      entry.ID,
+			Title:   entry.Title,
+			Updated: atomTime(entry.Updated),
+			Summary: entry.Summary,
+			Links:   []atomLink{{Rel: "alternate", Href: entry.URL}},
+		}
+		if !entry.Published.IsZero() {
+			item.Published = atomTime(entry.Published)
+		}
+		for _, author := range entry.Authors {
+			item.Authors = append(item.Authors, atomAuthor{Name: author})
+		}
+		if entry.Image != "" {
+			item.Links = append(item.Links, atomLink{Rel: "enclosure", Href: entry.Image})
+		}
+		for _, tag := range entry.Tags {
+			item.Categories = append(item.Categories, atomCategory{Term: tag})
+		}
+
+		doc.Entries = append(doc.Entries, item)
+	}
+
+	buf := bytes.NewBufferString(xml.Header)
+
+	enc := xml.NewEncoder(buf)
+	enc.Indent("", "  ")
+
+	err := enc.Encode(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// atomTime - время в RFC 3339, как требует Atom. Нулевое время - начало эпохи, updated обязателен.
+func atomTime(t time.Time) string {
+	if t.IsZero() {
+		t = time.Unix(0, 0)
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
+
+type jsonFeed struct {
+	Version     string     `json:"version"`
+	Title       string     `json:"title"`
+	HomePageURL string     `json:"home_page_url"`
+	FeedURL     string     `json:"feed_url"`
+	Items       []jsonItem `json:"items"`
+}
+
+type jsonAuthor struct {
+	Name string `json:"name"`
+}
+
+type jsonItem struct {
+	ID            string       `json:"id"`
+	URL           string       `json:"url"`
+	Title         string       `json:"title"`
+	ContentText   string       `json:"content_text"`
+	Image         string       `json:"image,omitempty"`
+	DatePublished *time.Time   `json:"date_published,omitempty"`
+	DateModified  *time.Time   `json:"date_modified,omitempty"`
+	Authors       []jsonAuthor `json:"authors,omitempty"`
+	Tags          []string     `json:"tags,omitempty"`
+}
+
+// JSON - лента в формате JSON Feed 1.1
+func JSON(f Feed) ([]byte, error) {
+	doc := jsonFeed{
+		Version:     jsonVersion,
+		Title:       f.Title,
+		HomePageURL: f.HomeURL,
+		FeedURL:     f.FeedURL + "." + FormatJSON,
+		Items:       make([]jsonItem, 0, len(f.Entries)),
+	}
+
+	for _, entry := range f.Entries {
+		item := jsonItem{
+			ID:            entry.ID,
+			URL:           entry.URL,
+			Title:         entry.Title,
+			ContentText:   entry.Summary,
+			Image:         entry.Image,
+			DatePublished: utcTime(entry.Published),
+			DateModified:  utcTime(entry.Updated),
+			Tags:          entry.Tags,
+		}
+		for _, author := range entry.Authors {
+			item.Authors = append(item.Authors, jsonAuthor{Name: author})
+		}
+
+		doc.Items = append(doc.Items, item)
+	}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// utcTime - nil для нулевого времени
+func utcTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	t = t.UTC()
+
+	return &t
+}
+
+// Encode собирает ленту в формате format и возвращает её вместе с Content-Type
+func Encode(f Feed, format string) ([]byte, string, error) {
+	switch format {
+	case FormatAtom:
+		body, err := Atom(f)
+		return body, atomMimeType, err
+	case FormatJSON:
+		body, err := JSON(f)
+		return body, jsonMimeType, err
+	default:
+		return nil, "", ErrUnknownFormat
+	}
+}
+
+// Serve отдаёт готовую ленту или карту сайта с ETag от её содержимого и Last-Modified от updated.
+// На If-None-Match и If-Modified-Since, которые совпали, отвечает 304 без тела.
+func Serve(w http.ResponseWriter, r *http.Request, body []byte, contentType string, updated time.Time) {
+	sum := sha256.Sum256(body)
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+
+	http.ServeContent(w, r, "", updated, bytes.NewReader(body))
+}
@@ -0,0 +1,139 @@
+package feed_test
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"github.com/stretchr/testify/assert"
+	"jadesheart/comix_back/internal/lib/feed"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+var updated = time.Date(2026, 3, 2, 10, 30, 0, 0, time.UTC)
+
+var testFeed = feed.Feed{
+	Title:   "Comix",
+	HomeURL: "https://comix.example",
+	FeedURL: "https://comix.example/feed",
+	Entries: []feed.Entry{
+		{
+			ID:        "https://comix.example/api/comix/watchmen",
+			URL:       "https://comix.example/api/comix/watchmen",
+			Title:     "Watchmen & Co",
+			Summary:   "Who watches the <watchmen>?",
+			Image:     "https://comix.example/api/comix/watchmen/cover",
+			Authors:   []string{"Alan Moore"},
+			Tags:      []string{"horror"},
+			Published: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+			Updated:   updated,
+		},
+		{
+			ID:      "https://comix.example/api/comix/hellboy",
+			URL:     "https://comix.example/api/comix/hellboy",
+			Title:   "Hellboy",
+			Updated: updated.Add(-time.Hour),
+		},
+	},
+}
+
+func TestAtom(t *testing.T) {
+	body, err := feed.Atom(testFeed)
+	assert.NoError(t, err)
+
+	var doc struct {
+		XMLName xml.Name `xml:"http://www.w3.org/2005/Atom feed"`
+		Updated string   `xml:"updated"`
+		Links   []struct {
+			Rel  string `xml:"rel,attr"`
+			Href string `xml:"href,attr"`
+		} `xml:"link"`
+		Entries []struct {
+			Title   string `xml:"title"`
+			Summary string `xml:"summary"`
+			Links   []struct {
+				Rel  string `xml:"rel,attr"`
+				Href string `xml:"href,attr"`
+			} `xml:"link"`
+		} `xml:"entry"`
+	}
+
+	assert.NoError(t, xml.Unmarshal(body, &doc))
+	assert.Equal(t, "2026-03-02T10:30:00Z", doc.Updated)
+	assert.Equal(t, "https://comix.example/feed.xml", doc.Links[0].Href)
+	assert.Len(t, doc.Entries, 2)
+	assert.Equal(t, "Watchmen & Co", doc.Entries[0].Title)
+	assert.Equal(t, "Who watches the <watchmen>?", doc.Entries[0].Summary)
+	assert.Equal(t, "enclosure", doc.Entries[0].Links[1].Rel)
+	assert.Equal(t, "https://comix.example/api/comix/watchmen/cover", doc.Entries[0].Links[1].Href)
+	// Без обложки ссылка только на сам комикс
+	assert.Len(t, doc.Entries[1].Links, 1)
+}
+
+func TestJSON(t *testing.T) {
+	body, err := feed.JSON(testFeed)
+	assert.NoError(t, err)
+
+	var doc map[string]interface{}
+	assert.NoError(t, json.Unmarshal(body, &doc))
+
+	assert.Equal(t, "https://jsonfeed.org/version/1.1", doc["version"])
+	assert.Equal(t, "https://comix.example/feed.json", doc["feed_url"])
+
+	items := doc["items"].([]interface{})
+	assert.Len(t, items, 2)
+
+	first := items[0].(map[string]interface{})
+	assert.Equal(t, "https://comix.example/api/comix/watchmen/cover", first["image"])
+	assert.Equal(t, "2026-03-02T10:30:00Z", first["date_modified"])
+	assert.Equal(t, []interface{}{"horror"}, first["tags"])
+
+	second := items[1].(map[string]interface{})
+	assert.NotContains(t, second, "image")
+	assert.NotContains(t, second, "date_published")
+}
+
+func TestEncode_UnknownFormat(t *testing.T) {
+	_, _, err := feed.Encode(testFeed, "rss")
+	assert.ErrorIs(t, err, feed.ErrUnknownFormat)
+}
+
+func TestServe_Conditional(t *testing.T) {
+	body, contentType, err := feed.Encode(testFeed, feed.FormatAtom)
+	assert.NoError(t, err)
+
+	serve := func(header string, value string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/feed.xml", nil)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+
+		rr := httptest.NewRecorder()
+		feed.Serve(rr, req, body, contentType, testFeed.Updated())
+
+		return rr
+	}
+
+	rr := serve("", "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/atom+xml; charset=utf-8", rr.Header().Get("Content-Type"))
+	assert.Equal(t, updated.Format(http.TimeFormat), rr.Header().Get("Last-Modified"))
+	assert.Equal(t, body, rr.Body.Bytes())
+
+	etag := rr.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+
+	rr = serve("If-None-Match", etag)
+	assert.Equal(t, http.StatusNotModified, rr.Code)
+	assert.Empty(t, rr.Body.Bytes())
+
+	rr = serve("If-None-Match", `"stale"`)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	rr = serve("If-Modified-Since", updated.Format(http.TimeFormat))
+	assert.Equal(t, http.StatusNotModified, rr.Code)
+
+	rr = serve("If-Modified-Since", updated.Add(-time.Minute).Format(http.TimeFormat))
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
@@ -0,0 +1,158 @@
+package images
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"os"
+)
+
+var (
+	ErrNotImage      = errors.New("file is not a supported image")
+	ErrTooLarge      = errors.New("image file is too large")
+	ErrTooManyPixels = errors.New("image has too many pixels")
+)
+
+// jpegQuality - качество повторного кодирования jpeg. Ниже заметны артефакты на тонких линиях.
+const jpegQuality = 92
+
+// sniffLen - сколько байт нужно http.DetectContentType
+const sniffLen = 512
+
+// Limits - ограничения на загружаемые картинки
+type Limits struct {
+	// MaxFileSize - наибольший размер одной картинки
+	MaxFileSize int64
+	// MaxRequestSize - наибольший размер всех картинок одного запроса
+	MaxRequestSize int64
+	// MaxPixels - наибольшая площадь картинки. Проверяется до распаковки: маленький png может распаковаться в гигабайты.
+	MaxPixels int64
+}
+
+// Image - картинка после проверки и повторного кодирования
+type Image struct {
+	Data        []byte
+	ContentType string
+	// Ext - расширение файла по содержимому, а не по имени загруженного файла
+	Ext    string
+	Width  int
+	Height int
+}
+
+/*
+*
+  - Проверяет загруженную картинку и кодирует её заново. Формат определяется по содержимому, имя файла не важно.
+  - Повторное кодирование выбрасывает EXIF с GPS и прочие метаданные; поворот из EXIF перед этим применяется к пикселям.
+  - Jpeg остаётся jpeg, png остаётся png, gif становится png из первого кадра
+    @param
+  - src - картинка
+  - limits - ограничения
+    @return
+  - err - ошибка, ErrNotImage, ErrTooLarge, ErrTooManyPixels
+  - Image - картинка
+    *
+*/
+func Sanitize(src io.Reader, limits Limits) (Image, error) {
+	data, err := io.ReadAll(io.LimitReader(src, limits.MaxFileSize+1))
+	if err != nil {
+		return Image{}, err
+	}
+	if int64(len(data)) > limits.MaxFileSize {
+		return Image{}, ErrTooLarge
+	}
+
+	contentType := http.DetectContentType(data)
+
+	var decode func(io.Reader) (image.Image, error)
+
+	switch contentType {
+	case "image/jpeg":
+		decode = jpeg.Decode
+	case "image/png":
+		decode = png.Decode
+	case "image/gif":
+		decode = gif.Decode
+	default:
+		return Image{}, fmt.Errorf("%w: %s", ErrNotImage, contentType)
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return Image{}, fmt.Errorf("%w: %s", ErrNotImage, err)
+	}
+	if config.Width <= 0 || config.Height <= 0 {
+		return Image{}, ErrNotImage
+	}
+	if int64(config.Width)*int64(config.Height) > limits.MaxPixels {
+		return Image{}, fmt.Errorf("%w: %dx%d", ErrTooManyPixels, config.Width, config.Height)
+	}
+
+	img, err := decode(bytes.NewReader(data))
+	if err != nil {
+		return Image{}, fmt.Errorf("%w: %s", ErrNotImage, err)
+	}
+
+	buf := &bytes.Buffer{}
+
+	result := Image{}
+
+	if contentType == "image/jpeg" {
+		img = orient(img, jpegOrientation(data))
+
+		err = jpeg.Encode(buf, img, &jpeg.Options{Quality: jpegQuality})
+		result.ContentType, result.Ext = "image/jpeg", ".jpg"
+	} else {
+		err = png.Encode(buf, img)
+		result.ContentType, result.Ext = "image/png", ".png"
+	}
+	if err != nil {
+		return Image{}, err
+	}
+
+	result.Data = buf.Bytes()
+	result.Width = img.Bounds().Dx()
+	result.Height = img.Bounds().Dy()
+
+	return result, nil
+}
+
+// ServeFile отдаёт картинку с Content-Type по содержимому. Страницы в png исторически лежат
+// с расширением .jpg, поэтому http.ServeFile подставил бы неверный тип.
+func ServeFile(w http.ResponseWriter, r *http.Request, path string) {
+	f, err := os.Open(path)
+	if err != nil {
+		http.NotFound(w, r)
+
+		return
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil || stat.IsDir() {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	head := make([]byte, sniffLen)
+
+	n, _ := io.ReadFull(f, head)
+
+	_, err = f.Seek(0, io.SeekStart)
+	if err != nil {
+		http.Error(w, "failed read file", http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", http.DetectContentType(head[:n]))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	http.ServeContent(w, r, stat.Name(), stat.ModTime(), f)
+}
@@ -0,0 +1,137 @@
+package images_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"jadesheart/comix_back/internal/lib/images"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var limits = images.Limits{MaxFileSize: 1 << 20, MaxRequestSize: 4 << 20, MaxPixels: 1 << 20}
+
+func testImage(w, h int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for x := 0; x < w; x++ {
+		img.Set(x, 0, color.RGBA{R: 255, A: 255})
+	}
+
+	return img
+}
+
+// withExif вставляет после SOI сегмент APP1 с поворотом и GPS-широтой
+func withExif(t *testing.T, jpg []byte, orientation uint16) []byte {
+	tiff := &bytes.Buffer{}
+	tiff.WriteString("MM")
+	binary.Write(tiff, binary.BigEndian, uint16(42))
+	binary.Write(tiff, binary.BigEndian, uint32(8))
+	binary.Write(tiff, binary.BigEndian, uint16(2))
+	// Orientation, SHORT, 1 значение
+	binary.Write(tiff, binary.BigEndian, []uint16{0x0112, 3})
+	binary.Write(tiff, binary.BigEndian, uint32(1))
+	binary.Write(tiff, binary.BigEndian, []uint16{orientation, 0})
+	// GPSInfo, LONG, указатель - содержимое не важно, важно что его не будет в результате
+	binary.Write(tiff, binary.BigEndian, []uint16{0x8825, 4})
+	binary.Write(tiff, binary.BigEndian, []uint32{1, 0})
+	binary.Write(tiff, binary.BigEndian, uint32(0))
+	tiff.WriteString("GPS 55.7558 37.6173")
+
+	segment := append([]byte("Exif\x00\x00"), tiff.Bytes()...)
+
+	out := &bytes.Buffer{}
+	out.Write(jpg[:2])
+	out.Write([]byte{0xFF, 0xE1})
+	binary.Write(out, binary.BigEndian, uint16(len(segment)+2))
+	out.Write(segment)
+	out.Write(jpg[2:])
+
+	return out.Bytes()
+}
+
+func TestSanitize_JPEG(t *testing.T) {
+	buf := &bytes.Buffer{}
+	assert.NoError(t, jpeg.Encode(buf, testImage(40, 20), nil))
+
+	data := withExif(t, buf.Bytes(), 6)
+	assert.Contains(t, string(data), "GPS")
+
+	img, err := images.Sanitize(bytes.NewReader(data), limits)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "image/jpeg", img.ContentType)
+	assert.Equal(t, ".jpg", img.Ext)
+	assert.NotContains(t, string(img.Data), "Exif")
+	assert.NotContains(t, string(img.Data), "GPS")
+
+	// Поворот на 90 градусов применён к пикселям
+	assert.Equal(t, 20, img.Width)
+	assert.Equal(t, 40, img.Height)
+}
+
+func TestSanitize_GIFBecomesPNG(t *testing.T) {
+	buf := &bytes.Buffer{}
+	assert.NoError(t, gif.Encode(buf, testImage(10, 10), nil))
+
+	img, err := images.Sanitize(bytes.NewReader(buf.Bytes()), limits)
+	assert.NoError(t, err)
+	assert.Equal(t, "image/png", img.ContentType)
+
+	_, err = png.Decode(bytes.NewReader(img.Data))
+	assert.NoError(t, err)
+}
+
+// pngHeader - png, в заголовке которого заявлен размер w x h; пикселей в нём нет
+func pngHeader(w, h uint32) []byte {
+	ihdr := &bytes.Buffer{}
+	ihdr.WriteString("IHDR")
+	binary.Write(ihdr, binary.BigEndian, []uint32{w, h})
+	ihdr.Write([]byte{8, 6, 0, 0, 0})
+
+	out := &bytes.Buffer{}
+	out.WriteString("\x89PNG\r\n\x1a\n")
+	binary.Write(out, binary.BigEndian, uint32(ihdr.Len()-4))
+	out.Write(ihdr.Bytes())
+	binary.Write(out, binary.BigEndian, crc32.ChecksumIEEE(ihdr.Bytes()))
+
+	return out.Bytes()
+}
+
+func TestSanitize_Rejects(t *testing.T) {
+	_, err := images.Sanitize(bytes.NewReader(pngHeader(100000, 100000)), limits)
+	assert.ErrorIs(t, err, images.ErrTooManyPixels)
+
+	_, err = images.Sanitize(bytes.NewReader([]byte("<html><script>alert(1)</script></html>")), limits)
+	assert.ErrorIs(t, err, images.ErrNotImage)
+
+	_, err = images.Sanitize(bytes.NewReader(pngHeader(10, 10)), limits)
+	assert.ErrorIs(t, err, images.ErrNotImage)
+
+	_, err = images.Sanitize(bytes.NewReader(make([]byte, 2<<20)), limits)
+	assert.ErrorIs(t, err, images.ErrTooLarge)
+}
+
+func TestServeFile_SniffsContentType(t *testing.T) {
+	buf := &bytes.Buffer{}
+	assert.NoError(t, png.Encode(buf, testImage(10, 10)))
+
+	path := filepath.Join(t.TempDir(), "1.jpg")
+	assert.NoError(t, os.WriteFile(path, buf.Bytes(), 0644))
+
+	rr := httptest.NewRecorder()
+	images.ServeFile(rr, httptest.NewRequest(http.MethodGet, "/1.jpg", nil), path)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "image/png", rr.Header().Get("Content-Type"))
+	assert.Equal(t, "nosniff", rr.Header().Get("X-Content-Type-Options"))
+	assert.Equal(t, buf.Bytes(), rr.Body.Bytes())
+}
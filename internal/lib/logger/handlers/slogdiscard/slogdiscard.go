@@ -20,11 +20,11 @@ func (h *DiscardHandler) Handle(_ context.Context, _ slog.Record) error {
 }
 
 func (h *DiscardHandler) WithAttrs(_ []slog.Attr) slog.Handler {
-	return nil
+	return h
 }
 
 func (h *DiscardHandler) WithGroup(_ string) slog.Handler {
-	return nil
+	return h
 }
 
 func (h *DiscardHandler) Enabled(_ context.Context, _ slog.Level) bool {
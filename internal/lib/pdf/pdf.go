@@ -0,0 +1,308 @@
+package pdf
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"os"
+	"unicode/utf16"
+)
+
+var ErrUnsupportedImage = errors.New("page image format is not supported in pdf")
+
+// jpegQuality - качество, с которым png и gif страницы перекодируются в jpeg
+const jpegQuality = 90
+
+// Info - сведения о документе, которые показывают читалки
+type Info struct {
+	Title    string
+	Author   string
+	Subject  string
+	Keywords string
+}
+
+// Page - страница, проверенная до начала записи. В pdf каждая страница - одна картинка в размер страницы.
+type Page struct {
+	path   string
+	width  int
+	height int
+	// jpeg - можно вставить файл как есть, иначе картинка перекодируется
+	jpeg       bool
+	colorSpace string
+	// decode - /Decode для CMYK jpeg из Photoshop, которые хранят цвета инвертированными
+	decode string
+}
+
+/*
+*
+  - Проверяет страницы до записи, читая только заголовки картинок, чтобы ошибка формата
+  - обнаружилась раньше, чем клиенту уйдёт первый байт
+    @param
+  - paths - пути к файлам страниц по порядку
+    @return
+  - err - ошибка, ErrUnsupportedImage если картинку нельзя положить в pdf
+  - []Page - страницы
+    *
+*/
+func Scan(paths []string) ([]Page, error) {
+	pages := make([]Page, 0, len(paths))
+
+	for _, path := range paths {
+		page, err := scanPage(path)
+		if err != nil {
+			return nil, err
+		}
+
+		pages = append(pages, page)
+	}
+
+	return pages, nil
+}
+
+func scanPage(path string) (Page, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Page{}, err
+	}
+	defer f.Close()
+
+	config, format, err := image.DecodeConfig(f)
+	if errors.Is(err, image.ErrFormat) {
+		return Page{}, fmt.Errorf("%w: %s", ErrUnsupportedImage, path)
+	}
+	if err != nil {
+		return Page{}, err
+	}
+
+	page := Page{path: path, width: config.Width, height: config.Height, colorSpace: "/DeviceRGB"}
+
+	if format != "jpeg" {
+		return page, nil
+	}
+
+	page.jpeg = true
+
+	switch config.ColorModel {
+	case color.GrayModel:
+		page.colorSpace = "/DeviceGray"
+	case color.CMYKModel:
+		page.colorSpace = "/DeviceCMYK"
+		page.decode = " /Decode [1 0 1 0 1 0 1 0]"
+	}
+
+	return page, nil
+}
+
+// writer считает записанные байты, чтобы знать смещения объектов для таблицы xref
+type writer struct {
+	w       *bufio.Writer
+	n       int64
+	offsets []int64
+}
+
+func (w *writer) Write(b []byte) (int, error) {
+	n, err := w.w.Write(b)
+	w.n += int64(n)
+
+	return n, err
+}
+
+func (w *writer) printf(format string, args ...interface{}) error {
+	_, err := fmt.Fprintf(w, format, args...)
+
+	return err
+}
+
+// object начинает объект с номером id
+func (w *writer) object(id int) error {
+	for len(w.offsets) < id {
+		w.offsets = append(w.offsets, 0)
+	}
+	w.offsets[id-1] = w.n
+
+	return w.printf("%d 0 obj\n", id)
+}
+
+// Номера объектов: каталог, дерево страниц, сведения, затем по три объекта на страницу
+const (
+	catalogID   = 1
+	pagesID     = 2
+	infoID      = 3
+	firstPageID = 4
+)
+
+/*
+*
+  - Пишет pdf в w по ходу чтения страниц: в памяти держится не больше одной перекодированной страницы
+    @param
+  - w - куда писать документ
+  - info - сведения о документе
+  - pages - страницы из Scan
+    @return
+  - err - ошибка
+    *
+*/
+func Write(w io.Writer, info Info, pages []Page) error {
+	pw := &writer{w: bufio.NewWriter(w)}
+
+	err := pw.printf("%%PDF-1.4\n%%\xe2\xe3\xcf\xd3\n")
+	if err != nil {
+		return err
+	}
+
+	kids := make([]byte, 0, len(pages)*10)
+
+	for i, page := range pages {
+		pageID := firstPageID + i*3
+
+		err = writePage(pw, pageID, page)
+		if err != nil {
+			return err
+		}
+
+		kids = append(kids, fmt.Sprintf("%d 0 R ", pageID)...)
+	}
+
+	err = pw.object(pagesID)
+	if err == nil {
+		err = pw.printf("<< /Type /Pages /Kids [%s] /Count %d >>\nendobj\n", kids, len(pages))
+	}
+	if err == nil {
+		err = pw.object(catalogID)
+	}
+	if err == nil {
+		err = pw.printf("<< /Type /Catalog /Pages %d 0 R >>\nendobj\n", pagesID)
+	}
+	if err == nil {
+		err = pw.object(infoID)
+	}
+	if err == nil {
+		err = pw.printf("<< /Title %s /Author %s /Subject %s /Keywords %s /Producer %s >>\nendobj\n",
+			text(info.Title), text(info.Author), text(info.Subject), text(info.Keywords), text("comix"))
+	}
+	if err != nil {
+		return err
+	}
+
+	xref := pw.n
+
+	err = pw.printf("xref\n0 %d\n0000000000 65535 f \n", len(pw.offsets)+1)
+	if err != nil {
+		return err
+	}
+
+	for _, offset := range pw.offsets {
+		err = pw.printf("%010d 00000 n \n", offset)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = pw.printf("trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(pw.offsets)+1, catalogID, infoID, xref)
+	if err != nil {
+		return err
+	}
+
+	return pw.w.Flush()
+}
+
+// writePage пишет страницу, её содержимое и картинку объектами pageID, pageID+1, pageID+2
+func writePage(pw *writer, pageID int, page Page) error {
+	contentID, imageID := pageID+1, pageID+2
+
+	content := fmt.Sprintf("q %d 0 0 %d 0 0 cm /Im0 Do Q", page.width, page.height)
+
+	err := pw.object(pageID)
+	if err == nil {
+		err = pw.printf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %d %d] /Resources << /XObject << /Im0 %d 0 R >> >> /Contents %d 0 R >>\nendobj\n",
+			pagesID, page.width, page.height, imageID, contentID)
+	}
+	if err == nil {
+		err = pw.object(contentID)
+	}
+	if err == nil {
+		err = pw.printf("<< /Length %d >>\nstream\n%s\nendstream\nendobj\n", len(content), content)
+	}
+	if err != nil {
+		return err
+	}
+
+	src, size, err := openImage(page)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	err = pw.object(imageID)
+	if err == nil {
+		err = pw.printf("<< /Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace %s /BitsPerComponent 8%s /Filter /DCTDecode /Length %d >>\nstream\n",
+			page.width, page.height, page.colorSpace, page.decode, size)
+	}
+	if err == nil {
+		_, err = io.Copy(pw, src)
+	}
+	if err == nil {
+		err = pw.printf("\nendstream\nendobj\n")
+	}
+
+	return err
+}
+
+// openImage возвращает картинку страницы в jpeg и её размер. Jpeg отдаётся с диска как есть,
+// остальные форматы перекодируются в память поверх белого фона: в jpeg нет прозрачности.
+func openImage(page Page) (io.ReadCloser, int64, error) {
+	f, err := os.Open(page.path)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if page.jpeg {
+		stat, err := f.Stat()
+		if err != nil {
+			f.Close()
+
+			return nil, 0, err
+		}
+
+		return f, stat.Size(), nil
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rgb := image.NewRGBA(img.Bounds())
+	draw.Draw(rgb, rgb.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(rgb, rgb.Bounds(), img, img.Bounds().Min, draw.Over)
+
+	buf := &bytes.Buffer{}
+
+	err = jpeg.Encode(buf, rgb, &jpeg.Options{Quality: jpegQuality})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return io.NopCloser(buf), int64(buf.Len()), nil
+}
+
+// text - строка pdf в UTF-16BE: названия комиксов бывают не латиницей
+func text(s string) string {
+	b := []byte{'<', 'F', 'E', 'F', 'F'}
+
+	for _, c := range utf16.Encode([]rune(s)) {
+		b = append(b, fmt.Sprintf("%04X", c)...)
+	}
+
+	return string(append(b, '>'))
+}
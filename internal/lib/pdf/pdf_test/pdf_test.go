@@ -0,0 +1,74 @@
+package pdf_test
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"jadesheart/comix_back/internal/lib/pdf"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+func writeImage(t *testing.T, path string, encode func(f *os.File, img image.Image) error) string {
+	img := image.NewRGBA(image.Rect(0, 0, 40, 60))
+	for x := 0; x < 40; x++ {
+		img.Set(x, x, color.RGBA{R: 200, A: 255})
+	}
+
+	f, err := os.Create(path)
+	assert.NoError(t, err)
+	defer f.Close()
+
+	assert.NoError(t, encode(f, img))
+
+	return path
+}
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+
+	paths := []string{
+		writeImage(t, filepath.Join(dir, "1.jpg"), func(f *os.File, img image.Image) error { return jpeg.Encode(f, img, nil) }),
+		// Страницы в png тоже хранятся с расширением .jpg
+		writeImage(t, filepath.Join(dir, "2.jpg"), func(f *os.File, img image.Image) error { return png.Encode(f, img) }),
+	}
+
+	pages, err := pdf.Scan(paths)
+	assert.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, pdf.Write(buf, pdf.Info{Title: "Хранители"}, pages))
+
+	doc := buf.Bytes()
+	assert.True(t, bytes.HasPrefix(doc, []byte("%PDF-1.4")))
+	assert.True(t, bytes.HasSuffix(doc, []byte("%%EOF\n")))
+	assert.Contains(t, string(doc), "/Count 2")
+	assert.Contains(t, string(doc), "/MediaBox [0 0 40 60]")
+
+	// Смещения в xref указывают на начала объектов
+	m := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(doc)
+	assert.NotNil(t, m)
+	xref, _ := strconv.Atoi(string(m[1]))
+	assert.True(t, bytes.HasPrefix(doc[xref:], []byte("xref\n0 10\n")))
+
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(doc[xref:], -1)
+	assert.Len(t, entries, 9)
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(string(entry[1]))
+		assert.True(t, bytes.HasPrefix(doc[offset:], []byte(strconv.Itoa(i+1)+" 0 obj\n")), i+1)
+	}
+}
+
+func TestScan_Unsupported(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "1.jpg")
+	assert.NoError(t, os.WriteFile(path, []byte("not an image"), 0644))
+
+	_, err := pdf.Scan([]string{path})
+	assert.ErrorIs(t, err, pdf.ErrUnsupportedImage)
+}
@@ -0,0 +1,125 @@
+package photos
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PageExt - расширение страниц 1.jpg...N.jpg, загруженных до хранения порядка в базе.
+// Тогда страницы в png тоже сохранялись с ним; новые страницы получают расширение по содержимому.
+const PageExt = ".jpg"
+
+// TagDir находит папку тэга в root. В базе тэг хранится в нижнем регистре,
+// а папка создаётся с тем регистром, с которым тэг ввели, поэтому сравнение без учёта регистра.
+// Если папки нет, возвращается путь, по которому её нужно создать.
+func TagDir(root string, tag string) string {
+	entries, err := os.ReadDir(root)
+	if err == nil {
+		for _, entry := range entries {
+			if entry.IsDir() && strings.EqualFold(entry.Name(), tag) {
+				return filepath.Join(root, entry.Name())
+			}
+		}
+	}
+
+	return filepath.Join(root, tag)
+}
+
+// MoveDir переносит папку одним переименованием, создавая родительскую папку назначения.
+// Если исходной папки нет, переносить нечего. Существующую папку назначения не трогает.
+func MoveDir(sourceDir string, destDir string) error {
+	if _, err := os.Stat(sourceDir); os.IsNotExist(err) {
+		return nil
+	}
+
+	if _, err := os.Stat(destDir); err == nil {
+		return &os.PathError{Op: "move", Path: destDir, Err: os.ErrExist}
+	}
+
+	err := os.MkdirAll(filepath.Dir(destDir), 0755)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(sourceDir, destDir)
+}
+
+// WriteFile пишет файл через временный в той же папке и переименовывает его,
+// чтобы читатели никогда не видели файл записанным наполовину.
+func WriteFile(dir string, name string, src io.Reader) error {
+	tmp, err := os.CreateTemp(dir, "."+name+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = io.Copy(tmp, src)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), filepath.Join(dir, name))
+}
+
+// NewPageName придумывает имя файла для новой страницы с расширением ext. Имя случайное, чтобы не зависеть
+// от позиции страницы и не совпадать со страницами 1.jpg...N.jpg, загруженными до хранения порядка в базе.
+func NewPageName(ext string) (string, error) {
+	b := make([]byte, 8)
+
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return "page-" + hex.EncodeToString(b) + ext, nil
+}
+
+// LegacyPages возвращает страницы вида 1.jpg...N.jpg из папки комикса, отсортированные по номеру.
+// Так страницы хранились до того, как их порядок стал храниться в базе.
+func LegacyPages(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	type page struct {
+		number int
+		name   string
+	}
+
+	var pages []page
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, PageExt) {
+			continue
+		}
+
+		number, err := strconv.Atoi(strings.TrimSuffix(name, PageExt))
+		if err != nil {
+			continue
+		}
+
+		pages = append(pages, page{number: number, name: name})
+	}
+
+	sort.Slice(pages, func(i, j int) bool {
+		return pages[i].number < pages[j].number
+	})
+
+	names := make([]string, 0, len(pages))
+	for _, p := range pages {
+		names = append(names, p.name)
+	}
+
+	return names, nil
+}
@@ -0,0 +1,227 @@
+package photos_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"jadesheart/comix_back/internal/lib/photos"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTagDir(t *testing.T) {
+	root := t.TempDir()
+	assert.NoError(t, os.Mkdir(filepath.Join(root, "Horror"), 0755))
+
+	assert.Equal(t, filepath.Join(root, "Horror"), photos.TagDir(root, "horror"))
+	assert.Equal(t, filepath.Join(root, "Comedy"), photos.TagDir(root, "Comedy"))
+}
+
+func TestMoveDir(t *testing.T) {
+	root := t.TempDir()
+	source := filepath.Join(root, "a", "comix")
+	assert.NoError(t, os.MkdirAll(source, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(source, "1.jpg"), []byte("page"), 0644))
+
+	dest := filepath.Join(root, "b", "comix")
+	assert.NoError(t, photos.MoveDir(source, dest))
+
+	_, err := os.Stat(filepath.Join(dest, "1.jpg"))
+	assert.NoError(t, err)
+
+	// Исходной папки больше нет - переносить нечего
+	assert.NoError(t, photos.MoveDir(source, dest))
+
+	assert.NoError(t, os.MkdirAll(source, 0755))
+	assert.ErrorIs(t, photos.MoveDir(source, dest), os.ErrExist)
+}
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+
+	assert.NoError(t, photos.WriteFile(dir, "cover.jpg", strings.NewReader("image")))
+
+	data, err := os.ReadFile(filepath.Join(dir, "cover.jpg"))
+	assert.NoError(t, err)
+	assert.Equal(t, "image", string(data))
+
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestLegacyPages(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"10.jpg", "2.jpg", "1.jpg", "cover.jpg", "page-0a1b.jpg", "3.png"} {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("page"), 0644))
+	}
+
+	pages, err := photos.LegacyPages(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1.jpg", "2.jpg", "10.jpg"}, pages)
+}
+
+func TestNewPageName(t *testing.T) {
+	first, err := photos.NewPageName(".jpg")
+	assert.NoError(t, err)
+	second, err := photos.NewPageName(".jpg")
+	assert.NoError(t, err)
+	pngPage, err := photos.NewPageName(".png")
+	assert.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(first, "page-"))
+	assert.True(t, strings.HasSuffix(first, ".jpg"))
+	assert.True(t, strings.HasSuffix(pngPage, ".png"))
+	assert.NotEqual(t, first, second)
+}
+
+// newRoot - папка медиа с тэгом Horror/Watchmen, ссылкой Inside на папку внутри и ссылками Outside и Broken наружу
+func newRoot(t testing.TB) (*photos.Root, string) {
+	dir := t.TempDir()
+	outside := t.TempDir()
+
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "media", "Horror", "Watchmen"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(outside, "secret.jpg"), []byte("secret"), 0644))
+	assert.NoError(t, os.Symlink(filepath.Join(dir, "media", "Horror"), filepath.Join(dir, "media", "Inside")))
+	assert.NoError(t, os.Symlink(outside, filepath.Join(dir, "media", "Outside")))
+	assert.NoError(t, os.Symlink(filepath.Join(outside, "missing"), filepath.Join(dir, "media", "Horror", "Broken")))
+	assert.NoError(t, os.Symlink(filepath.Join(outside, "secret.jpg"), filepath.Join(dir, "media", "Horror", "Watchmen", "1.jpg")))
+
+	root, err := photos.NewRoot(filepath.Join(dir, "media"))
+	assert.NoError(t, err)
+
+	return root, outside
+}
+
+func TestRoot_Resolve(t *testing.T) {
+	root, _ := newRoot(t)
+
+	path, err := root.Resolve("Horror", "Watchmen", "2.jpg")
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(root.Dir(), "Horror", "Watchmen", "2.jpg"), path)
+
+	path, err = root.Resolve("Inside", "Watchmen")
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(root.Dir(), "Inside", "Watchmen"), path)
+
+	unsafe := [][]string{
+		{},
+		{""},
+		{".."},
+		{"Horror", ".."},
+		{"Horror", "."},
+		{"../etc"},
+		{"Horror/Watchmen"},
+		{`..\etc`},
+		{"/etc/passwd"},
+		{"Horror", "a\x00b"},
+		{"Outside"},
+		{"Outside", "secret.jpg"},
+		{"Outside", "new", "file.jpg"},
+		{"Horror", "Broken"},
+		{"Horror", "Broken", "file.jpg"},
+		{"Horror", "Watchmen", "1.jpg"},
+	}
+
+	for _, elem := range unsafe {
+		_, err := root.Resolve(elem...)
+		assert.ErrorIs(t, err, photos.ErrUnsafePath, elem)
+	}
+}
+
+func TestRoot_ComixFile(t *testing.T) {
+	root, _ := newRoot(t)
+
+	path, err := root.ComixFile("horror", "Watchmen", "2.jpg")
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(root.Dir(), "Horror", "Watchmen", "2.jpg"), path)
+
+	path, err = root.TagFile("HORROR", "cover.png")
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(root.Dir(), "Horror", "cover.png"), path)
+
+	_, err = root.ComixFile("horror", "Watchmen", "1.jpg")
+	assert.ErrorIs(t, err, photos.ErrUnsafePath)
+
+	_, err = root.ComixDir("Outside", "x")
+	assert.ErrorIs(t, err, photos.ErrUnsafePath)
+
+	_, err = root.Join(filepath.Dir(root.Dir()), "media")
+	assert.ErrorIs(t, err, photos.ErrUnsafePath)
+
+	_, err = root.Join("Horror", "Watchmen")
+	assert.ErrorIs(t, err, photos.ErrUnsafePath)
+}
+
+// FuzzResolve проверяет, что никакие имена тэга, комикса и файла не дают путь за пределами папки медиа
+func FuzzResolve(f *testing.F) {
+	seeds := [][3]string{
+		{"Horror", "Watchmen", "1.jpg"},
+		{"horror", "Watchmen", "2.jpg"},
+		{"..", "..", "etc"},
+		{"Horror", "..", "passwd"},
+		{"../..", "etc", "passwd"},
+		{"Outside", "secret.jpg", ""},
+		{"Inside", "Watchmen", "x.jpg"},
+		{"Horror", "Broken", "x"},
+		{"/", "etc", "passwd"},
+		{`..\..`, "etc", "passwd"},
+		{"Horror\x00", "a", "b"},
+		{"...", "....", "."},
+	}
+	for _, s := range seeds {
+		f.Add(s[0], s[1], s[2])
+	}
+
+	root, outside := newRoot(f)
+
+	f.Fuzz(func(t *testing.T, tag string, name string, file string) {
+		for _, path := range resolveAll(root, tag, name, file) {
+			rel, err := filepath.Rel(root.Dir(), path)
+			if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) || filepath.IsAbs(rel) {
+				t.Fatalf("path %q escapes root %q", path, root.Dir())
+			}
+
+			// Существующая часть пути после раскрытия ссылок тоже внутри папки
+			existing := path
+			for {
+				if _, err := os.Lstat(existing); err == nil {
+					break
+				}
+				existing = filepath.Dir(existing)
+			}
+
+			resolved, err := filepath.EvalSymlinks(existing)
+			if err != nil {
+				t.Fatalf("path %q has broken link: %s", path, err)
+			}
+			if resolved != root.Dir() && !strings.HasPrefix(resolved, root.Dir()+string(filepath.Separator)) {
+				t.Fatalf("path %q resolves to %q outside root", path, resolved)
+			}
+			if strings.HasPrefix(resolved, outside) {
+				t.Fatalf("path %q resolves to %q", path, resolved)
+			}
+		}
+	})
+}
+
+// resolveAll - пути, которые Root согласился построить из имён
+func resolveAll(root *photos.Root, tag string, name string, file string) []string {
+	var paths []string
+
+	for _, resolve := range []func() (string, error){
+		func() (string, error) { return root.Resolve(tag, name, file) },
+		func() (string, error) { return root.Resolve(tag) },
+		func() (string, error) { return root.TagDir(tag) },
+		func() (string, error) { return root.TagFile(tag, file) },
+		func() (string, error) { return root.ComixDir(tag, name) },
+		func() (string, error) { return root.ComixFile(tag, name, file) },
+	} {
+		path, err := resolve()
+		if err == nil {
+			paths = append(paths, path)
+		}
+	}
+
+	return paths
+}
@@ -0,0 +1,41 @@
+// Package photostest - общие заготовки для тестов обработчиков, которые работают с медиафайлами.
+package photostest
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"jadesheart/comix_back/internal/lib/photos"
+)
+
+// NewRoot создаёт папку медиафайлов во временной папке теста
+func NewRoot(t *testing.T) *photos.Root {
+	mediaRoot, err := photos.NewRoot(t.TempDir())
+	assert.NoError(t, err)
+
+	return mediaRoot
+}
+
+// FileData отдаёт содержимое загружаемого файла по его имени: картинку PNG для "*.png",
+// HTML со скриптом для "fake*" и картинку JPEG для остальных
+func FileData(fileName string) []byte {
+	buf := &bytes.Buffer{}
+	img := image.NewGray(image.Rect(0, 0, 10, 10))
+
+	switch {
+	case strings.HasPrefix(fileName, "fake"):
+		buf.WriteString("<html><script>alert(1)</script></html>")
+	case strings.HasSuffix(fileName, ".png"):
+		png.Encode(buf, img)
+	default:
+		jpeg.Encode(buf, img, nil)
+	}
+
+	return buf.Bytes()
+}
@@ -0,0 +1,190 @@
+package photos
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var ErrUnsafePath = errors.New("path escapes media root")
+
+// Root - папка медиафайлов: <тэг>/<комикс>/<файл>. Все пути к файлам строятся через Root,
+// чтобы названия тэгов, комиксов и файлов из запроса не могли указать за пределы папки.
+type Root struct {
+	// dir - абсолютный путь без символических ссылок, с ним сравниваются проверяемые пути
+	dir string
+}
+
+// NewRoot создаёт папку медиафайлов, если её нет
+func NewRoot(dir string) (*Root, error) {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved, err := filepath.EvalSymlinks(abs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Root{dir: resolved}, nil
+}
+
+// Dir - абсолютный путь к папке медиафайлов
+func (r *Root) Dir() string {
+	return r.dir
+}
+
+/*
+*
+  - Строит путь внутри папки медиафайлов. Каждый элемент - ровно одно имя: без разделителей пути, не "." и не "..".
+  - Если часть пути уже существует и ведёт через символическую ссылку, ссылка должна указывать внутрь папки
+    @param
+  - elem - имена папок и файла по порядку
+    @return
+  - err - ошибка, ErrUnsafePath если путь выходит за пределы папки
+  - string - путь
+    *
+*/
+func (r *Root) Resolve(elem ...string) (string, error) {
+	if len(elem) == 0 {
+		return "", fmt.Errorf("%w: empty path", ErrUnsafePath)
+	}
+
+	for _, e := range elem {
+		err := checkName(e)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	path := filepath.Join(append([]string{r.dir}, elem...)...)
+
+	err := r.checkLinks(path)
+	if err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
+
+// TagDir - папка тэга. Ищется без учёта регистра, как photos.TagDir.
+func (r *Root) TagDir(tag string) (string, error) {
+	err := checkName(tag)
+	if err != nil {
+		return "", err
+	}
+
+	return r.Resolve(filepath.Base(TagDir(r.dir, tag)))
+}
+
+// TagFile - файл обложки в папке тэга
+func (r *Root) TagFile(tag string, file string) (string, error) {
+	tagDir, err := r.TagDir(tag)
+	if err != nil {
+		return "", err
+	}
+
+	return r.Join(tagDir, file)
+}
+
+// ComixDir - папка комикса в папке тэга
+func (r *Root) ComixDir(tag string, name string) (string, error) {
+	tagDir, err := r.TagDir(tag)
+	if err != nil {
+		return "", err
+	}
+
+	return r.Join(tagDir, name)
+}
+
+// ComixFile - файл страницы или обложки в папке комикса
+func (r *Root) ComixFile(tag string, name string, file string) (string, error) {
+	comixDir, err := r.ComixDir(tag, name)
+	if err != nil {
+		return "", err
+	}
+
+	return r.Join(comixDir, file)
+}
+
+// Join - файл или папка name в папке dir, полученной от Root
+func (r *Root) Join(dir string, name string) (string, error) {
+	err := checkName(name)
+	if err != nil {
+		return "", err
+	}
+
+	if !filepath.IsAbs(dir) || !r.contains(filepath.Clean(dir)) {
+		return "", fmt.Errorf("%w: %s", ErrUnsafePath, dir)
+	}
+
+	path := filepath.Join(dir, name)
+
+	err = r.checkLinks(path)
+	if err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
+
+// checkName - одно имя файла или папки
+func checkName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, "/\\\x00") {
+		return fmt.Errorf("%w: %q", ErrUnsafePath, name)
+	}
+
+	return nil
+}
+
+// checkLinks проверяет, что самая длинная существующая часть пути после раскрытия ссылок остаётся в папке.
+// Несуществующий остаток пути ссылок содержать не может.
+func (r *Root) checkLinks(path string) error {
+	existing := path
+
+	for {
+		_, err := os.Lstat(existing)
+		if err == nil {
+			break
+		}
+		if !os.IsNotExist(err) {
+			return err
+		}
+
+		parent := filepath.Dir(existing)
+		if parent == existing || !r.contains(parent) {
+			return fmt.Errorf("%w: %s", ErrUnsafePath, path)
+		}
+		existing = parent
+	}
+
+	// Битая ссылка тоже отвергается: неизвестно, куда она укажет, когда цель появится
+	resolved, err := filepath.EvalSymlinks(existing)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrUnsafePath, path)
+	}
+
+	if !r.contains(resolved) {
+		return fmt.Errorf("%w: %s", ErrUnsafePath, path)
+	}
+
+	return nil
+}
+
+// contains - лежит ли path в папке или совпадает с ней
+func (r *Root) contains(path string) bool {
+	rel, err := filepath.Rel(r.dir, path)
+	if err != nil {
+		return false
+	}
+
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel))
+}
@@ -0,0 +1,106 @@
+package sitemap
+
+import (
+	"bytes"
+	"encoding/xml"
+	"time"
+)
+
+// MaxURLs - больше адресов в одном файле протокол sitemaps не допускает, дальше нужен индекс
+const MaxURLs = 50000
+
+// MimeType - Content-Type карты сайта и индекса
+const MimeType = "application/xml; charset=utf-8"
+
+const ns = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+// URL - адрес в карте сайта или файл карты в индексе. LastMod нулевой - дата изменения неизвестна.
+type URL struct {
+	Loc     string
+	LastMod time.Time
+}
+
+type urlSet struct {
+	XMLName xml.Name   `xml:"urlset"`
+	NS      string     `xml:"xmlns,attr"`
+	URLs    []urlEntry `xml:"url"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name   `xml:"sitemapindex"`
+	NS       string     `xml:"xmlns,attr"`
+	Sitemaps []urlEntry `xml:"sitemap"`
+}
+
+type urlEntry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// URLSet - карта сайта с адресами urls
+func URLSet(urls []URL) ([]byte, error) {
+	return encode(urlSet{NS: ns, URLs: entries(urls)})
+}
+
+// Index - индекс карт сайта, sitemaps - адреса файлов карты
+func Index(sitemaps []URL) ([]byte, error) {
+	return encode(sitemapIndex{NS: ns, Sitemaps: entries(sitemaps)})
+}
+
+// Split делит адреса на файлы не больше чем по size адресов. Пустой список - один пустой файл.
+func Split(urls []URL, size int) [][]URL {
+	if len(urls) == 0 || size <= 0 {
+		return [][]URL{urls}
+	}
+
+	parts := make([][]URL, 0, (len(urls)+size-1)/size)
+
+	for start := 0; start < len(urls); start += size {
+		end := min(start+size, len(urls))
+		parts = append(parts, urls[start:end])
+	}
+
+	return parts
+}
+
+// LastMod - самое позднее изменение среди urls, нулевое если ни одно не известно
+func LastMod(urls []URL) time.Time {
+	var lastMod time.Time
+
+	for _, url := range urls {
+		if url.LastMod.After(lastMod) {
+			lastMod = url.LastMod
+		}
+	}
+
+	return lastMod
+}
+
+func entries(urls []URL) []urlEntry {
+	result := make([]urlEntry, 0, len(urls))
+
+	for _, url := range urls {
+		entry := urlEntry{Loc: url.Loc}
+		if !url.LastMod.IsZero() {
+			entry.LastMod = url.LastMod.UTC().Format(time.RFC3339)
+		}
+
+		result = append(result, entry)
+	}
+
+	return result
+}
+
+func encode(doc interface{}) ([]byte, error) {
+	buf := bytes.NewBufferString(xml.Header)
+
+	enc := xml.NewEncoder(buf)
+	enc.Indent("", "  ")
+
+	err := enc.Encode(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
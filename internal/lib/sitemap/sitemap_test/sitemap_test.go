@@ -0,0 +1,26 @@
+package sitemap_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"jadesheart/comix_back/internal/lib/sitemap"
+	"strconv"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	urls := make([]sitemap.URL, 0, 7)
+	for i := 0; i < 7; i++ {
+		urls = append(urls, sitemap.URL{Loc: "https://comix.example/" + strconv.Itoa(i)})
+	}
+
+	parts := sitemap.Split(urls, 3)
+
+	assert.Len(t, parts, 3)
+	assert.Len(t, parts[0], 3)
+	assert.Len(t, parts[2], 1)
+	assert.Equal(t, "https://comix.example/6", parts[2][0].Loc)
+
+	assert.Len(t, sitemap.Split(urls, sitemap.MaxURLs), 1)
+	// Пустая карта сайта - один пустой файл, а не ни одного
+	assert.Len(t, sitemap.Split(nil, 3), 1)
+}
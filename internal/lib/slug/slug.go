@@ -0,0 +1,53 @@
+package slug
+
+import (
+	"strings"
+	"unicode"
+)
+
+// fallback - slug для названий, в которых не осталось ни одной латинской буквы или цифры.
+const fallback = "comix"
+
+var cyrillic = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "h", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "sch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya",
+}
+
+// Make делает из названия slug для URL: латиница в нижнем регистре, цифры и дефисы.
+// Кириллица транслитерируется, всё остальное становится разделителем.
+func Make(name string) string {
+	var b strings.Builder
+
+	dash := false
+
+	for _, r := range strings.ToLower(name) {
+		if latin, ok := cyrillic[r]; ok {
+			b.WriteString(latin)
+			dash = false
+
+			continue
+		}
+
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(r)
+			dash = false
+
+			continue
+		}
+
+		if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+
+	slug := strings.TrimSuffix(b.String(), "-")
+	if slug == "" {
+		return fallback
+	}
+
+	return slug
+}
@@ -0,0 +1,24 @@
+package slug_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"jadesheart/comix_back/internal/lib/slug"
+	"testing"
+)
+
+func TestMake(t *testing.T) {
+	cases := map[string]string{
+		"Spider Man":          "spider-man",
+		"  Hello,  World!! ":  "hello-world",
+		"Ночной дозор":        "nochnoy-dozor",
+		"Щит и Ёж 2":          "schit-i-ezh-2",
+		"already-a-slug":      "already-a-slug",
+		"日本語":                 "comix",
+		"":                    "comix",
+		"Mix: Кот & Dog_2024": "mix-kot-dog-2024",
+	}
+
+	for name, expected := range cases {
+		assert.Equal(t, expected, slug.Make(name), name)
+	}
+}
@@ -0,0 +1,43 @@
+package upload
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+// Заголовки протокола tus (https://tus.io/protocols/resumable-upload), которые использует загрузка частями.
+const (
+	HeaderResumable = "Tus-Resumable"
+	HeaderOffset    = "Upload-Offset"
+	HeaderLength    = "Upload-Length"
+	HeaderChecksum  = "Upload-Checksum"
+
+	TusVersion  = "1.0.0"
+	ContentType = "application/offset+octet-stream"
+
+	// StatusChecksumMismatch - ответ tus на часть, не совпавшую с Upload-Checksum
+	StatusChecksumMismatch = 460
+)
+
+var ErrChecksumHeader = errors.New("Upload-Checksum must be \"sha256 <base64>\"")
+
+// ParseChecksum разбирает заголовок Upload-Checksum. Пустой заголовок - часть не проверяется, возвращается nil.
+func ParseChecksum(header string) ([]byte, error) {
+	if header == "" {
+		return nil, nil
+	}
+
+	algorithm, value, ok := strings.Cut(header, " ")
+	if !ok || algorithm != "sha256" {
+		return nil, ErrChecksumHeader
+	}
+
+	sum, err := base64.StdEncoding.DecodeString(value)
+	if err != nil || len(sum) != sha256.Size {
+		return nil, ErrChecksumHeader
+	}
+
+	return sum, nil
+}
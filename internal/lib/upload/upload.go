@@ -0,0 +1,384 @@
+package upload
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrNotFound         = errors.New("upload not found")
+	ErrOffsetMismatch   = errors.New("upload offset mismatch")
+	ErrTooLarge         = errors.New("upload too large")
+	ErrChecksumMismatch = errors.New("upload checksum mismatch")
+	ErrIncomplete       = errors.New("upload incomplete")
+	ErrBusy             = errors.New("upload busy")
+)
+
+// idPattern - id загрузки: 32 hex-символа. Проверяется до обращения к диску, чтобы id не мог указать за пределы папки.
+var idPattern = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+const (
+	dataExt = ".bin"
+	infoExt = ".json"
+)
+
+// Info - состояние загрузки. Хранится рядом с данными в <id>.json.
+type Info struct {
+	ID       string `json:"id"`
+	FileName string `json:"fileName"`
+	Size     int64  `json:"size"`
+	Offset   int64  `json:"offset"`
+	// Checksum - sha256 всего файла в hex, пустая строка - не проверяется
+	Checksum  string    `json:"checksum,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Store хранит незавершённые загрузки на диске: данные в <id>.bin, состояние в <id>.json.
+// Загрузку одновременно может дописывать только один запрос.
+type Store struct {
+	dir     string
+	maxSize int64
+
+	mu   sync.Mutex
+	busy map[string]bool
+}
+
+func New(dir string, maxSize int64) (*Store, error) {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Store{
+		dir:     dir,
+		maxSize: maxSize,
+		busy:    make(map[string]bool),
+	}, nil
+}
+
+// MaxSize - наибольший размер одной загрузки
+func (s *Store) MaxSize() int64 {
+	return s.maxSize
+}
+
+// Create заводит пустую загрузку размером size. checksum - sha256 всего файла в hex или пустая строка.
+func (s *Store) Create(fileName string, size int64, checksum string) (Info, error) {
+	if size <= 0 || size > s.maxSize {
+		return Info{}, ErrTooLarge
+	}
+
+	b := make([]byte, 16)
+
+	_, err := rand.Read(b)
+	if err != nil {
+		return Info{}, err
+	}
+
+	now := time.Now()
+
+	info := Info{
+		ID:        hex.EncodeToString(b),
+		FileName:  fileName,
+		Size:      size,
+		Checksum:  strings.ToLower(checksum),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	f, err := os.OpenFile(s.dataPath(info.ID), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return Info{}, err
+	}
+	f.Close()
+
+	err = s.saveInfo(info)
+	if err != nil {
+		os.Remove(s.dataPath(info.ID))
+
+		return Info{}, err
+	}
+
+	return info, nil
+}
+
+// Get возвращает состояние загрузки
+func (s *Store) Get(id string) (Info, error) {
+	if !idPattern.MatchString(id) {
+		return Info{}, ErrNotFound
+	}
+
+	data, err := os.ReadFile(s.infoPath(id))
+	if os.IsNotExist(err) {
+		return Info{}, ErrNotFound
+	}
+	if err != nil {
+		return Info{}, err
+	}
+
+	var info Info
+
+	err = json.Unmarshal(data, &info)
+	if err != nil {
+		return Info{}, err
+	}
+
+	return info, nil
+}
+
+// WriteChunk дописывает в загрузку часть с позиции offset, которая должна совпадать с уже загруженным объёмом.
+// chunkSum - sha256 части, nil - не проверяется; при несовпадении часть отбрасывается.
+// Если соединение оборвалось, принятые байты сохраняются, и клиент продолжает с нового offset.
+// Возвращает новый offset.
+func (s *Store) WriteChunk(id string, offset int64, src io.Reader, chunkSum []byte) (int64, error) {
+	if !idPattern.MatchString(id) {
+		return 0, ErrNotFound
+	}
+
+	if !s.acquire(id) {
+		return 0, ErrBusy
+	}
+	defer s.release(id)
+
+	info, err := s.Get(id)
+	if err != nil {
+		return 0, err
+	}
+
+	if offset != info.Offset {
+		return info.Offset, ErrOffsetMismatch
+	}
+
+	f, err := os.OpenFile(s.dataPath(id), os.O_WRONLY, 0644)
+	if err != nil {
+		return info.Offset, err
+	}
+	defer f.Close()
+
+	_, err = f.Seek(offset, io.SeekStart)
+	if err != nil {
+		return info.Offset, err
+	}
+
+	var h hash.Hash
+	dst := io.Writer(f)
+	if chunkSum != nil {
+		h = sha256.New()
+		dst = io.MultiWriter(f, h)
+	}
+
+	// Читаем на байт больше остатка, чтобы заметить, что клиент шлёт лишнее
+	remaining := info.Size - offset
+	n, copyErr := io.Copy(dst, io.LimitReader(src, remaining+1))
+
+	// discard отбрасывает всю принятую часть
+	discard := func(err error) (int64, error) {
+		if truncErr := f.Truncate(offset); truncErr != nil {
+			return info.Offset, truncErr
+		}
+		return info.Offset, err
+	}
+
+	if n > remaining {
+		return discard(ErrTooLarge)
+	}
+
+	if h != nil {
+		if copyErr != nil {
+			return discard(copyErr)
+		}
+		if !bytes.Equal(h.Sum(nil), chunkSum) {
+			return discard(ErrChecksumMismatch)
+		}
+	}
+
+	if n > 0 {
+		info.Offset += n
+		info.UpdatedAt = time.Now()
+
+		err = s.saveInfo(info)
+		if err != nil {
+			return discard(err)
+		}
+	}
+
+	if copyErr != nil {
+		return info.Offset, copyErr
+	}
+
+	return info.Offset, nil
+}
+
+// Finish проверяет, что загрузка получена целиком и совпадает с контрольной суммой,
+// и возвращает путь к файлу с данными. Файл можно забрать переименованием, после чего вызвать Remove.
+func (s *Store) Finish(id string) (string, Info, error) {
+	if !idPattern.MatchString(id) {
+		return "", Info{}, ErrNotFound
+	}
+
+	if !s.acquire(id) {
+		return "", Info{}, ErrBusy
+	}
+	defer s.release(id)
+
+	info, err := s.Get(id)
+	if err != nil {
+		return "", Info{}, err
+	}
+
+	if info.Offset != info.Size {
+		return "", info, ErrIncomplete
+	}
+
+	if info.Checksum != "" {
+		f, err := os.Open(s.dataPath(id))
+		if err != nil {
+			return "", info, err
+		}
+		defer f.Close()
+
+		h := sha256.New()
+
+		_, err = io.Copy(h, f)
+		if err != nil {
+			return "", info, err
+		}
+
+		if hex.EncodeToString(h.Sum(nil)) != info.Checksum {
+			return "", info, ErrChecksumMismatch
+		}
+	}
+
+	return s.dataPath(id), info, nil
+}
+
+// Remove удаляет загрузку вместе с данными
+func (s *Store) Remove(id string) error {
+	if !idPattern.MatchString(id) {
+		return ErrNotFound
+	}
+
+	if !s.acquire(id) {
+		return ErrBusy
+	}
+	defer s.release(id)
+
+	return s.remove(id)
+}
+
+func (s *Store) remove(id string) error {
+	err := os.Remove(s.infoPath(id))
+	if os.IsNotExist(err) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(s.dataPath(id))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+// RemoveExpired удаляет загрузки, которые не дописывались с момента before, и возвращает их количество.
+// Заодно убирает файлы данных без состояния, оставшиеся от прерванного Create.
+func (s *Store) RemoveExpired(before time.Time) (int, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+
+	for _, entry := range entries {
+		name := entry.Name()
+		id := strings.TrimSuffix(strings.TrimSuffix(name, infoExt), dataExt)
+		if !idPattern.MatchString(id) {
+			continue
+		}
+
+		if !s.acquire(id) {
+			continue
+		}
+
+		switch filepath.Ext(name) {
+		case infoExt:
+			info, err := s.Get(id)
+			if err == nil && info.UpdatedAt.Before(before) {
+				if s.remove(id) == nil {
+					removed++
+				}
+			}
+		case dataExt:
+			stat, err := entry.Info()
+			if err == nil && stat.ModTime().Before(before) {
+				if _, err := os.Stat(s.infoPath(id)); os.IsNotExist(err) {
+					os.Remove(s.dataPath(id))
+				}
+			}
+		}
+
+		s.release(id)
+	}
+
+	return removed, nil
+}
+
+func (s *Store) acquire(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.busy[id] {
+		return false
+	}
+	s.busy[id] = true
+
+	return true
+}
+
+func (s *Store) release(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.busy, id)
+}
+
+func (s *Store) saveInfo(info Info) error {
+	data, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+
+	// Через временный файл, чтобы обрыв записи не оставил испорченное состояние
+	tmp := s.infoPath(info.ID) + ".tmp"
+
+	err = os.WriteFile(tmp, data, 0644)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, s.infoPath(info.ID))
+}
+
+func (s *Store) dataPath(id string) string {
+	return filepath.Join(s.dir, id+dataExt)
+}
+
+func (s *Store) infoPath(id string) string {
+	return filepath.Join(s.dir, id+infoExt)
+}
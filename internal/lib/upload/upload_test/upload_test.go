@@ -0,0 +1,100 @@
+package upload_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/stretchr/testify/assert"
+	"jadesheart/comix_back/internal/lib/upload"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func sum(data string) []byte {
+	h := sha256.Sum256([]byte(data))
+	return h[:]
+}
+
+func TestUpload_Chunks(t *testing.T) {
+	store, err := upload.New(t.TempDir(), 100)
+	assert.NoError(t, err)
+
+	info, err := store.Create("page.jpg", 10, hex.EncodeToString(sum("0123456789")))
+	assert.NoError(t, err)
+
+	offset, err := store.WriteChunk(info.ID, 0, strings.NewReader("0123"), sum("0123"))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), offset)
+
+	// Повтор части со старой позиции не принимается
+	_, err = store.WriteChunk(info.ID, 0, strings.NewReader("0123"), nil)
+	assert.ErrorIs(t, err, upload.ErrOffsetMismatch)
+
+	// Испорченная часть отбрасывается
+	offset, err = store.WriteChunk(info.ID, 4, strings.NewReader("45xx"), sum("4567"))
+	assert.ErrorIs(t, err, upload.ErrChecksumMismatch)
+	assert.Equal(t, int64(4), offset)
+
+	_, _, err = store.Finish(info.ID)
+	assert.ErrorIs(t, err, upload.ErrIncomplete)
+
+	// Больше объявленного размера записать нельзя
+	_, err = store.WriteChunk(info.ID, 4, strings.NewReader("456789abc"), nil)
+	assert.ErrorIs(t, err, upload.ErrTooLarge)
+
+	offset, err = store.WriteChunk(info.ID, 4, strings.NewReader("456789"), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), offset)
+
+	path, _, err := store.Finish(info.ID)
+	assert.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "0123456789", string(data))
+
+	assert.NoError(t, store.Remove(info.ID))
+	_, err = store.Get(info.ID)
+	assert.ErrorIs(t, err, upload.ErrNotFound)
+}
+
+func TestUpload_Limits(t *testing.T) {
+	store, err := upload.New(t.TempDir(), 100)
+	assert.NoError(t, err)
+
+	_, err = store.Create("page.jpg", 101, "")
+	assert.ErrorIs(t, err, upload.ErrTooLarge)
+
+	_, err = store.Get("../../etc/passwd")
+	assert.ErrorIs(t, err, upload.ErrNotFound)
+
+	info, err := store.Create("page.jpg", 4, hex.EncodeToString(sum("abcd")))
+	assert.NoError(t, err)
+
+	_, err = store.WriteChunk(info.ID, 0, bytes.NewReader([]byte("abce")), nil)
+	assert.NoError(t, err)
+
+	_, _, err = store.Finish(info.ID)
+	assert.ErrorIs(t, err, upload.ErrChecksumMismatch)
+}
+
+func TestUpload_RemoveExpired(t *testing.T) {
+	store, err := upload.New(t.TempDir(), 100)
+	assert.NoError(t, err)
+
+	old, err := store.Create("old.jpg", 4, "")
+	assert.NoError(t, err)
+
+	removed, err := store.RemoveExpired(time.Now().Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, removed)
+
+	removed, err = store.RemoveExpired(time.Now().Add(time.Second))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, removed)
+
+	_, err = store.Get(old.ID)
+	assert.ErrorIs(t, err, upload.ErrNotFound)
+}
@@ -0,0 +1,43 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+)
+
+var ErrPrivateAddress = errors.New("webhook address is not public")
+
+// sharedAddressSpace - 100.64.0.0/10 из RFC 6598, адреса внутри сетей провайдеров
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// PublicIP - ip можно отправлять вебхуки: это не loopback, не частная сеть, не link-local
+// (в том числе адрес метаданных облака 169.254.169.254), не 0.0.0.0 и не multicast.
+func PublicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() ||
+		ip.IsUnspecified() ||
+		sharedAddressSpace.Contains(ip))
+}
+
+// DialPublicOnly - Control для net.Dialer, который не даёт подключиться к непубличным адресам.
+// Адрес проверяется после разрешения имени, поэтому имя, указывающее на внутреннюю сеть,
+// тоже не пройдёт, даже если DNS поменяли после создания вебхука.
+func DialPublicOnly(network string, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || !PublicIP(ip) {
+		return fmt.Errorf("%w: %s", ErrPrivateAddress, host)
+	}
+
+	return nil
+}
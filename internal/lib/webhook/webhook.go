@@ -0,0 +1,123 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// События, на которые можно подписать вебхук. Событие отправляется, когда изменение видят читатели:
+// комикс на модерации или с запланированным выходом станет comix.created, когда его одобрят или он выйдет.
+const (
+	EventTagCreated      = "tag.created"
+	EventTagUpdated      = "tag.updated"
+	EventTagDeleted      = "tag.deleted"
+	EventComixCreated    = "comix.created"
+	EventComixUpdated    = "comix.updated"
+	EventComixDeleted    = "comix.deleted"
+	EventComixPagesAdded = "comix.pages_added"
+)
+
+// Events - все события по порядку
+var Events = []string{
+	EventTagCreated,
+	EventTagUpdated,
+	EventTagDeleted,
+	EventComixCreated,
+	EventComixUpdated,
+	EventComixDeleted,
+	EventComixPagesAdded,
+}
+
+// Заголовки доставки. Подпись - HMAC-SHA256 секрета вебхука от "<HeaderTimestamp>.<тело>",
+// время входит в подпись, чтобы получатель мог отбросить старые повторы.
+const (
+	HeaderEvent     = "X-Comix-Event"
+	HeaderDelivery  = "X-Comix-Delivery"
+	HeaderTimestamp = "X-Comix-Timestamp"
+	HeaderSignature = "X-Comix-Signature"
+)
+
+const (
+	signaturePrefix = "sha256="
+	secretSize      = 32
+)
+
+// ValidEvent проверяет, что на событие можно подписаться
+func ValidEvent(event string) bool {
+	for _, e := range Events {
+		if e == event {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NewSecret - случайный секрет для подписи доставок, hex
+func NewSecret() (string, error) {
+	secret := make([]byte, secretSize)
+
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(secret), nil
+}
+
+// Sign подписывает тело доставки секретом вебхука, timestamp - unix-время отправки
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// SetHeaders проставляет заголовки доставки с подписью на момент now
+func SetHeaders(header http.Header, secret string, event string, deliveryID int64, body []byte, now time.Time) {
+	timestamp := now.Unix()
+
+	header.Set("Content-Type", "application/json")
+	header.Set(HeaderEvent, event)
+	header.Set(HeaderDelivery, strconv.FormatInt(deliveryID, 10))
+	header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	header.Set(HeaderSignature, Sign(secret, timestamp, body))
+}
+
+// Verify проверяет подпись доставки на стороне получателя. Доставки старше tolerance не принимаются.
+func Verify(secret string, header http.Header, body []byte, tolerance time.Duration, now time.Time) bool {
+	timestamp, err := strconv.ParseInt(header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		return false
+	}
+
+	age := now.Sub(time.Unix(timestamp, 0))
+	if age > tolerance || age < -tolerance {
+		return false
+	}
+
+	return hmac.Equal([]byte(header.Get(HeaderSignature)), []byte(Sign(secret, timestamp, body)))
+}
+
+// Backoff - пауза перед повтором после attempt неудачных попыток: base, 2*base, 4*base... но не больше max
+func Backoff(attempt int, base time.Duration, max time.Duration) time.Duration {
+	delay := base
+
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= max {
+			return max
+		}
+	}
+
+	if delay > max {
+		return max
+	}
+
+	return delay
+}
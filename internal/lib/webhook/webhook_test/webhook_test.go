@@ -0,0 +1,72 @@
+package webhook_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"jadesheart/comix_back/internal/lib/webhook"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	body := []byte(`{"event":"tag.created"}`)
+	now := time.Unix(1767225600, 0)
+
+	header := http.Header{}
+	webhook.SetHeaders(header, "secret", webhook.EventTagCreated, 42, body, now)
+
+	assert.Equal(t, "tag.created", header.Get(webhook.HeaderEvent))
+	assert.Equal(t, "42", header.Get(webhook.HeaderDelivery))
+	assert.Equal(t, "1767225600", header.Get(webhook.HeaderTimestamp))
+	assert.Equal(t, webhook.Sign("secret", now.Unix(), body), header.Get(webhook.HeaderSignature))
+
+	assert.True(t, webhook.Verify("secret", header, body, 5*time.Minute, now.Add(time.Minute)))
+
+	assert.False(t, webhook.Verify("other", header, body, 5*time.Minute, now))
+	assert.False(t, webhook.Verify("secret", header, []byte(`{"event":"tag.deleted"}`), 5*time.Minute, now))
+	// Старая доставка, пересланная ещё раз
+	assert.False(t, webhook.Verify("secret", header, body, 5*time.Minute, now.Add(time.Hour)))
+}
+
+func TestBackoff(t *testing.T) {
+	cases := map[int]time.Duration{
+		1:  30 * time.Second,
+		2:  time.Minute,
+		3:  2 * time.Minute,
+		10: time.Hour,
+		60: time.Hour,
+	}
+
+	for attempt, expected := range cases {
+		assert.Equal(t, expected, webhook.Backoff(attempt, 30*time.Second, time.Hour), attempt)
+	}
+}
+
+func TestValidEvent(t *testing.T) {
+	assert.True(t, webhook.ValidEvent("comix.pages_added"))
+	assert.False(t, webhook.ValidEvent("comix.purged"))
+
+	secret, err := webhook.NewSecret()
+	assert.NoError(t, err)
+	assert.Len(t, secret, 64)
+}
+
+func TestDialPublicOnly(t *testing.T) {
+	refused := []string{
+		"127.0.0.1:80",
+		"10.0.0.5:443",
+		"192.168.1.1:80",
+		"169.254.169.254:80",
+		"100.64.0.1:80",
+		"0.0.0.0:80",
+		"[::1]:80",
+		"[fe80::1]:80",
+		"[::ffff:127.0.0.1]:80",
+	}
+	for _, address := range refused {
+		assert.ErrorIs(t, webhook.DialPublicOnly("tcp", address, nil), webhook.ErrPrivateAddress, address)
+	}
+
+	assert.NoError(t, webhook.DialPublicOnly("tcp", "93.184.216.34:443", nil))
+	assert.NoError(t, webhook.DialPublicOnly("tcp6", "[2606:2800:220:1::1]:443", nil))
+}
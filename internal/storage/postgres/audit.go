@@ -0,0 +1,143 @@
+package postgres
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+type AuditEvent struct {
+	ID         int64
+	CreatedAt  time.Time
+	Actor      string
+	Action     string
+	Target     string
+	Before     json.RawMessage
+	After      json.RawMessage
+	RequestID  string
+	RemoteAddr string
+	// WebhookEvent - событие вебхуков, которое вызывает запись; пустая строка - вебхуки о ней не узнают
+	WebhookEvent string `json:"-"`
+}
+
+type AuditFilter struct {
+	Actor  string
+	Action string
+	Target string
+	From   time.Time
+	To     time.Time
+}
+
+/*
+*
+  - Записывает событие в журнал административных изменений. Если событие вызывает WebhookEvent,
+    в той же транзакции ставит доставки подписанным вебхукам
+    @param
+  - event - событие, поля ID и CreatedAt заполняет база
+    @return
+  - err - ошибка
+    *
+*/
+func (s *Storage) AddAuditEvent(event AuditEvent) error {
+	const fn = "storage.postgres.AddAuditEvent"
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO audit_events (actor, action, target, before_value, after_value, request_id, remote_addr)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	_, err = tx.Exec(query, event.Actor, event.Action, event.Target,
+		nullJSON(event.Before), nullJSON(event.After), event.RequestID, event.RemoteAddr)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	if event.WebhookEvent != "" {
+		err = enqueueWebhooks(tx, event)
+		if err != nil {
+			return fmt.Errorf("%s: %w", fn, err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return nil
+}
+
+/*
+*
+  - Возвращает страницу журнала административных изменений, новые события первыми
+    @param
+  - filter - фильтры, пустые поля не учитываются
+  - pageToDisplay - номер страницы для отображения
+    @return
+  - err - ошибка
+  - []AuditEvent - события
+    *
+*/
+func (s *Storage) GetAuditEvents(filter AuditFilter, pageToDisplay int) ([]AuditEvent, error) {
+	const fn = "storage.postgres.GetAuditEvents"
+	const numberEventsPerPage = 50
+
+	var events []AuditEvent
+
+	offset := (pageToDisplay - 1) * numberEventsPerPage
+
+	query := `SELECT id, created_at, actor, action, target, before_value, after_value, request_id, remote_addr
+		FROM audit_events
+		WHERE ($1::text = '' OR actor = $1::text)
+			AND ($2::text = '' OR action = $2::text)
+			AND ($3::text = '' OR target = $3::text)
+			AND ($4::timestamptz IS NULL OR created_at >= $4::timestamptz)
+			AND ($5::timestamptz IS NULL OR created_at < $5::timestamptz)
+		ORDER BY id DESC
+		LIMIT $6 OFFSET $7`
+
+	rows, err := s.db.Query(query, filter.Actor, filter.Action, filter.Target,
+		nullTime(filter.From), nullTime(filter.To), numberEventsPerPage, offset)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var event AuditEvent
+		var before, after []byte
+
+		err := rows.Scan(&event.ID, &event.CreatedAt, &event.Actor, &event.Action, &event.Target,
+			&before, &after, &event.RequestID, &event.RemoteAddr)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fn, err)
+		}
+
+		event.Before = before
+		event.After = after
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return events, nil
+}
+
+func nullJSON(value json.RawMessage) interface{} {
+	if len(value) == 0 {
+		return nil
+	}
+
+	return string(value)
+}
+
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
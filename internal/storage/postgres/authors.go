@@ -0,0 +1,433 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"jadesheart/comix_back/internal/storage"
+)
+
+// Роли автора в комиксе
+const (
+	RoleWriter     = "writer"
+	RoleArtist     = "artist"
+	RoleColorist   = "colorist"
+	RoleTranslator = "translator"
+)
+
+type Author struct {
+	ID     int
+	Slug   string
+	Name   string
+	Bio    string
+	Avatar string
+	Links  []string
+}
+
+// AuthorUpdate - изменяемые поля автора. nil - поле не меняется.
+type AuthorUpdate struct {
+	Name   *string
+	Bio    *string
+	Avatar *string
+	Links  *[]string
+}
+
+// Credit - участие автора в комиксе
+type Credit struct {
+	AuthorSlug string
+	AuthorName string
+	Role       string
+}
+
+// AuthorComix - комикс автора вместе с его ролями в нём
+type AuthorComix struct {
+	ComixFromAllComix
+	Roles []string
+}
+
+const authorColumns = "id, COALESCE(slug, ''), name, bio, avatar, links"
+
+func authorFields(author *Author) []interface{} {
+	return []interface{}{&author.ID, &author.Slug, &author.Name, &author.Bio, &author.Avatar, pq.Array(&author.Links)}
+}
+
+/*
+*
+  - Добавляет автора и выдаёт ему slug
+    @param
+  - author - автор, ID и Slug не учитываются
+    @return
+  - err - ошибка
+  - Author - созданный автор
+    *
+*/
+func (s *Storage) CreateAuthor(author Author) (Author, error) {
+	const fn = "storage.postgres.CreateAuthor"
+
+	if author.Links == nil {
+		author.Links = []string{}
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return Author{}, fmt.Errorf("%s: %w", fn, err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(`INSERT INTO authors (name, bio, avatar, links) VALUES ($1, $2, $3, $4) RETURNING id`,
+		author.Name, author.Bio, author.Avatar, pq.Array(author.Links)).Scan(&author.ID)
+	if err != nil {
+		return Author{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	author.Slug, err = assignSlug(tx, slugKindAuthor, author.ID, author.Name)
+	if err != nil {
+		return Author{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return Author{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return author, nil
+}
+
+/*
+*
+  - Находит автора по текущему или старому slug
+    @param
+  - authorSlug - slug автора
+    @return
+  - err - ошибка, storage.ErrAuthorNotFound если автора нет
+  - Author - автор; если Slug отличается от запрошенного, запрошен старый slug
+    *
+*/
+func (s *Storage) GetAuthorBySlug(authorSlug string) (Author, error) {
+	const fn = "storage.postgres.GetAuthorBySlug"
+
+	var author Author
+
+	query := fmt.Sprintf(`SELECT %s FROM authors
+		WHERE slug = $1 OR id = (SELECT target_id FROM slug_history WHERE kind = $2 AND slug = $1)
+		ORDER BY slug = $1 DESC LIMIT 1`, authorColumns)
+
+	err := s.db.QueryRow(query, authorSlug, slugKindAuthor).Scan(authorFields(&author)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Author{}, fmt.Errorf("%s: %w", fn, storage.ErrAuthorNotFound)
+	}
+	if err != nil {
+		return Author{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return author, nil
+}
+
+/*
+*
+  - Возвращает 16 авторов, отсортированных по имени
+    @param
+  - pageToDisplay - номер страницы для отображения
+    @return
+  - err - ошибка
+  - []Author - авторы
+    *
+*/
+func (s *Storage) GetAuthors(pageToDisplay int) ([]Author, error) {
+	const fn = "storage.postgres.GetAuthors"
+	const numberAuthorsPerPage = 16
+
+	offset := (pageToDisplay - 1) * numberAuthorsPerPage
+
+	rows, err := s.db.Query(fmt.Sprintf("SELECT %s FROM authors ORDER BY lower(name), id LIMIT $1 OFFSET $2", authorColumns),
+		numberAuthorsPerPage, offset)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+	defer rows.Close()
+
+	var authors []Author
+
+	for rows.Next() {
+		var author Author
+		err := rows.Scan(authorFields(&author)...)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fn, err)
+		}
+		authors = append(authors, author)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return authors, nil
+}
+
+/*
+*
+  - Изменяет автора. При смене имени выдаёт новый slug, старый остаётся в истории
+    @param
+  - authorSlug - текущий slug автора
+  - update - новые значения, nil-поля не меняются
+    @return
+  - err - ошибка, storage.ErrAuthorNotFound если автора нет
+  - Author - автор после изменения
+    *
+*/
+func (s *Storage) EditAuthor(authorSlug string, update AuthorUpdate) (Author, error) {
+	const fn = "storage.postgres.EditAuthor"
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return Author{}, fmt.Errorf("%s: %w", fn, err)
+	}
+	defer tx.Rollback()
+
+	var author Author
+
+	err = tx.QueryRow(fmt.Sprintf("SELECT %s FROM authors WHERE slug = $1 FOR UPDATE", authorColumns), authorSlug).
+		Scan(authorFields(&author)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Author{}, fmt.Errorf("%s: %w", fn, storage.ErrAuthorNotFound)
+	}
+	if err != nil {
+		return Author{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	renamed := update.Name != nil && *update.Name != author.Name
+
+	if update.Name != nil {
+		author.Name = *update.Name
+	}
+	if update.Bio != nil {
+		author.Bio = *update.Bio
+	}
+	if update.Avatar != nil {
+		author.Avatar = *update.Avatar
+	}
+	if update.Links != nil {
+		author.Links = *update.Links
+	}
+
+	_, err = tx.Exec(`UPDATE authors SET name = $1, bio = $2, avatar = $3, links = $4 WHERE id = $5`,
+		author.Name, author.Bio, author.Avatar, pq.Array(author.Links), author.ID)
+	if err != nil {
+		return Author{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	if renamed {
+		_, err = tx.Exec(`INSERT INTO slug_history (kind, slug, target_id) VALUES ($1, $2, $3)
+			ON CONFLICT (kind, slug) DO NOTHING`, slugKindAuthor, author.Slug, author.ID)
+		if err != nil {
+			return Author{}, fmt.Errorf("%s: %w", fn, err)
+		}
+
+		author.Slug, err = assignSlug(tx, slugKindAuthor, author.ID, author.Name)
+		if err != nil {
+			return Author{}, fmt.Errorf("%s: %w", fn, err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return Author{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return author, nil
+}
+
+/*
+*
+  - Удаляет автора вместе с его участием в комиксах и историей slug
+    @param
+  - authorSlug - slug автора
+    @return
+  - err - ошибка, storage.ErrAuthorNotFound если автора нет
+    *
+*/
+func (s *Storage) DeleteAuthor(authorSlug string) error {
+	const fn = "storage.postgres.DeleteAuthor"
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+	defer tx.Rollback()
+
+	var id int
+
+	err = tx.QueryRow(`DELETE FROM authors WHERE slug = $1 RETURNING id`, authorSlug).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%s: %w", fn, storage.ErrAuthorNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	_, err = tx.Exec(`DELETE FROM slug_history WHERE kind = $1 AND target_id = $2`, slugKindAuthor, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return nil
+}
+
+/*
+*
+  - Заменяет список авторов комикса
+    @param
+  - tagName - название тэга
+  - name - название комикса
+  - credits - авторы и их роли, AuthorName не учитывается
+    @return
+  - err - ошибка, storage.ErrComixNotFound если комикса нет, storage.ErrAuthorNotFound если нет одного из авторов
+    *
+*/
+func (s *Storage) SetComixCredits(tagName string, name string, credits []Credit) error {
+	const fn = "storage.postgres.SetComixCredits"
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+	defer tx.Rollback()
+
+	comixID, err := lockComix(tx, tagName, name)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	_, err = tx.Exec(`DELETE FROM comix_authors WHERE comix_id = $1`, comixID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	for _, credit := range credits {
+		res, err := tx.Exec(`INSERT INTO comix_authors (comix_id, author_id, role)
+			SELECT $1, id, $3 FROM authors WHERE slug = $2
+			ON CONFLICT DO NOTHING`, comixID, credit.AuthorSlug, credit.Role)
+		if err != nil {
+			return fmt.Errorf("%s: %w", fn, err)
+		}
+
+		// ON CONFLICT тоже даёт 0 строк, поэтому автора проверяем отдельно
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("%s: %w", fn, err)
+		}
+		if affected == 0 {
+			var exists bool
+			err = tx.QueryRow(`SELECT EXISTS(SELECT 1 FROM authors WHERE slug = $1)`, credit.AuthorSlug).Scan(&exists)
+			if err != nil {
+				return fmt.Errorf("%s: %w", fn, err)
+			}
+			if !exists {
+				return fmt.Errorf("%s: %w", fn, storage.ErrAuthorNotFound)
+			}
+		}
+	}
+
+	_, err = tx.Exec(`UPDATE all_comix SET updated_at = now() WHERE id = $1`, comixID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return nil
+}
+
+/*
+*
+  - Возвращает авторов комикса
+    @param
+  - comixID - id комикса
+    @return
+  - err - ошибка
+  - []Credit - авторы и их роли
+    *
+*/
+func (s *Storage) GetComixCredits(comixID int) ([]Credit, error) {
+	const fn = "storage.postgres.GetComixCredits"
+
+	rows, err := s.db.Query(`SELECT COALESCE(a.slug, ''), a.name, ca.role
+		FROM comix_authors ca JOIN authors a ON a.id = ca.author_id
+		WHERE ca.comix_id = $1 ORDER BY ca.role, lower(a.name)`, comixID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+	defer rows.Close()
+
+	credits := []Credit{}
+
+	for rows.Next() {
+		var credit Credit
+		err := rows.Scan(&credit.AuthorSlug, &credit.AuthorName, &credit.Role)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fn, err)
+		}
+		credits = append(credits, credit)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return credits, nil
+}
+
+/*
+*
+  - Возвращает 16 комиксов автора, новые первыми
+    @param
+  - pageToDisplay - номер страницы для отображения
+  - authorID - id автора
+    @return
+  - err - ошибка
+  - []AuthorComix - комиксы с ролями автора в каждом
+    *
+*/
+func (s *Storage) GetAuthorComix(pageToDisplay int, authorID int) ([]AuthorComix, error) {
+	const fn = "storage.postgres.GetAuthorComix"
+	const numberComicsPerPage = 16
+
+	offset := (pageToDisplay - 1) * numberComicsPerPage
+
+	query := fmt.Sprintf(`SELECT %s, r.roles FROM all_comix c
+		JOIN (SELECT comix_id, array_agg(role ORDER BY role) AS roles FROM comix_authors
+			WHERE author_id = $1 GROUP BY comix_id) r ON r.comix_id = c.id
+		WHERE `+visibleComix+` ORDER BY c.id DESC LIMIT $2 OFFSET $3`, comixColumns("c"))
+
+	rows, err := s.db.Query(query, authorID, numberComicsPerPage, offset)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+	defer rows.Close()
+
+	var comixList []AuthorComix
+
+	for rows.Next() {
+		var comix AuthorComix
+		err := rows.Scan(append(comixFields(&comix.ComixFromAllComix), pq.Array(&comix.Roles))...)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fn, err)
+		}
+		comixList = append(comixList, comix)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return comixList, nil
+}
@@ -0,0 +1,140 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"jadesheart/comix_back/internal/storage"
+	"strings"
+	"time"
+)
+
+// Статусы публикации комикса
+const (
+	StatusOngoing   = "ongoing"
+	StatusCompleted = "completed"
+	StatusHiatus    = "hiatus"
+)
+
+// ComixMeta - сведения о комиксе, которые хранятся только в all_comix
+type ComixMeta struct {
+	// Cover - имя файла обложки в папке комикса, пустая строка - обложкой служит первая страница
+	Cover     string
+	Authors   []string
+	Status    string
+	Language  string
+	AgeRating string
+	UpdatedAt time.Time
+	Rating    RatingSummary
+}
+
+// ComixMetaUpdate - изменяемые сведения о комиксе. nil - поле не меняется.
+type ComixMetaUpdate struct {
+	Authors   *[]string
+	Status    *string
+	Language  *string
+	AgeRating *string
+}
+
+// comixColumns - столбцы all_comix, которые читаются в ComixFromAllComix; alias - псевдоним таблицы в запросе
+func comixColumns(alias string) string {
+	p := ""
+	if alias != "" {
+		p = alias + "."
+	}
+
+	return fmt.Sprintf("%sid, COALESCE(%sslug, ''), %scomix_name, %scomix_tag, %sdescription, %scomix_date, %sviews, %s",
+		p, p, p, p, p, p, p, metaColumns(alias))
+}
+
+func comixFields(comix *ComixFromAllComix) []interface{} {
+	return append([]interface{}{&comix.ID, &comix.Slug, &comix.ComixName, &comix.ComixTag, &comix.Description, &comix.ComixDate, &comix.Views},
+		metaFields(&comix.ComixMeta)...)
+}
+
+// metaColumns - столбцы ComixMeta. COALESCE нужен для LEFT JOIN таблицы тэга с all_comix.
+func metaColumns(alias string) string {
+	p := ""
+	if alias != "" {
+		p = alias + "."
+	}
+
+	return strings.NewReplacer("$", p).Replace(
+		"COALESCE($cover, ''), COALESCE($authors, '{}'), COALESCE($status, 'ongoing'), " +
+			"COALESCE($language, ''), COALESCE($age_rating, ''), COALESCE($updated_at, 'epoch'::timestamptz), " +
+			ratingColumns(alias))
+}
+
+func metaFields(meta *ComixMeta) []interface{} {
+	return append([]interface{}{&meta.Cover, pq.Array(&meta.Authors), &meta.Status, &meta.Language, &meta.AgeRating, &meta.UpdatedAt},
+		ratingFields(&meta.Rating)...)
+}
+
+/*
+*
+  - Изменяет сведения о комиксе: авторов, статус, язык, возрастной рейтинг
+    @param
+  - tagName - название тэга
+  - name - название комикса
+  - update - новые значения, nil-поля не меняются
+    @return
+  - err - ошибка, storage.ErrComixNotFound если комикса нет
+    *
+*/
+func (s *Storage) EditComixMeta(tagName string, name string, update ComixMetaUpdate) error {
+	const fn = "storage.postgres.EditComixMeta"
+
+	var authors interface{}
+	if update.Authors != nil {
+		authors = pq.Array(*update.Authors)
+	}
+
+	query := `UPDATE all_comix SET
+			authors = COALESCE($3, authors),
+			status = COALESCE($4, status),
+			language = COALESCE($5, language),
+			age_rating = COALESCE($6, age_rating),
+			updated_at = now()
+		WHERE comix_tag = lower($1) AND comix_name = $2 AND deleted_at IS NULL`
+
+	res, err := s.db.Exec(query, tagName, name, authors, update.Status, update.Language, update.AgeRating)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return checkAffected(fn, res)
+}
+
+/*
+*
+  - Запоминает файл обложки комикса
+    @param
+  - tagName - название тэга
+  - name - название комикса
+  - cover - имя файла обложки в папке комикса
+    @return
+  - err - ошибка, storage.ErrComixNotFound если комикса нет
+  - string - имя прежнего файла обложки, пустая строка если его не было
+    *
+*/
+func (s *Storage) SetComixCover(tagName string, name string, cover string) (string, error) {
+	const fn = "storage.postgres.SetComixCover"
+
+	var oldCover string
+
+	query := `UPDATE all_comix c SET cover = $3, updated_at = now()
+		FROM (SELECT id, cover FROM all_comix WHERE comix_tag = lower($1) AND comix_name = $2 AND deleted_at IS NULL FOR UPDATE) old
+		WHERE c.id = old.id
+		RETURNING old.cover`
+
+	err := s.db.QueryRow(query, tagName, name, cover).Scan(&oldCover)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("%s: %w", fn, storage.ErrComixNotFound)
+	}
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return oldCover, nil
+}
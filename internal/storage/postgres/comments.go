@@ -0,0 +1,370 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"jadesheart/comix_back/internal/storage"
+	"time"
+)
+
+// Порядок корневых комментариев комикса
+const (
+	CommentSortNew = "new"
+	// CommentSortTop - самые обсуждаемые: больше всего ответов
+	CommentSortTop = "top"
+)
+
+// commentsPerPage - комментариев на странице обсуждения и ответов
+const commentsPerPage = 16
+
+// Comment - комментарий читателя к комиксу. Обсуждение двухуровневое: у корневого комментария
+// ParentID равен 0, ответы на любом уровне собираются под корнем, а ParentID указывает, кому ответили.
+// У удалённого комментария Body и Author пустые, он остаётся в ветке, пока в ней есть ответы.
+type Comment struct {
+	ID       int64
+	ParentID int64
+	// ReaderID - id автора, наружу не отдаётся
+	ReaderID int `json:"-"`
+	Author   string
+	Body     string
+	Replies  int
+	Deleted  bool
+	// Removed - комментарий убран модератором, а не автором
+	Removed   bool
+	CreatedAt time.Time
+	EditedAt  *time.Time
+}
+
+// commentColumns - столбцы Comment; c - comments, r - readers
+const commentColumns = `c.id, COALESCE(c.parent_id, 0), c.reader_id, r.username, c.body,
+	(SELECT COUNT(*) FROM comments x WHERE x.root_id = c.id AND x.deleted_at IS NULL) AS replies,
+	c.deleted_at IS NOT NULL, c.removed, c.created_at, c.edited_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanComment(row rowScanner) (Comment, error) {
+	var comment Comment
+	var editedAt sql.NullTime
+
+	err := row.Scan(&comment.ID, &comment.ParentID, &comment.ReaderID, &comment.Author, &comment.Body,
+		&comment.Replies, &comment.Deleted, &comment.Removed, &comment.CreatedAt, &editedAt)
+	if err != nil {
+		return Comment{}, err
+	}
+
+	if editedAt.Valid {
+		comment.EditedAt = &editedAt.Time
+	}
+
+	if comment.Deleted {
+		comment.Body = ""
+		comment.Author = ""
+	}
+
+	return comment, nil
+}
+
+func queryComment(q querier, id int64) (Comment, error) {
+	comment, err := scanComment(q.QueryRow(fmt.Sprintf(`SELECT %s FROM comments c JOIN readers r ON r.id = c.reader_id
+		WHERE c.id = $1`, commentColumns), id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return Comment{}, storage.ErrCommentNotFound
+	}
+
+	return comment, err
+}
+
+func queryComments(q querier, query string, args ...interface{}) ([]Comment, error) {
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	comments := []Comment{}
+
+	for rows.Next() {
+		comment, err := scanComment(rows)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, comment)
+	}
+
+	return comments, rows.Err()
+}
+
+/*
+*
+  - Добавляет комментарий читателя к комиксу или ответ на другой комментарий
+    @param
+  - readerID - id автора
+  - comixID - id комикса
+  - parentID - id комментария, на который отвечают, 0 - корневой комментарий
+  - body - текст комментария
+    @return
+  - err - ошибка, storage.ErrComixNotFound если комикса нет или он в корзине,
+    storage.ErrCommentNotFound если комментария parentID нет у этого комикса или он удалён
+  - Comment - добавленный комментарий
+    *
+*/
+func (s *Storage) AddComment(readerID int, comixID int, parentID int64, body string) (Comment, error) {
+	const fn = "storage.postgres.AddComment"
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return Comment{}, fmt.Errorf("%s: %w", fn, err)
+	}
+	defer tx.Rollback()
+
+	var exists bool
+
+	err = tx.QueryRow(`SELECT EXISTS(SELECT 1 FROM all_comix c WHERE c.id = $1 AND `+visibleComix+`)`, comixID).Scan(&exists)
+	if err != nil {
+		return Comment{}, fmt.Errorf("%s: %w", fn, err)
+	}
+	if !exists {
+		return Comment{}, fmt.Errorf("%s: %w", fn, storage.ErrComixNotFound)
+	}
+
+	var rootID, parent sql.NullInt64
+
+	if parentID != 0 {
+		err = tx.QueryRow(`SELECT COALESCE(root_id, id) FROM comments
+			WHERE id = $1 AND comix_id = $2 AND deleted_at IS NULL`, parentID, comixID).Scan(&rootID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return Comment{}, fmt.Errorf("%s: %w", fn, storage.ErrCommentNotFound)
+		}
+		if err != nil {
+			return Comment{}, fmt.Errorf("%s: %w", fn, err)
+		}
+
+		parent = sql.NullInt64{Int64: parentID, Valid: true}
+	}
+
+	var id int64
+
+	err = tx.QueryRow(`INSERT INTO comments (comix_id, root_id, parent_id, reader_id, body) VALUES ($1, $2, $3, $4, $5)
+		RETURNING id`, comixID, rootID, parent, readerID, body).Scan(&id)
+	if err != nil {
+		return Comment{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	comment, err := queryComment(tx, id)
+	if err != nil {
+		return Comment{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return Comment{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return comment, nil
+}
+
+/*
+*
+  - Изменяет текст комментария читателя
+    @param
+  - readerID - id автора
+  - id - id комментария
+  - body - новый текст
+    @return
+  - err - ошибка, storage.ErrCommentNotFound если комментария нет, он чужой или удалён
+  - Comment - комментарий после изменения
+    *
+*/
+func (s *Storage) EditComment(readerID int, id int64, body string) (Comment, error) {
+	const fn = "storage.postgres.EditComment"
+
+	res, err := s.db.Exec(`UPDATE comments SET body = $1, edited_at = now()
+		WHERE id = $2 AND reader_id = $3 AND deleted_at IS NULL`, body, id, readerID)
+	if err != nil {
+		return Comment{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return Comment{}, fmt.Errorf("%s: %w", fn, err)
+	}
+	if n == 0 {
+		return Comment{}, fmt.Errorf("%s: %w", fn, storage.ErrCommentNotFound)
+	}
+
+	comment, err := queryComment(s.db, id)
+	if err != nil {
+		return Comment{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return comment, nil
+}
+
+/*
+*
+  - Удаляет комментарий читателя. Текст стирается, ответы на комментарий остаются
+    @param
+  - readerID - id автора
+  - id - id комментария
+    @return
+  - err - ошибка, storage.ErrCommentNotFound если комментария нет, он чужой или уже удалён
+    *
+*/
+func (s *Storage) DeleteComment(readerID int, id int64) error {
+	const fn = "storage.postgres.DeleteComment"
+
+	res, err := s.db.Exec(`UPDATE comments SET body = '', deleted_at = now()
+		WHERE id = $1 AND reader_id = $2 AND deleted_at IS NULL`, id, readerID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%s: %w", fn, storage.ErrCommentNotFound)
+	}
+
+	return nil
+}
+
+/*
+*
+  - Убирает комментарий по решению модератора. Текст стирается, ответы на комментарий остаются
+    @param
+  - id - id комментария
+    @return
+  - err - ошибка, storage.ErrCommentNotFound если комментария нет или он уже удалён
+  - Comment - комментарий до удаления, для журнала
+    *
+*/
+func (s *Storage) RemoveComment(id int64) (Comment, error) {
+	const fn = "storage.postgres.RemoveComment"
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return Comment{}, fmt.Errorf("%s: %w", fn, err)
+	}
+	defer tx.Rollback()
+
+	comment, err := scanComment(tx.QueryRow(fmt.Sprintf(`SELECT %s FROM comments c JOIN readers r ON r.id = c.reader_id
+		WHERE c.id = $1 AND c.deleted_at IS NULL FOR UPDATE OF c`, commentColumns), id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return Comment{}, fmt.Errorf("%s: %w", fn, storage.ErrCommentNotFound)
+	}
+	if err != nil {
+		return Comment{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	_, err = tx.Exec(`UPDATE comments SET body = '', deleted_at = now(), removed = true WHERE id = $1`, id)
+	if err != nil {
+		return Comment{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return Comment{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return comment, nil
+}
+
+/*
+*
+  - Возвращает 16 корневых комментариев комикса. Удалённые корни без ответов не показываются
+    @param
+  - comixID - id комикса
+  - sort - CommentSortNew или CommentSortTop
+  - pageToDisplay - номер страницы для отображения
+    @return
+  - err - ошибка
+  - []Comment - комментарии
+    *
+*/
+func (s *Storage) GetComments(comixID int, sort string, pageToDisplay int) ([]Comment, error) {
+	const fn = "storage.postgres.GetComments"
+
+	order := "c.created_at DESC, c.id DESC"
+	if sort == CommentSortTop {
+		order = "replies DESC, " + order
+	}
+
+	offset := (pageToDisplay - 1) * commentsPerPage
+
+	query := fmt.Sprintf(`SELECT %s FROM comments c JOIN readers r ON r.id = c.reader_id
+		WHERE c.comix_id = $1 AND c.root_id IS NULL
+			AND (c.deleted_at IS NULL OR EXISTS(SELECT 1 FROM comments x WHERE x.root_id = c.id AND x.deleted_at IS NULL))
+		ORDER BY %s LIMIT %d OFFSET %d`, commentColumns, order, commentsPerPage, offset)
+
+	comments, err := queryComments(s.db, query, comixID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return comments, nil
+}
+
+/*
+*
+  - Возвращает 16 ответов ветки по порядку написания
+    @param
+  - rootID - id корневого комментария
+  - pageToDisplay - номер страницы для отображения
+    @return
+  - err - ошибка, storage.ErrCommentNotFound если корневого комментария нет
+  - []Comment - ответы
+    *
+*/
+func (s *Storage) GetCommentReplies(rootID int64, pageToDisplay int) ([]Comment, error) {
+	const fn = "storage.postgres.GetCommentReplies"
+
+	var exists bool
+
+	err := s.db.QueryRow(`SELECT EXISTS(SELECT 1 FROM comments WHERE id = $1 AND root_id IS NULL)`, rootID).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("%s: %w", fn, storage.ErrCommentNotFound)
+	}
+
+	offset := (pageToDisplay - 1) * commentsPerPage
+
+	query := fmt.Sprintf(`SELECT %s FROM comments c JOIN readers r ON r.id = c.reader_id
+		WHERE c.root_id = $1 ORDER BY c.created_at, c.id LIMIT %d OFFSET %d`, commentColumns, commentsPerPage, offset)
+
+	comments, err := queryComments(s.db, query, rootID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return comments, nil
+}
+
+/*
+*
+  - Возвращает число не удалённых комментариев комикса вместе с ответами
+    @param
+  - comixID - id комикса
+    @return
+  - err - ошибка
+  - int - число комментариев
+    *
+*/
+func (s *Storage) GetCommentCount(comixID int) (int, error) {
+	const fn = "storage.postgres.GetCommentCount"
+
+	var count int
+
+	err := s.db.QueryRow(`SELECT COUNT(*) FROM comments WHERE comix_id = $1 AND deleted_at IS NULL`, comixID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return count, nil
+}
@@ -0,0 +1,122 @@
+package postgres
+
+import (
+	"fmt"
+	"github.com/lib/pq"
+	"jadesheart/comix_back/internal/storage"
+	"time"
+)
+
+// NewComix - комикс, который создаётся сразу со сведениями и страницами
+type NewComix struct {
+	TagName     string
+	Name        string
+	Description string
+	UploadDate  string
+	Meta        ComixMetaUpdate
+	// Pages - имена файлов страниц в папке комикса по порядку
+	Pages []string
+	// Pending - комикс вместе со страницами ждёт проверки модератором
+	Pending bool
+	// PublishAt - когда комикс выйдет, нулевое время - сразу
+	PublishAt time.Time
+}
+
+/*
+*
+  - Создаёт комикс вместе со сведениями и страницами одной транзакцией: при любой ошибке не остаётся полусозданного комикса
+    @param
+  - comix - новый комикс
+    @return
+  - err - ошибка, storage.ErrTagNotFound если тэга нет,
+    storage.ErrComixExists если в тэге уже есть комикс с таким названием, в том числе в корзине
+  - string - slug комикса
+  - []Page - страницы комикса
+    *
+*/
+func (s *Storage) ImportComix(comix NewComix) (string, []Page, error) {
+	const fn = "storage.postgres.ImportComix"
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: %w", fn, err)
+	}
+	defer tx.Rollback()
+
+	_, _, err = lockTag(tx, comix.TagName)
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	var exists bool
+
+	err = tx.QueryRow(`SELECT EXISTS(SELECT 1 FROM all_comix WHERE comix_tag = lower($1) AND comix_name = $2)`,
+		comix.TagName, comix.Name).Scan(&exists)
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: %w", fn, err)
+	}
+	if exists {
+		return "", nil, fmt.Errorf("%s: %w", fn, storage.ErrComixExists)
+	}
+
+	_, err = tx.Exec(fmt.Sprintf("INSERT INTO %s (name, description, upload_date, views) VALUES ($1, $2, $3, 1)", comix.TagName),
+		comix.Name, comix.Description, comix.UploadDate)
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	var comixID int
+
+	reviewStatus := ReviewApproved
+	if comix.Pending {
+		reviewStatus = ReviewPending
+	}
+
+	err = tx.QueryRow(`INSERT INTO all_comix (comix_name, comix_tag, description, comix_date, views, review_status, publish_at)
+		VALUES ($1, lower($2), $3, $4, 1, $5, $6) RETURNING id`,
+		comix.Name, comix.TagName, comix.Description, comix.UploadDate, reviewStatus, nullTime(comix.PublishAt)).Scan(&comixID)
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	var authors interface{}
+	if comix.Meta.Authors != nil {
+		authors = pq.Array(*comix.Meta.Authors)
+	}
+
+	_, err = tx.Exec(`UPDATE all_comix SET
+			authors = COALESCE($2, authors),
+			status = COALESCE($3, status),
+			language = COALESCE($4, language),
+			age_rating = COALESCE($5, age_rating)
+		WHERE id = $1`, comixID, authors, comix.Meta.Status, comix.Meta.Language, comix.Meta.AgeRating)
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	comixSlug, err := assignSlug(tx, slugKindComix, comixID, comix.Name)
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	pages := make([]Page, 0, len(comix.Pages))
+
+	for i, file := range comix.Pages {
+		page := Page{Position: i + 1, File: file}
+
+		err = tx.QueryRow(`INSERT INTO comix_pages (comix_id, position, file) VALUES ($1, $2, $3) RETURNING id`,
+			comixID, page.Position, page.File).Scan(&page.ID)
+		if err != nil {
+			return "", nil, fmt.Errorf("%s: %w", fn, err)
+		}
+
+		pages = append(pages, page)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return comixSlug, pages, nil
+}
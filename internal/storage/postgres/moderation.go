@@ -0,0 +1,584 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"jadesheart/comix_back/internal/storage"
+	"strings"
+	"time"
+)
+
+// Состояние проверки комикса
+const (
+	ReviewPending  = "pending"
+	ReviewApproved = "approved"
+)
+
+// Что можно отправить модераторам жалобой
+const (
+	ReportComix   = "comix"
+	ReportComment = "comment"
+)
+
+// moderationPerPage - записей на странице очереди модерации и жалоб
+const moderationPerPage = 16
+
+// visibleComix - условие на all_comix c, при котором комикс виден читателям: не в корзине, одобрен
+// и уже вышел. Вышедший комикс виден сразу, не дожидаясь, пока планировщик сбросит ему publish_at.
+const visibleComix = "c.deleted_at IS NULL AND c.review_status = '" + ReviewApproved + "' AND " +
+	"(c.publish_at IS NULL OR c.publish_at <= now())"
+
+/*
+*
+  - Условие для таблицы тэга, которое скрывает комиксы в корзине, на проверке и ещё не вышедшие
+    @param
+  - tagName - название тэга
+    @return
+  - string - условие для WHERE
+    *
+*/
+func notHidden(tagName string) string {
+	return fmt.Sprintf(`name NOT IN (SELECT comix_name FROM all_comix WHERE comix_tag = '%s'
+		AND (deleted_at IS NOT NULL OR review_status <> '%s' OR publish_at > now()))`, strings.ToLower(tagName), ReviewApproved)
+}
+
+// PendingComix - комикс на проверке и число его страниц, которые ждут проверки вместе с ним
+type PendingComix struct {
+	ComixFromAllComix
+	PendingPages int
+}
+
+// PendingPage - страница на проверке. Position - куда её вставить после одобрения, 0 - в конец комикса.
+// PublishAt - когда страница выйдет после одобрения, nil - сразу.
+type PendingPage struct {
+	ID        int
+	ComixID   int
+	ComixTag  string
+	ComixName string
+	Position  int
+	File      string
+	PublishAt *time.Time `json:",omitempty"`
+	CreatedAt time.Time
+}
+
+// Report - жалоба читателя. Target - slug комикса или текст комментария, пустой если их уже нет.
+type Report struct {
+	ID         int
+	Kind       string
+	TargetID   int64
+	Target     string
+	Reporter   string
+	Reason     string
+	CreatedAt  time.Time
+	Resolution string `json:",omitempty"`
+}
+
+// pendingPageColumns - столбцы PendingPage; p - pending_pages, c - all_comix
+const pendingPageColumns = `p.id, p.comix_id, c.comix_tag, c.comix_name, p.position, p.file, p.publish_at, p.created_at`
+
+func pendingPageFields(page *PendingPage) []interface{} {
+	return []interface{}{&page.ID, &page.ComixID, &page.ComixTag, &page.ComixName, &page.Position, &page.File,
+		nullTimePtr{&page.PublishAt}, &page.CreatedAt}
+}
+
+/*
+*
+  - Откладывает новые страницы комикса до проверки модератором
+    @param
+  - tagName - название тэга
+  - name - название комикса
+  - position - куда вставить первую страницу после одобрения, 0 - в конец
+  - files - имена файлов новых страниц в папке комикса
+  - publishAt - когда страницы выйдут после одобрения, нулевое время - сразу
+    @return
+  - err - ошибка, storage.ErrComixNotFound если комикса нет
+  - []Page - страницы на проверке, ID - id в очереди модерации
+    *
+*/
+func (s *Storage) AddPendingPages(tagName string, name string, position int, files []string, publishAt time.Time) ([]Page, error) {
+	const fn = "storage.postgres.AddPendingPages"
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+	defer tx.Rollback()
+
+	comixID, err := lockComix(tx, tagName, name)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	pages := make([]Page, 0, len(files))
+
+	for i, file := range files {
+		page := Page{File: file, PublishAt: optionalTime(publishAt)}
+		if position > 0 {
+			page.Position = position + i
+		}
+
+		err = tx.QueryRow(`INSERT INTO pending_pages (comix_id, position, file, publish_at) VALUES ($1, $2, $3, $4) RETURNING id`,
+			comixID, page.Position, page.File, nullTime(publishAt)).Scan(&page.ID)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fn, err)
+		}
+
+		pages = append(pages, page)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return pages, nil
+}
+
+/*
+*
+  - Возвращает 16 комиксов на проверке, загруженные раньше первыми
+    @param
+  - pageToDisplay - номер страницы для отображения
+    @return
+  - err - ошибка
+  - []PendingComix - комиксы вместе с числом страниц на проверке
+    *
+*/
+func (s *Storage) GetPendingComix(pageToDisplay int) ([]PendingComix, error) {
+	const fn = "storage.postgres.GetPendingComix"
+
+	offset := (pageToDisplay - 1) * moderationPerPage
+
+	query := fmt.Sprintf(`SELECT %s, (SELECT COUNT(*) FROM pending_pages p WHERE p.comix_id = c.id)
+		FROM all_comix c WHERE c.review_status = $1 AND c.deleted_at IS NULL
+		ORDER BY c.id LIMIT $2 OFFSET $3`, comixColumns("c"))
+
+	rows, err := s.db.Query(query, ReviewPending, moderationPerPage, offset)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+	defer rows.Close()
+
+	comixList := []PendingComix{}
+
+	for rows.Next() {
+		var comix PendingComix
+		err := rows.Scan(append(comixFields(&comix.ComixFromAllComix), &comix.PendingPages)...)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fn, err)
+		}
+		comixList = append(comixList, comix)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return comixList, nil
+}
+
+/*
+*
+  - Возвращает 16 страниц на проверке у одобренных комиксов, загруженные раньше первыми.
+    Страницы комиксов на проверке одобряются вместе с комиксом и сюда не входят
+    @param
+  - pageToDisplay - номер страницы для отображения
+    @return
+  - err - ошибка
+  - []PendingPage - страницы
+    *
+*/
+func (s *Storage) GetPendingPages(pageToDisplay int) ([]PendingPage, error) {
+	const fn = "storage.postgres.GetPendingPages"
+
+	offset := (pageToDisplay - 1) * moderationPerPage
+
+	query := fmt.Sprintf(`SELECT %s FROM pending_pages p JOIN all_comix c ON c.id = p.comix_id
+		WHERE c.deleted_at IS NULL AND c.review_status = $1 ORDER BY p.id LIMIT $2 OFFSET $3`, pendingPageColumns)
+
+	rows, err := s.db.Query(query, ReviewApproved, moderationPerPage, offset)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+	defer rows.Close()
+
+	pages := []PendingPage{}
+
+	for rows.Next() {
+		var page PendingPage
+		err := rows.Scan(pendingPageFields(&page)...)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fn, err)
+		}
+		pages = append(pages, page)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return pages, nil
+}
+
+/*
+*
+  - Одобряет комикс на проверке вместе со страницами, которые ждали его одобрения
+    @param
+  - id - id комикса
+    @return
+  - err - ошибка, storage.ErrComixNotFound если комикса нет на проверке
+  - PendingComix - комикс до одобрения
+    *
+*/
+func (s *Storage) ApproveComix(id int) (PendingComix, error) {
+	const fn = "storage.postgres.ApproveComix"
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return PendingComix{}, fmt.Errorf("%s: %w", fn, err)
+	}
+	defer tx.Rollback()
+
+	comix, err := lockPendingComix(tx, id)
+	if err != nil {
+		return PendingComix{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	_, err = tx.Exec(`UPDATE all_comix SET review_status = $1 WHERE id = $2`, ReviewApproved, id)
+	if err != nil {
+		return PendingComix{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	rows, err := tx.Query(`SELECT position, file, publish_at FROM pending_pages WHERE comix_id = $1 ORDER BY id`, id)
+	if err != nil {
+		return PendingComix{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	var pending []Page
+
+	for rows.Next() {
+		var page Page
+		err := rows.Scan(&page.Position, &page.File, nullTimePtr{&page.PublishAt})
+		if err != nil {
+			rows.Close()
+			return PendingComix{}, fmt.Errorf("%s: %w", fn, err)
+		}
+		pending = append(pending, page)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return PendingComix{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	for _, page := range pending {
+		_, err = insertPages(tx, id, page.Position, []string{page.File}, timeOrZero(page.PublishAt))
+		if err != nil {
+			return PendingComix{}, fmt.Errorf("%s: %w", fn, err)
+		}
+	}
+
+	_, err = tx.Exec(`DELETE FROM pending_pages WHERE comix_id = $1`, id)
+	if err != nil {
+		return PendingComix{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	err = touchComix(tx, id)
+	if err != nil {
+		return PendingComix{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return PendingComix{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return comix, nil
+}
+
+/*
+*
+  - Отклоняет комикс на проверке: удаляет его строки так же, как окончательное удаление из корзины.
+    Папку комикса удаляет вызывающий
+    @param
+  - id - id комикса
+    @return
+  - err - ошибка, storage.ErrComixNotFound если комикса нет на проверке
+  - PendingComix - удалённый комикс
+    *
+*/
+func (s *Storage) RejectComix(id int) (PendingComix, error) {
+	const fn = "storage.postgres.RejectComix"
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return PendingComix{}, fmt.Errorf("%s: %w", fn, err)
+	}
+	defer tx.Rollback()
+
+	comix, err := lockPendingComix(tx, id)
+	if err != nil {
+		return PendingComix{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	_, err = tx.Exec(`DELETE FROM all_comix WHERE id = $1`, id)
+	if err != nil {
+		return PendingComix{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	err = deleteComixRows(tx, id, comix.ComixTag, comix.ComixName)
+	if err != nil {
+		return PendingComix{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return PendingComix{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return comix, nil
+}
+
+// lockPendingComix блокирует комикс на проверке до конца транзакции
+func lockPendingComix(tx *sql.Tx, id int) (PendingComix, error) {
+	var comix PendingComix
+
+	query := fmt.Sprintf(`SELECT %s, (SELECT COUNT(*) FROM pending_pages p WHERE p.comix_id = c.id)
+		FROM all_comix c WHERE c.id = $1 AND c.review_status = $2 AND c.deleted_at IS NULL
+		FOR UPDATE OF c`, comixColumns("c"))
+
+	err := tx.QueryRow(query, id, ReviewPending).Scan(append(comixFields(&comix.ComixFromAllComix), &comix.PendingPages)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return PendingComix{}, storage.ErrComixNotFound
+	}
+
+	return comix, err
+}
+
+/*
+*
+  - Одобряет страницу на проверке: она встаёт в комикс на сохранённую позицию
+    @param
+  - id - id страницы в очереди модерации
+    @return
+  - err - ошибка, storage.ErrPageNotFound если страницы нет в очереди или её комикс удалён
+  - PendingPage - страница из очереди
+  - Page - страница комикса
+    *
+*/
+func (s *Storage) ApprovePendingPage(id int) (PendingPage, Page, error) {
+	const fn = "storage.postgres.ApprovePendingPage"
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return PendingPage{}, Page{}, fmt.Errorf("%s: %w", fn, err)
+	}
+	defer tx.Rollback()
+
+	var pending PendingPage
+
+	query := fmt.Sprintf(`SELECT %s FROM pending_pages p JOIN all_comix c ON c.id = p.comix_id
+		WHERE p.id = $1 AND c.deleted_at IS NULL FOR UPDATE`, pendingPageColumns)
+
+	err = tx.QueryRow(query, id).Scan(pendingPageFields(&pending)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return PendingPage{}, Page{}, fmt.Errorf("%s: %w", fn, storage.ErrPageNotFound)
+	}
+	if err != nil {
+		return PendingPage{}, Page{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	pages, err := insertPages(tx, pending.ComixID, pending.Position, []string{pending.File}, timeOrZero(pending.PublishAt))
+	if err != nil {
+		return PendingPage{}, Page{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	_, err = tx.Exec(`DELETE FROM pending_pages WHERE id = $1`, id)
+	if err != nil {
+		return PendingPage{}, Page{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	err = touchComix(tx, pending.ComixID)
+	if err != nil {
+		return PendingPage{}, Page{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return PendingPage{}, Page{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return pending, pages[0], nil
+}
+
+/*
+*
+  - Отклоняет страницу на проверке. Файл страницы удаляет вызывающий
+    @param
+  - id - id страницы в очереди модерации
+    @return
+  - err - ошибка, storage.ErrPageNotFound если страницы нет в очереди
+  - PendingPage - удалённая из очереди страница
+    *
+*/
+func (s *Storage) RejectPendingPage(id int) (PendingPage, error) {
+	const fn = "storage.postgres.RejectPendingPage"
+
+	var pending PendingPage
+
+	query := fmt.Sprintf(`DELETE FROM pending_pages p USING all_comix c
+		WHERE p.id = $1 AND c.id = p.comix_id RETURNING %s`, pendingPageColumns)
+
+	err := s.db.QueryRow(query, id).Scan(pendingPageFields(&pending)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return PendingPage{}, fmt.Errorf("%s: %w", fn, storage.ErrPageNotFound)
+	}
+	if err != nil {
+		return PendingPage{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return pending, nil
+}
+
+/*
+*
+  - Добавляет жалобу читателя. Повторная жалоба на то же, пока первая не рассмотрена, ничего не меняет
+    @param
+  - readerID - id читателя
+  - kind - ReportComix или ReportComment
+  - targetID - id комикса или комментария
+  - reason - причина жалобы
+    @return
+  - err - ошибка, storage.ErrComixNotFound если комикса нет или он не виден читателям,
+    storage.ErrCommentNotFound если комментария нет или он удалён
+    *
+*/
+func (s *Storage) AddReport(readerID int, kind string, targetID int64, reason string) error {
+	const fn = "storage.postgres.AddReport"
+
+	query := `SELECT EXISTS(SELECT 1 FROM all_comix c WHERE c.id = $1 AND ` + visibleComix + `)`
+	notFound := storage.ErrComixNotFound
+	if kind == ReportComment {
+		query = `SELECT EXISTS(SELECT 1 FROM comments WHERE id = $1 AND deleted_at IS NULL)`
+		notFound = storage.ErrCommentNotFound
+	}
+
+	var exists bool
+
+	err := s.db.QueryRow(query, targetID).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+	if !exists {
+		return fmt.Errorf("%s: %w", fn, notFound)
+	}
+
+	_, err = s.db.Exec(`INSERT INTO reports (reader_id, kind, target_id, reason) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (reader_id, kind, target_id) WHERE resolved_at IS NULL DO NOTHING`, readerID, kind, targetID, reason)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return nil
+}
+
+// reportColumns - столбцы Report; r - reports, rd - readers
+var reportColumns = fmt.Sprintf(`r.id, r.kind, r.target_id,
+	COALESCE(CASE r.kind
+		WHEN '%s' THEN (SELECT c.slug FROM all_comix c WHERE c.id = r.target_id)
+		WHEN '%s' THEN (SELECT m.body FROM comments m WHERE m.id = r.target_id AND m.deleted_at IS NULL)
+	END, ''), rd.username, r.reason, r.created_at, r.resolution`, ReportComix, ReportComment)
+
+func reportFields(report *Report) []interface{} {
+	return []interface{}{&report.ID, &report.Kind, &report.TargetID, &report.Target, &report.Reporter,
+		&report.Reason, &report.CreatedAt, &report.Resolution}
+}
+
+/*
+*
+  - Возвращает 16 нерассмотренных жалоб, старые первыми
+    @param
+  - pageToDisplay - номер страницы для отображения
+    @return
+  - err - ошибка
+  - []Report - жалобы
+    *
+*/
+func (s *Storage) GetReports(pageToDisplay int) ([]Report, error) {
+	const fn = "storage.postgres.GetReports"
+
+	offset := (pageToDisplay - 1) * moderationPerPage
+
+	query := fmt.Sprintf(`SELECT %s FROM reports r JOIN readers rd ON rd.id = r.reader_id
+		WHERE r.resolved_at IS NULL ORDER BY r.created_at, r.id LIMIT $1 OFFSET $2`, reportColumns)
+
+	rows, err := s.db.Query(query, moderationPerPage, offset)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+	defer rows.Close()
+
+	reports := []Report{}
+
+	for rows.Next() {
+		var report Report
+		err := rows.Scan(reportFields(&report)...)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fn, err)
+		}
+		reports = append(reports, report)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return reports, nil
+}
+
+/*
+*
+  - Закрывает жалобу с решением модератора
+    @param
+  - id - id жалобы
+  - resolution - решение модератора
+    @return
+  - err - ошибка, storage.ErrReportNotFound если жалобы нет или она уже рассмотрена
+  - Report - жалоба до закрытия
+    *
+*/
+func (s *Storage) ResolveReport(id int, resolution string) (Report, error) {
+	const fn = "storage.postgres.ResolveReport"
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return Report{}, fmt.Errorf("%s: %w", fn, err)
+	}
+	defer tx.Rollback()
+
+	var report Report
+
+	query := fmt.Sprintf(`SELECT %s FROM reports r JOIN readers rd ON rd.id = r.reader_id
+		WHERE r.id = $1 AND r.resolved_at IS NULL FOR UPDATE OF r`, reportColumns)
+
+	err = tx.QueryRow(query, id).Scan(reportFields(&report)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Report{}, fmt.Errorf("%s: %w", fn, storage.ErrReportNotFound)
+	}
+	if err != nil {
+		return Report{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	_, err = tx.Exec(`UPDATE reports SET resolved_at = now(), resolution = $1 WHERE id = $2`, resolution, id)
+	if err != nil {
+		return Report{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return Report{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return report, nil
+}
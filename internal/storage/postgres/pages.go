@@ -0,0 +1,502 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"jadesheart/comix_back/internal/storage"
+	"time"
+)
+
+// Page - страница комикса. ID не меняется при перестановке и замене, File - имя файла в папке комикса.
+// PublishAt - когда страница выйдет, nil - уже вышла.
+type Page struct {
+	ID        int
+	Position  int
+	File      string
+	PublishAt *time.Time `json:",omitempty"`
+}
+
+/*
+*
+  - Блокирует комикс до конца транзакции, чтобы операции со страницами одного комикса шли по очереди
+    @param
+  - tx - транзакция
+  - tagName - название тэга
+  - name - название комикса
+    @return
+  - err - ошибка, storage.ErrComixNotFound если комикса нет или он в корзине
+  - int - id комикса
+    *
+*/
+func lockComix(tx *sql.Tx, tagName string, name string) (int, error) {
+	var id int
+
+	err := tx.QueryRow(`SELECT id FROM all_comix
+		WHERE comix_tag = lower($1) AND comix_name = $2 AND deleted_at IS NULL
+		FOR UPDATE`, tagName, name).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, storage.ErrComixNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// queryPages - страницы комикса по порядку; published - только уже вышедшие
+func queryPages(q querier, comixID int, published bool) ([]Page, error) {
+	query := `SELECT id, position, file, publish_at FROM comix_pages WHERE comix_id = $1`
+	if published {
+		query += ` AND ` + publishedPage
+	}
+
+	rows, err := q.Query(query+` ORDER BY position`, comixID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	pages := []Page{}
+
+	for rows.Next() {
+		var page Page
+		err := rows.Scan(&page.ID, &page.Position, &page.File, nullTimePtr{&page.PublishAt})
+		if err != nil {
+			return nil, err
+		}
+		pages = append(pages, page)
+	}
+
+	return pages, rows.Err()
+}
+
+/*
+*
+  - Возвращает страницы комикса по порядку. Страницы на проверке и ещё не вышедшие не входят
+    @param
+  - tagName - название тэга
+  - name - название комикса
+    @return
+  - err - ошибка, storage.ErrComixNotFound если комикса нет или он не виден читателям
+  - []Page - страницы
+    *
+*/
+func (s *Storage) GetComixPages(tagName string, name string) ([]Page, error) {
+	const fn = "storage.postgres.GetComixPages"
+
+	var id int
+
+	err := s.db.QueryRow(`SELECT c.id FROM all_comix c
+		WHERE c.comix_tag = lower($1) AND c.comix_name = $2 AND `+visibleComix, tagName, name).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%s: %w", fn, storage.ErrComixNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	pages, err := queryPages(s.db, id, true)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return pages, nil
+}
+
+/*
+*
+  - Вставляет страницы начиная с позиции, сдвигая следующие страницы
+    @param
+  - tagName - название тэга
+  - name - название комикса
+  - position - позиция первой новой страницы, начиная с 1; 0 или позиция за последней страницей - добавить в конец
+  - files - имена файлов новых страниц в папке комикса
+  - publishAt - когда страницы выйдут, нулевое время - сразу
+    @return
+  - err - ошибка, storage.ErrComixNotFound если комикса нет
+  - []Page - добавленные страницы
+    *
+*/
+func (s *Storage) AddPages(tagName string, name string, position int, files []string, publishAt time.Time) ([]Page, error) {
+	const fn = "storage.postgres.AddPages"
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+	defer tx.Rollback()
+
+	comixID, err := lockComix(tx, tagName, name)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	pages, err := insertPages(tx, comixID, position, files, publishAt)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	err = touchComix(tx, comixID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return pages, nil
+}
+
+/*
+*
+  - Вставляет страницы в заблокированный комикс начиная с позиции, сдвигая следующие страницы
+    @param
+  - tx - транзакция
+  - comixID - id комикса
+  - position - позиция первой новой страницы, начиная с 1; 0 или позиция за последней страницей - добавить в конец
+  - files - имена файлов новых страниц в папке комикса
+  - publishAt - когда страницы выйдут, нулевое время - сразу
+    @return
+  - err - ошибка
+  - []Page - добавленные страницы
+    *
+*/
+func insertPages(tx *sql.Tx, comixID int, position int, files []string, publishAt time.Time) ([]Page, error) {
+	var count int
+
+	err := tx.QueryRow(`SELECT COUNT(*) FROM comix_pages WHERE comix_id = $1`, comixID).Scan(&count)
+	if err != nil {
+		return nil, err
+	}
+
+	if position < 1 || position > count+1 {
+		position = count + 1
+	}
+
+	_, err = tx.Exec(`UPDATE comix_pages SET position = position + $1 WHERE comix_id = $2 AND position >= $3`,
+		len(files), comixID, position)
+	if err != nil {
+		return nil, err
+	}
+
+	pages := make([]Page, 0, len(files))
+
+	for i, file := range files {
+		page := Page{Position: position + i, File: file, PublishAt: optionalTime(publishAt)}
+
+		err = tx.QueryRow(`INSERT INTO comix_pages (comix_id, position, file, publish_at) VALUES ($1, $2, $3, $4) RETURNING id`,
+			comixID, page.Position, page.File, nullTime(publishAt)).Scan(&page.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		pages = append(pages, page)
+	}
+
+	return pages, nil
+}
+
+/*
+*
+  - Заменяет файл страницы, позиция и ID страницы не меняются
+    @param
+  - tagName - название тэга
+  - name - название комикса
+  - pageID - id страницы
+  - file - имя нового файла страницы
+    @return
+  - err - ошибка, storage.ErrComixNotFound если комикса нет, storage.ErrPageNotFound если у комикса нет такой страницы
+  - string - имя прежнего файла страницы
+    *
+*/
+func (s *Storage) ReplacePage(tagName string, name string, pageID int, file string) (string, error) {
+	const fn = "storage.postgres.ReplacePage"
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", fn, err)
+	}
+	defer tx.Rollback()
+
+	comixID, err := lockComix(tx, tagName, name)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", fn, err)
+	}
+
+	var oldFile string
+
+	err = tx.QueryRow(`SELECT file FROM comix_pages WHERE id = $1 AND comix_id = $2`, pageID, comixID).Scan(&oldFile)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("%s: %w", fn, storage.ErrPageNotFound)
+	}
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", fn, err)
+	}
+
+	_, err = tx.Exec(`UPDATE comix_pages SET file = $1 WHERE id = $2`, file, pageID)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", fn, err)
+	}
+
+	err = touchComix(tx, comixID)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", fn, err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return oldFile, nil
+}
+
+/*
+*
+  - Удаляет страницу и сдвигает следующие страницы на её место
+    @param
+  - tagName - название тэга
+  - name - название комикса
+  - pageID - id страницы
+    @return
+  - err - ошибка, storage.ErrComixNotFound если комикса нет, storage.ErrPageNotFound если у комикса нет такой страницы
+  - string - имя файла удалённой страницы
+    *
+*/
+func (s *Storage) DeletePage(tagName string, name string, pageID int) (string, error) {
+	const fn = "storage.postgres.DeletePage"
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", fn, err)
+	}
+	defer tx.Rollback()
+
+	comixID, err := lockComix(tx, tagName, name)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", fn, err)
+	}
+
+	var file string
+	var position int
+
+	err = tx.QueryRow(`DELETE FROM comix_pages WHERE id = $1 AND comix_id = $2 RETURNING file, position`,
+		pageID, comixID).Scan(&file, &position)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("%s: %w", fn, storage.ErrPageNotFound)
+	}
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", fn, err)
+	}
+
+	_, err = tx.Exec(`UPDATE comix_pages SET position = position - 1 WHERE comix_id = $1 AND position > $2`, comixID, position)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", fn, err)
+	}
+
+	err = touchComix(tx, comixID)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", fn, err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return file, nil
+}
+
+/*
+*
+  - Задаёт новый порядок страниц комикса
+    @param
+  - tagName - название тэга
+  - name - название комикса
+  - pageIDs - id всех страниц комикса в новом порядке
+    @return
+  - err - ошибка, storage.ErrComixNotFound если комикса нет,
+    storage.ErrPagesMismatch если pageIDs не совпадает со страницами комикса
+    *
+*/
+func (s *Storage) ReorderPages(tagName string, name string, pageIDs []int) error {
+	const fn = "storage.postgres.ReorderPages"
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+	defer tx.Rollback()
+
+	comixID, err := lockComix(tx, tagName, name)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	pages, err := queryPages(tx, comixID, false)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	if !samePages(pages, pageIDs) {
+		return fmt.Errorf("%s: %w", fn, storage.ErrPagesMismatch)
+	}
+
+	ids := make([]int64, len(pageIDs))
+	for i, id := range pageIDs {
+		ids[i] = int64(id)
+	}
+
+	_, err = tx.Exec(`UPDATE comix_pages p SET position = o.position
+		FROM unnest($1::int[]) WITH ORDINALITY AS o(id, position)
+		WHERE p.id = o.id`, pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	err = touchComix(tx, comixID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return nil
+}
+
+// samePages - pageIDs содержит каждую страницу ровно один раз
+func samePages(pages []Page, pageIDs []int) bool {
+	ids := make([]int, len(pages))
+	for i, page := range pages {
+		ids[i] = page.ID
+	}
+
+	return sameIDs(ids, pageIDs)
+}
+
+// sameIDs - got содержит каждый id из have ровно один раз и ничего больше
+func sameIDs(have []int, got []int) bool {
+	if len(have) != len(got) {
+		return false
+	}
+
+	left := make(map[int]bool, len(have))
+	for _, id := range have {
+		left[id] = true
+	}
+
+	for _, id := range got {
+		if !left[id] {
+			return false
+		}
+		delete(left, id)
+	}
+
+	return true
+}
+
+/*
+*
+  - Возвращает комиксы, у которых ещё нет страниц в базе, в том числе лежащие в корзине
+    @return
+  - err - ошибка
+  - []ComixFromAllComix - комиксы
+    *
+*/
+func (s *Storage) GetComixWithoutPages() ([]ComixFromAllComix, error) {
+	const fn = "storage.postgres.GetComixWithoutPages"
+
+	query := fmt.Sprintf(`SELECT %s FROM all_comix
+		WHERE NOT EXISTS (SELECT 1 FROM comix_pages p WHERE p.comix_id = all_comix.id) ORDER BY id`, allComixColumns)
+
+	rows, err := s.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+	defer rows.Close()
+
+	var comixList []ComixFromAllComix
+
+	for rows.Next() {
+		var comix ComixFromAllComix
+		err := rows.Scan(comixFields(&comix)...)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fn, err)
+		}
+		comixList = append(comixList, comix)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return comixList, nil
+}
+
+/*
+*
+  - Записывает в базу страницы, которые уже лежат в папке комикса. Ничего не делает, если страницы уже есть
+    @param
+  - comixID - id комикса
+  - files - имена файлов страниц по порядку
+    @return
+  - err - ошибка
+  - int - сколько страниц записано
+    *
+*/
+func (s *Storage) InitComixPages(comixID int, files []string) (int, error) {
+	const fn = "storage.postgres.InitComixPages"
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", fn, err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(`SELECT id FROM all_comix WHERE id = $1 FOR UPDATE`, comixID).Scan(&comixID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("%s: %w", fn, storage.ErrComixNotFound)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	var exists bool
+
+	err = tx.QueryRow(`SELECT EXISTS(SELECT 1 FROM comix_pages WHERE comix_id = $1)`, comixID).Scan(&exists)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", fn, err)
+	}
+	if exists {
+		return 0, nil
+	}
+
+	for i, file := range files {
+		_, err = tx.Exec(`INSERT INTO comix_pages (comix_id, position, file) VALUES ($1, $2, $3)`, comixID, i+1, file)
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", fn, err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return len(files), nil
+}
+
+// touchComix отмечает, что комикс обновился
+func touchComix(q querier, comixID int) error {
+	_, err := q.Exec(`UPDATE all_comix SET updated_at = now() WHERE id = $1`, comixID)
+
+	return err
+}
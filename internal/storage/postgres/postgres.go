@@ -2,9 +2,12 @@ package postgres
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	_ "github.com/lib/pq"
+	"jadesheart/comix_back/internal/storage"
 	"strings"
+	"time"
 )
 
 type Storage struct {
@@ -12,19 +15,30 @@ type Storage struct {
 }
 
 type Comix struct {
+	ID          int
+	Slug        string
 	Description string
 	UploadDate  string
 	Views       int
+	ComixMeta
 }
 
 type ComixFromAllComix struct {
+	ID          int
+	Slug        string
 	ComixName   string
 	ComixTag    string
 	Description string
 	ComixDate   string
 	Views       int
+	ComixMeta
+	// Progress - место чтения для читателя запроса, nil если читателя нет или он не открывал комикс
+	Progress *ReadingProgress `json:",omitempty"`
 }
 
+// allComixColumns - столбцы all_comix, которые читаются в ComixFromAllComix через comixFields
+var allComixColumns = comixColumns("")
+
 func New(storagePath string) (*Storage, error) {
 	const fn = "storage.postgres.New"
 
@@ -32,7 +46,25 @@ func New(storagePath string) (*Storage, error) {
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", fn, err)
 	}
-	return &Storage{db: db}, nil
+
+	storage := &Storage{db: db}
+
+	err = storage.createSchema()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	err = storage.backfillSlugs()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	err = storage.backfillHistoricalViews()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return storage, nil
 }
 
 /*
@@ -78,7 +110,7 @@ func (s *Storage) AddComixByTagName(tagName string, name string, description str
 func (s *Storage) CheckComixExists(tagName string, name string) (bool, error) {
 	const fn = "storage.postgres.CheckComixExists"
 
-	query := fmt.Sprintf("SELECT EXISTS(SELECT * FROM %s WHERE name='%s')", tagName, name)
+	query := fmt.Sprintf("SELECT EXISTS(SELECT * FROM %s WHERE name='%s' AND %s)", tagName, name, notDeleted(tagName))
 
 	var rowExist bool
 
@@ -107,13 +139,15 @@ func (s *Storage) CheckComixExists(tagName string, name string) (bool, error) {
   - name - название комикса
     @return
     -Comix - возвращает структуру комикса
-  - err - ошибка
+  - err - ошибка, storage.ErrComixNotFound если комикса нет или он не виден читателям
     *
 */
 func (s *Storage) GetComixByName(tagName string, name string) (Comix, error) {
 	const fn = "storage.postgres.GetComixByName"
 
-	query := fmt.Sprintf("SELECT description,upload_date,views FROM %s WHERE name='%s'", tagName, name)
+	query := fmt.Sprintf(`SELECT COALESCE(c.id, 0), COALESCE(c.slug, ''), t.description, t.upload_date, t.views, %s
+		FROM %s t LEFT JOIN all_comix c ON c.comix_tag = '%s' AND c.comix_name = t.name
+		WHERE t.name='%s' AND %s`, metaColumns("c"), tagName, strings.ToLower(tagName), name, notHidden(tagName))
 
 	comix := Comix{}
 
@@ -126,7 +160,11 @@ func (s *Storage) GetComixByName(tagName string, name string) (Comix, error) {
 		return Comix{}, fmt.Errorf("%s: %w", fn, err)
 	}
 
-	err = stmt.QueryRow().Scan(&comix.Description, &comix.UploadDate, &comix.Views)
+	err = stmt.QueryRow().Scan(append([]interface{}{&comix.ID, &comix.Slug, &comix.Description, &comix.UploadDate, &comix.Views},
+		metaFields(&comix.ComixMeta)...)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Comix{}, fmt.Errorf("%s: %w", fn, storage.ErrComixNotFound)
+	}
 	if err != nil {
 		return Comix{}, fmt.Errorf("%s: %w", fn, err)
 	}
@@ -142,21 +180,30 @@ func (s *Storage) GetComixByName(tagName string, name string) (Comix, error) {
   - name - название комикса
   - description - описание комикса
   - currentDate - дата добавления
+  - pending - комикс ждёт проверки модератором и не виден читателям
+  - publishAt - когда комикс выйдет, нулевое время - сразу
     @return
   - err - ошибка
     *
 */
-func (s *Storage) AddComixToAllComixTable(tagName string, name string, description string, currentDate string) error {
+func (s *Storage) AddComixToAllComixTable(tagName string, name string, description string, currentDate string, pending bool, publishAt time.Time) error {
 	const fn = "storage.postgres.AddComixToAllComixTable"
 
-	query := fmt.Sprintf("INSERT INTO all_comix (comix_name, comix_tag, description, comix_date, views)  VALUES('%s','%s','%s','%s',1);", name, strings.ToLower(tagName), description, currentDate)
+	var id int
 
-	stmt, err := s.db.Prepare(query)
+	reviewStatus := ReviewApproved
+	if pending {
+		reviewStatus = ReviewPending
+	}
+
+	query := fmt.Sprintf("INSERT INTO all_comix (comix_name, comix_tag, description, comix_date, views, review_status, publish_at)  VALUES('%s','%s','%s','%s',1,$1,$2) RETURNING id;", name, strings.ToLower(tagName), description, currentDate)
+
+	err := s.db.QueryRow(query, reviewStatus, nullTime(publishAt)).Scan(&id)
 	if err != nil {
 		return fmt.Errorf("%s: %w", fn, err)
 	}
 
-	_, err = stmt.Exec()
+	_, err = assignSlug(s.db, slugKindComix, id, name)
 	if err != nil {
 		return fmt.Errorf("%s: %w", fn, err)
 	}
@@ -176,14 +223,16 @@ func (s *Storage) AddComixToAllComixTable(tagName string, name string, descripti
 func (s *Storage) AddComixTagToAllTags(tagName string) error {
 	const fn = "storage.postgres.AddComixTagToAllTags"
 
-	query := fmt.Sprintf("INSERT INTO all_tags VALUES('%s');", tagName)
+	var id int
 
-	stmt, err := s.db.Prepare(query)
+	query := fmt.Sprintf("INSERT INTO all_tags (tag) VALUES('%s') RETURNING id;", tagName)
+
+	err := s.db.QueryRow(query).Scan(&id)
 	if err != nil {
 		return fmt.Errorf("%s: %w", fn, err)
 	}
 
-	_, err = stmt.Exec()
+	_, err = assignSlug(s.db, slugKindTag, id, tagName)
 	if err != nil {
 		return fmt.Errorf("%s: %w", fn, err)
 	}
@@ -204,7 +253,7 @@ func (s *Storage) GetComixQuantity() (int, error) {
 
 	var quantity int
 
-	query := fmt.Sprintf("SELECT COUNT(*) FROM all_comix")
+	query := "SELECT COUNT(*) FROM all_comix c WHERE " + visibleComix
 
 	err := s.db.QueryRow(query).Scan(&quantity)
 	if err != nil {
@@ -229,7 +278,7 @@ func (s *Storage) GetComixQuantityFromTag(tagName string) (int, error) {
 
 	var quantity int
 
-	query := fmt.Sprintf("SELECT COUNT(*) FROM %s", tagName)
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s", tagName, notHidden(tagName))
 
 	err := s.db.QueryRow(query).Scan(&quantity)
 	if err != nil {
@@ -254,7 +303,7 @@ func (s *Storage) GetComixQuantityFromName(name string) (int, error) {
 
 	var quantity int
 
-	query := fmt.Sprintf("SELECT COUNT(*) FROM all_comix WHERE comix_name LIKE '%%%s%%';", name)
+	query := fmt.Sprintf("SELECT COUNT(*) FROM all_comix c WHERE comix_name LIKE '%%%s%%' AND %s;", name, visibleComix)
 
 	err := s.db.QueryRow(query).Scan(&quantity)
 	if err != nil {
@@ -294,22 +343,21 @@ func (s *Storage) GetTagDescription(tagName string) (string, error) {
   - Возвращает 16 комиксов из таблицы всех комиксов по параметру "pageToDisplay"
     @param
   - pageToDisplay - номер страницы для отображения
+  - order - порядок списка, по умолчанию сначала новые
     @return
   - err - ошибка
   - []ComixFromAllComix - 16 комиксов, каждый в виде структуры ComixFromAllComix
     *
 */
-func (s *Storage) GetComixForMainPage(pageToDisplay int) ([]ComixFromAllComix, error) {
+func (s *Storage) GetComixForMainPage(pageToDisplay int, order ComixOrder) ([]ComixFromAllComix, error) {
 	const fn = "storage.postgres.GetComixForMainPage"
 	const numberComicsPerPage = 16
 
-	foo := 0
-
 	var comixList []ComixFromAllComix
 
 	offset := (pageToDisplay - 1) * numberComicsPerPage
 
-	query := fmt.Sprintf("SELECT * FROM all_comix ORDER BY id DESC LIMIT %d OFFSET %d", numberComicsPerPage, offset)
+	query := fmt.Sprintf("SELECT %s FROM all_comix c WHERE %s ORDER BY %s LIMIT %d OFFSET %d", allComixColumns, visibleComix, order.orderBy("c", "c.id DESC"), numberComicsPerPage, offset)
 
 	rows, err := s.db.Query(query)
 	if err != nil {
@@ -318,7 +366,7 @@ func (s *Storage) GetComixForMainPage(pageToDisplay int) ([]ComixFromAllComix, e
 
 	for rows.Next() {
 		var comix ComixFromAllComix
-		err := rows.Scan(&foo, &comix.ComixName, &comix.ComixTag, &comix.Description, &comix.ComixDate, &comix.Views)
+		err := rows.Scan(comixFields(&comix)...)
 		if err != nil {
 			return []ComixFromAllComix{}, fmt.Errorf("%s: %w", fn, err)
 		}
@@ -334,22 +382,24 @@ func (s *Storage) GetComixForMainPage(pageToDisplay int) ([]ComixFromAllComix, e
     @param
   - pageToDisplay - номер страницы для отображения
     -tagName - название тэга
+  - order - порядок списка, по умолчанию сначала новые
     @return
   - err - ошибка
   - []ComixFromAllComix - 16 комиксов, каждый в виде структуры ComixFromAllComix
     *
 */
-func (s *Storage) GetAllTagComix(pageToDisplay int, tagName string) ([]ComixFromAllComix, error) {
+func (s *Storage) GetAllTagComix(pageToDisplay int, tagName string, order ComixOrder) ([]ComixFromAllComix, error) {
 	const fn = "storage.postgres.GetAllTagComix"
 	const numberComicsPerPage = 16
 
-	foo := 0
-
 	var comixList []ComixFromAllComix
 
 	offset := (pageToDisplay - 1) * numberComicsPerPage
 
-	query := fmt.Sprintf("SELECT * FROM %s ORDER BY id DESC LIMIT %d OFFSET %d", tagName, numberComicsPerPage, offset)
+	query := fmt.Sprintf(`SELECT COALESCE(c.id, 0), COALESCE(c.slug, ''), t.name, t.description, t.upload_date, t.views, %s
+		FROM %s t LEFT JOIN all_comix c ON c.comix_tag = '%s' AND c.comix_name = t.name
+		WHERE %s ORDER BY %s LIMIT %d OFFSET %d`, metaColumns("c"), tagName, strings.ToLower(tagName), notHidden(tagName),
+		order.orderBy("c", "t.id DESC"), numberComicsPerPage, offset)
 
 	rows, err := s.db.Query(query)
 	if err != nil {
@@ -358,7 +408,8 @@ func (s *Storage) GetAllTagComix(pageToDisplay int, tagName string) ([]ComixFrom
 
 	for rows.Next() {
 		var comix ComixFromAllComix
-		err := rows.Scan(&foo, &comix.ComixName, &comix.Description, &comix.ComixDate, &comix.Views)
+		err := rows.Scan(append([]interface{}{&comix.ID, &comix.Slug, &comix.ComixName, &comix.Description, &comix.ComixDate, &comix.Views},
+			metaFields(&comix.ComixMeta)...)...)
 		if err != nil {
 			return []ComixFromAllComix{}, fmt.Errorf("%s: %w", fn, err)
 		}
@@ -371,7 +422,7 @@ func (s *Storage) GetAllTagComix(pageToDisplay int, tagName string) ([]ComixFrom
 
 /*
 *
-  - Добавляет просмотры к комиксу
+  - Добавляет просмотры к комиксу, в том числе в дневную корзину просмотров
     @param
   - tag - название тэга
     -name - название комикса
@@ -384,7 +435,12 @@ func (s *Storage) AddViews(tag string, name string) error {
 
 	query := fmt.Sprintf("UPDATE %s SET views = views + 1 WHERE name='%s';", tag, name)
 
-	_, err := s.db.Query(query)
+	_, err := s.db.Exec(query)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	err = s.addDailyView(tag, name)
 	if err != nil {
 		return fmt.Errorf("%s: %w", fn, err)
 	}
@@ -427,7 +483,7 @@ func (s *Storage) GetAllTags() ([]string, error) {
 
 	var TagsList []string
 
-	query := fmt.Sprintf("SELECT * FROM all_tags")
+	query := fmt.Sprintf("SELECT tag FROM all_tags ORDER BY id")
 
 	rows, err := s.db.Query(query)
 	if err != nil {
@@ -448,20 +504,22 @@ func (s *Storage) GetAllTags() ([]string, error) {
 
 /*
 *
-  - Удаляет комикс из таблицы по тэгу
+  - Изменяет параметры комикса из таблицы всех комиксов: название, описание
     @param
-  - tag - название тэга
+  - param - параметр
     -name - название комикса
+    -newValue - новое значение параметра комикса
     @return
   - err - ошибка
     *
 */
-func (s *Storage) DeleteComixFromTagTable(tag string, name string) error {
-	const fn = "storage.postgres.DeleteComixFromTagTable"
+func (s *Storage) EditComixFromAllComixTable(name string, param string, newValue string) error {
+	const fn = "storage.postgres.EditComixFromAllComixTable"
 
-	query := fmt.Sprintf("DELETE FROM %s WHERE name = '%s'", tag, name)
+	// param - имя столбца из списка разрешённых в обработчике, значения передаются параметрами
+	query := fmt.Sprintf("UPDATE all_comix SET %s = $1, updated_at = now() WHERE comix_name = $2", param)
 
-	_, err := s.db.Query(query)
+	_, err := s.db.Exec(query, newValue, name)
 	if err != nil {
 		return fmt.Errorf("%s: %w", fn, err)
 	}
@@ -470,81 +528,69 @@ func (s *Storage) DeleteComixFromTagTable(tag string, name string) error {
 
 /*
 *
-  - Удаляет комикс из таблицы всех комиксов по имени
+  - Переносит комикс в другой тэг одной транзакцией: строку all_comix по id и строку из таблицы старого тэга
+  - в таблицу нового
     @param
+  - tagName - текущий тэг комикса
     -name - название комикса
+    -newTagName - тэг, в который переносится комикс
     @return
-  - err - ошибка
+  - err - ошибка, storage.ErrComixNotFound если комикса нет, storage.ErrTagNotFound если нет нового тэга,
+    storage.ErrComixExists если в новом тэге уже есть комикс с таким названием
     *
 */
-func (s *Storage) DeleteComixFromAllComixTable(name string) error {
-	const fn = "storage.postgres.DeleteComixFromAllComixTable"
-
-	query := fmt.Sprintf("DELETE FROM all_comix WHERE comix_name = '%s'", name)
+func (s *Storage) MoveComixToTag(tagName string, name string, newTagName string) error {
+	const fn = "storage.postgres.MoveComixToTag"
 
-	_, err := s.db.Query(query)
+	tx, err := s.db.Begin()
 	if err != nil {
 		return fmt.Errorf("%s: %w", fn, err)
 	}
-	return nil
-}
+	defer tx.Rollback()
 
-/*
-*
-  - Изменяет параметры комикса из таблицы всех комиксов: название, описание
-    @param
-  - param - параметр
-    -name - название комикса
-    -newValue - новое значение параметра комикса
-    @return
-  - err - ошибка
-    *
-*/
-func (s *Storage) EditComixFromAllComixTable(name string, param string, newValue string) error {
-	const fn = "storage.postgres.EditComixFromAllComixTable"
-
-	query := fmt.Sprintf("UPDATE all_comix SET %s = '%s' WHERE comix_name = '%s'", param, newValue, name)
-
-	_, err := s.db.Query(query)
+	_, _, err = lockTag(tx, newTagName)
 	if err != nil {
 		return fmt.Errorf("%s: %w", fn, err)
 	}
-	return nil
-}
 
-/*
-*
-  - Изменяет тэг комикса
-    @param
-  - tag - название тэга
-    -name - название комикса
-    -newValue - новое значение тэга комикса
-    @return
-  - err - ошибка
-    *
-*/
-func (s *Storage) EditComixTag(tag string, name string, newValue string) error {
-	const fn = "storage.postgres.EditComixTag"
+	var id int
 
-	comix := Comix{}
+	err = tx.QueryRow(`SELECT id FROM all_comix
+		WHERE comix_tag = lower($1) AND comix_name = $2 AND deleted_at IS NULL
+		FOR UPDATE`, tagName, name).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%s: %w", fn, storage.ErrComixNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
 
-	query := fmt.Sprintf("SELECT description,upload_date,views FROM %s WHERE name = '%s'", tag, name)
+	// Комикс в корзине тоже занимает название: его можно восстановить
+	var taken bool
 
-	err := s.db.QueryRow(query).Scan(&comix.Description, &comix.UploadDate, &comix.Views)
+	err = tx.QueryRow(`SELECT EXISTS(SELECT 1 FROM all_comix WHERE comix_tag = lower($1) AND comix_name = $2)`,
+		newTagName, name).Scan(&taken)
 	if err != nil {
 		return fmt.Errorf("%s: %w", fn, err)
 	}
 
-	query = fmt.Sprintf("DELETE FROM %s WHERE name = '%s'", tag, name)
+	if taken {
+		return fmt.Errorf("%s: %w", fn, storage.ErrComixExists)
+	}
 
-	_, err = s.db.Query(query)
+	_, err = tx.Exec(`UPDATE all_comix SET comix_tag = lower($1), updated_at = now() WHERE id = $2`, newTagName, id)
 	if err != nil {
 		return fmt.Errorf("%s: %w", fn, err)
 	}
 
-	query = fmt.Sprintf("INSERT into %s (name,description,upload_date,views) VALUES('%s','%s','%s',1);", newValue, name, comix.Description, comix.UploadDate)
+	_, err = tx.Exec(fmt.Sprintf(`WITH moved AS (DELETE FROM %s WHERE name = $1 RETURNING name, description, upload_date, views)
+		INSERT INTO %s (name, description, upload_date, views) SELECT name, description, upload_date, views FROM moved`,
+		tagName, newTagName), name)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
 
-	_, err = s.db.Query(query)
+	err = tx.Commit()
 	if err != nil {
 		return fmt.Errorf("%s: %w", fn, err)
 	}
@@ -592,9 +638,9 @@ func (s *Storage) EditComixFromTagTable(tag string, name string, param string, n
 
 		return nil
 	}
-	query := fmt.Sprintf("UPDATE %s SET %s = '%s' WHERE name = '%s'", tag, param, newValue, name)
+	query := fmt.Sprintf("UPDATE %s SET %s = $1 WHERE name = $2", tag, param)
 
-	_, err := s.db.Query(query)
+	_, err := s.db.Exec(query, newValue, name)
 	if err != nil {
 		return fmt.Errorf("%s: %w", fn, err)
 	}
@@ -668,23 +714,22 @@ func (s *Storage) TagExist(tagName string) (bool, error) {
     @param
   - name - название комикса
   - pageToDisplay - страница для отображения
+  - order - порядок списка, по умолчанию сначала новые
     @return
   - err - ошибка
   - []ComixFromAllComix - список комиксов
     *
 */
-func (s *Storage) FindComixFromAllComix(name string, pageToDisplay int) ([]ComixFromAllComix, error) {
+func (s *Storage) FindComixFromAllComix(name string, pageToDisplay int, order ComixOrder) ([]ComixFromAllComix, error) {
 
 	const fn = "storage.postgres.FindComixFromAllComix"
 	const numberComicsPerPage = 16
 
-	foo := 0
-
 	var comixList []ComixFromAllComix
 
 	offset := (pageToDisplay - 1) * numberComicsPerPage
 
-	query := fmt.Sprintf("SELECT * FROM all_comix WHERE comix_name LIKE '%%%s%%' ORDER BY id DESC LIMIT %d OFFSET %d;", name, numberComicsPerPage, offset)
+	query := fmt.Sprintf("SELECT %s FROM all_comix c WHERE comix_name LIKE '%%%s%%' AND %s ORDER BY %s LIMIT %d OFFSET %d;", allComixColumns, name, visibleComix, order.orderBy("c", "c.id DESC"), numberComicsPerPage, offset)
 
 	rows, err := s.db.Query(query)
 	if err != nil {
@@ -693,7 +738,7 @@ func (s *Storage) FindComixFromAllComix(name string, pageToDisplay int) ([]Comix
 
 	for rows.Next() {
 		var comix ComixFromAllComix
-		err := rows.Scan(&foo, &comix.ComixName, &comix.ComixTag, &comix.Description, &comix.ComixDate, &comix.Views)
+		err := rows.Scan(comixFields(&comix)...)
 		if err != nil {
 			return []ComixFromAllComix{}, fmt.Errorf("%s: %w", fn, err)
 		}
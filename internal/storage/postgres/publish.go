@@ -0,0 +1,277 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"jadesheart/comix_back/internal/storage"
+	"time"
+)
+
+// Что выходит по расписанию: комикс целиком или новые страницы уже вышедшего комикса
+const (
+	ReleaseComix = "comix"
+	ReleasePages = "pages"
+)
+
+// releasesPerPage - выпусков на странице расписания
+const releasesPerPage = 16
+
+// publishedPage - условие на comix_pages, при котором страница уже вышла
+const publishedPage = "(publish_at IS NULL OR publish_at <= now())"
+
+// Release - запланированный выпуск. Страницы одного комикса с одним временем выхода - один выпуск.
+// Pages - число страниц выпуска, у комикса - все его страницы.
+type Release struct {
+	Kind      string
+	ComixID   int
+	ComixTag  string
+	ComixName string
+	Slug      string
+	PublishAt time.Time
+	Pages     int
+}
+
+// nullTimePtr читает время, которое может быть NULL, в *time.Time: NULL - nil
+type nullTimePtr struct {
+	dest **time.Time
+}
+
+func (n nullTimePtr) Scan(value interface{}) error {
+	var t sql.NullTime
+
+	err := t.Scan(value)
+	if err != nil {
+		return err
+	}
+
+	*n.dest = nil
+	if t.Valid {
+		*n.dest = &t.Time
+	}
+
+	return nil
+}
+
+// optionalTime - nil для нулевого времени
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
+
+// timeOrZero - нулевое время для nil
+func timeOrZero(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+
+	return *t
+}
+
+/*
+*
+  - Возвращает 16 запланированных выпусков, ближайшие первыми. Комиксы в корзине не входят
+    @param
+  - pageToDisplay - номер страницы для отображения
+    @return
+  - err - ошибка
+  - []Release - выпуски
+    *
+*/
+func (s *Storage) GetScheduledReleases(pageToDisplay int) ([]Release, error) {
+	const fn = "storage.postgres.GetScheduledReleases"
+
+	offset := (pageToDisplay - 1) * releasesPerPage
+
+	query := `SELECT $1, c.id, c.comix_tag, c.comix_name, COALESCE(c.slug, ''), c.publish_at,
+			(SELECT COUNT(*) FROM comix_pages p WHERE p.comix_id = c.id)
+		FROM all_comix c WHERE c.publish_at > now() AND c.deleted_at IS NULL
+		UNION ALL
+		SELECT $2, c.id, c.comix_tag, c.comix_name, COALESCE(c.slug, ''), p.publish_at, COUNT(*)
+		FROM comix_pages p JOIN all_comix c ON c.id = p.comix_id
+		WHERE p.publish_at > now() AND c.deleted_at IS NULL
+		GROUP BY c.id, p.publish_at
+		ORDER BY 6, 2 LIMIT $3 OFFSET $4`
+
+	rows, err := s.db.Query(query, ReleaseComix, ReleasePages, releasesPerPage, offset)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+	defer rows.Close()
+
+	releases := []Release{}
+
+	for rows.Next() {
+		var release Release
+		err := rows.Scan(&release.Kind, &release.ComixID, &release.ComixTag, &release.ComixName, &release.Slug,
+			&release.PublishAt, &release.Pages)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fn, err)
+		}
+		releases = append(releases, release)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return releases, nil
+}
+
+/*
+*
+  - Переносит выход запланированного комикса
+    @param
+  - tagName - название тэга
+  - name - название комикса
+  - publishAt - новое время выхода, нулевое время - выпустить сразу
+    @return
+  - err - ошибка, storage.ErrReleaseNotFound если комикс не ждёт выхода
+  - time.Time - прежнее время выхода
+    *
+*/
+func (s *Storage) RescheduleComix(tagName string, name string, publishAt time.Time) (time.Time, error) {
+	const fn = "storage.postgres.RescheduleComix"
+
+	var before time.Time
+
+	err := s.db.QueryRow(`WITH old AS (
+			SELECT id, publish_at FROM all_comix
+			WHERE comix_tag = lower($1) AND comix_name = $2 AND deleted_at IS NULL AND publish_at > now()
+			FOR UPDATE
+		)
+		UPDATE all_comix c SET publish_at = $3 FROM old WHERE c.id = old.id
+		RETURNING old.publish_at`, tagName, name, nullTime(publishAt)).Scan(&before)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, fmt.Errorf("%s: %w", fn, storage.ErrReleaseNotFound)
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return before, nil
+}
+
+/*
+*
+  - Переносит выход запланированных страниц комикса
+    @param
+  - tagName - название тэга
+  - name - название комикса
+  - from - время выхода, под которым страницы запланированы сейчас
+  - publishAt - новое время выхода, нулевое время - выпустить сразу
+    @return
+  - err - ошибка, storage.ErrReleaseNotFound если у комикса нет страниц, которые ждут выхода в from
+  - int - сколько страниц перенесено
+    *
+*/
+func (s *Storage) ReschedulePages(tagName string, name string, from time.Time, publishAt time.Time) (int, error) {
+	const fn = "storage.postgres.ReschedulePages"
+
+	res, err := s.db.Exec(`UPDATE comix_pages SET publish_at = $4
+		WHERE comix_id = (SELECT id FROM all_comix WHERE comix_tag = lower($1) AND comix_name = $2 AND deleted_at IS NULL)
+			AND publish_at = $3 AND publish_at > now()`, tagName, name, from, nullTime(publishAt))
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", fn, err)
+	}
+	if n == 0 {
+		return 0, fmt.Errorf("%s: %w", fn, storage.ErrReleaseNotFound)
+	}
+
+	return int(n), nil
+}
+
+/*
+*
+  - Выпускает всё, чьё время выхода наступило: сбрасывает publish_at у комиксов и страниц.
+    Комиксам с новыми страницами обновляется updated_at
+    @return
+  - err - ошибка
+  - []Release - вышедшие выпуски с их временем выхода
+    *
+*/
+func (s *Storage) PublishDue() ([]Release, error) {
+	const fn = "storage.postgres.PublishDue"
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+	defer tx.Rollback()
+
+	releases := []Release{}
+
+	comixRows, err := tx.Query(`WITH due AS (
+			SELECT id, publish_at FROM all_comix WHERE publish_at <= now() FOR UPDATE
+		)
+		UPDATE all_comix c SET publish_at = NULL FROM due WHERE c.id = due.id
+		RETURNING c.id, c.comix_tag, c.comix_name, COALESCE(c.slug, ''), due.publish_at,
+			(SELECT COUNT(*) FROM comix_pages p WHERE p.comix_id = c.id)`)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	releases, err = scanReleases(comixRows, ReleaseComix, releases)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	pageRows, err := tx.Query(`WITH due AS (
+			SELECT id, comix_id, publish_at FROM comix_pages WHERE publish_at <= now() FOR UPDATE
+		), updated AS (
+			UPDATE comix_pages p SET publish_at = NULL FROM due WHERE p.id = due.id
+			RETURNING due.comix_id, due.publish_at
+		)
+		SELECT c.id, c.comix_tag, c.comix_name, COALESCE(c.slug, ''), u.publish_at, COUNT(*)
+		FROM updated u JOIN all_comix c ON c.id = u.comix_id
+		GROUP BY c.id, u.publish_at ORDER BY u.publish_at, c.id`)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	count := len(releases)
+
+	releases, err = scanReleases(pageRows, ReleasePages, releases)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	for _, release := range releases[count:] {
+		err = touchComix(tx, release.ComixID)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fn, err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return releases, nil
+}
+
+// scanReleases дописывает к releases выпуски вида kind из rows и закрывает rows
+func scanReleases(rows *sql.Rows, kind string, releases []Release) ([]Release, error) {
+	defer rows.Close()
+
+	for rows.Next() {
+		release := Release{Kind: kind}
+		err := rows.Scan(&release.ComixID, &release.ComixTag, &release.ComixName, &release.Slug,
+			&release.PublishAt, &release.Pages)
+		if err != nil {
+			return nil, err
+		}
+		releases = append(releases, release)
+	}
+
+	return releases, rows.Err()
+}
@@ -0,0 +1,289 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"jadesheart/comix_back/internal/storage"
+	"strings"
+	"time"
+)
+
+// Порядок списков комиксов
+const (
+	ComixSortNew = "new"
+	// ComixSortRating - по средней оценке, комиксы с малым числом оценок в конце
+	ComixSortRating = "rating"
+)
+
+// reviewsPerPage - отзывов на странице
+const reviewsPerPage = 16
+
+// RatingSummary - сводка оценок комикса. Histogram[i] - число оценок i+1.
+type RatingSummary struct {
+	Average   float64
+	Votes     int
+	Histogram []int64
+}
+
+// Rating - оценка читателя и его отзыв, Review пустой если отзыва нет
+type Rating struct {
+	Score     int
+	Review    string
+	UpdatedAt time.Time
+}
+
+// Review - отзыв читателя к комиксу
+type Review struct {
+	Author    string
+	Score     int
+	Review    string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// ComixOrder - порядок списка комиксов. При ComixSortRating комиксы, у которых меньше MinVotes оценок,
+// идут после остальных в обычном порядке: средняя по одной-двум оценкам ничего не говорит.
+type ComixOrder struct {
+	Sort     string
+	MinVotes int
+}
+
+// orderBy - выражение ORDER BY; alias - псевдоним all_comix, fallback - обычный порядок списка
+func (o ComixOrder) orderBy(alias string, fallback string) string {
+	if o.Sort != ComixSortRating {
+		return fallback
+	}
+
+	p := ""
+	if alias != "" {
+		p = alias + "."
+	}
+
+	minVotes := o.MinVotes
+	if minVotes < 1 {
+		minVotes = 1
+	}
+
+	return fmt.Sprintf("CASE WHEN COALESCE(%srating_votes, 0) >= %d THEN %srating_sum::float8 / %srating_votes END DESC NULLS LAST, "+
+		"%srating_votes DESC NULLS LAST, %s", p, minVotes, p, p, p, fallback)
+}
+
+// ratingColumns - столбцы RatingSummary в all_comix; alias - псевдоним таблицы в запросе
+func ratingColumns(alias string) string {
+	p := ""
+	if alias != "" {
+		p = alias + "."
+	}
+
+	return strings.NewReplacer("$", p).Replace(
+		"COALESCE(ROUND($rating_sum::numeric / NULLIF($rating_votes, 0), 2), 0)::float8, " +
+			"COALESCE($rating_votes, 0), COALESCE($rating_histogram, '{0,0,0,0,0}')")
+}
+
+func ratingFields(summary *RatingSummary) []interface{} {
+	return []interface{}{&summary.Average, &summary.Votes, pq.Array(&summary.Histogram)}
+}
+
+// lockRatedComix блокирует строку комикса до конца транзакции, чтобы оценки одного комикса
+// пересчитывались по очереди
+func lockRatedComix(tx *sql.Tx, comixID int) error {
+	var id int
+
+	err := tx.QueryRow(`SELECT c.id FROM all_comix c WHERE c.id = $1 AND `+visibleComix+` FOR UPDATE`, comixID).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return storage.ErrComixNotFound
+	}
+
+	return err
+}
+
+// updateRatingSummary пересчитывает сводку оценок комикса из ratings
+func updateRatingSummary(q querier, comixID int) (RatingSummary, error) {
+	var summary RatingSummary
+
+	err := q.QueryRow(fmt.Sprintf(`UPDATE all_comix c SET rating_votes = a.votes, rating_sum = a.total, rating_histogram = a.histogram
+		FROM (SELECT COUNT(*)::int AS votes, COALESCE(SUM(score), 0)::int AS total,
+				ARRAY[
+					COUNT(*) FILTER (WHERE score = 1), COUNT(*) FILTER (WHERE score = 2), COUNT(*) FILTER (WHERE score = 3),
+					COUNT(*) FILTER (WHERE score = 4), COUNT(*) FILTER (WHERE score = 5)
+				]::int[] AS histogram
+			FROM ratings WHERE comix_id = $1) a
+		WHERE c.id = $1 RETURNING %s`, ratingColumns("c")), comixID).Scan(ratingFields(&summary)...)
+	if err != nil {
+		return RatingSummary{}, err
+	}
+
+	return summary, nil
+}
+
+/*
+*
+  - Ставит или меняет оценку читателя комиксу и пересчитывает сводку оценок
+    @param
+  - readerID - id читателя
+  - comixID - id комикса
+  - score - оценка от 1 до 5
+  - review - текст отзыва, пустая строка - без отзыва
+    @return
+  - err - ошибка, storage.ErrComixNotFound если комикса нет или он в корзине
+  - RatingSummary - сводка оценок комикса после изменения
+    *
+*/
+func (s *Storage) RateComix(readerID int, comixID int, score int, review string) (RatingSummary, error) {
+	const fn = "storage.postgres.RateComix"
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return RatingSummary{}, fmt.Errorf("%s: %w", fn, err)
+	}
+	defer tx.Rollback()
+
+	err = lockRatedComix(tx, comixID)
+	if err != nil {
+		return RatingSummary{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	_, err = tx.Exec(`INSERT INTO ratings (reader_id, comix_id, score, review) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (reader_id, comix_id) DO UPDATE SET score = EXCLUDED.score, review = EXCLUDED.review, updated_at = now()`,
+		readerID, comixID, score, review)
+	if err != nil {
+		return RatingSummary{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	summary, err := updateRatingSummary(tx, comixID)
+	if err != nil {
+		return RatingSummary{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return RatingSummary{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return summary, nil
+}
+
+/*
+*
+  - Убирает оценку читателя вместе с отзывом и пересчитывает сводку оценок
+    @param
+  - readerID - id читателя
+  - comixID - id комикса
+    @return
+  - err - ошибка, storage.ErrComixNotFound если комикса нет, storage.ErrRatingNotFound если оценки нет
+  - RatingSummary - сводка оценок комикса после изменения
+    *
+*/
+func (s *Storage) DeleteRating(readerID int, comixID int) (RatingSummary, error) {
+	const fn = "storage.postgres.DeleteRating"
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return RatingSummary{}, fmt.Errorf("%s: %w", fn, err)
+	}
+	defer tx.Rollback()
+
+	err = lockRatedComix(tx, comixID)
+	if err != nil {
+		return RatingSummary{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	res, err := tx.Exec(`DELETE FROM ratings WHERE reader_id = $1 AND comix_id = $2`, readerID, comixID)
+	if err != nil {
+		return RatingSummary{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return RatingSummary{}, fmt.Errorf("%s: %w", fn, err)
+	}
+	if n == 0 {
+		return RatingSummary{}, fmt.Errorf("%s: %w", fn, storage.ErrRatingNotFound)
+	}
+
+	summary, err := updateRatingSummary(tx, comixID)
+	if err != nil {
+		return RatingSummary{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return RatingSummary{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return summary, nil
+}
+
+/*
+*
+  - Возвращает оценку читателя комиксу
+    @param
+  - readerID - id читателя
+  - comixID - id комикса
+    @return
+  - err - ошибка, storage.ErrRatingNotFound если читатель не оценивал комикс
+  - Rating - оценка и отзыв
+    *
+*/
+func (s *Storage) GetRating(readerID int, comixID int) (Rating, error) {
+	const fn = "storage.postgres.GetRating"
+
+	var rating Rating
+
+	err := s.db.QueryRow(`SELECT score, review, updated_at FROM ratings WHERE reader_id = $1 AND comix_id = $2`,
+		readerID, comixID).Scan(&rating.Score, &rating.Review, &rating.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Rating{}, fmt.Errorf("%s: %w", fn, storage.ErrRatingNotFound)
+	}
+	if err != nil {
+		return Rating{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return rating, nil
+}
+
+/*
+*
+  - Возвращает 16 отзывов к комиксу, сначала недавно изменённые. Оценки без текста не возвращаются
+    @param
+  - comixID - id комикса
+  - pageToDisplay - номер страницы для отображения
+    @return
+  - err - ошибка
+  - []Review - отзывы
+    *
+*/
+func (s *Storage) GetReviews(comixID int, pageToDisplay int) ([]Review, error) {
+	const fn = "storage.postgres.GetReviews"
+
+	offset := (pageToDisplay - 1) * reviewsPerPage
+
+	rows, err := s.db.Query(fmt.Sprintf(`SELECT r.username, g.score, g.review, g.created_at, g.updated_at
+		FROM ratings g JOIN readers r ON r.id = g.reader_id
+		WHERE g.comix_id = $1 AND g.review <> ''
+		ORDER BY g.updated_at DESC, g.reader_id LIMIT %d OFFSET %d`, reviewsPerPage, offset), comixID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+	defer rows.Close()
+
+	reviews := []Review{}
+
+	for rows.Next() {
+		var review Review
+
+		err := rows.Scan(&review.Author, &review.Score, &review.Review, &review.CreatedAt, &review.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fn, err)
+		}
+		reviews = append(reviews, review)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return reviews, nil
+}
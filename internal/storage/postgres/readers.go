@@ -0,0 +1,161 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"jadesheart/comix_back/internal/storage"
+	"time"
+)
+
+// Reader - учётная запись читателя. Пароль читателя не связан с паролем администратора.
+type Reader struct {
+	ID        int
+	Username  string
+	CreatedAt time.Time
+}
+
+/*
+*
+  - Создаёт читателя. Имена сравниваются без учёта регистра
+    @param
+  - username - имя читателя
+  - passwordHash - хэш пароля из account.HashPassword
+    @return
+  - err - ошибка, storage.ErrReaderExists если имя занято
+  - Reader - созданный читатель
+    *
+*/
+func (s *Storage) CreateReader(username string, passwordHash string) (Reader, error) {
+	const fn = "storage.postgres.CreateReader"
+
+	var reader Reader
+
+	err := s.db.QueryRow(`INSERT INTO readers (username, password_hash) VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+		RETURNING id, username, created_at`, username, passwordHash).Scan(&reader.ID, &reader.Username, &reader.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Reader{}, fmt.Errorf("%s: %w", fn, storage.ErrReaderExists)
+	}
+	if err != nil {
+		return Reader{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return reader, nil
+}
+
+/*
+*
+  - Находит читателя по имени для входа
+    @param
+  - username - имя читателя, регистр не учитывается
+    @return
+  - err - ошибка, storage.ErrReaderNotFound если читателя нет
+  - Reader - читатель
+  - string - хэш пароля
+    *
+*/
+func (s *Storage) GetReaderByName(username string) (Reader, string, error) {
+	const fn = "storage.postgres.GetReaderByName"
+
+	var reader Reader
+	var passwordHash string
+
+	err := s.db.QueryRow(`SELECT id, username, created_at, password_hash FROM readers WHERE lower(username) = lower($1)`,
+		username).Scan(&reader.ID, &reader.Username, &reader.CreatedAt, &passwordHash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Reader{}, "", fmt.Errorf("%s: %w", fn, storage.ErrReaderNotFound)
+	}
+	if err != nil {
+		return Reader{}, "", fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return reader, passwordHash, nil
+}
+
+/*
+*
+  - Открывает сессию читателя. Заодно удаляет его истёкшие сессии
+    @param
+  - readerID - id читателя
+  - tokenHash - хэш токена из account.NewToken
+  - ttl - время жизни сессии
+    @return
+  - err - ошибка
+    *
+*/
+func (s *Storage) CreateSession(readerID int, tokenHash string, ttl time.Duration) error {
+	const fn = "storage.postgres.CreateSession"
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`DELETE FROM reader_sessions WHERE reader_id = $1 AND expires_at <= now()`, readerID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	_, err = tx.Exec(`INSERT INTO reader_sessions (token_hash, reader_id, expires_at) VALUES ($1, $2, $3)`,
+		tokenHash, readerID, time.Now().Add(ttl))
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return nil
+}
+
+/*
+*
+  - Возвращает читателя по действующей сессии
+    @param
+  - tokenHash - хэш токена сессии
+    @return
+  - err - ошибка, storage.ErrSessionNotFound если сессии нет или она истекла
+  - Reader - читатель
+    *
+*/
+func (s *Storage) GetReaderBySession(tokenHash string) (Reader, error) {
+	const fn = "storage.postgres.GetReaderBySession"
+
+	var reader Reader
+
+	err := s.db.QueryRow(`SELECT r.id, r.username, r.created_at
+		FROM reader_sessions rs JOIN readers r ON r.id = rs.reader_id
+		WHERE rs.token_hash = $1 AND rs.expires_at > now()`, tokenHash).Scan(&reader.ID, &reader.Username, &reader.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Reader{}, fmt.Errorf("%s: %w", fn, storage.ErrSessionNotFound)
+	}
+	if err != nil {
+		return Reader{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return reader, nil
+}
+
+/*
+*
+  - Закрывает сессию читателя
+    @param
+  - tokenHash - хэш токена сессии
+    @return
+  - err - ошибка
+    *
+*/
+func (s *Storage) DeleteSession(tokenHash string) error {
+	const fn = "storage.postgres.DeleteSession"
+
+	_, err := s.db.Exec(`DELETE FROM reader_sessions WHERE token_hash = $1`, tokenHash)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return nil
+}
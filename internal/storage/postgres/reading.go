@@ -0,0 +1,283 @@
+package postgres
+
+import (
+	"fmt"
+	"github.com/lib/pq"
+	"jadesheart/comix_back/internal/storage"
+	"time"
+)
+
+// ReadingProgress - место, на котором читатель остановился в комиксе.
+// Position - позиция страницы PageID, 0 если страницу с тех пор удалили.
+type ReadingProgress struct {
+	PageID    int
+	Position  int
+	Pages     int
+	Read      bool
+	UpdatedAt time.Time
+}
+
+// readingPerPage - комиксов на странице закладок и списка "продолжить чтение"
+const readingPerPage = 16
+
+// progressColumns - столбцы ReadingProgress; p - reading_progress, pg - comix_pages, c - all_comix
+const progressColumns = `p.page_id, COALESCE(pg.position, 0),
+	(SELECT COUNT(*) FROM comix_pages WHERE comix_id = c.id AND ` + publishedPage + `), p.updated_at`
+
+func progressFields(progress *ReadingProgress) []interface{} {
+	return []interface{}{&progress.PageID, &progress.Position, &progress.Pages, &progress.UpdatedAt}
+}
+
+// markRead отмечает комикс прочитанным, если читатель дошёл до последней страницы
+func (p *ReadingProgress) markRead() {
+	p.Read = p.Position > 0 && p.Position >= p.Pages
+}
+
+/*
+*
+  - Добавляет комикс в закладки читателя. Повторное добавление ничего не меняет
+    @param
+  - readerID - id читателя
+  - comixID - id комикса
+    @return
+  - err - ошибка, storage.ErrComixNotFound если комикса нет или он в корзине
+    *
+*/
+func (s *Storage) AddBookmark(readerID int, comixID int) error {
+	const fn = "storage.postgres.AddBookmark"
+
+	res, err := s.db.Exec(`INSERT INTO bookmarks (reader_id, comix_id)
+		SELECT $1, c.id FROM all_comix c WHERE c.id = $2 AND `+visibleComix+`
+		ON CONFLICT DO NOTHING`, readerID, comixID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	if n == 0 {
+		var exists bool
+
+		err = s.db.QueryRow(`SELECT EXISTS(SELECT 1 FROM all_comix c WHERE c.id = $1 AND `+visibleComix+`)`, comixID).Scan(&exists)
+		if err != nil {
+			return fmt.Errorf("%s: %w", fn, err)
+		}
+		if !exists {
+			return fmt.Errorf("%s: %w", fn, storage.ErrComixNotFound)
+		}
+	}
+
+	return nil
+}
+
+/*
+*
+  - Убирает комикс из закладок читателя
+    @param
+  - readerID - id читателя
+  - comixID - id комикса
+    @return
+  - err - ошибка
+    *
+*/
+func (s *Storage) DeleteBookmark(readerID int, comixID int) error {
+	const fn = "storage.postgres.DeleteBookmark"
+
+	_, err := s.db.Exec(`DELETE FROM bookmarks WHERE reader_id = $1 AND comix_id = $2`, readerID, comixID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return nil
+}
+
+/*
+*
+  - Возвращает закладки читателя, последние добавленные первыми, вместе с местом чтения
+    @param
+  - readerID - id читателя
+  - pageToDisplay - номер страницы для отображения
+    @return
+  - err - ошибка
+  - []ComixFromAllComix - 16 комиксов
+    *
+*/
+func (s *Storage) GetBookmarks(readerID int, pageToDisplay int) ([]ComixFromAllComix, error) {
+	const fn = "storage.postgres.GetBookmarks"
+
+	offset := (pageToDisplay - 1) * readingPerPage
+
+	query := fmt.Sprintf(`SELECT %s FROM bookmarks b JOIN all_comix c ON c.id = b.comix_id
+		WHERE b.reader_id = $1 AND `+visibleComix+`
+		ORDER BY b.created_at DESC, c.id DESC LIMIT %d OFFSET %d`, comixColumns("c"), readingPerPage, offset)
+
+	rows, err := s.db.Query(query, readerID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+	defer rows.Close()
+
+	comixList := []ComixFromAllComix{}
+
+	for rows.Next() {
+		var comix ComixFromAllComix
+		err := rows.Scan(comixFields(&comix)...)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fn, err)
+		}
+		comixList = append(comixList, comix)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	err = s.AttachReadingProgress(readerID, comixList)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return comixList, nil
+}
+
+/*
+*
+  - Запоминает страницу, на которой читатель остановился в комиксе
+    @param
+  - readerID - id читателя
+  - comixID - id комикса
+  - pageID - id страницы комикса
+    @return
+  - err - ошибка, storage.ErrPageNotFound если у комикса нет такой страницы или она ещё не вышла
+    *
+*/
+func (s *Storage) SaveProgress(readerID int, comixID int, pageID int) error {
+	const fn = "storage.postgres.SaveProgress"
+
+	res, err := s.db.Exec(`INSERT INTO reading_progress (reader_id, comix_id, page_id)
+		SELECT $1, comix_id, id FROM comix_pages WHERE id = $3 AND comix_id = $2 AND `+publishedPage+`
+		ON CONFLICT (reader_id, comix_id) DO UPDATE SET page_id = EXCLUDED.page_id, updated_at = now()`,
+		readerID, comixID, pageID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%s: %w", fn, storage.ErrPageNotFound)
+	}
+
+	return nil
+}
+
+/*
+*
+  - Возвращает начатые и не дочитанные комиксы читателя, последние читанные первыми
+    @param
+  - readerID - id читателя
+  - pageToDisplay - номер страницы для отображения
+    @return
+  - err - ошибка
+  - []ComixFromAllComix - 16 комиксов с заполненным Progress
+    *
+*/
+func (s *Storage) GetContinueReading(readerID int, pageToDisplay int) ([]ComixFromAllComix, error) {
+	const fn = "storage.postgres.GetContinueReading"
+
+	offset := (pageToDisplay - 1) * readingPerPage
+
+	query := fmt.Sprintf(`SELECT %s, %s FROM reading_progress p
+		JOIN all_comix c ON c.id = p.comix_id
+		LEFT JOIN comix_pages pg ON pg.id = p.page_id
+		WHERE p.reader_id = $1 AND `+visibleComix+`
+			AND COALESCE(pg.position, 0) < (SELECT COUNT(*) FROM comix_pages WHERE comix_id = c.id AND `+publishedPage+`)
+		ORDER BY p.updated_at DESC, c.id DESC LIMIT %d OFFSET %d`, comixColumns("c"), progressColumns, readingPerPage, offset)
+
+	rows, err := s.db.Query(query, readerID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+	defer rows.Close()
+
+	comixList := []ComixFromAllComix{}
+
+	for rows.Next() {
+		var comix ComixFromAllComix
+		progress := &ReadingProgress{}
+		err := rows.Scan(append(comixFields(&comix), progressFields(progress)...)...)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fn, err)
+		}
+		progress.markRead()
+		comix.Progress = progress
+		comixList = append(comixList, comix)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return comixList, nil
+}
+
+/*
+*
+  - Заполняет Progress у комиксов списка, которые читатель уже открывал.
+  - У нечитанных комиксов и строк без id в all_comix Progress остаётся nil
+    @param
+  - readerID - id читателя
+  - comixList - комиксы списка, меняются на месте
+    @return
+  - err - ошибка
+    *
+*/
+func (s *Storage) AttachReadingProgress(readerID int, comixList []ComixFromAllComix) error {
+	const fn = "storage.postgres.AttachReadingProgress"
+
+	ids := make([]int64, 0, len(comixList))
+	for _, comix := range comixList {
+		if comix.ID != 0 {
+			ids = append(ids, int64(comix.ID))
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	query := fmt.Sprintf(`SELECT c.id, %s FROM reading_progress p
+		JOIN all_comix c ON c.id = p.comix_id
+		LEFT JOIN comix_pages pg ON pg.id = p.page_id
+		WHERE p.reader_id = $1 AND p.comix_id = ANY($2)`, progressColumns)
+
+	rows, err := s.db.Query(query, readerID, pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+	defer rows.Close()
+
+	progressByID := make(map[int]*ReadingProgress, len(ids))
+
+	for rows.Next() {
+		var id int
+		progress := &ReadingProgress{}
+		err := rows.Scan(append([]interface{}{&id}, progressFields(progress)...)...)
+		if err != nil {
+			return fmt.Errorf("%s: %w", fn, err)
+		}
+		progress.markRead()
+		progressByID[id] = progress
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	for i := range comixList {
+		comixList[i].Progress = progressByID[comixList[i].ID]
+	}
+
+	return nil
+}
@@ -0,0 +1,549 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"jadesheart/comix_back/internal/storage"
+	"time"
+)
+
+// ReadingList - список комиксов читателя, например "Избранное" или "Прочитать".
+// Публичный список открывается по slug любым посетителем, личный - только владельцем.
+type ReadingList struct {
+	ID     int
+	Slug   string
+	Name   string
+	Public bool
+	Owner  string
+	// ReaderID - id владельца, наружу не отдаётся
+	ReaderID int `json:"-"`
+	// Comix - сколько комиксов в списке, без лежащих в корзине
+	Comix     int
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// ReadingListUpdate - изменяемые поля списка. nil - поле не меняется.
+type ReadingListUpdate struct {
+	Name   *string
+	Public *bool
+}
+
+// readingListColumns - столбцы ReadingList; l - reading_lists, r - readers
+const readingListColumns = `l.id, COALESCE(l.slug, ''), l.name, l.public, r.username, l.reader_id,
+	(SELECT COUNT(*) FROM reading_list_items i JOIN all_comix c ON c.id = i.comix_id
+		WHERE i.list_id = l.id AND ` + visibleComix + `),
+	l.created_at, l.updated_at`
+
+func readingListFields(list *ReadingList) []interface{} {
+	return []interface{}{&list.ID, &list.Slug, &list.Name, &list.Public, &list.Owner, &list.ReaderID,
+		&list.Comix, &list.CreatedAt, &list.UpdatedAt}
+}
+
+/*
+*
+  - Блокирует список читателя до конца транзакции
+    @param
+  - tx - транзакция
+  - readerID - id владельца
+  - listSlug - текущий slug списка
+    @return
+  - err - ошибка, storage.ErrListNotFound если списка нет или он чужой
+  - int - id списка
+    *
+*/
+func lockReadingList(tx *sql.Tx, readerID int, listSlug string) (int, error) {
+	var id int
+
+	err := tx.QueryRow(`SELECT id FROM reading_lists WHERE slug = $1 AND reader_id = $2 FOR UPDATE`,
+		listSlug, readerID).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, storage.ErrListNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// touchReadingList отмечает изменение списка, списки читателя сортируются по нему
+func touchReadingList(q querier, listID int) error {
+	_, err := q.Exec(`UPDATE reading_lists SET updated_at = now() WHERE id = $1`, listID)
+
+	return err
+}
+
+// queryReadingList - список по id
+func queryReadingList(q querier, listID int) (ReadingList, error) {
+	var list ReadingList
+
+	query := fmt.Sprintf(`SELECT %s FROM reading_lists l JOIN readers r ON r.id = l.reader_id WHERE l.id = $1`, readingListColumns)
+
+	err := q.QueryRow(query, listID).Scan(readingListFields(&list)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ReadingList{}, storage.ErrListNotFound
+	}
+
+	return list, err
+}
+
+/*
+*
+  - Создаёт список читателя и выдаёт ему slug
+    @param
+  - readerID - id владельца
+  - name - название списка
+  - public - список виден всем по slug
+    @return
+  - err - ошибка
+  - ReadingList - созданный список
+    *
+*/
+func (s *Storage) CreateReadingList(readerID int, name string, public bool) (ReadingList, error) {
+	const fn = "storage.postgres.CreateReadingList"
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return ReadingList{}, fmt.Errorf("%s: %w", fn, err)
+	}
+	defer tx.Rollback()
+
+	var id int
+
+	err = tx.QueryRow(`INSERT INTO reading_lists (reader_id, name, public) VALUES ($1, $2, $3) RETURNING id`,
+		readerID, name, public).Scan(&id)
+	if err != nil {
+		return ReadingList{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	_, err = assignSlug(tx, slugKindList, id, name)
+	if err != nil {
+		return ReadingList{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	list, err := queryReadingList(tx, id)
+	if err != nil {
+		return ReadingList{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return ReadingList{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return list, nil
+}
+
+/*
+*
+  - Возвращает все списки читателя, последние изменённые первыми
+    @param
+  - readerID - id владельца
+    @return
+  - err - ошибка
+  - []ReadingList - списки
+    *
+*/
+func (s *Storage) GetReadingLists(readerID int) ([]ReadingList, error) {
+	const fn = "storage.postgres.GetReadingLists"
+
+	query := fmt.Sprintf(`SELECT %s FROM reading_lists l JOIN readers r ON r.id = l.reader_id
+		WHERE l.reader_id = $1 ORDER BY l.updated_at DESC, l.id DESC`, readingListColumns)
+
+	rows, err := s.db.Query(query, readerID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+	defer rows.Close()
+
+	lists := []ReadingList{}
+
+	for rows.Next() {
+		var list ReadingList
+		err := rows.Scan(readingListFields(&list)...)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fn, err)
+		}
+		lists = append(lists, list)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return lists, nil
+}
+
+/*
+*
+  - Находит список по текущему или старому slug. Видимость списка проверяет вызывающий
+    @param
+  - listSlug - slug списка
+    @return
+  - err - ошибка, storage.ErrListNotFound если списка нет
+  - ReadingList - список; если Slug отличается от запрошенного, запрошен старый slug
+    *
+*/
+func (s *Storage) GetReadingListBySlug(listSlug string) (ReadingList, error) {
+	const fn = "storage.postgres.GetReadingListBySlug"
+
+	var list ReadingList
+
+	query := fmt.Sprintf(`SELECT %s FROM reading_lists l JOIN readers r ON r.id = l.reader_id
+		WHERE l.slug = $1 OR l.id = (SELECT target_id FROM slug_history WHERE kind = $2 AND slug = $1)
+		ORDER BY l.slug = $1 DESC LIMIT 1`, readingListColumns)
+
+	err := s.db.QueryRow(query, listSlug, slugKindList).Scan(readingListFields(&list)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ReadingList{}, fmt.Errorf("%s: %w", fn, storage.ErrListNotFound)
+	}
+	if err != nil {
+		return ReadingList{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return list, nil
+}
+
+/*
+*
+  - Изменяет список читателя. При смене названия выдаёт новый slug, старый остаётся в истории
+    @param
+  - readerID - id владельца
+  - listSlug - текущий slug списка
+  - update - новые значения, nil-поля не меняются
+    @return
+  - err - ошибка, storage.ErrListNotFound если списка нет или он чужой
+  - ReadingList - список после изменения
+    *
+*/
+func (s *Storage) EditReadingList(readerID int, listSlug string, update ReadingListUpdate) (ReadingList, error) {
+	const fn = "storage.postgres.EditReadingList"
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return ReadingList{}, fmt.Errorf("%s: %w", fn, err)
+	}
+	defer tx.Rollback()
+
+	id, err := lockReadingList(tx, readerID, listSlug)
+	if err != nil {
+		return ReadingList{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	list, err := queryReadingList(tx, id)
+	if err != nil {
+		return ReadingList{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	renamed := update.Name != nil && *update.Name != list.Name
+
+	if update.Name != nil {
+		list.Name = *update.Name
+	}
+	if update.Public != nil {
+		list.Public = *update.Public
+	}
+
+	_, err = tx.Exec(`UPDATE reading_lists SET name = $1, public = $2, updated_at = now() WHERE id = $3`,
+		list.Name, list.Public, id)
+	if err != nil {
+		return ReadingList{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	if renamed {
+		_, err = tx.Exec(`INSERT INTO slug_history (kind, slug, target_id) VALUES ($1, $2, $3)
+			ON CONFLICT (kind, slug) DO NOTHING`, slugKindList, list.Slug, id)
+		if err != nil {
+			return ReadingList{}, fmt.Errorf("%s: %w", fn, err)
+		}
+
+		_, err = assignSlug(tx, slugKindList, id, list.Name)
+		if err != nil {
+			return ReadingList{}, fmt.Errorf("%s: %w", fn, err)
+		}
+	}
+
+	list, err = queryReadingList(tx, id)
+	if err != nil {
+		return ReadingList{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return ReadingList{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return list, nil
+}
+
+/*
+*
+  - Удаляет список читателя вместе с его историей slug
+    @param
+  - readerID - id владельца
+  - listSlug - текущий slug списка
+    @return
+  - err - ошибка, storage.ErrListNotFound если списка нет или он чужой
+    *
+*/
+func (s *Storage) DeleteReadingList(readerID int, listSlug string) error {
+	const fn = "storage.postgres.DeleteReadingList"
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+	defer tx.Rollback()
+
+	var id int
+
+	err = tx.QueryRow(`DELETE FROM reading_lists WHERE slug = $1 AND reader_id = $2 RETURNING id`, listSlug, readerID).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%s: %w", fn, storage.ErrListNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	_, err = tx.Exec(`DELETE FROM slug_history WHERE kind = $1 AND target_id = $2`, slugKindList, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return nil
+}
+
+/*
+*
+  - Добавляет комикс в конец списка читателя. Комикс, который уже есть в списке, не двигается
+    @param
+  - readerID - id владельца
+  - listSlug - текущий slug списка
+  - comixID - id комикса
+    @return
+  - err - ошибка, storage.ErrListNotFound если списка нет или он чужой,
+    storage.ErrComixNotFound если комикса нет или он в корзине
+    *
+*/
+func (s *Storage) AddToReadingList(readerID int, listSlug string, comixID int) error {
+	const fn = "storage.postgres.AddToReadingList"
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+	defer tx.Rollback()
+
+	listID, err := lockReadingList(tx, readerID, listSlug)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	var exists bool
+
+	err = tx.QueryRow(`SELECT EXISTS(SELECT 1 FROM all_comix c WHERE c.id = $1 AND `+visibleComix+`)`, comixID).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+	if !exists {
+		return fmt.Errorf("%s: %w", fn, storage.ErrComixNotFound)
+	}
+
+	_, err = tx.Exec(`INSERT INTO reading_list_items (list_id, comix_id, position)
+		SELECT $1, $2, COALESCE(MAX(position), 0) + 1 FROM reading_list_items WHERE list_id = $1
+		ON CONFLICT (list_id, comix_id) DO NOTHING`, listID, comixID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	err = touchReadingList(tx, listID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return nil
+}
+
+/*
+*
+  - Убирает комикс из списка читателя
+    @param
+  - readerID - id владельца
+  - listSlug - текущий slug списка
+  - comixID - id комикса
+    @return
+  - err - ошибка, storage.ErrListNotFound если списка нет или он чужой
+    *
+*/
+func (s *Storage) RemoveFromReadingList(readerID int, listSlug string, comixID int) error {
+	const fn = "storage.postgres.RemoveFromReadingList"
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+	defer tx.Rollback()
+
+	listID, err := lockReadingList(tx, readerID, listSlug)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	_, err = tx.Exec(`DELETE FROM reading_list_items WHERE list_id = $1 AND comix_id = $2`, listID, comixID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	err = touchReadingList(tx, listID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return nil
+}
+
+/*
+*
+  - Задаёт новый порядок комиксов в списке читателя.
+  - Комиксы из корзины читатель не видит, они остаются в списке после остальных
+    @param
+  - readerID - id владельца
+  - listSlug - текущий slug списка
+  - comixIDs - id всех видимых комиксов списка в новом порядке
+    @return
+  - err - ошибка, storage.ErrListNotFound если списка нет или он чужой,
+    storage.ErrListMismatch если comixIDs не совпадает с комиксами списка
+    *
+*/
+func (s *Storage) ReorderReadingList(readerID int, listSlug string, comixIDs []int) error {
+	const fn = "storage.postgres.ReorderReadingList"
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+	defer tx.Rollback()
+
+	listID, err := lockReadingList(tx, readerID, listSlug)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	rows, err := tx.Query(`SELECT i.comix_id FROM reading_list_items i JOIN all_comix c ON c.id = i.comix_id
+		WHERE i.list_id = $1 AND `+visibleComix, listID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	var visible []int
+
+	for rows.Next() {
+		var id int
+		err := rows.Scan(&id)
+		if err != nil {
+			rows.Close()
+			return fmt.Errorf("%s: %w", fn, err)
+		}
+		visible = append(visible, id)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	if !sameIDs(visible, comixIDs) {
+		return fmt.Errorf("%s: %w", fn, storage.ErrListMismatch)
+	}
+
+	ids := make([]int64, len(comixIDs))
+	for i, id := range comixIDs {
+		ids[i] = int64(id)
+	}
+
+	// Комиксы из корзины уезжают за видимые, сохраняя свой порядок
+	_, err = tx.Exec(`UPDATE reading_list_items SET position = position + $2
+		WHERE list_id = $1 AND NOT comix_id = ANY($3)`, listID, len(ids), pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	_, err = tx.Exec(`UPDATE reading_list_items i SET position = o.position
+		FROM unnest($2::int[]) WITH ORDINALITY AS o(id, position)
+		WHERE i.list_id = $1 AND i.comix_id = o.id`, listID, pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	err = touchReadingList(tx, listID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return nil
+}
+
+/*
+*
+  - Возвращает 16 комиксов списка по порядку, без лежащих в корзине
+    @param
+  - listID - id списка
+  - pageToDisplay - номер страницы для отображения
+    @return
+  - err - ошибка
+  - []ComixFromAllComix - 16 комиксов, каждый в виде структуры ComixFromAllComix
+    *
+*/
+func (s *Storage) GetReadingListComix(listID int, pageToDisplay int) ([]ComixFromAllComix, error) {
+	const fn = "storage.postgres.GetReadingListComix"
+
+	offset := (pageToDisplay - 1) * readingPerPage
+
+	query := fmt.Sprintf(`SELECT %s FROM reading_list_items i JOIN all_comix c ON c.id = i.comix_id
+		WHERE i.list_id = $1 AND `+visibleComix+`
+		ORDER BY i.position, i.added_at LIMIT %d OFFSET %d`, comixColumns("c"), readingPerPage, offset)
+
+	rows, err := s.db.Query(query, listID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+	defer rows.Close()
+
+	comixList := []ComixFromAllComix{}
+
+	for rows.Next() {
+		var comix ComixFromAllComix
+		err := rows.Scan(comixFields(&comix)...)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fn, err)
+		}
+		comixList = append(comixList, comix)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return comixList, nil
+}
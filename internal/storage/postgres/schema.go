@@ -0,0 +1,237 @@
+package postgres
+
+import "fmt"
+
+// schema - служебные таблицы, которые создаются при старте приложения.
+// Таблицы тэгов создаются отдельно в CreateNewTag.
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS all_comix (
+		id SERIAL PRIMARY KEY,
+		comix_name TEXT NOT NULL,
+		comix_tag TEXT NOT NULL,
+		description TEXT NOT NULL,
+		comix_date DATE,
+		views INTEGER NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS all_tags (tag TEXT NOT NULL)`,
+	`CREATE TABLE IF NOT EXISTS tags_description (tag TEXT NOT NULL, description TEXT NOT NULL)`,
+	`CREATE TABLE IF NOT EXISTS comix_views_daily (
+		comix_id INTEGER NOT NULL,
+		day DATE NOT NULL,
+		views INTEGER NOT NULL,
+		PRIMARY KEY (comix_id, day)
+	)`,
+	`CREATE TABLE IF NOT EXISTS audit_events (
+		id BIGSERIAL PRIMARY KEY,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		actor TEXT NOT NULL,
+		action TEXT NOT NULL,
+		target TEXT NOT NULL,
+		before_value JSONB,
+		after_value JSONB,
+		request_id TEXT NOT NULL,
+		remote_addr TEXT NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS audit_events_target_idx ON audit_events (target)`,
+	`ALTER TABLE all_comix ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ`,
+	`ALTER TABLE all_comix ADD COLUMN IF NOT EXISTS slug TEXT`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS all_comix_slug_idx ON all_comix (slug)`,
+	`ALTER TABLE all_tags ADD COLUMN IF NOT EXISTS id SERIAL`,
+	`ALTER TABLE all_tags ADD COLUMN IF NOT EXISTS slug TEXT`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS all_tags_slug_idx ON all_tags (slug)`,
+	`ALTER TABLE all_tags ADD COLUMN IF NOT EXISTS parent_id INTEGER`,
+	`ALTER TABLE all_tags ADD COLUMN IF NOT EXISTS cover TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE all_tags ADD COLUMN IF NOT EXISTS color TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE all_tags ADD COLUMN IF NOT EXISTS sort_order INTEGER NOT NULL DEFAULT 0`,
+	`ALTER TABLE all_comix ADD COLUMN IF NOT EXISTS cover TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE all_comix ADD COLUMN IF NOT EXISTS authors TEXT[] NOT NULL DEFAULT '{}'`,
+	`ALTER TABLE all_comix ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'ongoing'`,
+	`ALTER TABLE all_comix ADD COLUMN IF NOT EXISTS language TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE all_comix ADD COLUMN IF NOT EXISTS age_rating TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE all_comix ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT now()`,
+	`CREATE TABLE IF NOT EXISTS slug_history (
+		kind TEXT NOT NULL,
+		slug TEXT NOT NULL,
+		target_id INTEGER NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		PRIMARY KEY (kind, slug)
+	)`,
+	`CREATE TABLE IF NOT EXISTS authors (
+		id SERIAL PRIMARY KEY,
+		slug TEXT UNIQUE,
+		name TEXT NOT NULL,
+		bio TEXT NOT NULL DEFAULT '',
+		avatar TEXT NOT NULL DEFAULT '',
+		links TEXT[] NOT NULL DEFAULT '{}',
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+	`CREATE TABLE IF NOT EXISTS comix_authors (
+		comix_id INTEGER NOT NULL,
+		author_id INTEGER NOT NULL REFERENCES authors (id) ON DELETE CASCADE,
+		role TEXT NOT NULL,
+		PRIMARY KEY (comix_id, author_id, role)
+	)`,
+	`CREATE INDEX IF NOT EXISTS comix_authors_author_idx ON comix_authors (author_id)`,
+	`CREATE TABLE IF NOT EXISTS comix_pages (
+		id SERIAL PRIMARY KEY,
+		comix_id INTEGER NOT NULL,
+		position INTEGER NOT NULL,
+		file TEXT NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		UNIQUE (comix_id, position) DEFERRABLE INITIALLY DEFERRED
+	)`,
+	`CREATE TABLE IF NOT EXISTS readers (
+		id SERIAL PRIMARY KEY,
+		username TEXT NOT NULL,
+		password_hash TEXT NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS readers_username_idx ON readers (lower(username))`,
+	`CREATE TABLE IF NOT EXISTS reader_sessions (
+		token_hash TEXT PRIMARY KEY,
+		reader_id INTEGER NOT NULL REFERENCES readers (id) ON DELETE CASCADE,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		expires_at TIMESTAMPTZ NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS reader_sessions_reader_idx ON reader_sessions (reader_id)`,
+	`CREATE TABLE IF NOT EXISTS bookmarks (
+		reader_id INTEGER NOT NULL REFERENCES readers (id) ON DELETE CASCADE,
+		comix_id INTEGER NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		PRIMARY KEY (reader_id, comix_id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS reading_progress (
+		reader_id INTEGER NOT NULL REFERENCES readers (id) ON DELETE CASCADE,
+		comix_id INTEGER NOT NULL,
+		page_id INTEGER NOT NULL,
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		PRIMARY KEY (reader_id, comix_id)
+	)`,
+	`CREATE INDEX IF NOT EXISTS reading_progress_recent_idx ON reading_progress (reader_id, updated_at DESC)`,
+	`CREATE TABLE IF NOT EXISTS reading_lists (
+		id SERIAL PRIMARY KEY,
+		reader_id INTEGER NOT NULL REFERENCES readers (id) ON DELETE CASCADE,
+		slug TEXT UNIQUE,
+		name TEXT NOT NULL,
+		public BOOLEAN NOT NULL DEFAULT false,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+	`CREATE INDEX IF NOT EXISTS reading_lists_reader_idx ON reading_lists (reader_id)`,
+	`CREATE TABLE IF NOT EXISTS reading_list_items (
+		list_id INTEGER NOT NULL REFERENCES reading_lists (id) ON DELETE CASCADE,
+		comix_id INTEGER NOT NULL,
+		position INTEGER NOT NULL,
+		added_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		PRIMARY KEY (list_id, comix_id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS comments (
+		id BIGSERIAL PRIMARY KEY,
+		comix_id INTEGER NOT NULL,
+		root_id BIGINT REFERENCES comments (id) ON DELETE CASCADE,
+		parent_id BIGINT REFERENCES comments (id) ON DELETE CASCADE,
+		reader_id INTEGER NOT NULL REFERENCES readers (id) ON DELETE CASCADE,
+		body TEXT NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		edited_at TIMESTAMPTZ,
+		deleted_at TIMESTAMPTZ,
+		removed BOOLEAN NOT NULL DEFAULT false
+	)`,
+	`CREATE INDEX IF NOT EXISTS comments_comix_idx ON comments (comix_id, created_at DESC) WHERE root_id IS NULL`,
+	`CREATE INDEX IF NOT EXISTS comments_root_idx ON comments (root_id, created_at)`,
+	`CREATE TABLE IF NOT EXISTS ratings (
+		reader_id INTEGER NOT NULL REFERENCES readers (id) ON DELETE CASCADE,
+		comix_id INTEGER NOT NULL,
+		score SMALLINT NOT NULL CHECK (score BETWEEN 1 AND 5),
+		review TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		PRIMARY KEY (reader_id, comix_id)
+	)`,
+	`CREATE INDEX IF NOT EXISTS ratings_reviews_idx ON ratings (comix_id, updated_at DESC) WHERE review <> ''`,
+	// Сводка оценок пересчитывается из ratings в той же транзакции, что и сама оценка
+	`ALTER TABLE all_comix ADD COLUMN IF NOT EXISTS rating_votes INTEGER NOT NULL DEFAULT 0`,
+	`ALTER TABLE all_comix ADD COLUMN IF NOT EXISTS rating_sum INTEGER NOT NULL DEFAULT 0`,
+	`ALTER TABLE all_comix ADD COLUMN IF NOT EXISTS rating_histogram INTEGER[] NOT NULL DEFAULT '{0,0,0,0,0}'`,
+	// Уже загруженные комиксы одобрены, новые попадают на проверку только при включённой модерации
+	`ALTER TABLE all_comix ADD COLUMN IF NOT EXISTS review_status TEXT NOT NULL DEFAULT 'approved'`,
+	`CREATE INDEX IF NOT EXISTS all_comix_pending_idx ON all_comix (id) WHERE review_status = 'pending'`,
+	`CREATE TABLE IF NOT EXISTS pending_pages (
+		id SERIAL PRIMARY KEY,
+		comix_id INTEGER NOT NULL,
+		position INTEGER NOT NULL DEFAULT 0,
+		file TEXT NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+	`CREATE INDEX IF NOT EXISTS pending_pages_comix_idx ON pending_pages (comix_id)`,
+	`CREATE TABLE IF NOT EXISTS reports (
+		id SERIAL PRIMARY KEY,
+		reader_id INTEGER NOT NULL REFERENCES readers (id) ON DELETE CASCADE,
+		kind TEXT NOT NULL,
+		target_id BIGINT NOT NULL,
+		reason TEXT NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		resolved_at TIMESTAMPTZ,
+		resolution TEXT NOT NULL DEFAULT ''
+	)`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS reports_open_idx ON reports (reader_id, kind, target_id) WHERE resolved_at IS NULL`,
+	// publish_at - когда комикс или страница выйдет; NULL - уже вышли. Планировщик сбрасывает наступившие значения
+	`ALTER TABLE all_comix ADD COLUMN IF NOT EXISTS publish_at TIMESTAMPTZ`,
+	`CREATE INDEX IF NOT EXISTS all_comix_publish_idx ON all_comix (publish_at) WHERE publish_at IS NOT NULL`,
+	`ALTER TABLE comix_pages ADD COLUMN IF NOT EXISTS publish_at TIMESTAMPTZ`,
+	`CREATE INDEX IF NOT EXISTS comix_pages_publish_idx ON comix_pages (publish_at) WHERE publish_at IS NOT NULL`,
+	`ALTER TABLE pending_pages ADD COLUMN IF NOT EXISTS publish_at TIMESTAMPTZ`,
+	// events - на какие события подписан вебхук, пустой массив - на все
+	`CREATE TABLE IF NOT EXISTS webhooks (
+		id SERIAL PRIMARY KEY,
+		url TEXT NOT NULL,
+		secret TEXT NOT NULL,
+		events TEXT[] NOT NULL DEFAULT '{}',
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+	// webhook_outbox - доставки, которые пишутся в одной транзакции с журналом и отправляются фоновой задачей.
+	// delivered_at и failed_at пустые, пока доставка ждёт отправки или повтора в next_attempt_at
+	`CREATE TABLE IF NOT EXISTS webhook_outbox (
+		id BIGSERIAL PRIMARY KEY,
+		webhook_id INTEGER NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+		event TEXT NOT NULL,
+		payload JSONB NOT NULL,
+		attempts INTEGER NOT NULL DEFAULT 0,
+		next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		delivered_at TIMESTAMPTZ,
+		failed_at TIMESTAMPTZ,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+	`CREATE INDEX IF NOT EXISTS webhook_outbox_due_idx ON webhook_outbox (next_attempt_at)
+		WHERE delivered_at IS NULL AND failed_at IS NULL`,
+	`CREATE TABLE IF NOT EXISTS webhook_attempts (
+		id BIGSERIAL PRIMARY KEY,
+		delivery_id BIGINT NOT NULL REFERENCES webhook_outbox (id) ON DELETE CASCADE,
+		attempt INTEGER NOT NULL,
+		status_code INTEGER NOT NULL,
+		error TEXT NOT NULL,
+		duration_ms INTEGER NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+	`CREATE INDEX IF NOT EXISTS webhook_attempts_delivery_idx ON webhook_attempts (delivery_id)`,
+}
+
+/*
+*
+  - Создаёт служебные таблицы, если их ещё нет
+    @return
+  - err - ошибка
+    *
+*/
+func (s *Storage) createSchema() error {
+	const fn = "storage.postgres.createSchema"
+
+	for _, query := range schema {
+		_, err := s.db.Exec(query)
+		if err != nil {
+			return fmt.Errorf("%s: %w", fn, err)
+		}
+	}
+
+	return nil
+}
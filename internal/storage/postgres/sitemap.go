@@ -0,0 +1,66 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Что лежит в карте сайта: страница тэга или страница комикса
+const (
+	SitemapTag   = "tag"
+	SitemapComix = "comix"
+)
+
+// SitemapEntry - страница для карты сайта. UpdatedAt - последнее изменение, нулевое если неизвестно.
+type SitemapEntry struct {
+	Kind      string
+	Slug      string
+	UpdatedAt time.Time
+}
+
+/*
+*
+  - Возвращает страницы для карты сайта: сначала все тэги, затем видимые читателям комиксы.
+  - Тэг считается изменённым, когда изменился самый свежий его комикс
+    @return
+  - err - ошибка
+  - []SitemapEntry - страницы; записи без slug не попадают, на них нельзя сослаться
+    *
+*/
+func (s *Storage) GetSitemapEntries() ([]SitemapEntry, error) {
+	const fn = "storage.postgres.GetSitemapEntries"
+
+	rows, err := s.db.Query(`SELECT kind, slug, updated_at FROM (
+			SELECT 0 AS ord, t.id, $1::text AS kind, t.slug,
+				(SELECT max(c.updated_at) FROM all_comix c WHERE c.comix_tag = lower(t.tag) AND `+visibleComix+`) AS updated_at
+			FROM all_tags t WHERE t.slug IS NOT NULL
+			UNION ALL
+			SELECT 1, c.id, $2::text, c.slug, c.updated_at FROM all_comix c WHERE c.slug IS NOT NULL AND `+visibleComix+`
+		) entries ORDER BY ord, id`, SitemapTag, SitemapComix)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+	defer rows.Close()
+
+	entries := []SitemapEntry{}
+
+	for rows.Next() {
+		var entry SitemapEntry
+		var updatedAt sql.NullTime
+
+		err := rows.Scan(&entry.Kind, &entry.Slug, &updatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fn, err)
+		}
+
+		entry.UpdatedAt = updatedAt.Time
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,310 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"jadesheart/comix_back/internal/lib/slug"
+	"jadesheart/comix_back/internal/storage"
+)
+
+// Виды сущностей, у которых есть slug. Старые slug всех видов лежат в slug_history.
+const (
+	slugKindComix  = "comix"
+	slugKindTag    = "tag"
+	slugKindAuthor = "author"
+	slugKindList   = "list"
+)
+
+// slugTables - таблица, в которой хранится текущий slug сущности.
+var slugTables = map[string]string{
+	slugKindComix:  "all_comix",
+	slugKindTag:    "all_tags",
+	slugKindAuthor: "authors",
+	slugKindList:   "reading_lists",
+}
+
+type Tag struct {
+	ID          int
+	Slug        string
+	Name        string
+	Description string
+	// ParentID - id родительского тэга, 0 у тэгов верхнего уровня
+	ParentID  int
+	Cover     string
+	Color     string
+	SortOrder int
+}
+
+// querier - общее у *sql.DB и *sql.Tx, чтобы slug можно было выдавать внутри транзакции.
+type querier interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+/*
+*
+  - Выдаёт сущности уникальный slug по её названию. Если slug занят, добавляет номер: name-2, name-3...
+  - Slug, который раньше принадлежал этой же сущности, забирается из истории обратно
+    @param
+  - q - база или транзакция
+  - kind - вид сущности (slugKindComix, slugKindTag)
+  - id - id сущности
+  - name - название, из которого делается slug
+    @return
+  - err - ошибка
+  - string - выданный slug
+    *
+*/
+func assignSlug(q querier, kind string, id int, name string) (string, error) {
+	const fn = "storage.postgres.assignSlug"
+
+	table := slugTables[kind]
+	base := slug.Make(name)
+	candidate := base
+
+	for i := 2; ; i++ {
+		var taken bool
+
+		query := fmt.Sprintf(`SELECT EXISTS(SELECT 1 FROM %s WHERE slug = $1 AND id <> $2)
+			OR EXISTS(SELECT 1 FROM slug_history WHERE kind = $3 AND slug = $1 AND target_id <> $2)`, table)
+
+		err := q.QueryRow(query, candidate, id, kind).Scan(&taken)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", fn, err)
+		}
+
+		if !taken {
+			break
+		}
+
+		candidate = fmt.Sprintf("%s-%d", base, i)
+	}
+
+	_, err := q.Exec(`DELETE FROM slug_history WHERE kind = $1 AND slug = $2`, kind, candidate)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", fn, err)
+	}
+
+	_, err = q.Exec(fmt.Sprintf("UPDATE %s SET slug = $1 WHERE id = $2", table), candidate, id)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return candidate, nil
+}
+
+/*
+*
+  - Выдаёт slug комиксам и тэгам, созданным до появления slug
+    @return
+  - err - ошибка
+    *
+*/
+func (s *Storage) backfillSlugs() error {
+	const fn = "storage.postgres.backfillSlugs"
+
+	sources := []struct {
+		kind  string
+		query string
+	}{
+		{slugKindComix, "SELECT id, comix_name FROM all_comix WHERE slug IS NULL ORDER BY id"},
+		{slugKindTag, "SELECT id, tag FROM all_tags WHERE slug IS NULL ORDER BY id"},
+	}
+
+	for _, source := range sources {
+		type entity struct {
+			id   int
+			name string
+		}
+
+		var entities []entity
+
+		rows, err := s.db.Query(source.query)
+		if err != nil {
+			return fmt.Errorf("%s: %w", fn, err)
+		}
+
+		for rows.Next() {
+			var e entity
+			err := rows.Scan(&e.id, &e.name)
+			if err != nil {
+				rows.Close()
+				return fmt.Errorf("%s: %w", fn, err)
+			}
+			entities = append(entities, e)
+		}
+		rows.Close()
+
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("%s: %w", fn, err)
+		}
+
+		for _, e := range entities {
+			_, err := assignSlug(s.db, source.kind, e.id, e.name)
+			if err != nil {
+				return fmt.Errorf("%s: %w", fn, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+/*
+*
+  - Переименовывает комикс: название в all_comix и таблице тэга и slug.
+  - Старый slug остаётся в истории и продолжает вести на комикс
+    @param
+  - tagName - название тэга
+  - name - текущее название комикса
+  - newName - новое название комикса
+    @return
+  - err - ошибка, storage.ErrComixNotFound если комикса нет
+  - string - новый slug
+    *
+*/
+func (s *Storage) RenameComix(tagName string, name string, newName string) (string, error) {
+	const fn = "storage.postgres.RenameComix"
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", fn, err)
+	}
+	defer tx.Rollback()
+
+	var id int
+	var oldSlug sql.NullString
+
+	err = tx.QueryRow(`SELECT id, slug FROM all_comix
+		WHERE comix_tag = lower($1) AND comix_name = $2 AND deleted_at IS NULL
+		FOR UPDATE`, tagName, name).Scan(&id, &oldSlug)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("%s: %w", fn, storage.ErrComixNotFound)
+	}
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", fn, err)
+	}
+
+	_, err = tx.Exec(`UPDATE all_comix SET comix_name = $1, updated_at = now() WHERE id = $2`, newName, id)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", fn, err)
+	}
+
+	_, err = tx.Exec(fmt.Sprintf("UPDATE %s SET name = $1 WHERE name = $2", tagName), newName, name)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", fn, err)
+	}
+
+	if oldSlug.Valid {
+		_, err = tx.Exec(`INSERT INTO slug_history (kind, slug, target_id) VALUES ($1, $2, $3)
+			ON CONFLICT (kind, slug) DO NOTHING`, slugKindComix, oldSlug.String, id)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", fn, err)
+		}
+	}
+
+	newSlug, err := assignSlug(tx, slugKindComix, id, newName)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", fn, err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return newSlug, nil
+}
+
+/*
+*
+  - Находит комикс по текущему или старому slug
+    @param
+  - comixSlug - slug комикса
+    @return
+  - err - ошибка, storage.ErrComixNotFound если комикса нет
+  - ComixFromAllComix - комикс; если Slug отличается от запрошенного, запрошен старый slug
+    *
+*/
+func (s *Storage) GetComixBySlug(comixSlug string) (ComixFromAllComix, error) {
+	const fn = "storage.postgres.GetComixBySlug"
+
+	var comix ComixFromAllComix
+
+	query := fmt.Sprintf(`SELECT %s FROM all_comix c
+		WHERE `+visibleComix+` AND (c.slug = $1 OR c.id = (SELECT target_id FROM slug_history WHERE kind = $2 AND slug = $1))
+		ORDER BY c.slug = $1 DESC LIMIT 1`, allComixColumns)
+
+	err := s.db.QueryRow(query, comixSlug, slugKindComix).Scan(comixFields(&comix)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ComixFromAllComix{}, fmt.Errorf("%s: %w", fn, storage.ErrComixNotFound)
+	}
+	if err != nil {
+		return ComixFromAllComix{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return comix, nil
+}
+
+/*
+*
+  - Находит видимый комикс по id
+    @param
+  - id - id комикса в all_comix
+    @return
+  - err - ошибка, storage.ErrComixNotFound если комикса нет
+  - ComixFromAllComix - комикс
+    *
+*/
+func (s *Storage) GetComixByID(id int) (ComixFromAllComix, error) {
+	const fn = "storage.postgres.GetComixByID"
+
+	var comix ComixFromAllComix
+
+	query := fmt.Sprintf(`SELECT %s FROM all_comix c WHERE `+visibleComix+` AND c.id = $1`, allComixColumns)
+
+	err := s.db.QueryRow(query, id).Scan(comixFields(&comix)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ComixFromAllComix{}, fmt.Errorf("%s: %w", fn, storage.ErrComixNotFound)
+	}
+	if err != nil {
+		return ComixFromAllComix{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return comix, nil
+}
+
+/*
+*
+  - Находит тэг по текущему или старому slug
+    @param
+  - tagSlug - slug тэга
+    @return
+  - err - ошибка, storage.ErrTagNotFound если тэга нет
+  - Tag - тэг вместе с описанием; если Slug отличается от запрошенного, запрошен старый slug
+    *
+*/
+func (s *Storage) GetTagBySlug(tagSlug string) (Tag, error) {
+	const fn = "storage.postgres.GetTagBySlug"
+
+	var tag Tag
+
+	query := `SELECT t.id, COALESCE(t.slug, ''), t.tag, COALESCE(d.description, ''),
+			COALESCE(t.parent_id, 0), t.cover, t.color, t.sort_order
+		FROM all_tags t LEFT JOIN tags_description d ON lower(d.tag) = lower(t.tag)
+		WHERE t.slug = $1 OR t.id = (SELECT target_id FROM slug_history WHERE kind = $2 AND slug = $1)
+		ORDER BY t.slug = $1 DESC LIMIT 1`
+
+	err := s.db.QueryRow(query, tagSlug, slugKindTag).Scan(&tag.ID, &tag.Slug, &tag.Name, &tag.Description,
+		&tag.ParentID, &tag.Cover, &tag.Color, &tag.SortOrder)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Tag{}, fmt.Errorf("%s: %w", fn, storage.ErrTagNotFound)
+	}
+	if err != nil {
+		return Tag{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return tag, nil
+}
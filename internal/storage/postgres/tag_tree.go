@@ -0,0 +1,235 @@
+package postgres
+
+import (
+	"fmt"
+	"jadesheart/comix_back/internal/storage"
+	"sort"
+)
+
+type TagNode struct {
+	Tag
+	// ComixCount - комиксы самого тэга, TotalComixCount - вместе со всеми подтэгами
+	ComixCount      int
+	TotalComixCount int
+	Children        []*TagNode
+}
+
+// TagMeta - изменяемые свойства тэга. nil - свойство не меняется.
+type TagMeta struct {
+	// Parent - название родительского тэга, пустая строка - тэг верхнего уровня
+	Parent    *string
+	Color     *string
+	SortOrder *int
+}
+
+/*
+*
+  - Возвращает дерево тэгов с количеством комиксов в каждом узле.
+  - Узлы одного уровня отсортированы по sort_order, затем по названию
+    @return
+  - err - ошибка
+  - []*TagNode - тэги верхнего уровня
+    *
+*/
+func (s *Storage) GetTagTree() ([]*TagNode, error) {
+	const fn = "storage.postgres.GetTagTree"
+
+	query := `SELECT t.id, COALESCE(t.slug, ''), t.tag, COALESCE(d.description, ''),
+			COALESCE(t.parent_id, 0), t.cover, t.color, t.sort_order, COALESCE(c.quantity, 0)
+		FROM all_tags t
+		LEFT JOIN tags_description d ON lower(d.tag) = lower(t.tag)
+		LEFT JOIN (SELECT c.comix_tag, COUNT(*) AS quantity FROM all_comix c
+			WHERE ` + visibleComix + ` GROUP BY c.comix_tag) c ON c.comix_tag = lower(t.tag)`
+
+	rows, err := s.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+	defer rows.Close()
+
+	var nodes []*TagNode
+
+	for rows.Next() {
+		node := &TagNode{}
+		err := rows.Scan(&node.ID, &node.Slug, &node.Name, &node.Description,
+			&node.ParentID, &node.Cover, &node.Color, &node.SortOrder, &node.ComixCount)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fn, err)
+		}
+		nodes = append(nodes, node)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return buildTagTree(nodes), nil
+}
+
+// buildTagTree раскладывает тэги по родителям. Тэг, чей родитель не найден, становится корнем.
+func buildTagTree(nodes []*TagNode) []*TagNode {
+	byID := make(map[int]*TagNode, len(nodes))
+	for _, node := range nodes {
+		byID[node.ID] = node
+	}
+
+	var roots []*TagNode
+
+	for _, node := range nodes {
+		parent, ok := byID[node.ParentID]
+		if !ok || node.ParentID == node.ID {
+			roots = append(roots, node)
+
+			continue
+		}
+		parent.Children = append(parent.Children, node)
+	}
+
+	var fill func(list []*TagNode) int
+	fill = func(list []*TagNode) int {
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].SortOrder != list[j].SortOrder {
+				return list[i].SortOrder < list[j].SortOrder
+			}
+			return list[i].Name < list[j].Name
+		})
+
+		total := 0
+		for _, node := range list {
+			node.TotalComixCount = node.ComixCount + fill(node.Children)
+			total += node.TotalComixCount
+		}
+
+		return total
+	}
+
+	fill(roots)
+
+	return roots
+}
+
+/*
+*
+  - Изменяет родителя, цвет и порядок отображения тэга
+    @param
+  - tagName - название тэга
+  - meta - новые значения, nil-поля не меняются
+    @return
+  - err - ошибка, storage.ErrTagNotFound если тэга или родителя нет,
+    storage.ErrTagCycle если родитель - сам тэг или его подтэг
+    *
+*/
+func (s *Storage) EditTagMeta(tagName string, meta TagMeta) error {
+	const fn = "storage.postgres.EditTagMeta"
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+	defer tx.Rollback()
+
+	id, _, err := lockTag(tx, tagName)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	if meta.Parent != nil {
+		var parentID *int
+
+		if *meta.Parent != "" {
+			pid, _, err := lockTag(tx, *meta.Parent)
+			if err != nil {
+				return fmt.Errorf("%s: %w", fn, err)
+			}
+
+			var cycle bool
+
+			err = tx.QueryRow(`WITH RECURSIVE up AS (
+					SELECT id, parent_id FROM all_tags WHERE id = $1
+					UNION
+					SELECT t.id, t.parent_id FROM all_tags t JOIN up ON t.id = up.parent_id
+				)
+				SELECT EXISTS(SELECT 1 FROM up WHERE id = $2)`, pid, id).Scan(&cycle)
+			if err != nil {
+				return fmt.Errorf("%s: %w", fn, err)
+			}
+
+			if cycle {
+				return fmt.Errorf("%s: %w", fn, storage.ErrTagCycle)
+			}
+
+			parentID = &pid
+		}
+
+		_, err = tx.Exec(`UPDATE all_tags SET parent_id = $1 WHERE id = $2`, parentID, id)
+		if err != nil {
+			return fmt.Errorf("%s: %w", fn, err)
+		}
+	}
+
+	if meta.Color != nil {
+		_, err = tx.Exec(`UPDATE all_tags SET color = $1 WHERE id = $2`, *meta.Color, id)
+		if err != nil {
+			return fmt.Errorf("%s: %w", fn, err)
+		}
+	}
+
+	if meta.SortOrder != nil {
+		_, err = tx.Exec(`UPDATE all_tags SET sort_order = $1 WHERE id = $2`, *meta.SortOrder, id)
+		if err != nil {
+			return fmt.Errorf("%s: %w", fn, err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return nil
+}
+
+/*
+*
+  - Запоминает файл обложки тэга
+    @param
+  - tagName - название тэга
+  - cover - имя файла обложки в папке тэга
+    @return
+  - err - ошибка, storage.ErrTagNotFound если тэга нет
+  - string - имя прежнего файла обложки, пустая строка если его не было
+    *
+*/
+func (s *Storage) SetTagCover(tagName string, cover string) (string, error) {
+	const fn = "storage.postgres.SetTagCover"
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", fn, err)
+	}
+	defer tx.Rollback()
+
+	id, _, err := lockTag(tx, tagName)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", fn, err)
+	}
+
+	var oldCover string
+
+	err = tx.QueryRow(`SELECT cover FROM all_tags WHERE id = $1`, id).Scan(&oldCover)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", fn, err)
+	}
+
+	_, err = tx.Exec(`UPDATE all_tags SET cover = $1 WHERE id = $2`, cover, id)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", fn, err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return oldCover, nil
+}
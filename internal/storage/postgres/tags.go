@@ -0,0 +1,351 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"jadesheart/comix_back/internal/storage"
+	"strings"
+)
+
+/*
+*
+  - Изменяет описание тэга, создавая его, если описания ещё не было
+    @param
+  - tagName - название тэга
+  - description - новое описание
+    @return
+  - err - ошибка, storage.ErrTagNotFound если тэга нет
+    *
+*/
+func (s *Storage) EditTagDescription(tagName string, description string) error {
+	const fn = "storage.postgres.EditTagDescription"
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+	defer tx.Rollback()
+
+	_, _, err = lockTag(tx, tagName)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	res, err := tx.Exec(`UPDATE tags_description SET description = $1 WHERE lower(tag) = lower($2)`, description, tagName)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	if n == 0 {
+		_, err = tx.Exec(`INSERT INTO tags_description (tag, description) VALUES ($1, $2)`, tagName, description)
+		if err != nil {
+			return fmt.Errorf("%s: %w", fn, err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return nil
+}
+
+/*
+*
+  - Переименовывает тэг: таблицу тэга, all_tags, tags_description, тэг у комиксов и slug.
+  - Старый slug остаётся в истории и продолжает вести на тэг
+    @param
+  - tagName - текущее название тэга
+  - newTagName - новое название тэга
+    @return
+  - err - ошибка, storage.ErrTagNotFound если тэга нет, storage.ComixTagIsExists если новое название занято
+  - string - новый slug
+    *
+*/
+func (s *Storage) RenameTag(tagName string, newTagName string) (string, error) {
+	const fn = "storage.postgres.RenameTag"
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", fn, err)
+	}
+	defer tx.Rollback()
+
+	id, oldSlug, err := lockTag(tx, tagName)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", fn, err)
+	}
+
+	// Смена только регистра не меняет имя таблицы
+	if !strings.EqualFold(tagName, newTagName) {
+		var taken bool
+
+		err = tx.QueryRow(`SELECT EXISTS(SELECT 1 FROM all_tags WHERE lower(tag) = lower($1))
+			OR EXISTS(SELECT 1 FROM information_schema.tables WHERE table_name = lower($1))`, newTagName).Scan(&taken)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", fn, err)
+		}
+
+		if taken {
+			return "", fmt.Errorf("%s: %w", fn, storage.ComixTagIsExists)
+		}
+
+		_, err = tx.Exec(fmt.Sprintf("ALTER TABLE %s RENAME TO %s", tagName, newTagName))
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", fn, err)
+		}
+
+		_, err = tx.Exec(`UPDATE all_comix SET comix_tag = lower($1) WHERE comix_tag = lower($2)`, newTagName, tagName)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", fn, err)
+		}
+	}
+
+	_, err = tx.Exec(`UPDATE all_tags SET tag = $1 WHERE id = $2`, newTagName, id)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", fn, err)
+	}
+
+	_, err = tx.Exec(`UPDATE tags_description SET tag = $1 WHERE lower(tag) = lower($2)`, newTagName, tagName)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", fn, err)
+	}
+
+	if oldSlug.Valid {
+		_, err = tx.Exec(`INSERT INTO slug_history (kind, slug, target_id) VALUES ($1, $2, $3)
+			ON CONFLICT (kind, slug) DO NOTHING`, slugKindTag, oldSlug.String, id)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", fn, err)
+		}
+	}
+
+	newSlug, err := assignSlug(tx, slugKindTag, id, newTagName)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", fn, err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return newSlug, nil
+}
+
+/*
+*
+  - Переносит все комиксы тэга sourceTag в тэг targetTag и удаляет sourceTag.
+  - Slug удалённого тэга начинает вести на targetTag
+    @param
+  - sourceTag - тэг, который поглощается
+  - targetTag - тэг, в который переносятся комиксы
+    @return
+  - err - ошибка, storage.ErrTagNotFound если какого-то тэга нет,
+    storage.ErrComixExists если в обоих тэгах есть комиксы с одинаковым названием
+    *
+*/
+func (s *Storage) MergeTags(sourceTag string, targetTag string) error {
+	const fn = "storage.postgres.MergeTags"
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+	defer tx.Rollback()
+
+	sourceID, sourceSlug, err := lockTag(tx, sourceTag)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	targetID, _, err := lockTag(tx, targetTag)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	var conflict bool
+
+	err = tx.QueryRow(fmt.Sprintf("SELECT EXISTS(SELECT 1 FROM %s s JOIN %s t ON s.name = t.name)", sourceTag, targetTag)).Scan(&conflict)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	if conflict {
+		return fmt.Errorf("%s: %w", fn, storage.ErrComixExists)
+	}
+
+	queries := []string{
+		fmt.Sprintf(`INSERT INTO %s (name, description, upload_date, views)
+			SELECT name, description, upload_date, views FROM %s ORDER BY id`, targetTag, sourceTag),
+		fmt.Sprintf("DROP TABLE %s", sourceTag),
+	}
+
+	for _, query := range queries {
+		_, err = tx.Exec(query)
+		if err != nil {
+			return fmt.Errorf("%s: %w", fn, err)
+		}
+	}
+
+	_, err = tx.Exec(`UPDATE all_comix SET comix_tag = lower($1) WHERE comix_tag = lower($2)`, targetTag, sourceTag)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	_, err = tx.Exec(`DELETE FROM all_tags WHERE id = $1`, sourceID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	_, err = tx.Exec(`DELETE FROM tags_description WHERE lower(tag) = lower($1)`, sourceTag)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	// Подтэги поглощённого тэга переходят к targetTag
+	_, err = tx.Exec(`UPDATE all_tags SET parent_id = $1 WHERE parent_id = $2`, targetID, sourceID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	_, err = tx.Exec(`UPDATE slug_history SET target_id = $1 WHERE kind = $2 AND target_id = $3`, targetID, slugKindTag, sourceID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	if sourceSlug.Valid {
+		_, err = tx.Exec(`INSERT INTO slug_history (kind, slug, target_id) VALUES ($1, $2, $3)
+			ON CONFLICT (kind, slug) DO NOTHING`, slugKindTag, sourceSlug.String, targetID)
+		if err != nil {
+			return fmt.Errorf("%s: %w", fn, err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return nil
+}
+
+/*
+*
+  - Удаляет тэг. Тэг с комиксами (в том числе лежащими в корзине) удаляется только с force,
+  - тогда вместе с ним окончательно удаляются и все его комиксы
+    @param
+  - tagName - название тэга
+  - force - удалять ли тэг вместе с комиксами
+    @return
+  - err - ошибка, storage.ErrTagNotFound если тэга нет, storage.ErrTagNotEmpty если в тэге есть комиксы
+    *
+*/
+func (s *Storage) DeleteTag(tagName string, force bool) error {
+	const fn = "storage.postgres.DeleteTag"
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+	defer tx.Rollback()
+
+	id, _, err := lockTag(tx, tagName)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	var quantity int
+
+	err = tx.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM %s", tagName)).Scan(&quantity)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	if quantity > 0 && !force {
+		return fmt.Errorf("%s: %w", fn, storage.ErrTagNotEmpty)
+	}
+
+	// Комиксы тэга удаляются так же, как при очистке корзины, чтобы не оставалось их строк в других таблицах
+	type comix struct {
+		id   int
+		name string
+	}
+
+	var comixList []comix
+
+	rows, err := tx.Query(`SELECT id, comix_name FROM all_comix WHERE comix_tag = lower($1)`, tagName)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	for rows.Next() {
+		var c comix
+		if err := rows.Scan(&c.id, &c.name); err != nil {
+			rows.Close()
+			return fmt.Errorf("%s: %w", fn, err)
+		}
+		comixList = append(comixList, c)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	for _, c := range comixList {
+		err = deleteComixRows(tx, c.id, tagName, c.name)
+		if err != nil {
+			return fmt.Errorf("%s: %w", fn, err)
+		}
+	}
+
+	queries := []struct {
+		query string
+		args  []interface{}
+	}{
+		{`DELETE FROM all_comix WHERE comix_tag = lower($1)`, []interface{}{tagName}},
+		{fmt.Sprintf("DROP TABLE %s", tagName), nil},
+		// Подтэги удалённого тэга поднимаются на его уровень
+		{`UPDATE all_tags SET parent_id = (SELECT parent_id FROM all_tags WHERE id = $1) WHERE parent_id = $1`, []interface{}{id}},
+		{`DELETE FROM all_tags WHERE id = $1`, []interface{}{id}},
+		{`DELETE FROM tags_description WHERE lower(tag) = lower($1)`, []interface{}{tagName}},
+		{`DELETE FROM slug_history WHERE kind = $1 AND target_id = $2`, []interface{}{slugKindTag, id}},
+	}
+
+	for _, q := range queries {
+		_, err = tx.Exec(q.query, q.args...)
+		if err != nil {
+			return fmt.Errorf("%s: %w", fn, err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return nil
+}
+
+// lockTag находит тэг в all_tags и блокирует строку до конца транзакции.
+func lockTag(tx *sql.Tx, tagName string) (int, sql.NullString, error) {
+	var id int
+	var tagSlug sql.NullString
+
+	err := tx.QueryRow(`SELECT id, slug FROM all_tags WHERE lower(tag) = lower($1) FOR UPDATE`, tagName).Scan(&id, &tagSlug)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, sql.NullString{}, storage.ErrTagNotFound
+	}
+	if err != nil {
+		return 0, sql.NullString{}, err
+	}
+
+	return id, tagSlug, nil
+}
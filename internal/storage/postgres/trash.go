@@ -0,0 +1,258 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"jadesheart/comix_back/internal/storage"
+	"strings"
+	"time"
+)
+
+type DeletedComix struct {
+	ComixFromAllComix
+	DeletedAt time.Time
+}
+
+/*
+*
+  - Условие для таблицы тэга, которое скрывает комиксы, лежащие в корзине
+    @param
+  - tagName - название тэга
+    @return
+  - string - условие для WHERE
+    *
+*/
+func notDeleted(tagName string) string {
+	return fmt.Sprintf("name NOT IN (SELECT comix_name FROM all_comix WHERE comix_tag = '%s' AND deleted_at IS NOT NULL)", strings.ToLower(tagName))
+}
+
+/*
+*
+  - Переносит комикс в корзину: он пропадает из всех списков, но строки и страницы остаются
+    @param
+  - tagName - название тэга
+  - name - название комикса
+    @return
+  - err - ошибка, storage.ErrComixNotFound если комикса нет или он уже в корзине
+    *
+*/
+func (s *Storage) SoftDeleteComix(tagName string, name string) error {
+	const fn = "storage.postgres.SoftDeleteComix"
+
+	query := `UPDATE all_comix SET deleted_at = now()
+		WHERE comix_tag = lower($1) AND comix_name = $2 AND deleted_at IS NULL`
+
+	res, err := s.db.Exec(query, tagName, name)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return checkAffected(fn, res)
+}
+
+/*
+*
+  - Возвращает комикс из корзины
+    @param
+  - tagName - название тэга
+  - name - название комикса
+    @return
+  - err - ошибка, storage.ErrComixNotFound если комикса нет в корзине
+    *
+*/
+func (s *Storage) RestoreComix(tagName string, name string) error {
+	const fn = "storage.postgres.RestoreComix"
+
+	query := `UPDATE all_comix SET deleted_at = NULL
+		WHERE comix_tag = lower($1) AND comix_name = $2 AND deleted_at IS NOT NULL`
+
+	res, err := s.db.Exec(query, tagName, name)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return checkAffected(fn, res)
+}
+
+/*
+*
+  - Проверяет, лежит ли комикс в корзине
+    @param
+  - tagName - название тэга
+  - name - название комикса
+    @return
+  - err - ошибка
+  - bool - лежит или нет
+    *
+*/
+func (s *Storage) ComixInTrash(tagName string, name string) (bool, error) {
+	const fn = "storage.postgres.ComixInTrash"
+
+	var inTrash bool
+
+	query := `SELECT EXISTS(SELECT 1 FROM all_comix
+		WHERE comix_tag = lower($1) AND comix_name = $2 AND deleted_at IS NOT NULL)`
+
+	err := s.db.QueryRow(query, tagName, name).Scan(&inTrash)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return inTrash, nil
+}
+
+/*
+*
+  - Возвращает 16 комиксов из корзины, недавно удалённые первыми
+    @param
+  - pageToDisplay - номер страницы для отображения
+    @return
+  - err - ошибка
+  - []DeletedComix - комиксы вместе с датой удаления
+    *
+*/
+func (s *Storage) GetDeletedComix(pageToDisplay int) ([]DeletedComix, error) {
+	const fn = "storage.postgres.GetDeletedComix"
+	const numberComicsPerPage = 16
+
+	offset := (pageToDisplay - 1) * numberComicsPerPage
+
+	query := fmt.Sprintf(`SELECT %s, deleted_at
+		FROM all_comix WHERE deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC LIMIT $1 OFFSET $2`, allComixColumns)
+
+	return s.queryDeletedComix(fn, query, numberComicsPerPage, offset)
+}
+
+/*
+*
+  - Возвращает комиксы, которые лежат в корзине дольше срока хранения
+    @param
+  - deletedBefore - комиксы, удалённые раньше этого момента
+    @return
+  - err - ошибка
+  - []DeletedComix - комиксы для окончательного удаления
+    *
+*/
+func (s *Storage) GetExpiredDeletedComix(deletedBefore time.Time) ([]DeletedComix, error) {
+	const fn = "storage.postgres.GetExpiredDeletedComix"
+
+	query := fmt.Sprintf(`SELECT %s, deleted_at
+		FROM all_comix WHERE deleted_at IS NOT NULL AND deleted_at < $1
+		ORDER BY deleted_at`, allComixColumns)
+
+	return s.queryDeletedComix(fn, query, deletedBefore)
+}
+
+func (s *Storage) queryDeletedComix(fn string, query string, args ...interface{}) ([]DeletedComix, error) {
+	var comixList []DeletedComix
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var comix DeletedComix
+		err := rows.Scan(append(comixFields(&comix.ComixFromAllComix), &comix.DeletedAt)...)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fn, err)
+		}
+		comixList = append(comixList, comix)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return comixList, nil
+}
+
+/*
+*
+  - Окончательно удаляет комикс из корзины: строки в таблице тэга, all_comix, просмотры, старые slug, страницы на проверке, закладки, места чтения, строки списков читателей, комментарии и оценки
+    @param
+  - tagName - название тэга
+  - name - название комикса
+    @return
+  - err - ошибка
+    *
+*/
+func (s *Storage) PurgeComix(tagName string, name string) error {
+	const fn = "storage.postgres.PurgeComix"
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+	defer tx.Rollback()
+
+	var id int
+
+	err = tx.QueryRow(`DELETE FROM all_comix
+		WHERE comix_tag = lower($1) AND comix_name = $2 AND deleted_at IS NOT NULL
+		RETURNING id`, tagName, name).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%s: %w", fn, storage.ErrComixNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	err = deleteComixRows(tx, id, tagName, name)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return nil
+}
+
+// deleteComixRows удаляет всё, что относится к комиксу id, кроме строки all_comix: строку в таблице тэга,
+// просмотры, старые slug, авторов, страницы, закладки, места чтения, строки списков, комментарии и оценки
+func deleteComixRows(tx *sql.Tx, id int, tagName string, name string) error {
+	queries := []struct {
+		query string
+		args  []interface{}
+	}{
+		{`DELETE FROM comix_views_daily WHERE comix_id = $1`, []interface{}{id}},
+		{`DELETE FROM slug_history WHERE kind = $1 AND target_id = $2`, []interface{}{slugKindComix, id}},
+		{`DELETE FROM comix_authors WHERE comix_id = $1`, []interface{}{id}},
+		{`DELETE FROM comix_pages WHERE comix_id = $1`, []interface{}{id}},
+		{`DELETE FROM pending_pages WHERE comix_id = $1`, []interface{}{id}},
+		{`DELETE FROM bookmarks WHERE comix_id = $1`, []interface{}{id}},
+		{`DELETE FROM reading_progress WHERE comix_id = $1`, []interface{}{id}},
+		{`DELETE FROM reading_list_items WHERE comix_id = $1`, []interface{}{id}},
+		{`DELETE FROM comments WHERE comix_id = $1`, []interface{}{id}},
+		{`DELETE FROM ratings WHERE comix_id = $1`, []interface{}{id}},
+		{fmt.Sprintf("DELETE FROM %s WHERE name = $1", tagName), []interface{}{name}},
+	}
+
+	for _, q := range queries {
+		_, err := tx.Exec(q.query, q.args...)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func checkAffected(fn string, res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	if n == 0 {
+		return fmt.Errorf("%s: %w", fn, storage.ErrComixNotFound)
+	}
+
+	return nil
+}
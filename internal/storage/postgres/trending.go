@@ -0,0 +1,167 @@
+package postgres
+
+import "fmt"
+
+// historicalViewsDay - день корзины, в которую переносятся просмотры из таблиц тэгов,
+// накопленные до появления дневных корзин. Окна в днях её не захватывают.
+const historicalViewsDay = "1970-01-01"
+
+// TrendingComix - Score называется в JSON так же, как поля ComixFromAllComix рядом с ним
+type TrendingComix struct {
+	ComixFromAllComix
+	Score float64 `json:"Score"`
+}
+
+/*
+*
+  - Увеличивает счётчик просмотров комикса за текущий день
+    @param
+  - tag - название тэга
+    -name - название комикса
+    @return
+  - err - ошибка
+    *
+*/
+func (s *Storage) addDailyView(tag string, name string) error {
+	const fn = "storage.postgres.addDailyView"
+
+	query := `INSERT INTO comix_views_daily (comix_id, day, views)
+		SELECT id, CURRENT_DATE, 1 FROM all_comix WHERE comix_tag = lower($1) AND comix_name = $2
+		ON CONFLICT (comix_id, day) DO UPDATE SET views = comix_views_daily.views + 1`
+
+	_, err := s.db.Exec(query, tag, name)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return nil
+}
+
+/*
+*
+  - Возвращает 16 самых просматриваемых комиксов за период по дневным корзинам просмотров.
+  - Просмотры до появления корзин лежат в корзине historicalViewsDay (см. backfillHistoricalViews)
+    и считаются только за всё время, без затухания: когда они были, неизвестно
+    @param
+  - days - размер окна в днях, 0 - за всё время
+  - tagName - тэг для фильтрации, пустая строка - все тэги
+  - halfLifeDays - период полураспада просмотров в днях, 0 - без затухания
+  - pageToDisplay - номер страницы для отображения
+    @return
+  - err - ошибка
+  - []TrendingComix - комиксы вместе с их рейтингом популярности
+    *
+*/
+func (s *Storage) GetTrendingComix(days int, tagName string, halfLifeDays float64, pageToDisplay int) ([]TrendingComix, error) {
+	const fn = "storage.postgres.GetTrendingComix"
+	const numberComicsPerPage = 16
+
+	var comixList []TrendingComix
+
+	offset := (pageToDisplay - 1) * numberComicsPerPage
+
+	query := fmt.Sprintf(`SELECT %s,
+			COALESCE(SUM(d.views * CASE WHEN $3::float8 > 0 AND d.day <> $6::date
+				THEN POWER(0.5, (CURRENT_DATE - d.day) / $3::float8)
+				ELSE 1 END), 0) AS score
+		FROM all_comix c
+		LEFT JOIN comix_views_daily d ON d.comix_id = c.id AND ($1::int = 0 OR d.day > CURRENT_DATE - $1::int)
+		WHERE `+visibleComix+`
+			AND ($2::text = '' OR c.comix_tag = lower($2::text))
+		GROUP BY c.id
+		HAVING $1::int = 0 OR COUNT(d.comix_id) > 0
+		ORDER BY score DESC, c.id DESC
+		LIMIT $4 OFFSET $5`, comixColumns("c"))
+
+	rows, err := s.db.Query(query, days, tagName, halfLifeDays, numberComicsPerPage, offset, historicalViewsDay)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var comix TrendingComix
+		err := rows.Scan(append(comixFields(&comix.ComixFromAllComix), &comix.Score)...)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fn, err)
+		}
+		comixList = append(comixList, comix)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return comixList, nil
+}
+
+/*
+*
+  - Переносит в корзину historicalViewsDay просмотры из таблиц тэгов, накопленные до появления дневных корзин:
+  - счётчик таблицы тэга минус то, что уже лежит в корзинах. Выполняется один раз - пока такой корзины нет ни у кого
+    @return
+  - err - ошибка
+    *
+*/
+func (s *Storage) backfillHistoricalViews() error {
+	const fn = "storage.postgres.backfillHistoricalViews"
+
+	var done bool
+
+	err := s.db.QueryRow(`SELECT EXISTS(SELECT 1 FROM comix_views_daily WHERE day = $1::date)`, historicalViewsDay).Scan(&done)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	if done {
+		return nil
+	}
+
+	rows, err := s.db.Query(`SELECT t.tag FROM all_tags t
+		WHERE EXISTS(SELECT 1 FROM information_schema.tables WHERE table_name = lower(t.tag))`)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	var tags []string
+
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			rows.Close()
+			return fmt.Errorf("%s: %w", fn, err)
+		}
+		tags = append(tags, tag)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+	defer tx.Rollback()
+
+	for _, tag := range tags {
+		query := fmt.Sprintf(`INSERT INTO comix_views_daily (comix_id, day, views)
+			SELECT c.id, $1::date, GREATEST(t.views - COALESCE((SELECT SUM(d.views) FROM comix_views_daily d WHERE d.comix_id = c.id), 0), 0)
+			FROM all_comix c JOIN %s t ON t.name = c.comix_name
+			WHERE c.comix_tag = lower($2)
+			ON CONFLICT (comix_id, day) DO NOTHING`, tag)
+
+		_, err = tx.Exec(query, historicalViewsDay, tag)
+		if err != nil {
+			return fmt.Errorf("%s: %w", fn, err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,353 @@
+package postgres
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"jadesheart/comix_back/internal/storage"
+	"time"
+)
+
+// deliveriesPerPage - доставок на странице журнала вебхука
+const deliveriesPerPage = 50
+
+// Состояние доставки
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// Webhook - адрес, на который отправляются события. Events пустой - вебхук подписан на все события.
+// Секрет отдаётся только при создании.
+type Webhook struct {
+	ID        int
+	URL       string
+	Secret    string `json:",omitempty"`
+	Events    []string
+	CreatedAt time.Time
+}
+
+// WebhookDelivery - доставка события одному вебхуку. URL и Secret заполнены только у доставок к отправке.
+// LastStatusCode и LastError - итог последней попытки, 0 и пустая строка - попыток ещё не было.
+type WebhookDelivery struct {
+	ID             int64
+	WebhookID      int
+	URL            string `json:"-"`
+	Secret         string `json:"-"`
+	Event          string
+	Payload        json.RawMessage
+	State          string
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastStatusCode int
+	LastError      string
+	CreatedAt      time.Time
+}
+
+// WebhookAttempt - попытка доставки. StatusCode 0 - ответа не было, причина в Error.
+type WebhookAttempt struct {
+	DeliveryID int64
+	StatusCode int
+	Error      string
+	Duration   time.Duration
+}
+
+// enqueueWebhooks ставит доставку события вебхукам, которые на него подписаны
+func enqueueWebhooks(tx *sql.Tx, event AuditEvent) error {
+	_, err := tx.Exec(`INSERT INTO webhook_outbox (webhook_id, event, payload)
+		SELECT id, $1, jsonb_build_object('event', $1::text, 'target', $2::text, 'actor', $3::text,
+			'occurredAt', now(), 'before', $4::jsonb, 'after', $5::jsonb)
+		FROM webhooks WHERE cardinality(events) = 0 OR $1 = ANY(events)`,
+		event.WebhookEvent, event.Target, event.Actor, nullJSON(event.Before), nullJSON(event.After))
+
+	return err
+}
+
+/*
+*
+  - Регистрирует вебхук
+    @param
+  - url - адрес, на который отправляются события
+  - secret - секрет для подписи доставок
+  - events - события, пустой список - все
+    @return
+  - err - ошибка
+  - Webhook - созданный вебхук вместе с секретом
+    *
+*/
+func (s *Storage) CreateWebhook(url string, secret string, events []string) (Webhook, error) {
+	const fn = "storage.postgres.CreateWebhook"
+
+	if events == nil {
+		events = []string{}
+	}
+
+	webhook := Webhook{URL: url, Secret: secret, Events: events}
+
+	err := s.db.QueryRow(`INSERT INTO webhooks (url, secret, events) VALUES ($1, $2, $3) RETURNING id, created_at`,
+		url, secret, pq.Array(events)).Scan(&webhook.ID, &webhook.CreatedAt)
+	if err != nil {
+		return Webhook{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return webhook, nil
+}
+
+/*
+*
+  - Возвращает все вебхуки без секретов
+    @return
+  - err - ошибка
+  - []Webhook - вебхуки
+    *
+*/
+func (s *Storage) GetWebhooks() ([]Webhook, error) {
+	const fn = "storage.postgres.GetWebhooks"
+
+	rows, err := s.db.Query(`SELECT id, url, events, created_at FROM webhooks ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+	defer rows.Close()
+
+	webhooks := []Webhook{}
+
+	for rows.Next() {
+		var webhook Webhook
+		err := rows.Scan(&webhook.ID, &webhook.URL, pq.Array(&webhook.Events), &webhook.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fn, err)
+		}
+		webhooks = append(webhooks, webhook)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return webhooks, nil
+}
+
+/*
+*
+  - Удаляет вебхук вместе с его доставками
+    @param
+  - id - id вебхука
+    @return
+  - err - ошибка, storage.ErrWebhookNotFound если вебхука нет
+  - Webhook - удалённый вебхук без секрета, для журнала
+    *
+*/
+func (s *Storage) DeleteWebhook(id int) (Webhook, error) {
+	const fn = "storage.postgres.DeleteWebhook"
+
+	var webhook Webhook
+
+	err := s.db.QueryRow(`DELETE FROM webhooks WHERE id = $1 RETURNING id, url, events, created_at`, id).
+		Scan(&webhook.ID, &webhook.URL, pq.Array(&webhook.Events), &webhook.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Webhook{}, fmt.Errorf("%s: %w", fn, storage.ErrWebhookNotFound)
+	}
+	if err != nil {
+		return Webhook{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return webhook, nil
+}
+
+// deliveryColumns - столбцы WebhookDelivery без URL и Secret; o - webhook_outbox
+const deliveryColumns = `o.id, o.webhook_id, o.event, o.payload,
+	CASE WHEN o.delivered_at IS NOT NULL THEN 'delivered' WHEN o.failed_at IS NOT NULL THEN 'failed' ELSE 'pending' END,
+	o.attempts, o.next_attempt_at,
+	COALESCE((SELECT a.status_code FROM webhook_attempts a WHERE a.delivery_id = o.id ORDER BY a.id DESC LIMIT 1), 0),
+	COALESCE((SELECT a.error FROM webhook_attempts a WHERE a.delivery_id = o.id ORDER BY a.id DESC LIMIT 1), ''),
+	o.created_at`
+
+func deliveryFields(delivery *WebhookDelivery) []interface{} {
+	return []interface{}{&delivery.ID, &delivery.WebhookID, &delivery.Event, (*[]byte)(&delivery.Payload), &delivery.State,
+		&delivery.Attempts, &delivery.NextAttemptAt, &delivery.LastStatusCode, &delivery.LastError, &delivery.CreatedAt}
+}
+
+/*
+*
+  - Возвращает 50 доставок вебхука, новые первыми
+    @param
+  - webhookID - id вебхука
+  - pageToDisplay - номер страницы для отображения
+    @return
+  - err - ошибка, storage.ErrWebhookNotFound если вебхука нет
+  - []WebhookDelivery - доставки
+    *
+*/
+func (s *Storage) GetWebhookDeliveries(webhookID int, pageToDisplay int) ([]WebhookDelivery, error) {
+	const fn = "storage.postgres.GetWebhookDeliveries"
+
+	var exists bool
+
+	err := s.db.QueryRow(`SELECT EXISTS(SELECT 1 FROM webhooks WHERE id = $1)`, webhookID).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("%s: %w", fn, storage.ErrWebhookNotFound)
+	}
+
+	offset := (pageToDisplay - 1) * deliveriesPerPage
+
+	rows, err := s.db.Query(fmt.Sprintf(`SELECT %s FROM webhook_outbox o WHERE o.webhook_id = $1
+		ORDER BY o.id DESC LIMIT %d OFFSET %d`, deliveryColumns, deliveriesPerPage, offset), webhookID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+	defer rows.Close()
+
+	deliveries := []WebhookDelivery{}
+
+	for rows.Next() {
+		var delivery WebhookDelivery
+		err := rows.Scan(deliveryFields(&delivery)...)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fn, err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return deliveries, nil
+}
+
+/*
+*
+  - Ставит событие доставки ещё раз: новая доставка с тем же телом тому же вебхуку
+    @param
+  - id - id доставки, которую повторяют
+    @return
+  - err - ошибка, storage.ErrDeliveryNotFound если доставки нет
+  - WebhookDelivery - новая доставка
+    *
+*/
+func (s *Storage) ReplayWebhookDelivery(id int64) (WebhookDelivery, error) {
+	const fn = "storage.postgres.ReplayWebhookDelivery"
+
+	var delivery WebhookDelivery
+
+	err := s.db.QueryRow(fmt.Sprintf(`WITH replay AS (
+			INSERT INTO webhook_outbox (webhook_id, event, payload)
+			SELECT webhook_id, event, payload FROM webhook_outbox WHERE id = $1
+			RETURNING *
+		)
+		SELECT %s FROM replay o`, deliveryColumns), id).Scan(deliveryFields(&delivery)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return WebhookDelivery{}, fmt.Errorf("%s: %w", fn, storage.ErrDeliveryNotFound)
+	}
+	if err != nil {
+		return WebhookDelivery{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return delivery, nil
+}
+
+/*
+*
+  - Забирает доставки, которым пора уйти. Забранные доставки откладываются на lease,
+    чтобы их не отправил второй экземпляр сервиса; итог попытки записывает RecordWebhookAttempt
+    @param
+  - limit - сколько доставок забрать
+  - lease - на сколько отложить забранные доставки
+    @return
+  - err - ошибка
+  - []WebhookDelivery - доставки вместе с адресом и секретом вебхука
+    *
+*/
+func (s *Storage) ClaimWebhookDeliveries(limit int, lease time.Duration) ([]WebhookDelivery, error) {
+	const fn = "storage.postgres.ClaimWebhookDeliveries"
+
+	rows, err := s.db.Query(fmt.Sprintf(`WITH due AS (
+			SELECT id FROM webhook_outbox
+			WHERE delivered_at IS NULL AND failed_at IS NULL AND next_attempt_at <= now()
+			ORDER BY next_attempt_at, id LIMIT $1 FOR UPDATE SKIP LOCKED
+		), claimed AS (
+			UPDATE webhook_outbox o SET next_attempt_at = now() + $2::float8 * interval '1 millisecond'
+			FROM due WHERE o.id = due.id
+			RETURNING o.*
+		)
+		SELECT %s, w.url, w.secret FROM claimed o JOIN webhooks w ON w.id = o.webhook_id
+		ORDER BY o.id`, deliveryColumns), limit, lease.Milliseconds())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+	defer rows.Close()
+
+	deliveries := []WebhookDelivery{}
+
+	for rows.Next() {
+		var delivery WebhookDelivery
+		err := rows.Scan(append(deliveryFields(&delivery), &delivery.URL, &delivery.Secret)...)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fn, err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return deliveries, nil
+}
+
+/*
+*
+  - Записывает попытку доставки в журнал вебхука. Успешная доставка закрывается,
+    неудачная ждёт повтора в retryAt, а с нулевым retryAt больше не повторяется
+    @param
+  - attempt - итог попытки
+  - delivered - получатель принял доставку
+  - retryAt - время следующей попытки
+    @return
+  - err - ошибка
+    *
+*/
+func (s *Storage) RecordWebhookAttempt(attempt WebhookAttempt, delivered bool, retryAt time.Time) error {
+	const fn = "storage.postgres.RecordWebhookAttempt"
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+	defer tx.Rollback()
+
+	var attempts int
+
+	err = tx.QueryRow(`UPDATE webhook_outbox SET attempts = attempts + 1,
+			delivered_at = CASE WHEN $2 THEN now() END,
+			failed_at = CASE WHEN NOT $2 AND $3::timestamptz IS NULL THEN now() END,
+			next_attempt_at = COALESCE($3::timestamptz, next_attempt_at)
+		WHERE id = $1 RETURNING attempts`, attempt.DeliveryID, delivered, nullTime(retryAt)).Scan(&attempts)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Вебхук удалили, пока шла доставка
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	_, err = tx.Exec(`INSERT INTO webhook_attempts (delivery_id, attempt, status_code, error, duration_ms)
+		VALUES ($1, $2, $3, $4, $5)`, attempt.DeliveryID, attempts, attempt.StatusCode, attempt.Error, attempt.Duration.Milliseconds())
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return nil
+}
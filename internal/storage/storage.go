@@ -3,7 +3,26 @@ package storage
 import "errors"
 
 var (
-	ErrURLNotFound   = errors.New("URL NOT FOUND")
-	ErrURLExists     = errors.New("URL EXISTS")
-	ComixTagIsExists = errors.New("TAG EXISTS")
+	ErrURLNotFound      = errors.New("URL NOT FOUND")
+	ErrURLExists        = errors.New("URL EXISTS")
+	ComixTagIsExists    = errors.New("TAG EXISTS")
+	ErrComixNotFound    = errors.New("COMIX NOT FOUND")
+	ErrComixExists      = errors.New("COMIX EXISTS")
+	ErrTagNotFound      = errors.New("TAG NOT FOUND")
+	ErrTagNotEmpty      = errors.New("TAG NOT EMPTY")
+	ErrTagCycle         = errors.New("TAG CYCLE")
+	ErrAuthorNotFound   = errors.New("AUTHOR NOT FOUND")
+	ErrPageNotFound     = errors.New("PAGE NOT FOUND")
+	ErrPagesMismatch    = errors.New("PAGES MISMATCH")
+	ErrReaderExists     = errors.New("READER EXISTS")
+	ErrReaderNotFound   = errors.New("READER NOT FOUND")
+	ErrSessionNotFound  = errors.New("SESSION NOT FOUND")
+	ErrListNotFound     = errors.New("LIST NOT FOUND")
+	ErrListMismatch     = errors.New("LIST MISMATCH")
+	ErrCommentNotFound  = errors.New("COMMENT NOT FOUND")
+	ErrRatingNotFound   = errors.New("RATING NOT FOUND")
+	ErrReportNotFound   = errors.New("REPORT NOT FOUND")
+	ErrReleaseNotFound  = errors.New("RELEASE NOT FOUND")
+	ErrWebhookNotFound  = errors.New("WEBHOOK NOT FOUND")
+	ErrDeliveryNotFound = errors.New("DELIVERY NOT FOUND")
 )
@@ -0,0 +1,77 @@
+package pages
+
+import (
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/lib/photos"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"os"
+)
+
+type PagesImporter interface {
+	GetComixWithoutPages() ([]postgres.ComixFromAllComix, error)
+	InitComixPages(comixID int, files []string) (int, error)
+}
+
+// Backfill записывает в базу порядок страниц комиксов, загруженных до того, как порядок стал храниться в базе.
+// Страницы таких комиксов лежат в папке как 1.jpg...N.jpg. Запускается при старте, до приёма запросов,
+// чтобы новые страницы не успели попасть в базу раньше старых. Возвращает количество комиксов со страницами.
+func Backfill(log *slog.Logger, pagesImporter PagesImporter, mediaRoot *photos.Root) int {
+	log = log.With(
+		slog.String("component", "worker/pages"),
+	)
+
+	comixList, err := pagesImporter.GetComixWithoutPages()
+	if err != nil {
+		log.Error("failed get comix without pages", sl.Err(err))
+
+		return 0
+	}
+
+	imported := 0
+
+	for _, comix := range comixList {
+		log := log.With(
+			slog.String("tag", comix.ComixTag),
+			slog.String("name", comix.ComixName),
+		)
+
+		comixDir, err := mediaRoot.ComixDir(comix.ComixTag, comix.ComixName)
+		if err != nil {
+			log.Error("unsafe comix directory", sl.Err(err))
+
+			continue
+		}
+
+		files, err := photos.LegacyPages(comixDir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			log.Error("failed read comix directory", sl.Err(err))
+
+			continue
+		}
+
+		if len(files) == 0 {
+			continue
+		}
+
+		n, err := pagesImporter.InitComixPages(comix.ID, files)
+		if err != nil {
+			log.Error("failed import comix pages", sl.Err(err))
+
+			continue
+		}
+
+		if n > 0 {
+			imported++
+		}
+	}
+
+	if imported > 0 {
+		log.Info("comix pages imported", slog.Int("comix", imported))
+	}
+
+	return imported
+}
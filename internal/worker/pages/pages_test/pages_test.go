@@ -0,0 +1,50 @@
+package pages_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"jadesheart/comix_back/internal/lib/photos"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"jadesheart/comix_back/internal/worker/pages"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type MockPagesImporter struct {
+	imported map[int][]string
+}
+
+func (m *MockPagesImporter) GetComixWithoutPages() ([]postgres.ComixFromAllComix, error) {
+	return []postgres.ComixFromAllComix{
+		{ID: 1, ComixTag: "horror", ComixName: "old"},
+		{ID: 2, ComixTag: "horror", ComixName: "empty"},
+		{ID: 3, ComixTag: "horror", ComixName: "missing"},
+	}, nil
+}
+
+func (m *MockPagesImporter) InitComixPages(comixID int, files []string) (int, error) {
+	m.imported[comixID] = files
+	return len(files), nil
+}
+
+func TestBackfill(t *testing.T) {
+	photosDir := t.TempDir()
+
+	dir := filepath.Join(photosDir, "Horror", "old")
+	assert.NoError(t, os.MkdirAll(dir, 0755))
+	for _, name := range []string{"2.jpg", "1.jpg", "cover.jpg"} {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("page"), 0644))
+	}
+	assert.NoError(t, os.MkdirAll(filepath.Join(photosDir, "Horror", "empty"), 0755))
+
+	importer := &MockPagesImporter{imported: map[int][]string{}}
+
+	mediaRoot, err := photos.NewRoot(photosDir)
+	assert.NoError(t, err)
+
+	imported := pages.Backfill(slogdiscard.NewDiscardLogger(), importer, mediaRoot)
+
+	assert.Equal(t, 1, imported)
+	assert.Equal(t, map[int][]string{1: {"1.jpg", "2.jpg"}}, importer.imported)
+}
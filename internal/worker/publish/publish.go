@@ -0,0 +1,106 @@
+package publish
+
+import (
+	"context"
+	"jadesheart/comix_back/internal/lib/audit"
+	"jadesheart/comix_back/internal/lib/cache"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"time"
+)
+
+type ReleasePublisher interface {
+	PublishDue() ([]postgres.Release, error)
+	AddAuditEvent(event postgres.AuditEvent) error
+}
+
+type CacheInvalidator interface {
+	Invalidate(groups ...string)
+}
+
+// Publisher выпускает комиксы и страницы, чьё время выхода наступило, и сбрасывает кэш списков,
+// в которых они должны появиться.
+type Publisher struct {
+	log              *slog.Logger
+	releasePublisher ReleasePublisher
+	cacheInvalidator CacheInvalidator
+	interval         time.Duration
+}
+
+func New(log *slog.Logger, releasePublisher ReleasePublisher, cacheInvalidator CacheInvalidator, interval time.Duration) *Publisher {
+	return &Publisher{
+		log: log.With(
+			slog.String("component", "worker/publish"),
+		),
+		releasePublisher: releasePublisher,
+		cacheInvalidator: cacheInvalidator,
+		interval:         interval,
+	}
+}
+
+// Run выпускает наступившие выпуски сразу и затем раз в interval, пока не отменён ctx.
+func (p *Publisher) Run(ctx context.Context) {
+	p.log.Info("scheduled publishing started", slog.String("interval", p.interval.String()))
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.PublishDue()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PublishDue делает один проход и возвращает количество вышедших выпусков.
+func (p *Publisher) PublishDue() int {
+	releases, err := p.releasePublisher.PublishDue()
+	if err != nil {
+		p.log.Error("failed publish due releases", sl.Err(err))
+
+		return 0
+	}
+
+	if len(releases) == 0 {
+		return 0
+	}
+
+	groups := []string{cache.KeyMainPage, cache.KeySearch, cache.KeyAllTags}
+	seen := make(map[string]bool)
+
+	for _, release := range releases {
+		if key := cache.KeyTagComix(release.ComixTag); !seen[key] {
+			seen[key] = true
+			groups = append(groups, key)
+		}
+
+		action := audit.ActionComixPublish
+		if release.Kind == postgres.ReleasePages {
+			action = audit.ActionPagesPublish
+		}
+
+		err := p.releasePublisher.AddAuditEvent(audit.NewSystemEvent(action, audit.ComixTarget(release.ComixTag, release.ComixName), nil, map[string]interface{}{
+			"publishAt": release.PublishAt,
+			"pages":     release.Pages,
+		}))
+		if err != nil {
+			p.log.Error("failed write audit event", sl.Err(err))
+		}
+
+		p.log.Info("release published",
+			slog.String("kind", release.Kind),
+			slog.String("tag", release.ComixTag),
+			slog.String("name", release.ComixName),
+			slog.Int("pages", release.Pages),
+		)
+	}
+
+	p.cacheInvalidator.Invalidate(groups...)
+
+	return len(releases)
+}
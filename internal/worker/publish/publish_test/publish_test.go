@@ -0,0 +1,67 @@
+package publish_test
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"jadesheart/comix_back/internal/worker/publish"
+	"testing"
+	"time"
+)
+
+type MockReleasePublisher struct {
+	releases []postgres.Release
+	err      error
+	events   []postgres.AuditEvent
+}
+
+func (m *MockReleasePublisher) PublishDue() ([]postgres.Release, error) {
+	return m.releases, m.err
+}
+
+func (m *MockReleasePublisher) AddAuditEvent(event postgres.AuditEvent) error {
+	m.events = append(m.events, event)
+	return nil
+}
+
+type MockCacheInvalidator struct {
+	groups []string
+}
+
+func (m *MockCacheInvalidator) Invalidate(groups ...string) {
+	m.groups = append(m.groups, groups...)
+}
+
+func TestPublishDue(t *testing.T) {
+	publishAt := time.Now().Add(-time.Minute)
+	releaser := &MockReleasePublisher{releases: []postgres.Release{
+		{Kind: postgres.ReleaseComix, ComixTag: "horror", ComixName: "Watchmen", PublishAt: publishAt, Pages: 12},
+		{Kind: postgres.ReleasePages, ComixTag: "Horror", ComixName: "Hellboy", PublishAt: publishAt, Pages: 3},
+	}}
+	invalidator := &MockCacheInvalidator{}
+
+	publisher := publish.New(slogdiscard.NewDiscardLogger(), releaser, invalidator, time.Minute)
+
+	assert.Equal(t, 2, publisher.PublishDue())
+
+	assert.ElementsMatch(t, []string{"main_page", "search", "all_tags", "tag_comix:horror"}, invalidator.groups)
+
+	assert.Len(t, releaser.events, 2)
+	assert.Equal(t, "system", releaser.events[0].Actor)
+	assert.Equal(t, "comix.publish", releaser.events[0].Action)
+	assert.Equal(t, "comix.pages.publish", releaser.events[1].Action)
+}
+
+func TestPublishDue_Nothing(t *testing.T) {
+	invalidator := &MockCacheInvalidator{}
+
+	publisher := publish.New(slogdiscard.NewDiscardLogger(), &MockReleasePublisher{}, invalidator, time.Minute)
+	assert.Equal(t, 0, publisher.PublishDue())
+
+	publisher = publish.New(slogdiscard.NewDiscardLogger(), &MockReleasePublisher{err: errors.New("db is down")}, invalidator, time.Minute)
+	assert.Equal(t, 0, publisher.PublishDue())
+
+	// Пока ничего не вышло, кэш не трогается
+	assert.Empty(t, invalidator.groups)
+}
@@ -0,0 +1,108 @@
+package purge
+
+import (
+	"context"
+	"jadesheart/comix_back/internal/lib/audit"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/lib/photos"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"os"
+	"time"
+)
+
+type TrashPurger interface {
+	GetExpiredDeletedComix(deletedBefore time.Time) ([]postgres.DeletedComix, error)
+	PurgeComix(tagName string, name string) error
+	AddAuditEvent(event postgres.AuditEvent) error
+}
+
+// Purger окончательно удаляет комиксы, которые лежат в корзине дольше срока хранения,
+// вместе с папкой их страниц.
+type Purger struct {
+	log         *slog.Logger
+	trashPurger TrashPurger
+	mediaRoot   *photos.Root
+	retention   time.Duration
+	interval    time.Duration
+}
+
+func New(log *slog.Logger, trashPurger TrashPurger, mediaRoot *photos.Root, retention time.Duration, interval time.Duration) *Purger {
+	return &Purger{
+		log: log.With(
+			slog.String("component", "worker/purge"),
+		),
+		trashPurger: trashPurger,
+		mediaRoot:   mediaRoot,
+		retention:   retention,
+		interval:    interval,
+	}
+}
+
+// Run чистит корзину сразу и затем раз в interval, пока не отменён ctx.
+func (p *Purger) Run(ctx context.Context) {
+	p.log.Info("trash purge started", slog.String("retention", p.retention.String()), slog.String("interval", p.interval.String()))
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.PurgeExpired()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PurgeExpired делает один проход по корзине и возвращает количество удалённых комиксов.
+func (p *Purger) PurgeExpired() int {
+	comixList, err := p.trashPurger.GetExpiredDeletedComix(time.Now().Add(-p.retention))
+	if err != nil {
+		p.log.Error("failed get expired comix from trash", sl.Err(err))
+
+		return 0
+	}
+
+	purged := 0
+
+	for _, comix := range comixList {
+		log := p.log.With(
+			slog.String("tag", comix.ComixTag),
+			slog.String("name", comix.ComixName),
+		)
+
+		err := p.trashPurger.PurgeComix(comix.ComixTag, comix.ComixName)
+		if err != nil {
+			log.Error("failed purge comix", sl.Err(err))
+
+			continue
+		}
+
+		comixDir, err := p.mediaRoot.ComixDir(comix.ComixTag, comix.ComixName)
+		if err == nil {
+			err = os.RemoveAll(comixDir)
+		}
+		if err != nil {
+			log.Error("failed remove comix photo folder", sl.Err(err))
+		}
+
+		err = p.trashPurger.AddAuditEvent(audit.NewSystemEvent(audit.ActionComixPurge, audit.ComixTarget(comix.ComixTag, comix.ComixName), map[string]interface{}{
+			"tagName":     comix.ComixTag,
+			"name":        comix.ComixName,
+			"description": comix.Description,
+			"deletedAt":   comix.DeletedAt,
+		}, nil))
+		if err != nil {
+			log.Error("failed write audit event", sl.Err(err))
+		}
+
+		log.Info("comix purged from trash")
+
+		purged++
+	}
+
+	return purged
+}
@@ -0,0 +1,74 @@
+package purge_test
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"jadesheart/comix_back/internal/lib/photos"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"jadesheart/comix_back/internal/worker/purge"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type MockTrashPurger struct {
+	deletedBefore time.Time
+	purged        []string
+	events        []postgres.AuditEvent
+}
+
+func (m *MockTrashPurger) GetExpiredDeletedComix(deletedBefore time.Time) ([]postgres.DeletedComix, error) {
+	m.deletedBefore = deletedBefore
+	return []postgres.DeletedComix{
+		{ComixFromAllComix: postgres.ComixFromAllComix{ComixTag: "horror", ComixName: "old"}},
+		{ComixFromAllComix: postgres.ComixFromAllComix{ComixTag: "horror", ComixName: "broken"}},
+	}, nil
+}
+
+func (m *MockTrashPurger) PurgeComix(tagName string, name string) error {
+	if name == "broken" {
+		return errors.New("db error")
+	}
+	m.purged = append(m.purged, tagName+"/"+name)
+	return nil
+}
+
+func (m *MockTrashPurger) AddAuditEvent(event postgres.AuditEvent) error {
+	m.events = append(m.events, event)
+	return nil
+}
+
+func TestPurgeExpired(t *testing.T) {
+	photosDir := t.TempDir()
+
+	for _, name := range []string{"old", "broken"} {
+		dir := filepath.Join(photosDir, "Horror", name)
+		assert.NoError(t, os.MkdirAll(dir, 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "1.jpg"), []byte("page"), 0644))
+	}
+
+	trash := &MockTrashPurger{}
+	mediaRoot, err := photos.NewRoot(photosDir)
+	assert.NoError(t, err)
+
+	purger := purge.New(slogdiscard.NewDiscardLogger(), trash, mediaRoot, 30*24*time.Hour, time.Hour)
+
+	purged := purger.PurgeExpired()
+
+	assert.Equal(t, 1, purged)
+	assert.Equal(t, []string{"horror/old"}, trash.purged)
+	assert.WithinDuration(t, time.Now().Add(-30*24*time.Hour), trash.deletedBefore, time.Minute)
+
+	_, err = os.Stat(filepath.Join(photosDir, "Horror", "old"))
+	assert.True(t, os.IsNotExist(err))
+
+	// Папка комикса, который не удалось удалить из базы, остаётся на месте
+	_, err = os.Stat(filepath.Join(photosDir, "Horror", "broken"))
+	assert.NoError(t, err)
+
+	assert.Len(t, trash.events, 1)
+	assert.Equal(t, "system", trash.events[0].Actor)
+	assert.Equal(t, "comix.purge", trash.events[0].Action)
+}
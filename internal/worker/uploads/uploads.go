@@ -0,0 +1,63 @@
+package uploads
+
+import (
+	"context"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"log/slog"
+	"time"
+)
+
+type ExpiredRemover interface {
+	RemoveExpired(before time.Time) (int, error)
+}
+
+// Cleaner удаляет загрузки, которые бросили и не дописывали дольше ttl.
+type Cleaner struct {
+	log      *slog.Logger
+	remover  ExpiredRemover
+	ttl      time.Duration
+	interval time.Duration
+}
+
+func New(log *slog.Logger, remover ExpiredRemover, ttl time.Duration, interval time.Duration) *Cleaner {
+	return &Cleaner{
+		log: log.With(
+			slog.String("component", "worker/uploads"),
+		),
+		remover:  remover,
+		ttl:      ttl,
+		interval: interval,
+	}
+}
+
+// Run чистит загрузки сразу и затем раз в interval, пока не отменён ctx.
+func (c *Cleaner) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		c.CleanExpired()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CleanExpired делает один проход и возвращает количество удалённых загрузок.
+func (c *Cleaner) CleanExpired() int {
+	removed, err := c.remover.RemoveExpired(time.Now().Add(-c.ttl))
+	if err != nil {
+		c.log.Error("failed remove expired uploads", sl.Err(err))
+
+		return 0
+	}
+
+	if removed > 0 {
+		c.log.Info("expired uploads removed", slog.Int("count", removed))
+	}
+
+	return removed
+}
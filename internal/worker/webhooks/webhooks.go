@@ -0,0 +1,175 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"jadesheart/comix_back/internal/lib/logger/sl"
+	"jadesheart/comix_back/internal/lib/webhook"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+)
+
+// batchSize - сколько доставок отправляется за один проход
+const batchSize = 50
+
+// maxErrorSize - сколько тела ответа с ошибкой попадает в журнал доставки
+const maxErrorSize = 512
+
+type DeliveryStore interface {
+	ClaimWebhookDeliveries(limit int, lease time.Duration) ([]postgres.WebhookDelivery, error)
+	RecordWebhookAttempt(attempt postgres.WebhookAttempt, delivered bool, retryAt time.Time) error
+}
+
+// Retry - повторы неудачных доставок: после n-й неудачи пауза Base * 2^(n-1), но не больше Max.
+// После MaxAttempts попыток доставка считается неудавшейся.
+type Retry struct {
+	MaxAttempts int
+	Base        time.Duration
+	Max         time.Duration
+}
+
+// Dispatcher отправляет доставки из outbox вебхуков с подписью и повторяет неудачные.
+type Dispatcher struct {
+	log           *slog.Logger
+	deliveryStore DeliveryStore
+	client        *http.Client
+	retry         Retry
+	interval      time.Duration
+}
+
+// New - timeout ограничивает одну попытку доставки вместе с чтением ответа.
+// Без allowPrivate доставки на loopback, частные и link-local адреса не отправляются:
+// адрес вебхука задаёт клиент API, и иначе через него можно достучаться до внутренних сервисов.
+func New(log *slog.Logger, deliveryStore DeliveryStore, timeout time.Duration, retry Retry, interval time.Duration, allowPrivate bool) *Dispatcher {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivate {
+		dialer.Control = webhook.DialPublicOnly
+	}
+
+	return &Dispatcher{
+		log: log.With(
+			slog.String("component", "worker/webhooks"),
+		),
+		deliveryStore: deliveryStore,
+		client: &http.Client{
+			Timeout: timeout,
+			// Без прокси из окружения: иначе Control проверял бы адрес прокси, а не получателя
+			Transport: &http.Transport{
+				DialContext:         dialer.DialContext,
+				TLSHandshakeTimeout: timeout,
+			},
+			// Вебхук должен отвечать сам, перенаправления не выполняются
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		retry:    retry,
+		interval: interval,
+	}
+}
+
+// Run отправляет доставки сразу и затем раз в interval, пока не отменён ctx.
+func (d *Dispatcher) Run(ctx context.Context) {
+	d.log.Info("webhook delivery started", slog.String("interval", d.interval.String()))
+
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		d.DeliverDue()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeliverDue делает один проход по outbox и возвращает количество принятых получателями доставок.
+func (d *Dispatcher) DeliverDue() int {
+	// Пока попытки идут, доставки не забирает второй экземпляр сервиса
+	lease := time.Duration(batchSize)*d.client.Timeout + time.Minute
+
+	deliveries, err := d.deliveryStore.ClaimWebhookDeliveries(batchSize, lease)
+	if err != nil {
+		d.log.Error("failed claim webhook deliveries", sl.Err(err))
+
+		return 0
+	}
+
+	delivered := 0
+
+	for _, delivery := range deliveries {
+		log := d.log.With(
+			slog.Int64("delivery", delivery.ID),
+			slog.Int("webhook", delivery.WebhookID),
+			slog.String("event", delivery.Event),
+		)
+
+		attempt := d.send(delivery)
+		ok := attempt.Error == ""
+
+		var retryAt time.Time
+		if !ok && delivery.Attempts+1 < d.retry.MaxAttempts {
+			retryAt = time.Now().Add(webhook.Backoff(delivery.Attempts+1, d.retry.Base, d.retry.Max))
+		}
+
+		err := d.deliveryStore.RecordWebhookAttempt(attempt, ok, retryAt)
+		if err != nil {
+			log.Error("failed record webhook attempt", sl.Err(err))
+		}
+
+		switch {
+		case ok:
+			delivered++
+		case retryAt.IsZero():
+			log.Warn("webhook delivery failed, no retries left", slog.String("error", attempt.Error))
+		default:
+			log.Info("webhook delivery failed, will retry", slog.String("error", attempt.Error), slog.Time("retry_at", retryAt))
+		}
+	}
+
+	return delivered
+}
+
+// send делает одну попытку доставки. Доставка принята, если получатель ответил 2xx.
+func (d *Dispatcher) send(delivery postgres.WebhookDelivery) postgres.WebhookAttempt {
+	attempt := postgres.WebhookAttempt{DeliveryID: delivery.ID}
+
+	req, err := http.NewRequest(http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		attempt.Error = err.Error()
+
+		return attempt
+	}
+
+	webhook.SetHeaders(req.Header, delivery.Secret, delivery.Event, delivery.ID, delivery.Payload, time.Now())
+
+	start := time.Now()
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		attempt.Duration = time.Since(start)
+		attempt.Error = err.Error()
+
+		return attempt
+	}
+	defer res.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(res.Body, maxErrorSize))
+
+	attempt.Duration = time.Since(start)
+	attempt.StatusCode = res.StatusCode
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		attempt.Error = fmt.Sprintf("unexpected status %d: %s", res.StatusCode, bytes.TrimSpace(body))
+	}
+
+	return attempt
+}
@@ -0,0 +1,176 @@
+package webhooks_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io"
+	"jadesheart/comix_back/internal/lib/logger/handlers/slogdiscard"
+	"jadesheart/comix_back/internal/lib/webhook"
+	"jadesheart/comix_back/internal/storage/postgres"
+	"jadesheart/comix_back/internal/worker/webhooks"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recorded struct {
+	attempt   postgres.WebhookAttempt
+	delivered bool
+	retryAt   time.Time
+}
+
+type MockDeliveryStore struct {
+	deliveries []postgres.WebhookDelivery
+	recorded   []recorded
+}
+
+func (m *MockDeliveryStore) ClaimWebhookDeliveries(limit int, lease time.Duration) ([]postgres.WebhookDelivery, error) {
+	deliveries := m.deliveries
+	m.deliveries = nil
+	return deliveries, nil
+}
+
+func (m *MockDeliveryStore) RecordWebhookAttempt(attempt postgres.WebhookAttempt, delivered bool, retryAt time.Time) error {
+	m.recorded = append(m.recorded, recorded{attempt: attempt, delivered: delivered, retryAt: retryAt})
+	return nil
+}
+
+// receiver - получатель вебхуков: проверяет подпись и отвечает статусами из statuses по очереди
+type receiver struct {
+	mu       sync.Mutex
+	secret   string
+	statuses []int
+	events   []string
+	bodies   []string
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	body, _ := io.ReadAll(r.Body)
+
+	if !webhook.Verify(rc.secret, r.Header, body, time.Minute, time.Now()) {
+		http.Error(w, "bad signature", http.StatusUnauthorized)
+
+		return
+	}
+
+	rc.events = append(rc.events, r.Header.Get(webhook.HeaderEvent))
+	rc.bodies = append(rc.bodies, string(body))
+
+	status := http.StatusOK
+	if len(rc.statuses) > 0 {
+		status = rc.statuses[0]
+		rc.statuses = rc.statuses[1:]
+	}
+
+	w.WriteHeader(status)
+}
+
+var retry = webhooks.Retry{MaxAttempts: 3, Base: time.Minute, Max: time.Hour}
+
+func delivery(url string, secret string, attempts int) postgres.WebhookDelivery {
+	return postgres.WebhookDelivery{
+		ID:        7,
+		WebhookID: 1,
+		URL:       url,
+		Secret:    secret,
+		Event:     webhook.EventComixCreated,
+		Payload:   []byte(`{"event":"comix.created","target":"comix:horror/Watchmen"}`),
+		Attempts:  attempts,
+	}
+}
+
+func TestDeliverDue_Success(t *testing.T) {
+	rc := &receiver{secret: "secret"}
+	server := httptest.NewServer(rc)
+	defer server.Close()
+
+	store := &MockDeliveryStore{deliveries: []postgres.WebhookDelivery{delivery(server.URL, "secret", 0)}}
+	dispatcher := webhooks.New(slogdiscard.NewDiscardLogger(), store, time.Second, retry, time.Minute, true)
+
+	assert.Equal(t, 1, dispatcher.DeliverDue())
+
+	assert.Equal(t, []string{"comix.created"}, rc.events)
+	assert.Equal(t, `{"event":"comix.created","target":"comix:horror/Watchmen"}`, rc.bodies[0])
+
+	assert.Len(t, store.recorded, 1)
+	assert.True(t, store.recorded[0].delivered)
+	assert.Equal(t, http.StatusOK, store.recorded[0].attempt.StatusCode)
+	assert.Empty(t, store.recorded[0].attempt.Error)
+}
+
+func TestDeliverDue_RetriesWithBackoff(t *testing.T) {
+	rc := &receiver{secret: "secret", statuses: []int{http.StatusInternalServerError}}
+	server := httptest.NewServer(rc)
+	defer server.Close()
+
+	store := &MockDeliveryStore{deliveries: []postgres.WebhookDelivery{delivery(server.URL, "secret", 1)}}
+	dispatcher := webhooks.New(slogdiscard.NewDiscardLogger(), store, time.Second, retry, time.Minute, true)
+
+	assert.Equal(t, 0, dispatcher.DeliverDue())
+
+	assert.Len(t, store.recorded, 1)
+	assert.False(t, store.recorded[0].delivered)
+	assert.Equal(t, http.StatusInternalServerError, store.recorded[0].attempt.StatusCode)
+	assert.Contains(t, store.recorded[0].attempt.Error, "500")
+	// Вторая неудача - пауза 2 * Base
+	assert.WithinDuration(t, time.Now().Add(2*time.Minute), store.recorded[0].retryAt, 5*time.Second)
+}
+
+func TestDeliverDue_GivesUp(t *testing.T) {
+	rc := &receiver{secret: "other secret"}
+	server := httptest.NewServer(rc)
+	defer server.Close()
+
+	// Получатель не принимает подпись, а попытка последняя
+	store := &MockDeliveryStore{deliveries: []postgres.WebhookDelivery{delivery(server.URL, "secret", 2)}}
+	dispatcher := webhooks.New(slogdiscard.NewDiscardLogger(), store, time.Second, retry, time.Minute, true)
+
+	assert.Equal(t, 0, dispatcher.DeliverDue())
+
+	assert.Empty(t, rc.events)
+	assert.Len(t, store.recorded, 1)
+	assert.False(t, store.recorded[0].delivered)
+	assert.Equal(t, http.StatusUnauthorized, store.recorded[0].attempt.StatusCode)
+	assert.True(t, store.recorded[0].retryAt.IsZero())
+}
+
+func TestDeliverDue_Unreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	store := &MockDeliveryStore{deliveries: []postgres.WebhookDelivery{delivery(url, "secret", 0)}}
+	dispatcher := webhooks.New(slogdiscard.NewDiscardLogger(), store, time.Second, retry, time.Minute, true)
+
+	assert.Equal(t, 0, dispatcher.DeliverDue())
+
+	assert.Len(t, store.recorded, 1)
+	assert.Equal(t, 0, store.recorded[0].attempt.StatusCode)
+	assert.NotEmpty(t, store.recorded[0].attempt.Error)
+	assert.False(t, store.recorded[0].retryAt.IsZero())
+}
+
+func TestDeliverDue_RefusesPrivateAddresses(t *testing.T) {
+	rc := &receiver{secret: "secret"}
+	server := httptest.NewServer(rc)
+	defer server.Close()
+
+	store := &MockDeliveryStore{deliveries: []postgres.WebhookDelivery{
+		delivery(server.URL, "secret", 0),
+		delivery("http://169.254.169.254/latest/meta-data/", "secret", 0),
+	}}
+	dispatcher := webhooks.New(slogdiscard.NewDiscardLogger(), store, time.Second, retry, time.Minute, false)
+
+	assert.Equal(t, 0, dispatcher.DeliverDue())
+
+	assert.Empty(t, rc.events)
+	assert.Len(t, store.recorded, 2)
+	for _, r := range store.recorded {
+		assert.False(t, r.delivered)
+		assert.Contains(t, r.attempt.Error, webhook.ErrPrivateAddress.Error())
+	}
+}